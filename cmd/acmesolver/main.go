@@ -17,6 +17,7 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"flag"
 	"log"
 
@@ -24,15 +25,16 @@ import (
 	"github.com/jetstack/cert-manager/pkg/logs"
 )
 
-// acmesolver solves ACME http-01 challenges. This is intended to run as a pod
-// in the target kubernetes cluster in order to solve challenges for
-// cert-manager.
+// acmesolver solves ACME http-01 and tls-alpn-01 challenges. This is intended
+// to run as a pod in the target kubernetes cluster in order to solve
+// challenges for cert-manager.
 
 var (
-	listenPort = flag.Int("listen-port", 8089, "the port number to listen on for connections")
-	domain     = flag.String("domain", "", "the domain name to verify")
-	token      = flag.String("token", "", "the challenge token to verify against")
-	key        = flag.String("key", "", "the challenge key to respond with")
+	listenPort    = flag.Int("listen-port", 8089, "the port number to listen on for connections")
+	challengeType = flag.String("challenge-type", "http-01", "the type of challenge to solve, either http-01 or tls-alpn-01")
+	domain        = flag.String("domain", "", "the domain name to verify")
+	token         = flag.String("token", "", "the challenge token to verify against")
+	key           = flag.String("key", "", "the challenge key to respond with")
 )
 
 func main() {
@@ -41,11 +43,25 @@ func main() {
 	flag.Parse()
 	ctx := logs.NewContext(nil, nil, "acmesolver")
 
-	s := &solver.HTTP01Solver{
-		ListenPort: *listenPort,
-		Domain:     *domain,
-		Token:      *token,
-		Key:        *key,
+	var s interface {
+		Listen(context.Context) error
+	}
+	switch *challengeType {
+	case "http-01":
+		s = &solver.HTTP01Solver{
+			ListenPort: *listenPort,
+			Domain:     *domain,
+			Token:      *token,
+			Key:        *key,
+		}
+	case "tls-alpn-01":
+		s = &solver.TLSALPN01Solver{
+			ListenPort: *listenPort,
+			Domain:     *domain,
+			Key:        *key,
+		}
+	default:
+		log.Fatalf("unsupported challenge type %q", *challengeType)
 	}
 
 	if err := s.Listen(ctx); err != nil {
@@ -33,6 +33,8 @@ import (
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
 	kubeinformers "k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
@@ -56,12 +58,14 @@ import (
 	crvenaficontroller "github.com/jetstack/cert-manager/pkg/controller/certificaterequests/venafi"
 	certificatescontroller "github.com/jetstack/cert-manager/pkg/controller/certificates"
 	"github.com/jetstack/cert-manager/pkg/controller/clusterissuers"
+	podrefreshcontroller "github.com/jetstack/cert-manager/pkg/controller/podrefresh"
 	"github.com/jetstack/cert-manager/pkg/feature"
 	dnsutil "github.com/jetstack/cert-manager/pkg/issuer/acme/dns/util"
 	logf "github.com/jetstack/cert-manager/pkg/logs"
 	"github.com/jetstack/cert-manager/pkg/metrics"
 	"github.com/jetstack/cert-manager/pkg/util"
 	utilfeature "github.com/jetstack/cert-manager/pkg/util/feature"
+	"github.com/jetstack/cert-manager/pkg/util/ingress"
 	"github.com/jetstack/cert-manager/pkg/util/kube"
 )
 
@@ -98,6 +102,14 @@ func Run(opts *options.ControllerOptions, stopCh <-chan struct{}) {
 		}...)
 	}
 
+	// the pod refresh controller watches every workload in the cluster, so
+	// it is only started by default if pod refresh is enabled globally.
+	// Otherwise it must be enabled explicitly for spec.podRefresh to be used.
+	if ctx.CertificateOptions.EnablePodRefresh && !util.Contains(opts.EnabledControllers, podrefreshcontroller.ControllerName) {
+		opts.EnabledControllers = append(opts.EnabledControllers, podrefreshcontroller.ControllerName)
+	}
+	ctx.CertificateOptions.PodRefreshControllerEnabled = util.Contains(opts.EnabledControllers, podrefreshcontroller.ControllerName)
+
 	var additionalRunFuncs []controller.RunFunc
 	run := func(_ context.Context) {
 		for n, fn := range controller.Known() {
@@ -139,6 +151,7 @@ func Run(opts *options.ControllerOptions, stopCh <-chan struct{}) {
 		log.V(4).Info("starting shared informer factories")
 		ctx.SharedInformerFactory.Start(stopCh)
 		ctx.KubeSharedInformerFactory.Start(stopCh)
+		ctx.DynamicSharedInformerFactory.Start(stopCh)
 		// start any additional controllers
 		for _, r := range additionalRunFuncs {
 			go r(stopCh)
@@ -184,6 +197,21 @@ func buildControllerContext(ctx context.Context, stopCh <-chan struct{}, opts *o
 		return nil, nil, fmt.Errorf("error creating kubernetes client: %s", err.Error())
 	}
 
+	// Create a Kubernetes dynamic client
+	dynamicCl, err := dynamic.NewForConfig(kubeCfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating dynamic client: %s", err.Error())
+	}
+
+	ingressResource, err := ingress.Discover(cl.Discovery())
+	if err != nil {
+		// fall back to the resource used before discovery was introduced so
+		// that discovery problems do not prevent cert-manager starting
+		log.Error(err, "error discovering ingress api version, falling back to the default", "resource", ingress.ExtensionsV1beta1.String())
+		ingressResource = ingress.ExtensionsV1beta1
+	}
+	log.WithValues("resource", ingressResource.String()).Info("using ingress api version")
+
 	nameservers := opts.DNS01RecursiveNameservers
 	if len(nameservers) == 0 {
 		nameservers = dnsutil.RecursiveNameservers
@@ -222,6 +250,7 @@ func buildControllerContext(ctx context.Context, stopCh <-chan struct{}, opts *o
 
 	sharedInformerFactory := informers.NewSharedInformerFactoryWithOptions(intcl, time.Second*30, informers.WithNamespace(opts.Namespace))
 	kubeSharedInformerFactory := kubeinformers.NewSharedInformerFactoryWithOptions(cl, time.Second*30, kubeinformers.WithNamespace(opts.Namespace))
+	dynamicSharedInformerFactory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(dynamicCl, time.Second*30, opts.Namespace, nil)
 	enablePodRefresh := opts.EnablePodRefresh
 	if value, ok := os.LookupEnv("POD_RESTART"); ok {
 		boolValue, err := strconv.ParseBool(value)
@@ -232,16 +261,19 @@ func buildControllerContext(ctx context.Context, stopCh <-chan struct{}, opts *o
 		enablePodRefresh = boolValue
 	}
 	return &controller.Context{
-		RootContext:               ctx,
-		StopCh:                    stopCh,
-		RESTConfig:                kubeCfg,
-		Client:                    cl,
-		CMClient:                  intcl,
-		Recorder:                  recorder,
-		KubeSharedInformerFactory: kubeSharedInformerFactory,
-		SharedInformerFactory:     sharedInformerFactory,
-		Namespace:                 opts.Namespace,
-		Clock:                     clock.RealClock{},
+		RootContext:                  ctx,
+		StopCh:                       stopCh,
+		RESTConfig:                   kubeCfg,
+		Client:                       cl,
+		CMClient:                     intcl,
+		DynamicClient:                dynamicCl,
+		Recorder:                     recorder,
+		KubeSharedInformerFactory:    kubeSharedInformerFactory,
+		SharedInformerFactory:        sharedInformerFactory,
+		DynamicSharedInformerFactory: dynamicSharedInformerFactory,
+		IngressResource:              ingressResource,
+		Namespace:                    opts.Namespace,
+		Clock:                        clock.RealClock{},
 		ACMEOptions: controller.ACMEOptions{
 			HTTP01SolverImage:                 opts.ACMEHTTP01SolverImage,
 			HTTP01SolverResourceRequestCPU:    HTTP01SolverResourceRequestCPU,
@@ -265,8 +297,10 @@ func buildControllerContext(ctx context.Context, stopCh <-chan struct{}, opts *o
 			DefaultACMEIssuerDNS01ProviderName: opts.DefaultACMEIssuerDNS01ProviderName,
 		},
 		CertificateOptions: controller.CertificateOptions{
-			EnableOwnerRef:   opts.EnableCertificateOwnerRef,
-			EnablePodRefresh: enablePodRefresh,
+			EnableOwnerRef:                  opts.EnableCertificateOwnerRef,
+			EnablePodRefresh:                enablePodRefresh,
+			PodRefreshMaxConcurrentRestarts: opts.PodRefreshMaxConcurrentRestarts,
+			PodRefreshRestartInterval:       opts.PodRefreshRestartInterval,
 		},
 		SchedulerOptions: controller.SchedulerOptions{
 			MaxConcurrentChallenges: opts.MaxConcurrentChallenges,
@@ -33,10 +33,14 @@ import (
 	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha1"
 	challengescontroller "github.com/jetstack/cert-manager/pkg/controller/acmechallenges"
 	orderscontroller "github.com/jetstack/cert-manager/pkg/controller/acmeorders"
+	acmerevocationscontroller "github.com/jetstack/cert-manager/pkg/controller/acmerevocations"
 	certificatescontroller "github.com/jetstack/cert-manager/pkg/controller/certificates"
 	clusterissuerscontroller "github.com/jetstack/cert-manager/pkg/controller/clusterissuers"
+	crlcontroller "github.com/jetstack/cert-manager/pkg/controller/crl"
 	ingressshimcontroller "github.com/jetstack/cert-manager/pkg/controller/ingress-shim"
 	issuerscontroller "github.com/jetstack/cert-manager/pkg/controller/issuers"
+	podrefreshcontroller "github.com/jetstack/cert-manager/pkg/controller/podrefresh"
+	routeshimcontroller "github.com/jetstack/cert-manager/pkg/controller/route-shim"
 	"github.com/jetstack/cert-manager/pkg/controller/webhookbootstrap"
 	"github.com/jetstack/cert-manager/pkg/util"
 )
@@ -79,8 +83,10 @@ type ControllerOptions struct {
 
 	EnableCertificateOwnerRef bool
 
-	EnablePodRefresh        bool
-	MaxConcurrentChallenges int
+	EnablePodRefresh                bool
+	PodRefreshMaxConcurrentRestarts int
+	PodRefreshRestartInterval       time.Duration
+	MaxConcurrentChallenges         int
 
 	// Namespace is the namespace the webhook CA and serving secret will be
 	// created in.
@@ -122,6 +128,9 @@ const (
 	defaultDNS01RecursiveNameserversOnly = false
 	defaultEnablePodRefresh              = false
 
+	defaultPodRefreshMaxConcurrentRestarts = 5
+	defaultPodRefreshRestartInterval       = 30 * time.Second
+
 	defaultMaxConcurrentChallenges = 60
 
 	defaultWebhookNamespace         = "cert-manager"
@@ -164,6 +173,8 @@ var (
 		orderscontroller.ControllerName,
 		challengescontroller.ControllerName,
 		webhookbootstrap.ControllerName,
+		crlcontroller.ControllerName,
+		acmerevocationscontroller.ControllerName,
 	}
 )
 
@@ -190,6 +201,8 @@ func NewControllerOptions() *ControllerOptions {
 		DNS01RecursiveNameserversOnly:      defaultDNS01RecursiveNameserversOnly,
 		EnableCertificateOwnerRef:          defaultEnableCertificateOwnerRef,
 		EnablePodRefresh:                   defaultEnablePodRefresh,
+		PodRefreshMaxConcurrentRestarts:    defaultPodRefreshMaxConcurrentRestarts,
+		PodRefreshRestartInterval:          defaultPodRefreshRestartInterval,
 	}
 }
 
@@ -223,7 +236,9 @@ func (s *ControllerOptions) AddFlags(fs *pflag.FlagSet) {
 		"of a leadership. This is only applicable if leader election is enabled.")
 
 	fs.StringSliceVar(&s.EnabledControllers, "controllers", defaultEnabledControllers, ""+
-		"The set of controllers to enable.")
+		"The set of controllers to enable. The "+routeshimcontroller.ControllerName+" controller is not "+
+		"enabled by default as it requires the OpenShift Route API to be available. The "+
+		podrefreshcontroller.ControllerName+" controller is only enabled by default if --enable-pod-refresh is set.")
 
 	fs.StringVar(&s.ACMEHTTP01SolverImage, "acme-http01-solver-image", defaultACMEHTTP01SolverImage, ""+
 		"The docker image to use to solve ACME HTTP01 challenges. You most likely will not "+
@@ -285,7 +300,15 @@ func (s *ControllerOptions) AddFlags(fs *pflag.FlagSet) {
 		"Whether to set the certificate resource as an owner of secret where the tls certificate is stored. "+
 		"When this flag is enabled, the secret will be automatically removed when the certificate resource is deleted.")
 	fs.BoolVar(&s.EnablePodRefresh, "enable-pod-refresh", defaultEnablePodRefresh, "When true, anytime a certificate is regnerated "+
-		"the pods that mount that certificate will be restarted so the services can pick up the new certificate.")
+		"the pods that mount that certificate will be restarted so the services can pick up the new certificate. "+
+		"Individual Certificates can opt in to pod refresh using spec.podRefresh regardless of this flag, "+
+		"provided the "+podrefreshcontroller.ControllerName+" controller is enabled.")
+	fs.IntVar(&s.PodRefreshMaxConcurrentRestarts, "pod-refresh-max-concurrent-restarts", defaultPodRefreshMaxConcurrentRestarts, ""+
+		"The maximum number of workloads that will be restarted within each --pod-refresh-restart-interval "+
+		"by the pod refresh controller.")
+	fs.DurationVar(&s.PodRefreshRestartInterval, "pod-refresh-restart-interval", defaultPodRefreshRestartInterval, ""+
+		"The interval over which workload restarts are staggered by the pod refresh controller. "+
+		"If zero, restarts are only limited by --pod-refresh-max-concurrent-restarts.")
 	fs.IntVar(&s.MaxConcurrentChallenges, "max-concurrent-challenges", defaultMaxConcurrentChallenges, ""+
 		"The maximum number of challenges that can be scheduled as 'processing' at once.")
 
@@ -307,6 +330,10 @@ func (o *ControllerOptions) Validate() error {
 		return fmt.Errorf("invalid default issuer kind: %v", o.DefaultIssuerKind)
 	}
 
+	if o.PodRefreshMaxConcurrentRestarts < 1 {
+		return fmt.Errorf("invalid pod refresh max concurrent restarts: %d", o.PodRefreshMaxConcurrentRestarts)
+	}
+
 	for _, server := range o.DNS01RecursiveNameservers {
 		// ensure all servers have a port number
 		host, _, err := net.SplitHostPort(server)
@@ -0,0 +1,215 @@
+/*
+Copyright 2019 The Jetstack cert-manager contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	kubeinformers "k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	_ "k8s.io/client-go/plugin/pkg/client/auth"
+	"k8s.io/utils/clock"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha1"
+	clientset "github.com/jetstack/cert-manager/pkg/client/clientset/versioned"
+	informers "github.com/jetstack/cert-manager/pkg/client/informers/externalversions"
+	logf "github.com/jetstack/cert-manager/pkg/logs"
+	"github.com/jetstack/cert-manager/pkg/ocsp/responder"
+	"github.com/jetstack/cert-manager/pkg/util"
+	"github.com/jetstack/cert-manager/pkg/util/kube"
+)
+
+const (
+	defaultListenAddress            = ":8080"
+	defaultClusterResourceNamespace = "kube-system"
+	defaultResponseValidity         = time.Hour
+)
+
+type OCSPResponderOptions struct {
+	APIServerHost string
+	ListenAddress string
+
+	IssuerName               string
+	IssuerKind               string
+	IssuerNamespace          string
+	ClusterResourceNamespace string
+
+	SignerSecretName string
+	ResponseValidity time.Duration
+}
+
+func (o *OCSPResponderOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.APIServerHost, "master", "", ""+
+		"Optional apiserver host address to connect to. If not specified, autoconfiguration "+
+		"will be attempted.")
+	fs.StringVar(&o.ListenAddress, "listen-address", defaultListenAddress, ""+
+		"The address to listen on for OCSP requests.")
+	fs.StringVar(&o.IssuerName, "issuer-name", "", ""+
+		"The name of the CA Issuer or ClusterIssuer to answer OCSP requests for.")
+	fs.StringVar(&o.IssuerKind, "issuer-kind", cmapi.IssuerKind, ""+
+		"The kind of the issuer to answer OCSP requests for, either Issuer or ClusterIssuer.")
+	fs.StringVar(&o.IssuerNamespace, "issuer-namespace", "", ""+
+		"The namespace of the Issuer to answer OCSP requests for. The OCSP signing "+
+		"certificate Secret must also be in this namespace. Only used if --issuer-kind is Issuer.")
+	fs.StringVar(&o.ClusterResourceNamespace, "cluster-resource-namespace", defaultClusterResourceNamespace, ""+
+		"The namespace cert-manager stores resources used by ClusterIssuers in. The OCSP "+
+		"signing certificate Secret must also be in this namespace. Only used if --issuer-kind "+
+		"is ClusterIssuer.")
+	fs.StringVar(&o.SignerSecretName, "signer-secret-name", "", ""+
+		"The name of the Secret containing the certificate and private key used to sign "+
+		"OCSP responses. The certificate must be issued by the CA issuer and have the "+
+		"'ocsp signing' usage.")
+	fs.DurationVar(&o.ResponseValidity, "response-validity", defaultResponseValidity, ""+
+		"How long each OCSP response is valid for.")
+}
+
+func (o *OCSPResponderOptions) Validate() error {
+	if o.IssuerName == "" {
+		return fmt.Errorf("--issuer-name must be specified")
+	}
+	switch o.IssuerKind {
+	case cmapi.IssuerKind:
+		if o.IssuerNamespace == "" {
+			return fmt.Errorf("--issuer-namespace must be specified when --issuer-kind is %s", cmapi.IssuerKind)
+		}
+	case cmapi.ClusterIssuerKind:
+	default:
+		return fmt.Errorf("--issuer-kind must be one of %s or %s", cmapi.IssuerKind, cmapi.ClusterIssuerKind)
+	}
+	if o.SignerSecretName == "" {
+		return fmt.Errorf("--signer-secret-name must be specified")
+	}
+	if o.ResponseValidity <= 0 {
+		return fmt.Errorf("--response-validity must be greater than zero")
+	}
+	return nil
+}
+
+// NewCommandStartOCSPResponder is a CLI handler for starting the OCSP responder
+func NewCommandStartOCSPResponder(stopCh <-chan struct{}) *cobra.Command {
+	o := &OCSPResponderOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "ocsp-responder",
+		Short: fmt.Sprintf("OCSP responder for cert-manager CA issuers (%s) (%s)", util.AppVersion, util.AppGitCommit),
+		Long: `
+cert-manager OCSP responder answers OCSP requests for certificates issued by
+a single CA Issuer or ClusterIssuer.
+
+The status of each certificate is determined from the CertificateRequests and
+Certificates in the cluster that reference the issuer, along with the
+certificate revocation list published by the issuer if configured. Responses
+are signed by a delegated OCSP signing certificate, which can itself be
+issued by the CA issuer using a Certificate resource.`,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Validate(); err != nil {
+				return err
+			}
+			return o.RunOCSPResponder(stopCh)
+		},
+	}
+
+	o.AddFlags(cmd.Flags())
+
+	return cmd
+}
+
+func (o *OCSPResponderOptions) RunOCSPResponder(stopCh <-chan struct{}) error {
+	ctx := util.ContextWithStopCh(context.Background(), stopCh)
+	ctx = logf.NewContext(ctx, nil, "ocsp-responder")
+	log := logf.FromContext(ctx)
+	log.Info("starting ocsp-responder", "version", util.AppVersion, "revision", util.AppGitCommit)
+
+	kubeCfg, err := kube.KubeConfig(o.APIServerHost)
+	if err != nil {
+		return fmt.Errorf("error creating rest config: %s", err.Error())
+	}
+	intcl, err := clientset.NewForConfig(kubeCfg)
+	if err != nil {
+		return fmt.Errorf("error creating internal group client: %s", err.Error())
+	}
+	cl, err := kubernetes.NewForConfig(kubeCfg)
+	if err != nil {
+		return fmt.Errorf("error creating kubernetes client: %s", err.Error())
+	}
+
+	// Certificates and CertificateRequests referencing a ClusterIssuer may
+	// be in any namespace, whereas those referencing an Issuer must be in
+	// its namespace
+	namespace := o.IssuerNamespace
+	resourceNamespace := o.IssuerNamespace
+	if o.IssuerKind == cmapi.ClusterIssuerKind {
+		namespace = ""
+		resourceNamespace = o.ClusterResourceNamespace
+	}
+
+	sharedInformerFactory := informers.NewSharedInformerFactoryWithOptions(intcl, time.Second*30, informers.WithNamespace(namespace))
+	kubeSharedInformerFactory := kubeinformers.NewSharedInformerFactoryWithOptions(cl, time.Second*30, kubeinformers.WithNamespace(namespace))
+
+	certificateRequestInformer := sharedInformerFactory.Certmanager().V1alpha1().CertificateRequests().Informer()
+	if err := certificateRequestInformer.AddIndexers(responder.CertificateRequestIndexers); err != nil {
+		return fmt.Errorf("error adding indexers to certificaterequest informer: %s", err.Error())
+	}
+	certificateInformer := sharedInformerFactory.Certmanager().V1alpha1().Certificates().Informer()
+	if err := certificateInformer.AddIndexers(responder.CertificateIndexers); err != nil {
+		return fmt.Errorf("error adding indexers to certificate informer: %s", err.Error())
+	}
+	secretInformer := kubeSharedInformerFactory.Core().V1().Secrets()
+	if err := secretInformer.Informer().AddIndexers(responder.SecretIndexers); err != nil {
+		return fmt.Errorf("error adding indexers to secret informer: %s", err.Error())
+	}
+
+	r := &responder.Responder{
+		ListenAddress:             o.ListenAddress,
+		IssuerRef:                 cmapi.ObjectReference{Name: o.IssuerName, Kind: o.IssuerKind},
+		Namespace:                 resourceNamespace,
+		SignerSecretName:          o.SignerSecretName,
+		ResponseValidity:          o.ResponseValidity,
+		IssuerLister:              sharedInformerFactory.Certmanager().V1alpha1().Issuers().Lister(),
+		SecretLister:              secretInformer.Lister(),
+		ConfigMapLister:           kubeSharedInformerFactory.Core().V1().ConfigMaps().Lister(),
+		CertificateRequestIndexer: certificateRequestInformer.GetIndexer(),
+		CertificateIndexer:        certificateInformer.GetIndexer(),
+		SecretIndexer:             secretInformer.Informer().GetIndexer(),
+		Clock:                     clock.RealClock{},
+	}
+	if o.IssuerKind == cmapi.ClusterIssuerKind {
+		r.ClusterIssuerLister = sharedInformerFactory.Certmanager().V1alpha1().ClusterIssuers().Lister()
+	}
+
+	sharedInformerFactory.Start(stopCh)
+	kubeSharedInformerFactory.Start(stopCh)
+
+	log.Info("waiting for caches to sync")
+	for typ, synced := range sharedInformerFactory.WaitForCacheSync(stopCh) {
+		if !synced {
+			return fmt.Errorf("error waiting for %v informer to sync", typ)
+		}
+	}
+	for typ, synced := range kubeSharedInformerFactory.WaitForCacheSync(stopCh) {
+		if !synced {
+			return fmt.Errorf("error waiting for %v informer to sync", typ)
+		}
+	}
+
+	return r.Listen(ctx)
+}
@@ -90,3 +90,34 @@ Create chart name and version as used by the chart label.
 {{- define "webhook.servingCertificate" -}}
 {{ printf "%s-tls" (include "webhook.fullname" .) }}
 {{- end -}}
+
+{{/*
+Expand the name of the OCSP responder.
+*/}}
+{{- define "ocspResponder.name" -}}
+{{- printf "ocsp-responder" | trunc 63 | trimSuffix "-" -}}
+{{- end -}}
+
+{{/*
+Create a default fully qualified OCSP responder name.
+We truncate at 63 chars because some Kubernetes name fields are limited to this (by the DNS naming spec).
+*/}}
+{{- define "ocspResponder.fullname" -}}
+{{- if .Values.fullnameOverride -}}
+{{- printf "%s-ocsp-responder" .Values.fullnameOverride | trunc 63 | trimSuffix "-" -}}
+{{- else -}}
+{{- $name := default .Chart.Name .Values.nameOverride -}}
+{{- if contains $name .Release.Name -}}
+{{- printf "%s-ocsp-responder" .Release.Name | trunc 63 | trimSuffix "-" -}}
+{{- else -}}
+{{- printf "%s-%s-ocsp-responder" .Release.Name $name | trunc 63 | trimSuffix "-" -}}
+{{- end -}}
+{{- end -}}
+{{- end -}}
+
+{{/*
+Create chart name and version as used by the chart label.
+*/}}
+{{- define "ocspResponder.chart" -}}
+{{- printf "%s-%s" .Chart.Name .Chart.Version | replace "+" "_" | trunc 63 | trimSuffix "-" -}}
+{{- end -}}
@@ -0,0 +1,115 @@
+/*
+Copyright 2019 The Jetstack cert-manager contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// trim-crds replaces the OpenAPI schema of each ACME challenge solver pod
+// template in the CustomResourceDefinitions generated by controller-gen with
+// one that accepts any object.
+// The pod templates embed the Kubernetes affinity and toleration types,
+// whose schemas are otherwise repeated in full for every solver type in each
+// CRD. The webhook validates pod templates when it is enabled.
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strings"
+
+	apiext "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"sigs.k8s.io/yaml"
+)
+
+// podTemplateProperty is the name of the solver pod template fields
+const podTemplateProperty = "podTemplate"
+
+var documentSeparator = regexp.MustCompile(`(?m)^---$`)
+
+func main() {
+	if len(os.Args) != 2 {
+		fmt.Fprintf(os.Stderr, "usage: %s <crds.yaml>\n", os.Args[0])
+		os.Exit(1)
+	}
+	if err := trimFile(os.Args[1]); err != nil {
+		fmt.Fprintf(os.Stderr, "error trimming CRDs: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// trimFile trims the pod template schemas of every CRD in the given
+// multi-document YAML file, leaving the document separators unchanged.
+func trimFile(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	docs := documentSeparator.Split(string(data), -1)
+	for i, doc := range docs {
+		if strings.TrimSpace(doc) == "" {
+			continue
+		}
+		crd := &apiext.CustomResourceDefinition{}
+		if err := yaml.Unmarshal([]byte(doc), crd); err != nil {
+			return err
+		}
+		if crd.Spec.Validation != nil {
+			trimPodTemplates(crd.Spec.Validation.OpenAPIV3Schema)
+		}
+		for _, v := range crd.Spec.Versions {
+			if v.Schema != nil {
+				trimPodTemplates(v.Schema.OpenAPIV3Schema)
+			}
+		}
+		out, err := yaml.Marshal(crd)
+		if err != nil {
+			return err
+		}
+		docs[i] = "\n" + string(out)
+	}
+
+	return ioutil.WriteFile(path, []byte(strings.Join(docs, "---")), 0644)
+}
+
+// trimPodTemplates replaces the schema of each pod template property within
+// the given schema.
+func trimPodTemplates(props *apiext.JSONSchemaProps) {
+	if props == nil {
+		return
+	}
+	for name, p := range props.Properties {
+		if name == podTemplateProperty {
+			preserveUnknownFields := true
+			p = apiext.JSONSchemaProps{
+				Description:            p.Description,
+				Type:                   "object",
+				XPreserveUnknownFields: &preserveUnknownFields,
+			}
+		} else {
+			trimPodTemplates(&p)
+		}
+		props.Properties[name] = p
+	}
+	if props.Items != nil {
+		trimPodTemplates(props.Items.Schema)
+		for i := range props.Items.JSONSchemas {
+			trimPodTemplates(&props.Items.JSONSchemas[i])
+		}
+	}
+	if props.AdditionalProperties != nil {
+		trimPodTemplates(props.AdditionalProperties.Schema)
+	}
+}
@@ -53,4 +53,12 @@ generate-groups-internal.sh "deepcopy,defaulter,conversion" \
   --output-base "${GOPATH}/src/" \
   --go-header-file "${runfiles}/hack/boilerplate/boilerplate.go.txt"
 
+# The version independent Ingress types are not an API group, so deepcopy-gen
+# is run directly on them. It is installed by generate-groups.sh above.
+"${GOPATH}/bin/deepcopy-gen" \
+  --input-dirs github.com/jetstack/cert-manager/pkg/util/ingress \
+  -O zz_generated.deepcopy \
+  --output-base "${GOPATH}/src/" \
+  --go-header-file "${runfiles}/hack/boilerplate/boilerplate.go.txt"
+
 update-bazel.sh
@@ -43,3 +43,6 @@ for file in $(find "${output}" -type f | sort -V); do
     cat "$file" >> "$out"
     echo "---" >> "$out"
 done
+
+echo "Trimming embedded pod template schemas"
+trim-crds "$out"
@@ -0,0 +1,72 @@
+/*
+Copyright 2019 The Jetstack cert-manager contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package acme
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"fmt"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha1"
+	"github.com/jetstack/cert-manager/pkg/util/pki"
+)
+
+// GenerateAccountPrivateKey will generate a new private key for an ACME
+// account, taking into account the privateKeyAlgorithm and privateKeySize of
+// the given ACME issuer. An RSA key is generated if no algorithm is set.
+func GenerateAccountPrivateKey(spec *cmapi.ACMEIssuer) (crypto.Signer, error) {
+	switch spec.PrivateKeyAlgorithm {
+	case cmapi.KeyAlgorithm(""), cmapi.RSAKeyAlgorithm:
+		keySize := pki.MinRSAKeySize
+		if spec.PrivateKeySize > 0 {
+			keySize = spec.PrivateKeySize
+		}
+		return pki.GenerateRSAPrivateKey(keySize)
+	case cmapi.ECDSAKeyAlgorithm:
+		keySize := pki.ECCurve256
+		if spec.PrivateKeySize > 0 {
+			keySize = spec.PrivateKeySize
+		}
+		return pki.GenerateECPrivateKey(keySize)
+	default:
+		return nil, fmt.Errorf("unsupported ACME account private key algorithm specified: %s", spec.PrivateKeyAlgorithm)
+	}
+}
+
+// AccountPrivateKeyMatches returns true if the given ACME account private
+// key is of the privateKeyAlgorithm and privateKeySize of the given ACME
+// issuer. Any key matches if no algorithm is set, and a key of any size
+// matches the algorithm if no size is set.
+func AccountPrivateKeyMatches(spec *cmapi.ACMEIssuer, pk crypto.Signer) bool {
+	var keySize int
+	switch pk := pk.(type) {
+	case *rsa.PrivateKey:
+		if spec.PrivateKeyAlgorithm != cmapi.RSAKeyAlgorithm {
+			return spec.PrivateKeyAlgorithm == ""
+		}
+		keySize = pk.N.BitLen()
+	case *ecdsa.PrivateKey:
+		if spec.PrivateKeyAlgorithm != cmapi.ECDSAKeyAlgorithm {
+			return spec.PrivateKeyAlgorithm == ""
+		}
+		keySize = pk.Curve.Params().BitSize
+	default:
+		return false
+	}
+	return spec.PrivateKeySize == 0 || spec.PrivateKeySize == keySize
+}
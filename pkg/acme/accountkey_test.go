@@ -0,0 +1,115 @@
+/*
+Copyright 2019 The Jetstack cert-manager contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package acme
+
+import (
+	"crypto"
+	"testing"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha1"
+	"github.com/jetstack/cert-manager/pkg/util/pki"
+)
+
+func TestGenerateAccountPrivateKey(t *testing.T) {
+	tests := map[string]*cmapi.ACMEIssuer{
+		"default rsa key":   {},
+		"rsa key":           {PrivateKeyAlgorithm: cmapi.RSAKeyAlgorithm, PrivateKeySize: 3072},
+		"default ecdsa key": {PrivateKeyAlgorithm: cmapi.ECDSAKeyAlgorithm},
+		"ecdsa P-384 key":   {PrivateKeyAlgorithm: cmapi.ECDSAKeyAlgorithm, PrivateKeySize: 384},
+	}
+	for name, spec := range tests {
+		t.Run(name, func(t *testing.T) {
+			pk, err := GenerateAccountPrivateKey(spec)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !AccountPrivateKeyMatches(spec, pk) {
+				t.Errorf("generated key %T does not match %s %d", pk, spec.PrivateKeyAlgorithm, spec.PrivateKeySize)
+			}
+		})
+	}
+
+	if _, err := GenerateAccountPrivateKey(&cmapi.ACMEIssuer{PrivateKeyAlgorithm: cmapi.Ed25519KeyAlgorithm}); err == nil {
+		t.Errorf("expected an error generating an ed25519 account key")
+	}
+}
+
+func TestAccountPrivateKeyMatches(t *testing.T) {
+	rsaKey, err := pki.GenerateRSAPrivateKey(2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := pki.GenerateECPrivateKey(256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edKey, err := pki.GenerateEd25519PrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		spec    cmapi.ACMEIssuer
+		key     crypto.Signer
+		matches bool
+	}{
+		"rsa key with no algorithm set": {
+			key:     rsaKey,
+			matches: true,
+		},
+		"ecdsa key with no algorithm set": {
+			key:     ecKey,
+			matches: true,
+		},
+		"rsa key with rsa algorithm and no size": {
+			spec:    cmapi.ACMEIssuer{PrivateKeyAlgorithm: cmapi.RSAKeyAlgorithm},
+			key:     rsaKey,
+			matches: true,
+		},
+		"rsa key with a different size": {
+			spec: cmapi.ACMEIssuer{PrivateKeyAlgorithm: cmapi.RSAKeyAlgorithm, PrivateKeySize: 4096},
+			key:  rsaKey,
+		},
+		"rsa key with ecdsa algorithm": {
+			spec: cmapi.ACMEIssuer{PrivateKeyAlgorithm: cmapi.ECDSAKeyAlgorithm},
+			key:  rsaKey,
+		},
+		"ecdsa key with matching size": {
+			spec:    cmapi.ACMEIssuer{PrivateKeyAlgorithm: cmapi.ECDSAKeyAlgorithm, PrivateKeySize: 256},
+			key:     ecKey,
+			matches: true,
+		},
+		"ecdsa key with a different size": {
+			spec: cmapi.ACMEIssuer{PrivateKeyAlgorithm: cmapi.ECDSAKeyAlgorithm, PrivateKeySize: 384},
+			key:  ecKey,
+		},
+		"ecdsa key with rsa algorithm": {
+			spec: cmapi.ACMEIssuer{PrivateKeyAlgorithm: cmapi.RSAKeyAlgorithm},
+			key:  ecKey,
+		},
+		"ed25519 key with no algorithm set": {
+			key: edKey,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if matches := AccountPrivateKeyMatches(&test.spec, test.key); matches != test.matches {
+				t.Errorf("expected match %t but got %t", test.matches, matches)
+			}
+		})
+	}
+}
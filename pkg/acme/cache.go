@@ -18,8 +18,9 @@ package acme
 
 import (
 	"context"
-	"crypto/rsa"
+	"crypto"
 	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"net"
 	"net/http"
@@ -39,13 +40,16 @@ import (
 // the ClientWithKey function below.
 
 // ClientWithKey will construct a new ACME client for the provided Issuer, using
-// the given RSA private key.
-func ClientWithKey(iss cmapi.GenericIssuer, pk *rsa.PrivateKey) (acme.Interface, error) {
+// the given RSA or ECDSA private key.
+func ClientWithKey(iss cmapi.GenericIssuer, pk crypto.Signer) (acme.Interface, error) {
 	acmeSpec := iss.GetSpec().ACME
 	if acmeSpec == nil {
 		return nil, fmt.Errorf("issuer %q is not an ACME issuer. Ensure the 'acme' stanza is correctly specified on your Issuer resource", iss.GetObjectMeta().Name)
 	}
-	acmeCl := lookupClient(acmeSpec, pk)
+	acmeCl, err := lookupClient(acmeSpec, pk)
+	if err != nil {
+		return nil, err
+	}
 
 	return acmemw.NewLogger(acmeCl), nil
 }
@@ -63,27 +67,28 @@ type repoKey struct {
 	skiptls   bool
 	server    string
 	publickey string
-	exponent  int
 }
 
-func lookupClient(spec *cmapi.ACMEIssuer, pk *rsa.PrivateKey) *acmecl.Client {
+func lookupClient(spec *cmapi.ACMEIssuer, pk crypto.Signer) (*acmecl.Client, error) {
+	pkbytes, err := x509.MarshalPKIXPublicKey(pk.Public())
+	if err != nil {
+		return nil, fmt.Errorf("error encoding ACME account public key: %v", err)
+	}
+
 	clientRepoMu.Lock()
 	defer clientRepoMu.Unlock()
 	if clientRepo == nil {
 		clientRepo = make(map[repoKey]*acmecl.Client)
 	}
 	repokey := repoKey{
-		skiptls: spec.SkipTLSVerify, /* #nosec G402 */
-		server:  spec.Server,
+		skiptls:   spec.SkipTLSVerify, /* #nosec G402 */
+		server:    spec.Server,
+		publickey: string(pkbytes),
 	}
-	// Encoding a big.Int cannot fail
-	pkbytes, _ := pk.PublicKey.N.GobEncode()
-	repokey.publickey = string(pkbytes)
-	repokey.exponent = pk.PublicKey.E
 
 	client := clientRepo[repokey]
 	if client != nil {
-		return client
+		return client, nil
 	}
 	acmeCl := &acmecl.Client{
 		HTTPClient:   buildHTTPClient(spec.SkipTLSVerify),
@@ -92,7 +97,7 @@ func lookupClient(spec *cmapi.ACMEIssuer, pk *rsa.PrivateKey) *acmecl.Client {
 		UserAgent:    util.CertManagerUserAgent,
 	}
 	clientRepo[repokey] = acmeCl
-	return acmeCl
+	return acmeCl, nil
 }
 
 func ClearClientCache() {
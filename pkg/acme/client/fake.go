@@ -18,6 +18,7 @@ package client
 
 import (
 	"context"
+	"crypto"
 	"fmt"
 
 	"github.com/jetstack/cert-manager/third_party/crypto/acme"
@@ -31,6 +32,7 @@ type FakeACME struct {
 	FakeCreateOrder             func(ctx context.Context, order *acme.Order) (*acme.Order, error)
 	FakeGetOrder                func(ctx context.Context, url string) (*acme.Order, error)
 	FakeGetCertificate          func(ctx context.Context, url string) ([][]byte, error)
+	FakeListCertAlternates      func(ctx context.Context, url string) ([]string, error)
 	FakeWaitOrder               func(ctx context.Context, url string) (*acme.Order, error)
 	FakeFinalizeOrder           func(ctx context.Context, finalizeURL string, csr []byte) (der [][]byte, err error)
 	FakeAcceptChallenge         func(ctx context.Context, chal *acme.Challenge) (*acme.Challenge, error)
@@ -43,6 +45,8 @@ type FakeACME struct {
 	FakeDNS01ChallengeRecord    func(token string) (string, error)
 	FakeDiscover                func(ctx context.Context) (acme.Directory, error)
 	FakeUpdateAccount           func(ctx context.Context, a *acme.Account) (*acme.Account, error)
+	FakeRevokeCert              func(ctx context.Context, key crypto.Signer, cert []byte, reason acme.CRLReasonCode) error
+	FakeAccountKeyRollover      func(ctx context.Context, newKey crypto.Signer) error
 }
 
 func (f *FakeACME) CreateOrder(ctx context.Context, order *acme.Order) (*acme.Order, error) {
@@ -66,6 +70,13 @@ func (f *FakeACME) GetCertificate(ctx context.Context, url string) ([][]byte, er
 	return nil, fmt.Errorf("GetCertificate not implemented")
 }
 
+func (f *FakeACME) ListCertAlternates(ctx context.Context, url string) ([]string, error) {
+	if f.FakeListCertAlternates != nil {
+		return f.FakeListCertAlternates(ctx, url)
+	}
+	return nil, fmt.Errorf("ListCertAlternates not implemented")
+}
+
 func (f *FakeACME) WaitOrder(ctx context.Context, url string) (*acme.Order, error) {
 	if f.FakeWaitOrder != nil {
 		return f.FakeWaitOrder(ctx, url)
@@ -151,3 +162,17 @@ func (f *FakeACME) UpdateAccount(ctx context.Context, a *acme.Account) (*acme.Ac
 	}
 	return nil, fmt.Errorf("UpdateAccount not implemented")
 }
+
+func (f *FakeACME) RevokeCert(ctx context.Context, key crypto.Signer, cert []byte, reason acme.CRLReasonCode) error {
+	if f.FakeRevokeCert != nil {
+		return f.FakeRevokeCert(ctx, key, cert, reason)
+	}
+	return fmt.Errorf("RevokeCert not implemented")
+}
+
+func (f *FakeACME) AccountKeyRollover(ctx context.Context, newKey crypto.Signer) error {
+	if f.FakeAccountKeyRollover != nil {
+		return f.FakeAccountKeyRollover(ctx, newKey)
+	}
+	return fmt.Errorf("AccountKeyRollover not implemented")
+}
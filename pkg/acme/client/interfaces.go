@@ -18,6 +18,7 @@ package client
 
 import (
 	"context"
+	"crypto"
 
 	"github.com/jetstack/cert-manager/third_party/crypto/acme"
 )
@@ -26,6 +27,7 @@ type Interface interface {
 	CreateOrder(ctx context.Context, order *acme.Order) (*acme.Order, error)
 	GetOrder(ctx context.Context, url string) (*acme.Order, error)
 	GetCertificate(ctx context.Context, url string) ([][]byte, error)
+	ListCertAlternates(ctx context.Context, url string) ([]string, error)
 	WaitOrder(ctx context.Context, url string) (*acme.Order, error)
 	FinalizeOrder(ctx context.Context, finalizeURL string, csr []byte) (der [][]byte, err error)
 	AcceptChallenge(ctx context.Context, chal *acme.Challenge) (*acme.Challenge, error)
@@ -38,6 +40,8 @@ type Interface interface {
 	DNS01ChallengeRecord(token string) (string, error)
 	Discover(ctx context.Context) (acme.Directory, error)
 	UpdateAccount(ctx context.Context, a *acme.Account) (*acme.Account, error)
+	RevokeCert(ctx context.Context, key crypto.Signer, cert []byte, reason acme.CRLReasonCode) error
+	AccountKeyRollover(ctx context.Context, newKey crypto.Signer) error
 }
 
 var _ Interface = &acme.Client{}
@@ -18,6 +18,7 @@ package middleware
 
 import (
 	"context"
+	"crypto"
 
 	"k8s.io/klog"
 
@@ -49,6 +50,11 @@ func (l *Logger) GetCertificate(ctx context.Context, url string) ([][]byte, erro
 	return l.baseCl.GetCertificate(ctx, url)
 }
 
+func (l *Logger) ListCertAlternates(ctx context.Context, url string) ([]string, error) {
+	klog.Infof("Calling ListCertAlternates")
+	return l.baseCl.ListCertAlternates(ctx, url)
+}
+
 func (l *Logger) WaitOrder(ctx context.Context, url string) (*acme.Order, error) {
 	klog.Infof("Calling WaitOrder")
 	return l.baseCl.WaitOrder(ctx, url)
@@ -108,3 +114,13 @@ func (l *Logger) UpdateAccount(ctx context.Context, a *acme.Account) (*acme.Acco
 	klog.Infof("Calling UpdateAccount")
 	return l.baseCl.UpdateAccount(ctx, a)
 }
+
+func (l *Logger) RevokeCert(ctx context.Context, key crypto.Signer, cert []byte, reason acme.CRLReasonCode) error {
+	klog.Infof("Calling RevokeCert")
+	return l.baseCl.RevokeCert(ctx, key, cert, reason)
+}
+
+func (l *Logger) AccountKeyRollover(ctx context.Context, newKey crypto.Signer) error {
+	klog.Infof("Calling AccountKeyRollover")
+	return l.baseCl.AccountKeyRollover(ctx, newKey)
+}
@@ -17,7 +17,7 @@ limitations under the License.
 package fake
 
 import (
-	"crypto/rsa"
+	"crypto"
 
 	acmepkg "github.com/jetstack/cert-manager/pkg/acme"
 	acme "github.com/jetstack/cert-manager/pkg/acme/client"
@@ -31,7 +31,7 @@ import (
 
 type Helper struct {
 	ClientForIssuerFunc func(cmapi.GenericIssuer) (acme.Interface, error)
-	ReadPrivateKeyFunc  func(cmapi.SecretKeySelector, string) (*rsa.PrivateKey, error)
+	ReadPrivateKeyFunc  func(cmapi.SecretKeySelector, string) (crypto.Signer, error)
 }
 
 var _ acmepkg.Helper = &Helper{}
@@ -40,6 +40,6 @@ func (f *Helper) ClientForIssuer(i cmapi.GenericIssuer) (acme.Interface, error)
 	return f.ClientForIssuerFunc(i)
 }
 
-func (f *Helper) ReadPrivateKey(sel cmapi.SecretKeySelector, ns string) (*rsa.PrivateKey, error) {
+func (f *Helper) ReadPrivateKey(sel cmapi.SecretKeySelector, ns string) (crypto.Signer, error) {
 	return f.ReadPrivateKeyFunc(sel, ns)
 }
@@ -17,6 +17,8 @@ limitations under the License.
 package acme
 
 import (
+	"crypto"
+	"crypto/ecdsa"
 	"crypto/rsa"
 	"fmt"
 	corelisters "k8s.io/client-go/listers/core/v1"
@@ -29,7 +31,7 @@ import (
 
 type Helper interface {
 	ClientForIssuer(iss cmapi.GenericIssuer) (acme.Interface, error)
-	ReadPrivateKey(sel cmapi.SecretKeySelector, ns string) (*rsa.PrivateKey, error)
+	ReadPrivateKey(sel cmapi.SecretKeySelector, ns string) (crypto.Signer, error)
 }
 
 // Helper is a structure that provides 'glue' between cert-managers API types and
@@ -56,9 +58,9 @@ func NewHelper(lister corelisters.SecretLister, ns string) Helper {
 // ReadPrivateKey will attempt to read and parse an ACME private key from a secret.
 // If the referenced secret or key within that secret does not exist, an error will
 // be returned.
-// A *rsa.PrivateKey will be returned here, as ACME private keys can currently
-// only be RSA.
-func (h *helperImpl) ReadPrivateKey(sel cmapi.SecretKeySelector, ns string) (*rsa.PrivateKey, error) {
+// Only RSA and ECDSA private keys can be used to sign ACME requests, so an
+// InvalidData error will be returned for keys of any other type.
+func (h *helperImpl) ReadPrivateKey(sel cmapi.SecretKeySelector, ns string) (crypto.Signer, error) {
 	sel = PrivateKeySelector(sel)
 
 	s, err := h.SecretLister.Secrets(ns).Get(sel.Name)
@@ -77,12 +79,12 @@ func (h *helperImpl) ReadPrivateKey(sel cmapi.SecretKeySelector, ns string) (*rs
 		return nil, err
 	}
 
-	rsaKey, ok := pk.(*rsa.PrivateKey)
-	if !ok {
-		return nil, cmerrors.NewInvalidData("ACME private key in %q is not of type RSA", sel.Name)
+	switch pk.(type) {
+	case *rsa.PrivateKey, *ecdsa.PrivateKey:
+		return pk, nil
+	default:
+		return nil, cmerrors.NewInvalidData("ACME private key in %q is not of type RSA or ECDSA", sel.Name)
 	}
-
-	return rsaKey, nil
 }
 
 // ClientForIssuer will return a properly configure ACME client for the given
@@ -0,0 +1,147 @@
+/*
+Copyright 2019 The Jetstack cert-manager contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package acme
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/net/publicsuffix"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha1"
+	cmclient "github.com/jetstack/cert-manager/pkg/client/clientset/versioned"
+	acmeapi "github.com/jetstack/cert-manager/third_party/crypto/acme"
+)
+
+// DefaultRateLimitBackoff is the amount of time to wait before retrying a
+// request that was rate limited by an ACME server that did not specify when
+// the request may be retried.
+const DefaultRateLimitBackoff = time.Hour
+
+// RegisteredDomain returns the registered domain that the given DNS name
+// belongs to. ACME servers such as Let's Encrypt apply most rate limits per
+// registered domain. If the registered domain cannot be determined, the DNS
+// name itself is returned.
+func RegisteredDomain(dnsName string) string {
+	dnsName = strings.ToLower(strings.TrimPrefix(dnsName, "*."))
+	domain, err := publicsuffix.EffectiveTLDPlusOne(dnsName)
+	if err != nil {
+		return dnsName
+	}
+	return domain
+}
+
+// RateLimitRetryAfter reports whether err is a rate limit error returned by
+// an ACME server, and the time after which the request may be retried.
+func RateLimitRetryAfter(err error, now time.Time) (time.Time, bool) {
+	retryAfter, ok := acmeapi.RateLimit(err)
+	if !ok {
+		return time.Time{}, false
+	}
+	if retryAfter.Before(now) {
+		retryAfter = now.Add(DefaultRateLimitBackoff)
+	}
+	return retryAfter, true
+}
+
+// RateLimitedUntil returns the time after which requests for the given DNS
+// names may be retried, as recorded on the status of the given issuer. If
+// more than one of the DNS names is rate limited, the latest time is
+// returned along with the registered domain it applies to.
+func RateLimitedUntil(iss cmapi.GenericIssuer, dnsNames []string, now time.Time) (string, time.Time, bool) {
+	status := iss.GetStatus()
+	if status == nil || status.ACME == nil {
+		return "", time.Time{}, false
+	}
+
+	domains := registeredDomains(dnsNames)
+	var limitedDomain string
+	var limitedUntil time.Time
+	for _, rl := range status.ACME.RateLimits {
+		if !domains[rl.Domain] || !rl.RetryAfter.Time.After(now) {
+			continue
+		}
+		if rl.RetryAfter.Time.After(limitedUntil) {
+			limitedDomain = rl.Domain
+			limitedUntil = rl.RetryAfter.Time
+		}
+	}
+
+	return limitedDomain, limitedUntil, limitedDomain != ""
+}
+
+// SetRateLimit records on the given status that requests for the given DNS
+// names may not be retried until retryAfter. Rate limits that expired before
+// now are removed.
+func SetRateLimit(status *cmapi.ACMEIssuerStatus, dnsNames []string, retryAfter, now time.Time) {
+	domains := registeredDomains(dnsNames)
+
+	var rateLimits []cmapi.ACMERateLimit
+	for _, rl := range status.RateLimits {
+		if domains[rl.Domain] || !rl.RetryAfter.Time.After(now) {
+			continue
+		}
+		rateLimits = append(rateLimits, rl)
+	}
+	for domain := range domains {
+		rateLimits = append(rateLimits, cmapi.ACMERateLimit{
+			Domain:     domain,
+			RetryAfter: metav1.NewTime(retryAfter),
+		})
+	}
+
+	sort.Slice(rateLimits, func(i, j int) bool {
+		return rateLimits[i].Domain < rateLimits[j].Domain
+	})
+	status.RateLimits = rateLimits
+}
+
+// RecordRateLimit records that requests for the given DNS names may not be
+// retried until retryAfter on the status of the given issuer, and persists
+// the change with the API server.
+func RecordRateLimit(cl cmclient.Interface, iss cmapi.GenericIssuer, dnsNames []string, retryAfter, now time.Time) error {
+	// TODO: replace Update calls with UpdateStatus. This requires a custom API
+	// server with the /status subresource enabled and/or subresource support
+	// for CRDs (https://github.com/kubernetes/kubernetes/issues/38113)
+	switch iss := iss.(type) {
+	case *cmapi.Issuer:
+		iss = iss.DeepCopy()
+		SetRateLimit(iss.Status.ACMEStatus(), dnsNames, retryAfter, now)
+		_, err := cl.CertmanagerV1alpha1().Issuers(iss.Namespace).Update(iss)
+		return err
+	case *cmapi.ClusterIssuer:
+		iss = iss.DeepCopy()
+		SetRateLimit(iss.Status.ACMEStatus(), dnsNames, retryAfter, now)
+		_, err := cl.CertmanagerV1alpha1().ClusterIssuers().Update(iss)
+		return err
+	}
+	return fmt.Errorf("unknown issuer type %T", iss)
+}
+
+func registeredDomains(dnsNames []string) map[string]bool {
+	domains := make(map[string]bool)
+	for _, dnsName := range dnsNames {
+		if dnsName == "" {
+			continue
+		}
+		domains[RegisteredDomain(dnsName)] = true
+	}
+	return domains
+}
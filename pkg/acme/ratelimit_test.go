@@ -0,0 +1,175 @@
+/*
+Copyright 2019 The Jetstack cert-manager contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package acme
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha1"
+	acmeapi "github.com/jetstack/cert-manager/third_party/crypto/acme"
+)
+
+func TestRegisteredDomain(t *testing.T) {
+	tests := map[string]string{
+		"example.com":            "example.com",
+		"www.example.com":        "example.com",
+		"*.example.com":          "example.com",
+		"a.b.example.co.uk":      "example.co.uk",
+		"WWW.Example.COM":        "example.com",
+		"com":                    "com",
+		"my-app.herokuapp.com":   "my-app.herokuapp.com",
+		"a.my-app.herokuapp.com": "my-app.herokuapp.com",
+	}
+	for dnsName, expected := range tests {
+		t.Run(dnsName, func(t *testing.T) {
+			if actual := RegisteredDomain(dnsName); actual != expected {
+				t.Errorf("expected registered domain %q but got %q", expected, actual)
+			}
+		})
+	}
+}
+
+func TestRateLimitRetryAfter(t *testing.T) {
+	now := time.Date(2019, time.October, 1, 12, 0, 0, 0, time.UTC)
+	retryAfter := now.Add(time.Minute * 30)
+
+	tests := map[string]struct {
+		err           error
+		expectLimited bool
+		expectedRetry time.Time
+	}{
+		"not an ACME error": {
+			err: http.ErrHandlerTimeout,
+		},
+		"not a rate limit error": {
+			err: &acmeapi.Error{StatusCode: http.StatusForbidden, Type: "urn:ietf:params:acme:error:unauthorized"},
+		},
+		"rate limit error with a Retry-After header": {
+			err: &acmeapi.Error{
+				StatusCode: http.StatusTooManyRequests,
+				Type:       "urn:ietf:params:acme:error:rateLimited",
+				Header:     http.Header{"Retry-After": []string{retryAfter.Format(http.TimeFormat)}},
+			},
+			expectLimited: true,
+			expectedRetry: retryAfter,
+		},
+		"rate limit error without a Retry-After header uses the default back-off": {
+			err: &acmeapi.Error{
+				StatusCode: http.StatusTooManyRequests,
+				Type:       "urn:ietf:params:acme:error:rateLimited",
+			},
+			expectLimited: true,
+			expectedRetry: now.Add(DefaultRateLimitBackoff),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			retry, limited := RateLimitRetryAfter(test.err, now)
+			if limited != test.expectLimited {
+				t.Fatalf("expected rate limited to be %t but got %t", test.expectLimited, limited)
+			}
+			if !retry.Equal(test.expectedRetry) {
+				t.Errorf("expected retry time %s but got %s", test.expectedRetry, retry)
+			}
+		})
+	}
+}
+
+func TestSetRateLimit(t *testing.T) {
+	now := time.Date(2019, time.October, 1, 12, 0, 0, 0, time.UTC)
+	past := metav1.NewTime(now.Add(-time.Minute))
+	future := metav1.NewTime(now.Add(time.Hour))
+	retryAfter := now.Add(time.Hour * 2)
+
+	status := &cmapi.ACMEIssuerStatus{
+		RateLimits: []cmapi.ACMERateLimit{
+			{Domain: "expired.com", RetryAfter: past},
+			{Domain: "example.com", RetryAfter: future},
+			{Domain: "other.com", RetryAfter: future},
+		},
+	}
+	SetRateLimit(status, []string{"", "www.example.com", "*.example.com", "example.org"}, retryAfter, now)
+
+	expected := []cmapi.ACMERateLimit{
+		{Domain: "example.com", RetryAfter: metav1.NewTime(retryAfter)},
+		{Domain: "example.org", RetryAfter: metav1.NewTime(retryAfter)},
+		{Domain: "other.com", RetryAfter: future},
+	}
+	if !reflect.DeepEqual(status.RateLimits, expected) {
+		t.Errorf("unexpected rate limits, expected %v but got %v", expected, status.RateLimits)
+	}
+}
+
+func TestRateLimitedUntil(t *testing.T) {
+	now := time.Date(2019, time.October, 1, 12, 0, 0, 0, time.UTC)
+	iss := &cmapi.Issuer{
+		Status: cmapi.IssuerStatus{
+			ACME: &cmapi.ACMEIssuerStatus{
+				RateLimits: []cmapi.ACMERateLimit{
+					{Domain: "expired.com", RetryAfter: metav1.NewTime(now.Add(-time.Minute))},
+					{Domain: "example.com", RetryAfter: metav1.NewTime(now.Add(time.Hour))},
+					{Domain: "example.org", RetryAfter: metav1.NewTime(now.Add(time.Hour * 2))},
+				},
+			},
+		},
+	}
+
+	tests := map[string]struct {
+		dnsNames       []string
+		expectLimited  bool
+		expectedDomain string
+		expectedRetry  time.Time
+	}{
+		"no recorded rate limits for domain": {
+			dnsNames: []string{"example.net"},
+		},
+		"expired rate limit": {
+			dnsNames: []string{"www.expired.com"},
+		},
+		"rate limited domain": {
+			dnsNames:       []string{"www.example.com"},
+			expectLimited:  true,
+			expectedDomain: "example.com",
+			expectedRetry:  now.Add(time.Hour),
+		},
+		"latest rate limit is returned": {
+			dnsNames:       []string{"example.com", "www.example.org", "example.net"},
+			expectLimited:  true,
+			expectedDomain: "example.org",
+			expectedRetry:  now.Add(time.Hour * 2),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			domain, retry, limited := RateLimitedUntil(iss, test.dnsNames, now)
+			if limited != test.expectLimited {
+				t.Fatalf("expected rate limited to be %t but got %t", test.expectLimited, limited)
+			}
+			if domain != test.expectedDomain {
+				t.Errorf("expected domain %q but got %q", test.expectedDomain, domain)
+			}
+			if !retry.Equal(test.expectedRetry) {
+				t.Errorf("expected retry time %s but got %s", test.expectedRetry, retry)
+			}
+		})
+	}
+}
@@ -60,3 +60,21 @@ func IssuerKind(ref cmapi.ObjectReference) string {
 	}
 	return ref.Kind
 }
+
+// IssuerRefersTo returns true if the given issuerRef, defined on a resource
+// in the given namespace, references iss.
+func IssuerRefersTo(ref cmapi.ObjectReference, namespace string, iss cmapi.GenericIssuer) bool {
+	if ref.Group != "" && ref.Group != cmapi.SchemeGroupVersion.Group {
+		return false
+	}
+	if ref.Name != iss.GetName() {
+		return false
+	}
+	switch iss.(type) {
+	case *cmapi.Issuer:
+		return (ref.Kind == "" || ref.Kind == cmapi.IssuerKind) && namespace == iss.GetNamespace()
+	case *cmapi.ClusterIssuer:
+		return ref.Kind == cmapi.ClusterIssuerKind
+	}
+	return false
+}
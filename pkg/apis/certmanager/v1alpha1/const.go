@@ -30,8 +30,16 @@ const (
 
 	// Default duration before certificate expiration if  Issuer.spec.renewBefore is not set
 	DefaultRenewBefore = time.Hour * 24 * 30
+
+	// default period of validity of a CRL published by a CA issuer if
+	// Issuer.spec.ca.crl.duration is not set
+	DefaultCRLDuration = time.Hour * 24
 )
 
 const (
 	ACMEFinalizer = "finalizer.acme.cert-manager.io"
+
+	// RevokeOnDeleteFinalizer is added to Certificates with revokeOnDelete
+	// set, and removed once their certificate has been revoked
+	RevokeOnDeleteFinalizer = "finalizer.revocation.acme.cert-manager.io"
 )
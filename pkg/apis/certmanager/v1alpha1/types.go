@@ -31,6 +31,32 @@ const (
 	CRPrivateKeyAnnotationKey = "certmanager.k8s.io/private-key-secret-name"
 )
 
+// Annotation names for Certificates and CertificateRequests
+const (
+	// RevocationReasonAnnotationKey marks the certificate held by a
+	// Certificate or CertificateRequest as revoked. Its value must be one of
+	// the RevocationReasons. It is removed from Certificates once the
+	// certificate stored in their Secret has been revoked, and a new
+	// certificate is then issued.
+	RevocationReasonAnnotationKey = "certmanager.k8s.io/revocation-reason"
+)
+
+// RevocationReason is the reason a certificate has been revoked, named after
+// the CRLReason values defined in RFC 5280 section 5.3.1.
+type RevocationReason string
+
+const (
+	RevocationReasonUnspecified          RevocationReason = "unspecified"
+	RevocationReasonKeyCompromise        RevocationReason = "keyCompromise"
+	RevocationReasonCACompromise         RevocationReason = "cACompromise"
+	RevocationReasonAffiliationChanged   RevocationReason = "affiliationChanged"
+	RevocationReasonSuperseded           RevocationReason = "superseded"
+	RevocationReasonCessationOfOperation RevocationReason = "cessationOfOperation"
+	RevocationReasonCertificateHold      RevocationReason = "certificateHold"
+	RevocationReasonPrivilegeWithdrawn   RevocationReason = "privilegeWithdrawn"
+	RevocationReasonAACompromise         RevocationReason = "aACompromise"
+)
+
 // ConditionStatus represents a condition's status.
 // +kubebuilder:validation:Enum=True;False;Unknown
 type ConditionStatus string
@@ -85,6 +111,22 @@ type SecretKeySelector struct {
 
 const (
 	TLSCAKey = "ca.crt"
+
+	// CRLKey is the data key used to store the PEM encoded certificate
+	// revocation list published by a CA issuer
+	CRLKey = "ca.crl"
+
+	// PKCS12SecretKey and PKCS12TruststoreKey are the data keys used to store
+	// the PKCS#12 keystore and truststore when spec.keystores.pkcs12 is set
+	// on a Certificate
+	PKCS12SecretKey     = "keystore.p12"
+	PKCS12TruststoreKey = "truststore.p12"
+
+	// JKSSecretKey and JKSTruststoreKey are the data keys used to store the
+	// JKS keystore and truststore when spec.keystores.jks is set on a
+	// Certificate
+	JKSSecretKey     = "keystore.jks"
+	JKSTruststoreKey = "truststore.jks"
 )
 
 const (
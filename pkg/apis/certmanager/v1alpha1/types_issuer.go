@@ -119,7 +119,18 @@ type VenafiTPP struct {
 	// CredentialsRef is a reference to a Secret containing the username and
 	// password for the TPP server.
 	// The secret must contain two keys, 'username' and 'password'.
-	CredentialsRef LocalObjectReference `json:"credentialsRef"`
+	// Only one of CredentialsRef or AccessTokenSecretRef may be specified.
+	// +optional
+	CredentialsRef LocalObjectReference `json:"credentialsRef,omitempty"`
+
+	// AccessTokenSecretRef is a reference to a Secret containing an OAuth
+	// access token for the TPP server.
+	// The secret must contain the key 'access-token'. If it also contains a
+	// 'refresh-token', the access token will be refreshed before it expires
+	// and the rotated tokens written back to the Secret.
+	// Only one of CredentialsRef or AccessTokenSecretRef may be specified.
+	// +optional
+	AccessTokenSecretRef *LocalObjectReference `json:"accessTokenSecretRef,omitempty"`
 
 	// CABundle is a PEM encoded TLS certifiate to use to verify connections to
 	// the TPP instance.
@@ -152,18 +163,62 @@ type VaultIssuer struct {
 	// Vault URL path to the certificate role
 	Path string `json:"path"`
 
+	// Name of the Vault Enterprise namespace that Path and the configured
+	// auth method are mounted in. It is sent in the X-Vault-Namespace header
+	// of every request made to Vault.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// SignMode selects the Vault PKI endpoint used to sign certificates, and
+	// must match the endpoint referenced by Path.
+	// If set to Sign, Path must reference a role's 'sign' endpoint. The common
+	// name and subject alternative names in the CSR are sent to Vault. CSRs
+	// requesting any other subject fields are sent to the 'sign-verbatim'
+	// endpoint of the same role instead.
+	// If set to SignVerbatim, Path must reference the 'sign-verbatim'
+	// endpoint, and the CSR is signed with its subject as-is.
+	// If not set, Sign will be used.
+	// +optional
+	SignMode VaultSignMode `json:"signMode,omitempty"`
+
 	// Base64 encoded CA bundle to validate Vault server certificate. Only used
 	// if the Server URL is using HTTPS protocol. This parameter is ignored for
 	// plain HTTP protocol connection. If not set the system root certificates
 	// are used to validate the TLS connection.
 	// +optional
 	CABundle []byte `json:"caBundle,omitempty"`
+
+	// Reference to a key in a Secret resource containing a PEM encoded CA
+	// bundle used to validate the Vault server certificate. Mutually
+	// exclusive with CABundle. If the key is not set, defaults to 'ca.crt'.
+	// +optional
+	CABundleSecretRef *SecretKeySelector `json:"caBundleSecretRef,omitempty"`
 }
 
+// VaultSignMode denotes which Vault PKI endpoint is used to sign
+// certificates.
+// +kubebuilder:validation:Enum=Sign;SignVerbatim
+type VaultSignMode string
+
+const (
+	// VaultSignModeSign signs certificates using a role's 'sign' endpoint.
+	VaultSignModeSign VaultSignMode = "Sign"
+
+	// VaultSignModeSignVerbatim signs certificates using the 'sign-verbatim'
+	// endpoint.
+	VaultSignModeSignVerbatim VaultSignMode = "SignVerbatim"
+)
+
 // Vault authentication  can be configured:
 // - With a secret containing a token. Cert-manager is using this token as-is.
 // - With a secret containing a AppRole. This AppRole is used to authenticate to
 //   Vault and retrieve a token.
+// - With a secret containing a Kubernetes ServiceAccount token. This token is
+//   used to authenticate to Vault using the Kubernetes auth method and
+//   retrieve a token.
+// - With a secret containing a TLS client certificate and private key. The
+//   keypair is presented to Vault using the TLS certificate auth method to
+//   retrieve a token.
 type VaultAuth struct {
 	// This Secret contains the Vault token key
 	// +optional
@@ -172,6 +227,16 @@ type VaultAuth struct {
 	// This Secret contains a AppRole and Secret
 	// +optional
 	AppRole VaultAppRole `json:"appRole,omitempty"`
+
+	// Kubernetes authenticates with Vault by passing the ServiceAccount
+	// token stored in the named Secret resource to the Vault server.
+	// +optional
+	Kubernetes *VaultKubernetesAuth `json:"kubernetes,omitempty"`
+
+	// ClientCertificate authenticates with Vault by presenting the TLS client
+	// certificate stored in the named Secret resource to the Vault server.
+	// +optional
+	ClientCertificate *VaultClientCertificateAuth `json:"clientCertificate,omitempty"`
 }
 
 type VaultAppRole struct {
@@ -182,12 +247,127 @@ type VaultAppRole struct {
 	SecretRef SecretKeySelector `json:"secretRef"`
 }
 
+// VaultKubernetesAuth authenticates against Vault using a Kubernetes
+// ServiceAccount token stored in a Secret.
+type VaultKubernetesAuth struct {
+	// Where the Kubernetes authentication method is mounted in Vault.
+	// If not set, defaults to 'kubernetes'.
+	// +optional
+	Path string `json:"path,omitempty"`
+
+	// A required field containing the Vault Role to assume. A Role binds a
+	// Kubernetes ServiceAccount with a set of Vault policies.
+	Role string `json:"role"`
+
+	// The required Secret field containing a Kubernetes ServiceAccount JWT
+	// used for authenticating with Vault. If the key is not set, defaults to
+	// 'token'.
+	SecretRef SecretKeySelector `json:"secretRef"`
+}
+
+// VaultClientCertificateAuth authenticates against Vault using a TLS client
+// certificate stored in a Secret.
+type VaultClientCertificateAuth struct {
+	// Where the TLS certificate authentication method is mounted in Vault.
+	// If not set, defaults to 'cert'.
+	// +optional
+	Path string `json:"path,omitempty"`
+
+	// The name of the Vault certificate role to authenticate against. If not
+	// set, Vault will try all certificate roles that match the presented
+	// client certificate.
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// The name of a Secret resource of type 'kubernetes.io/tls' containing
+	// the client certificate and private key in the 'tls.crt' and 'tls.key'
+	// keys.
+	SecretName string `json:"secretName"`
+}
+
 type CAIssuer struct {
 	// SecretName is the name of the secret used to sign Certificates issued
 	// by this Issuer.
 	SecretName string `json:"secretName"`
+
+	// ChainLayout controls which certificates from the CA Secret are
+	// appended to the signed certificate in tls.crt.
+	// If set to Leaf, only the signed certificate is returned.
+	// If set to LeafAndIntermediates, the signed certificate is followed by
+	// the certificates in the CA Secret, excluding any self-signed root.
+	// If set to FullChain, the signed certificate is followed by every
+	// certificate in the CA Secret, including the root.
+	// Regardless of this setting, ca.crt will contain the top-most
+	// certificate found in the CA Secret.
+	// If not set, LeafAndIntermediates will be used.
+	// +optional
+	ChainLayout CAChainLayout `json:"chainLayout,omitempty"`
+
+	// CRLDistributionPoints is a list of URLs from which relying parties can
+	// fetch the certificate revocation list of this CA. Each URL is added to
+	// the CRL distribution points extension of every certificate signed by
+	// this Issuer.
+	// +optional
+	CRLDistributionPoints []string `json:"crlDistributionPoints,omitempty"`
+
+	// OCSPServers is a list of URLs of OCSP responders for this CA. Each URL
+	// is added to the authority information access extension of every
+	// certificate signed by this Issuer.
+	// +optional
+	OCSPServers []string `json:"ocspServers,omitempty"`
+
+	// CRL configures publishing of a certificate revocation list for this
+	// CA. Certificates and CertificateRequests signed by this Issuer are
+	// added to the CRL once they have been annotated with
+	// certmanager.k8s.io/revocation-reason.
+	// +optional
+	CRL *CACRL `json:"crl,omitempty"`
 }
 
+// CACRL configures where and how often a CA issuer publishes its certificate
+// revocation list. Exactly one of SecretName or ConfigMapName must be set.
+// The resource is kept in the same namespace as the CA Secret, and the PEM
+// encoded CRL is stored under the ca.crl key.
+type CACRL struct {
+	// SecretName is the name of the Secret to publish the CRL to.
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+
+	// ConfigMapName is the name of the ConfigMap to publish the CRL to.
+	// +optional
+	ConfigMapName string `json:"configMapName,omitempty"`
+
+	// Duration is the period of validity of each CRL, i.e. the time between
+	// its thisUpdate and nextUpdate fields.
+	// If not set, 24 hours will be used.
+	// +optional
+	Duration *metav1.Duration `json:"duration,omitempty"`
+
+	// RenewBefore is how long before nextUpdate a new CRL will be
+	// published, even if no certificates have been revoked.
+	// If not set, a third of Duration will be used.
+	// +optional
+	RenewBefore *metav1.Duration `json:"renewBefore,omitempty"`
+}
+
+// CAChainLayout denotes which certificates from a CA issuer's Secret are
+// included alongside a signed certificate.
+// +kubebuilder:validation:Enum=Leaf;LeafAndIntermediates;FullChain
+type CAChainLayout string
+
+const (
+	// CAChainLayoutLeaf means only the signed certificate is returned.
+	CAChainLayoutLeaf CAChainLayout = "Leaf"
+
+	// CAChainLayoutLeafAndIntermediates means the signed certificate is
+	// followed by any intermediate certificates in the CA Secret.
+	CAChainLayoutLeafAndIntermediates CAChainLayout = "LeafAndIntermediates"
+
+	// CAChainLayoutFullChain means the signed certificate is followed by
+	// all certificates in the CA Secret, including the root.
+	CAChainLayoutFullChain CAChainLayout = "FullChain"
+)
+
 // ACMEIssuer contains the specification for an ACME issuer
 type ACMEIssuer struct {
 	// Email is the email for this account
@@ -201,10 +381,45 @@ type ACMEIssuer struct {
 	// +optional
 	SkipTLSVerify bool `json:"skipTLSVerify,omitempty"`
 
+	// PreferredChain is the chain to use if the ACME server outputs multiple.
+	// PreferredChain is no guarantee that this one gets delivered by the ACME
+	// endpoint.
+	// For example, for Let's Encrypt's DST crosssign you would use:
+	// "DST Root CA X3" or "ISRG Root X1" for the newer Let's Encrypt root CA.
+	// This value is matched against the issuer common name of the top-most
+	// certificate in each chain offered by the ACME server. If no chain
+	// matches, the default chain is used.
+	// +kubebuilder:validation:MaxLength=64
+	// +optional
+	PreferredChain string `json:"preferredChain,omitempty"`
+
 	// PrivateKey is the name of a secret containing the private key for this
 	// user account.
 	PrivateKey SecretKeySelector `json:"privateKeySecretRef"`
 
+	// PrivateKeyAlgorithm is the algorithm of the private key generated for
+	// this user account. Valid values are "rsa" and "ecdsa".
+	// If set and the existing private key is of a different algorithm or
+	// size, the account key will be rolled over to a newly generated key,
+	// keeping the same account.
+	// If not set, any existing RSA or ECDSA private key will be used, and
+	// an RSA key will be generated if none exists.
+	// +optional
+	PrivateKeyAlgorithm KeyAlgorithm `json:"privateKeyAlgorithm,omitempty"`
+
+	// PrivateKeySize is the size of the private key generated for this user
+	// account. For "rsa" keys it is the key size in bits, between 2048 and
+	// 8192, and defaults to 2048. For "ecdsa" keys it is the curve size,
+	// 256 or 384, and defaults to 256.
+	// +optional
+	PrivateKeySize int `json:"privateKeySize,omitempty"`
+
+	// ExternalAccountBinding is a reference to a CA external account of the
+	// ACME server. It is required by some ACME servers in order to bind a
+	// newly registered ACME account to an existing account with the CA.
+	// +optional
+	ExternalAccountBinding *ACMEExternalAccountBinding `json:"externalAccountBinding,omitempty"`
+
 	// Solvers is a list of challenge solvers that will be used to solve
 	// ACME challenges for the matching domains.
 	// +optional
@@ -219,6 +434,33 @@ type ACMEIssuer struct {
 	DNS01 *ACMEIssuerDNS01Config `json:"dns01,omitempty"`
 }
 
+// ACMEExternalAccountBinding is a reference to a CA external account of the
+// ACME server.
+type ACMEExternalAccountBinding struct {
+	// KeyID is the ID of the CA key that the External Account is bound to.
+	KeyID string `json:"keyID"`
+
+	// Key is a Secret Key Selector referencing a data item in a Kubernetes
+	// Secret which holds the symmetric MAC key of the External Account Binding.
+	// The secret key stored in the Secret must be un-padded, base64 URL
+	// encoded data.
+	Key SecretKeySelector `json:"keySecretRef"`
+
+	// KeyAlgorithm is the MAC key algorithm that the key is used for. Valid
+	// values are "HS256", "HS384" and "HS512".
+	KeyAlgorithm HMACKeyAlgorithm `json:"keyAlgorithm"`
+}
+
+// HMACKeyAlgorithm is the name of a key algorithm used for HMAC encryption
+// +kubebuilder:validation:Enum=HS256;HS384;HS512
+type HMACKeyAlgorithm string
+
+const (
+	HS256 HMACKeyAlgorithm = "HS256"
+	HS384 HMACKeyAlgorithm = "HS384"
+	HS512 HMACKeyAlgorithm = "HS512"
+)
+
 type ACMEChallengeSolver struct {
 	// Selector selects a set of DNSNames on the Certificate resource that
 	// should be solved using this challenge solver.
@@ -229,6 +471,9 @@ type ACMEChallengeSolver struct {
 
 	// +optional
 	DNS01 *ACMEChallengeSolverDNS01 `json:"dns01,omitempty"`
+
+	// +optional
+	TLSALPN01 *ACMEChallengeSolverTLSALPN01 `json:"tlsALPN01,omitempty"`
 }
 
 // CertificateDomainSelector selects certificates using a label selector, and
@@ -264,6 +509,24 @@ type CertificateDNSNameSelector struct {
 	DNSZones []string `json:"dnsZones,omitempty"`
 }
 
+// ACMEChallengeSolverTLSALPN01 contains configuration detailing how to solve
+// TLS-ALPN-01 challenges within a Kubernetes cluster.
+// Challenges are solved by 'solver pods' that answer TLS connections
+// negotiating the 'acme-tls/1' protocol with a validation certificate, as
+// described in RFC 8737. Connections to port 443 of the domain being
+// validated must be passed through to the solver Service without terminating
+// TLS.
+type ACMEChallengeSolverTLSALPN01 struct {
+	// Optional service type for Kubernetes solver service
+	// +optional
+	ServiceType corev1.ServiceType `json:"serviceType,omitempty"`
+
+	// Optional pod template used to configure the ACME challenge solver pods
+	// used for TLS-ALPN-01 challenges
+	// +optional
+	PodTemplate *ACMEChallengeSolverHTTP01IngressPodTemplate `json:"podTemplate,omitempty"`
+}
+
 // ACMEChallengeSolverHTTP01 contains configuration detailing how to solve
 // HTTP01 challenges within a Kubernetes cluster.
 // Typically this is accomplished through creating 'routes' of some description
@@ -276,6 +539,30 @@ type ACMEChallengeSolverHTTP01 struct {
 	// provisioned by cert-manager for each Challenge to be completed.
 	// +optional
 	Ingress *ACMEChallengeSolverHTTP01Ingress `json:"ingress"`
+
+	// The route based HTTP01 challenge solver will solve challenges by
+	// creating OpenShift Route resources in order to route requests for
+	// '/.well-known/acme-challenge/XYZ' to 'challenge solver' pods that are
+	// provisioned by cert-manager for each Challenge to be completed.
+	// Only one of 'ingress' or 'route' may be specified.
+	// +optional
+	Route *ACMEChallengeSolverHTTP01Route `json:"route,omitempty"`
+}
+
+type ACMEChallengeSolverHTTP01Route struct {
+	// Optional service type for Kubernetes solver service
+	// +optional
+	ServiceType corev1.ServiceType `json:"serviceType,omitempty"`
+
+	// Labels to add to the Route resources created to solve ACME challenges,
+	// for example to select which OpenShift router shard admits them.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Optional pod template used to configure the ACME challenge solver pods
+	// used for HTTP01 challenges
+	// +optional
+	PodTemplate *ACMEChallengeSolverHTTP01IngressPodTemplate `json:"podTemplate,omitempty"`
 }
 
 type ACMEChallengeSolverHTTP01Ingress struct {
@@ -284,11 +571,20 @@ type ACMEChallengeSolverHTTP01Ingress struct {
 	ServiceType corev1.ServiceType `json:"serviceType,omitempty"`
 
 	// The ingress class to use when creating Ingress resources to solve ACME
-	// challenges that use this challenge solver.
-	// Only one of 'class' or 'name' may be specified.
+	// challenges that use this challenge solver. This is set as the
+	// 'kubernetes.io/ingress.class' annotation on the created Ingress.
+	// Only one of 'class', 'ingressClassName' or 'name' may be specified.
 	// +optional
 	Class *string `json:"class,omitempty"`
 
+	// The name of the IngressClass to set in the 'spec.ingressClassName'
+	// field of Ingress resources created to solve ACME challenges that use
+	// this challenge solver. This field is ignored by Kubernetes versions
+	// prior to 1.18.
+	// Only one of 'class', 'ingressClassName' or 'name' may be specified.
+	// +optional
+	IngressClassName *string `json:"ingressClassName,omitempty"`
+
 	// The name of the ingress resource that should have ACME challenge solving
 	// routes inserted into it in order to solve HTTP01 challenges.
 	// This is typically used in conjunction with ingress controllers like
@@ -610,6 +906,24 @@ type ACMEIssuerStatus struct {
 	// associated with the  Issuer
 	// +optional
 	LastRegisteredEmail string `json:"lastRegisteredEmail,omitempty"`
+
+	// RateLimits records the registered domains for which the ACME server
+	// has rate limited requests, along with the time after which requests
+	// for each domain will be retried.
+	// +optional
+	RateLimits []ACMERateLimit `json:"rateLimits,omitempty"`
+}
+
+// ACMERateLimit records that the ACME server has rate limited requests for
+// a registered domain, i.e. a public suffix plus one label such as
+// 'example.com'.
+type ACMERateLimit struct {
+	// Domain is the registered domain that requests were rate limited for.
+	Domain string `json:"domain"`
+
+	// RetryAfter is the time after which requests for the domain will be
+	// retried.
+	RetryAfter metav1.Time `json:"retryAfter"`
 }
 
 // IssuerCondition contains condition information for an Issuer.
@@ -27,6 +27,7 @@ import (
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/clock"
 
 	"github.com/jetstack/cert-manager/pkg/acme"
 	cmclient "github.com/jetstack/cert-manager/pkg/client/clientset/versioned"
@@ -54,13 +55,16 @@ type controller struct {
 	// ACME challenge solvers are instantiated once at the time of controller
 	// construction.
 	// This also allows for easy mocking of the different challenge mechanisms.
-	dnsSolver  solver
-	httpSolver solver
+	dnsSolver     solver
+	httpSolver    solver
+	tlsALPNSolver solver
 	// scheduler marks challenges as Processing=true if they can be scheduled
 	// for processing. This job runs periodically every N seconds, so it cannot
 	// be constructed as a traditional controller.
 	scheduler *scheduler.Scheduler
 
+	// used to determine whether rate limits recorded on issuers have expired
+	clock clock.Clock
 	// used to record Events about resources to the API
 	recorder record.EventRecorder
 	// clientset used to update cert-manager API resources
@@ -91,7 +95,7 @@ func (c *controller) Register(ctx *controllerpkg.Context) (workqueue.RateLimitin
 	// cache when managing pod/service/ingress resources
 	podInformer := ctx.KubeSharedInformerFactory.Core().V1().Pods()
 	serviceInformer := ctx.KubeSharedInformerFactory.Core().V1().Services()
-	ingressInformer := ctx.KubeSharedInformerFactory.Extensions().V1beta1().Ingresses()
+	ingressInformer := ctx.DynamicSharedInformerFactory.ForResource(ctx.IngressResource)
 	// build a list of InformerSynced functions that will be returned by the Register method.
 	// the controller will only begin processing items once all of these informers have synced.
 	mustSync := []cache.InformerSynced{
@@ -124,7 +128,9 @@ func (c *controller) Register(ctx *controllerpkg.Context) (workqueue.RateLimitin
 	c.scheduler = scheduler.New(logf.NewContext(ctx.RootContext, c.log), c.challengeLister, ctx.SchedulerOptions.MaxConcurrentChallenges)
 	c.recorder = ctx.Recorder
 	c.cmClient = ctx.CMClient
+	c.clock = ctx.Clock
 	c.httpSolver = http.NewSolver(ctx)
+	c.tlsALPNSolver = http.NewTLSALPN01Solver(ctx)
 	var err error
 	c.dnsSolver, err = dns.NewSolver(ctx)
 	if err != nil {
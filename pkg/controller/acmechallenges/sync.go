@@ -195,7 +195,11 @@ func (c *controller) Sync(ctx context.Context, ch *cmapi.Challenge) (err error)
 		return nil
 	}
 
-	err = c.acceptChallenge(ctx, cl, ch)
+	if c.waitForRateLimit(ctx, genericIssuer, ch) {
+		return nil
+	}
+
+	err = c.acceptChallenge(ctx, cl, genericIssuer, ch)
 	if err != nil {
 		return err
 	}
@@ -304,7 +308,7 @@ func (c *controller) syncChallengeStatus(ctx context.Context, cl acmecl.Interfac
 // It will update the challenge's status to reflect the final state of the
 // challenge if it failed, or the final state of the challenge's authorization
 // if accepting the challenge succeeds.
-func (c *controller) acceptChallenge(ctx context.Context, cl acmecl.Interface, ch *cmapi.Challenge) error {
+func (c *controller) acceptChallenge(ctx context.Context, cl acmecl.Interface, issuer cmapi.GenericIssuer, ch *cmapi.Challenge) error {
 	log := logf.FromContext(ctx, "acceptChallenge")
 
 	log.Info("accepting challenge with ACME server")
@@ -318,6 +322,9 @@ func (c *controller) acceptChallenge(ctx context.Context, cl acmecl.Interface, c
 	if acmeChal != nil {
 		ch.Status.State = cmapi.State(acmeChal.Status)
 	}
+	if retryAfter, ok := acme.RateLimitRetryAfter(err, c.clock.Now()); ok {
+		return c.recordRateLimit(ctx, issuer, ch, retryAfter, err)
+	}
 	if err != nil {
 		log.Error(err, "error accepting challenge")
 		ch.Status.Reason = fmt.Sprintf("Error accepting challenge: %v", err)
@@ -351,12 +358,61 @@ func (c *controller) acceptChallenge(ctx context.Context, cl acmecl.Interface, c
 	return nil
 }
 
+// waitForRateLimit returns true if the ACME server has rate limited requests
+// for the Challenge's domain, in which case the Challenge is requeued to be
+// processed again once the rate limit has expired.
+func (c *controller) waitForRateLimit(ctx context.Context, issuer cmapi.GenericIssuer, ch *cmapi.Challenge) bool {
+	log := logf.FromContext(ctx)
+
+	now := c.clock.Now()
+	domain, retryAfter, limited := acme.RateLimitedUntil(issuer, []string{ch.Spec.DNSName}, now)
+	if !limited {
+		return false
+	}
+
+	log.Info("waiting for ACME rate limit to expire before accepting challenge", "domain", domain, "retry_after", retryAfter)
+	ch.Status.Reason = fmt.Sprintf("Rate limited by the ACME server for domain %q, will retry after %s", domain, retryAfter.Format(time.RFC3339))
+	c.requeueAfter(ctx, ch, retryAfter.Sub(now))
+
+	return true
+}
+
+// recordRateLimit records on the issuer that the ACME server has rate limited
+// requests for the Challenge's domain until retryAfter, and requeues the
+// Challenge to be processed again at that time.
+func (c *controller) recordRateLimit(ctx context.Context, issuer cmapi.GenericIssuer, ch *cmapi.Challenge, retryAfter time.Time, rateLimitErr error) error {
+	log := logf.FromContext(ctx)
+
+	now := c.clock.Now()
+	log.Error(rateLimitErr, "accepting challenge was rate limited by the ACME server", "retry_after", retryAfter)
+	if err := acme.RecordRateLimit(c.cmClient, issuer, []string{ch.Spec.DNSName}, retryAfter, now); err != nil {
+		return fmt.Errorf("error recording rate limit on issuer: %v", err)
+	}
+
+	c.recorder.Eventf(ch, corev1.EventTypeWarning, "RateLimited", "Rate limited by the ACME server, will retry after %s: %v", retryAfter.Format(time.RFC3339), rateLimitErr)
+	ch.Status.Reason = fmt.Sprintf("Rate limited by the ACME server, will retry after %s: %v", retryAfter.Format(time.RFC3339), rateLimitErr)
+	c.requeueAfter(ctx, ch, retryAfter.Sub(now))
+
+	return nil
+}
+
+func (c *controller) requeueAfter(ctx context.Context, ch *cmapi.Challenge, d time.Duration) {
+	key, err := controllerpkg.KeyFunc(ch)
+	if err != nil {
+		logf.FromContext(ctx).Error(err, "error computing key for Challenge")
+		return
+	}
+	c.queue.AddAfter(key, d)
+}
+
 func (c *controller) solverFor(challengeType string) (solver, error) {
 	switch challengeType {
 	case "http-01":
 		return c.httpSolver, nil
 	case "dns-01":
 		return c.dnsSolver, nil
+	case "tls-alpn-01":
+		return c.tlsALPNSolver, nil
 	}
 	return nil, fmt.Errorf("no solver for %q implemented", challengeType)
 }
@@ -19,8 +19,11 @@ package acmechallenges
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"testing"
+	"time"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	coretesting "k8s.io/client-go/testing"
 
@@ -76,6 +79,30 @@ func TestSyncHappyPath(t *testing.T) {
 			Name: "testissuer",
 		}),
 	)
+	presentedChallenge := gen.ChallengeFrom(baseChallenge,
+		gen.SetChallengeProcessing(true),
+		gen.SetChallengeURL("testurl"),
+		gen.SetChallengeDNSName("www.test.com"),
+		gen.SetChallengeState(v1alpha1.Pending),
+		gen.SetChallengeType("http-01"),
+		gen.SetChallengePresented(true),
+	)
+
+	// Retry-After headers only have a precision of one second
+	retryAfter := time.Now().Add(time.Hour).Truncate(time.Second).UTC()
+	testRateLimitErr := &acmeapi.Error{
+		StatusCode: http.StatusTooManyRequests,
+		Type:       "urn:ietf:params:acme:error:rateLimited",
+		Detail:     "too many failed authorizations recently",
+		Header:     http.Header{"Retry-After": []string{retryAfter.Format(http.TimeFormat)}},
+	}
+	testIssuerRateLimited := gen.IssuerFrom(testIssuerHTTP01Enabled,
+		gen.SetIssuerACMERateLimits(v1alpha1.ACMERateLimit{
+			Domain:     "test.com",
+			RetryAfter: metav1.NewTime(retryAfter),
+		}),
+	)
+	rateLimitedReason := fmt.Sprintf("Rate limited by the ACME server, will retry after %s: %v", retryAfter.Format(time.RFC3339), testRateLimitErr)
 
 	tests := map[string]testT{
 		"update status if state is unknown": {
@@ -195,6 +222,50 @@ func TestSyncHappyPath(t *testing.T) {
 				},
 			},
 		},
+		"record the rate limit on the issuer if accepting the challenge is rate limited": {
+			challenge: presentedChallenge,
+			httpSolver: &fakeSolver{
+				fakeCheck: func(ctx context.Context, issuer v1alpha1.GenericIssuer, ch *v1alpha1.Challenge) error {
+					return nil
+				},
+			},
+			builder: &testpkg.Builder{
+				CertManagerObjects: []runtime.Object{presentedChallenge, testIssuerHTTP01Enabled},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateAction(v1alpha1.SchemeGroupVersion.WithResource("issuers"), gen.DefaultTestNamespace, testIssuerRateLimited)),
+					testpkg.NewAction(coretesting.NewUpdateAction(v1alpha1.SchemeGroupVersion.WithResource("challenges"), gen.DefaultTestNamespace,
+						gen.ChallengeFrom(presentedChallenge,
+							gen.SetChallengeReason(rateLimitedReason),
+						))),
+				},
+				ExpectedEvents: []string{
+					"Warning RateLimited " + rateLimitedReason,
+				},
+			},
+			acmeClient: &acmecl.FakeACME{
+				FakeAcceptChallenge: func(context.Context, *acmeapi.Challenge) (*acmeapi.Challenge, error) {
+					return nil, testRateLimitErr
+				},
+			},
+		},
+		"do not accept the challenge if the issuer has recorded a rate limit for its domain": {
+			challenge: presentedChallenge,
+			httpSolver: &fakeSolver{
+				fakeCheck: func(ctx context.Context, issuer v1alpha1.GenericIssuer, ch *v1alpha1.Challenge) error {
+					return nil
+				},
+			},
+			builder: &testpkg.Builder{
+				CertManagerObjects: []runtime.Object{presentedChallenge, testIssuerRateLimited},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateAction(v1alpha1.SchemeGroupVersion.WithResource("challenges"), gen.DefaultTestNamespace,
+						gen.ChallengeFrom(presentedChallenge,
+							gen.SetChallengeReason(fmt.Sprintf(`Rate limited by the ACME server for domain "test.com", will retry after %s`, retryAfter.Format(time.RFC3339))),
+						))),
+				},
+			},
+			acmeClient: &acmecl.FakeACME{},
+		},
 		"mark certificate as failed if accepting the authorization fails": {
 			challenge: gen.ChallengeFrom(baseChallenge,
 				gen.SetChallengeProcessing(true),
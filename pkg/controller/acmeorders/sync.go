@@ -19,9 +19,11 @@ package acmeorders
 import (
 	"bytes"
 	"context"
+	"crypto/x509"
 	"encoding/pem"
 	"fmt"
 	"reflect"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -94,10 +96,21 @@ func (c *controller) Sync(ctx context.Context, o *cmapi.Order) (err error) {
 	}
 
 	if o.Status.URL == "" {
+		if c.waitForRateLimit(ctx, genericIssuer, o) {
+			return nil
+		}
+
 		log.Info("creating Order with ACME server as one does not currently exist")
 		err := c.createOrder(ctx, cl, genericIssuer, o)
 
 		if err != nil {
+			// If the ACME server has rate limited the request, we record the
+			// rate limit on the issuer and retry once it has expired rather
+			// than marking the Order as 'errored'.
+			if retryAfter, ok := acme.RateLimitRetryAfter(err, c.clock.Now()); ok {
+				return c.recordRateLimit(ctx, genericIssuer, o, retryAfter, err)
+			}
+
 			// If we get a 4xx error, we mark the Order as 'error'.
 			// 4xx error codes include rate limit errors (429).
 			// This will cause the Certificate controller to retry the Order
@@ -145,6 +158,11 @@ func (c *controller) Sync(ctx context.Context, o *cmapi.Order) (err error) {
 			return err
 		}
 
+		certs, err = c.preferredChain(ctx, cl, genericIssuer, acmeOrder.CertificateURL, certs)
+		if err != nil {
+			return err
+		}
+
 		err = c.storeCertificateOnStatus(o, certs)
 		if err != nil {
 			return err
@@ -220,6 +238,9 @@ func (c *controller) Sync(ctx context.Context, o *cmapi.Order) (err error) {
 	// if the current state is 'ready', we need to generate a CSR and finalize
 	// the order
 	case cmapi.Ready:
+		if c.waitForRateLimit(ctx, genericIssuer, o) {
+			return nil
+		}
 
 		// Due to a bug in the initial release of this controller, we previously
 		// only supported DER encoded CSRs and not PEM encoded as they are intended
@@ -253,11 +274,25 @@ func (c *controller) Sync(ctx context.Context, o *cmapi.Order) (err error) {
 		}
 
 		// check for errors from FinalizeOrder
+		if retryAfter, ok := acme.RateLimitRetryAfter(err, c.clock.Now()); ok {
+			return c.recordRateLimit(ctx, genericIssuer, o, retryAfter, err)
+		}
 		if err != nil {
 			// TODO: check for acme error type and potentially mark order as errored
 			return fmt.Errorf("error finalizing order: %v", err)
 		}
 
+		if !chainMatches(certSlice, preferredChainName(genericIssuer)) {
+			acmeOrder, err := cl.GetOrder(ctx, o.Status.URL)
+			if err != nil {
+				return err
+			}
+			certSlice, err = c.preferredChain(ctx, cl, genericIssuer, acmeOrder.CertificateURL, certSlice)
+			if err != nil {
+				return fmt.Errorf("error retrieving preferred certificate chain: %v", err)
+			}
+		}
+
 		err = c.storeCertificateOnStatus(o, certSlice)
 		if err != nil {
 			// TODO: mark Order as 'errored'
@@ -359,6 +394,57 @@ func (c *controller) Sync(ctx context.Context, o *cmapi.Order) (err error) {
 	return nil
 }
 
+// waitForRateLimit returns true if the ACME server has rate limited requests
+// for any of the Order's domains, in which case the Order is requeued to be
+// processed again once the rate limit has expired.
+func (c *controller) waitForRateLimit(ctx context.Context, issuer cmapi.GenericIssuer, o *cmapi.Order) bool {
+	log := logf.FromContext(ctx)
+
+	now := c.clock.Now()
+	domain, retryAfter, limited := acme.RateLimitedUntil(issuer, orderDNSNames(o), now)
+	if !limited {
+		return false
+	}
+
+	log.Info("waiting for ACME rate limit to expire", "domain", domain, "retry_after", retryAfter)
+	o.Status.Reason = fmt.Sprintf("Rate limited by the ACME server for domain %q, will retry after %s", domain, retryAfter.Format(time.RFC3339))
+	c.requeueAfter(ctx, o, retryAfter.Sub(now))
+
+	return true
+}
+
+// recordRateLimit records on the issuer that the ACME server has rate limited
+// requests for the Order's domains until retryAfter, and requeues the Order
+// to be processed again at that time.
+func (c *controller) recordRateLimit(ctx context.Context, issuer cmapi.GenericIssuer, o *cmapi.Order, retryAfter time.Time, rateLimitErr error) error {
+	log := logf.FromContext(ctx)
+
+	now := c.clock.Now()
+	log.Error(rateLimitErr, "request was rate limited by the ACME server", "retry_after", retryAfter)
+	if err := acme.RecordRateLimit(c.cmClient, issuer, orderDNSNames(o), retryAfter, now); err != nil {
+		return fmt.Errorf("error recording rate limit on issuer: %v", err)
+	}
+
+	c.recorder.Eventf(o, corev1.EventTypeWarning, "RateLimited", "Rate limited by the ACME server, will retry after %s: %v", retryAfter.Format(time.RFC3339), rateLimitErr)
+	o.Status.Reason = fmt.Sprintf("Rate limited by the ACME server, will retry after %s: %v", retryAfter.Format(time.RFC3339), rateLimitErr)
+	c.requeueAfter(ctx, o, retryAfter.Sub(now))
+
+	return nil
+}
+
+func (c *controller) requeueAfter(ctx context.Context, o *cmapi.Order, d time.Duration) {
+	key, err := keyFunc(o)
+	if err != nil {
+		logf.FromContext(ctx).Error(err, "error computing key for Order")
+		return
+	}
+	c.queue.AddAfter(key, d)
+}
+
+func orderDNSNames(o *cmapi.Order) []string {
+	return append([]string{o.Spec.CommonName}, o.Spec.DNSNames...)
+}
+
 func (c *controller) listChallengesForOrder(o *cmapi.Order) ([]*cmapi.Challenge, error) {
 	// create a selector that we can use to find all existing Challenges for the order
 	sel, err := challengeSelectorForOrder(o)
@@ -394,7 +480,10 @@ func (c *controller) createOrder(ctx context.Context, cl acmecl.Interface, issue
 	dbg.Info("constructed order template", "template", orderTemplate)
 	acmeOrder, err := cl.CreateOrder(ctx, orderTemplate)
 	if err != nil {
-		return fmt.Errorf("error creating new order: %v", err)
+		log.Error(err, "error creating new order")
+		// the error is returned unwrapped so that ACME errors (including rate
+		// limit errors) can be handled by the caller
+		return err
 	}
 
 	log.Info("submitted Order to ACME server")
@@ -463,6 +552,8 @@ func challengeSpecForAuthorization(ctx context.Context, cl acmecl.Interface, iss
 				return ch
 			case ch.Type == "dns-01" && solver.DNS01 != nil:
 				return ch
+			case ch.Type == "tls-alpn-01" && solver.TLSALPN01 != nil:
+				return ch
 			}
 		}
 		return nil
@@ -677,6 +768,10 @@ func keyForChallenge(cl acmecl.Interface, challenge *acmeapi.Challenge) (string,
 		return cl.HTTP01ChallengeResponse(challenge.Token)
 	case "dns-01":
 		return cl.DNS01ChallengeRecord(challenge.Token)
+	case "tls-alpn-01":
+		// the tls-alpn-01 solver derives the acmeIdentifier extension from
+		// the key authorization, which is the same as the http-01 response
+		return cl.HTTP01ChallengeResponse(challenge.Token)
 	default:
 		err = fmt.Errorf("unsupported challenge type %s", challenge.Type)
 	}
@@ -787,6 +882,60 @@ func (c *controller) setOrderState(o *cmapi.OrderStatus, s cmapi.State) {
 	}
 }
 
+// preferredChain returns the certificate chain for the given certificate URL
+// whose top-most certificate is issued by the issuer's configured preferred
+// chain. If the default chain already matches, or none of the alternate
+// chains offered by the ACME server match, the default chain is returned.
+func (c *controller) preferredChain(ctx context.Context, cl acmecl.Interface, issuer cmapi.GenericIssuer, certURL string, defaultChain [][]byte) ([][]byte, error) {
+	name := preferredChainName(issuer)
+	if chainMatches(defaultChain, name) {
+		return defaultChain, nil
+	}
+
+	log := logf.FromContext(ctx)
+	alternates, err := cl.ListCertAlternates(ctx, certURL)
+	if err != nil {
+		return nil, err
+	}
+	for _, altURL := range alternates {
+		altChain, err := cl.GetCertificate(ctx, altURL)
+		if err != nil {
+			return nil, err
+		}
+		if chainMatches(altChain, name) {
+			log.V(logf.DebugLevel).Info("using alternate certificate chain", "preferred_chain", name, "url", altURL)
+			return altChain, nil
+		}
+	}
+
+	log.Info("no certificate chain matched the preferred chain, using the default chain", "preferred_chain", name)
+	return defaultChain, nil
+}
+
+func preferredChainName(issuer cmapi.GenericIssuer) string {
+	if issuer.GetSpec().ACME == nil {
+		return ""
+	}
+	return issuer.GetSpec().ACME.PreferredChain
+}
+
+// chainMatches returns true if name is empty, or if the top-most certificate
+// in the DER encoded chain has an issuer common name equal to name.
+// Chains that cannot be parsed never match a non-empty name.
+func chainMatches(chain [][]byte, name string) bool {
+	if name == "" {
+		return true
+	}
+	if len(chain) == 0 {
+		return false
+	}
+	cert, err := x509.ParseCertificate(chain[len(chain)-1])
+	if err != nil {
+		return false
+	}
+	return cert.Issuer.CommonName == name
+}
+
 func (c *controller) storeCertificateOnStatus(o *cmapi.Order, certs [][]byte) error {
 	// encode the retrieved certificates (including the chain)
 	certBuffer := bytes.NewBuffer([]byte{})
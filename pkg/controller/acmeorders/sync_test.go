@@ -17,7 +17,17 @@ limitations under the License.
 package acmeorders
 
 import (
+	"bytes"
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
 	"reflect"
 	"testing"
 	"time"
@@ -147,6 +157,28 @@ dGVzdA==
 	*testACMEOrderInvalid = *testACMEOrderPending
 	testACMEOrderInvalid.Status = acmeapi.StatusInvalid
 
+	// Retry-After headers only have a precision of one second
+	retryAfter := nowTime.Add(time.Hour).Truncate(time.Second).UTC()
+	testRateLimitErr := &acmeapi.Error{
+		StatusCode: http.StatusTooManyRequests,
+		Type:       "urn:ietf:params:acme:error:rateLimited",
+		Detail:     "too many certificates already issued for: test.com",
+		Header:     http.Header{"Retry-After": []string{retryAfter.Format(http.TimeFormat)}},
+	}
+	testIssuerRateLimited := gen.IssuerFrom(testIssuerHTTP01Enabled,
+		gen.SetIssuerACMERateLimits(v1alpha1.ACMERateLimit{
+			Domain:     "test.com",
+			RetryAfter: metav1.NewTime(retryAfter),
+		}),
+	)
+	rateLimitedReason := fmt.Sprintf("Rate limited by the ACME server, will retry after %s: %v", retryAfter.Format(time.RFC3339), testRateLimitErr)
+	testOrderRateLimited := testOrder.DeepCopy()
+	testOrderRateLimited.Status.Reason = rateLimitedReason
+	testOrderWaitingForRateLimit := testOrder.DeepCopy()
+	testOrderWaitingForRateLimit.Status.Reason = fmt.Sprintf(`Rate limited by the ACME server for domain "test.com", will retry after %s`, retryAfter.Format(time.RFC3339))
+	testOrderReadyRateLimited := testOrderReady.DeepCopy()
+	testOrderReadyRateLimited.Status.Reason = rateLimitedReason
+
 	tests := map[string]testT{
 		"create a new order with the acme server, set the order url on the status resource and return nil to avoid cache timing issues": {
 			order: testOrder,
@@ -170,6 +202,55 @@ dGVzdA==
 				},
 			},
 		},
+		"record the rate limit on the issuer and leave the order pending if creating the order is rate limited": {
+			order: testOrder,
+			builder: &testpkg.Builder{
+				CertManagerObjects: []runtime.Object{testIssuerHTTP01Enabled, testOrder},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateAction(v1alpha1.SchemeGroupVersion.WithResource("issuers"), testIssuerRateLimited.Namespace, testIssuerRateLimited)),
+					testpkg.NewAction(coretesting.NewUpdateAction(v1alpha1.SchemeGroupVersion.WithResource("orders"), testOrderRateLimited.Namespace, testOrderRateLimited)),
+				},
+				ExpectedEvents: []string{
+					"Warning RateLimited " + rateLimitedReason,
+				},
+			},
+			acmeClient: &acmecl.FakeACME{
+				FakeCreateOrder: func(ctx context.Context, o *acmeapi.Order) (*acmeapi.Order, error) {
+					return nil, testRateLimitErr
+				},
+			},
+		},
+		"do not create the order if the issuer has recorded a rate limit for its domain": {
+			order: testOrder,
+			builder: &testpkg.Builder{
+				CertManagerObjects: []runtime.Object{testIssuerRateLimited, testOrder},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateAction(v1alpha1.SchemeGroupVersion.WithResource("orders"), testOrderWaitingForRateLimit.Namespace, testOrderWaitingForRateLimit)),
+				},
+			},
+			acmeClient: &acmecl.FakeACME{},
+		},
+		"record the rate limit on the issuer if finalizing the order is rate limited": {
+			order: testOrderReady,
+			builder: &testpkg.Builder{
+				CertManagerObjects: []runtime.Object{testIssuerHTTP01Enabled, testOrderReady, testAuthorizationChallengeValid},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateAction(v1alpha1.SchemeGroupVersion.WithResource("issuers"), testIssuerRateLimited.Namespace, testIssuerRateLimited)),
+					testpkg.NewAction(coretesting.NewUpdateAction(v1alpha1.SchemeGroupVersion.WithResource("orders"), testOrderReadyRateLimited.Namespace, testOrderReadyRateLimited)),
+				},
+				ExpectedEvents: []string{
+					"Warning RateLimited " + rateLimitedReason,
+				},
+			},
+			acmeClient: &acmecl.FakeACME{
+				FakeGetOrder: func(_ context.Context, url string) (*acmeapi.Order, error) {
+					return testACMEOrderReady, nil
+				},
+				FakeFinalizeOrder: func(_ context.Context, url string, csr []byte) ([][]byte, error) {
+					return nil, testRateLimitErr
+				},
+			},
+		},
 		"create a challenge resource for the test.com dnsName on the order": {
 			order: testOrderPending,
 			builder: &testpkg.Builder{
@@ -336,6 +417,179 @@ dGVzdA==
 	}
 }
 
+func TestSyncPreferredChain(t *testing.T) {
+	testIssuer := gen.Issuer("testissuer", gen.SetIssuerACME(v1alpha1.ACMEIssuer{
+		HTTP01:         &v1alpha1.ACMEIssuerHTTP01Config{},
+		PreferredChain: "Alternate Root",
+	}))
+
+	defaultChain := generateTestChain(t, "Default Root")
+	alternateChain := generateTestChain(t, "Alternate Root")
+	otherChain := generateTestChain(t, "Other Root")
+	chains := map[string][][]byte{
+		"http://testurl.com/cert":       defaultChain,
+		"http://testurl.com/cert/alt/1": otherChain,
+		"http://testurl.com/cert/alt/2": alternateChain,
+	}
+
+	testOrderReady := &v1alpha1.Order{
+		ObjectMeta: metav1.ObjectMeta{Name: "testorder", Namespace: gen.DefaultTestNamespace},
+		Spec: v1alpha1.OrderSpec{
+			CommonName: "test.com",
+			IssuerRef: v1alpha1.ObjectReference{
+				Name: "testissuer",
+			},
+		},
+		Status: v1alpha1.OrderStatus{
+			State:       v1alpha1.Ready,
+			URL:         "http://testurl.com/abcde",
+			FinalizeURL: "http://testurl.com/abcde/finalize",
+		},
+	}
+	testOrderValidNoCert := testOrderReady.DeepCopy()
+	testOrderValidNoCert.Status.State = v1alpha1.Valid
+	testOrderValidAlternate := testOrderValidNoCert.DeepCopy()
+	testOrderValidAlternate.Status.Certificate = pemEncodeChain(alternateChain)
+	testOrderValidDefault := testOrderValidNoCert.DeepCopy()
+	testOrderValidDefault.Status.Certificate = pemEncodeChain(defaultChain)
+
+	testACMEOrderValid := &acmeapi.Order{
+		URL:            testOrderReady.Status.URL,
+		FinalizeURL:    testOrderReady.Status.FinalizeURL,
+		CertificateURL: "http://testurl.com/cert",
+		Status:         acmeapi.StatusValid,
+	}
+	getOrder := func(_ context.Context, url string) (*acmeapi.Order, error) {
+		return testACMEOrderValid, nil
+	}
+	getCertificate := func(_ context.Context, url string) ([][]byte, error) {
+		chain, ok := chains[url]
+		if !ok {
+			return nil, fmt.Errorf("unexpected certificate URL %q", url)
+		}
+		return chain, nil
+	}
+
+	tests := map[string]testT{
+		"store the preferred alternate chain after finalizing the order": {
+			order: testOrderReady,
+			builder: &testpkg.Builder{
+				CertManagerObjects: []runtime.Object{testIssuer, testOrderReady},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateAction(v1alpha1.SchemeGroupVersion.WithResource("orders"), testOrderValidAlternate.Namespace, testOrderValidAlternate)),
+				},
+				ExpectedEvents: []string{
+					"Normal OrderValid Order completed successfully",
+				},
+			},
+			acmeClient: &acmecl.FakeACME{
+				FakeGetOrder: getOrder,
+				FakeFinalizeOrder: func(_ context.Context, url string, csr []byte) ([][]byte, error) {
+					return defaultChain, nil
+				},
+				FakeListCertAlternates: func(_ context.Context, url string) ([]string, error) {
+					return []string{"http://testurl.com/cert/alt/1", "http://testurl.com/cert/alt/2"}, nil
+				},
+				FakeGetCertificate: getCertificate,
+			},
+		},
+		"do not list alternate chains if the default chain is the preferred chain": {
+			order: testOrderReady,
+			builder: &testpkg.Builder{
+				CertManagerObjects: []runtime.Object{testIssuer, testOrderReady},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateAction(v1alpha1.SchemeGroupVersion.WithResource("orders"), testOrderValidAlternate.Namespace, testOrderValidAlternate)),
+				},
+				ExpectedEvents: []string{
+					"Normal OrderValid Order completed successfully",
+				},
+			},
+			acmeClient: &acmecl.FakeACME{
+				FakeGetOrder: getOrder,
+				FakeFinalizeOrder: func(_ context.Context, url string, csr []byte) ([][]byte, error) {
+					return alternateChain, nil
+				},
+			},
+		},
+		"store the default chain if no alternate chain matches the preferred chain": {
+			order: testOrderValidNoCert,
+			builder: &testpkg.Builder{
+				CertManagerObjects: []runtime.Object{testIssuer, testOrderValidNoCert},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateAction(v1alpha1.SchemeGroupVersion.WithResource("orders"), testOrderValidDefault.Namespace, testOrderValidDefault)),
+				},
+				ExpectedEvents: []string{
+					"Normal OrderValid Order completed successfully",
+				},
+			},
+			acmeClient: &acmecl.FakeACME{
+				FakeGetOrder: getOrder,
+				FakeListCertAlternates: func(_ context.Context, url string) ([]string, error) {
+					return []string{"http://testurl.com/cert/alt/1"}, nil
+				},
+				FakeGetCertificate: getCertificate,
+			},
+		},
+		"return an error if an alternate chain cannot be retrieved": {
+			order: testOrderValidNoCert,
+			builder: &testpkg.Builder{
+				CertManagerObjects: []runtime.Object{testIssuer, testOrderValidNoCert},
+				ExpectedActions:    []testpkg.Action{},
+			},
+			acmeClient: &acmecl.FakeACME{
+				FakeGetOrder: getOrder,
+				FakeListCertAlternates: func(_ context.Context, url string) ([]string, error) {
+					return []string{"http://testurl.com/cert/alt/3"}, nil
+				},
+				FakeGetCertificate: getCertificate,
+			},
+			expectErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			runTest(t, test)
+		})
+	}
+}
+
+// generateTestChain returns a DER encoded chain containing a single
+// certificate issued by a CA with the given common name.
+func generateTestChain(t *testing.T, issuerCN string) [][]byte {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leafKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: issuerCN},
+	}
+	leaf := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "test.com"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, leaf, ca, &leafKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return [][]byte{der}
+}
+
+func pemEncodeChain(chain [][]byte) []byte {
+	var buf bytes.Buffer
+	for _, der := range chain {
+		pem.Encode(&buf, &pem.Block{Type: "CERTIFICATE", Bytes: der})
+	}
+	return buf.Bytes()
+}
+
 type testT struct {
 	order      *v1alpha1.Order
 	builder    *testpkg.Builder
@@ -418,6 +672,9 @@ func TestChallengeSpecForAuthorization(t *testing.T) {
 			},
 		},
 	}
+	emptySelectorSolverTLSALPN01 := v1alpha1.ACMEChallengeSolver{
+		TLSALPN01: &v1alpha1.ACMEChallengeSolverTLSALPN01{},
+	}
 	// define ACME challenges that are used during tests
 	acmeChallengeHTTP01 := &acmeapi.Challenge{
 		Type:  "http-01",
@@ -427,6 +684,10 @@ func TestChallengeSpecForAuthorization(t *testing.T) {
 		Type:  "dns-01",
 		Token: "dns-01-token",
 	}
+	acmeChallengeTLSALPN01 := &acmeapi.Challenge{
+		Type:  "tls-alpn-01",
+		Token: "tls-alpn-01-token",
+	}
 
 	tests := map[string]struct {
 		acmeClient acmecl.Interface
@@ -467,6 +728,36 @@ func TestChallengeSpecForAuthorization(t *testing.T) {
 				Solver:  &emptySelectorSolverHTTP01,
 			},
 		},
+		"should use a tls-alpn-01 solver for a tls-alpn-01 challenge": {
+			acmeClient: basicACMEClient,
+			issuer: &v1alpha1.Issuer{
+				Spec: v1alpha1.IssuerSpec{
+					IssuerConfig: v1alpha1.IssuerConfig{
+						ACME: &v1alpha1.ACMEIssuer{
+							Solvers: []v1alpha1.ACMEChallengeSolver{emptySelectorSolverTLSALPN01},
+						},
+					},
+				},
+			},
+			order: &v1alpha1.Order{
+				Spec: v1alpha1.OrderSpec{
+					DNSNames: []string{"example.com"},
+				},
+			},
+			authz: &acmeapi.Authorization{
+				Identifier: acmeapi.AuthzID{
+					Value: "example.com",
+				},
+				Challenges: []*acmeapi.Challenge{acmeChallengeHTTP01, acmeChallengeDNS01, acmeChallengeTLSALPN01},
+			},
+			expectedChallengeSpec: &v1alpha1.ChallengeSpec{
+				Type:    "tls-alpn-01",
+				DNSName: "example.com",
+				Token:   acmeChallengeTLSALPN01.Token,
+				Key:     "http01",
+				Solver:  &emptySelectorSolverTLSALPN01,
+			},
+		},
 		"should use configured default solver when no others are present but selector is non-nil": {
 			acmeClient: basicACMEClient,
 			issuer: &v1alpha1.Issuer{
@@ -0,0 +1,236 @@
+/*
+Copyright 2019 The Jetstack cert-manager contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package acmerevocations
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+
+	apiutil "github.com/jetstack/cert-manager/pkg/api/util"
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha1"
+	logf "github.com/jetstack/cert-manager/pkg/logs"
+	"github.com/jetstack/cert-manager/pkg/util/pki"
+	acmeapi "github.com/jetstack/cert-manager/third_party/crypto/acme"
+)
+
+const (
+	reasonRevoked          = "Revoked"
+	reasonRevocationFailed = "RevocationFailed"
+
+	errorTypeAlreadyRevoked = "urn:ietf:params:acme:error:alreadyRevoked"
+)
+
+// Sync revokes the certificate stored in the Secret of the given Certificate
+// if it has been annotated with a revocation reason, or if it is being
+// deleted and has spec.revokeOnDelete set.
+// The revocation reason annotation is removed once the certificate has been
+// revoked, after which the certificates controller issues a new certificate
+// using a new private key.
+// The finalizer that blocks deletion of the Certificate until its
+// certificate has been revoked is added and removed here too.
+func (c *controller) Sync(ctx context.Context, crt *cmapi.Certificate) error {
+	log := logf.FromContext(ctx)
+
+	old := crt
+	crt = crt.DeepCopy()
+
+	deleting := crt.DeletionTimestamp != nil
+	finalized := hasFinalizer(crt)
+	reason, annotated := crt.Annotations[cmapi.RevocationReasonAnnotationKey]
+	if deleting {
+		if !finalized {
+			return nil
+		}
+		if !annotated {
+			reason = string(cmapi.RevocationReasonCessationOfOperation)
+		}
+	} else if !annotated && crt.Spec.RevokeOnDelete == finalized {
+		return nil
+	}
+
+	iss, err := c.helper.GetGenericIssuer(crt.Spec.IssuerRef, crt.Namespace)
+	if err != nil && !k8sErrors.IsNotFound(err) {
+		return err
+	}
+	// certificates issued by other issuers cannot be revoked here, so make
+	// sure deletion is not blocked
+	if err != nil || iss.GetSpec().ACME == nil {
+		if !finalized || (!deleting && err != nil) {
+			log.V(logf.DebugLevel).Info("certificate does not reference an ACME issuer, not revoking")
+			return nil
+		}
+		if deleting {
+			c.recorder.Eventf(crt, corev1.EventTypeWarning, reasonRevocationFailed, "Certificate was not revoked as issuer %q does not exist or is not an ACME issuer", crt.Spec.IssuerRef.Name)
+		}
+		removeFinalizer(crt)
+		return c.updateCertificate(old, crt)
+	}
+
+	if !deleting && crt.Spec.RevokeOnDelete != finalized {
+		if crt.Spec.RevokeOnDelete {
+			crt.Finalizers = append(crt.Finalizers, cmapi.RevokeOnDeleteFinalizer)
+		} else {
+			removeFinalizer(crt)
+		}
+		// any revocation annotation will be handled once the update has
+		// been observed
+		return c.updateCertificate(old, crt)
+	}
+
+	if err := c.revoke(ctx, iss, crt, cmapi.RevocationReason(reason)); err != nil {
+		return err
+	}
+	if deleting {
+		removeFinalizer(crt)
+	} else {
+		// the annotation only applies to the certificate that was current
+		// when it was set. Removing it ensures the certificate issued to
+		// replace a revoked one is not revoked in turn.
+		delete(crt.Annotations, cmapi.RevocationReasonAnnotationKey)
+	}
+
+	return c.updateCertificate(old, crt)
+}
+
+// revoke revokes the certificate currently stored in the Certificate's
+// Secret with the ACME server, unless an attempt has already been made to
+// revoke it. The outcome is recorded in the Certificate's status and as an
+// Event. An error is only returned if the attempt should be retried.
+func (c *controller) revoke(ctx context.Context, iss cmapi.GenericIssuer, crt *cmapi.Certificate, reason cmapi.RevocationReason) error {
+	log := logf.FromContext(ctx)
+
+	secret, err := c.secretLister.Secrets(crt.Namespace).Get(crt.Spec.SecretName)
+	if k8sErrors.IsNotFound(err) {
+		log.Info("secret does not exist, no certificate to revoke")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	cert, err := pki.DecodeX509CertificateBytes(secret.Data[corev1.TLSCertKey])
+	if err != nil {
+		log.Error(err, "error decoding certificate to revoke")
+		return nil
+	}
+
+	serialNumber := cert.SerialNumber.String()
+	log = log.WithValues("serial_number", serialNumber)
+	if serialNumber == crt.Status.RevocationSerialNumber {
+		log.V(logf.DebugLevel).Info("certificate has already been revoked")
+		return nil
+	}
+
+	code, err := pki.RevocationReasonCode(reason)
+	if err != nil {
+		// this should be prevented by validation
+		log.Error(err, "invalid revocation reason")
+		return nil
+	}
+
+	cl, err := c.acmeHelper.ClientForIssuer(iss)
+	if err != nil {
+		return err
+	}
+
+	key := certificateKey(secret, cert)
+	if key != nil {
+		log.V(logf.DebugLevel).Info("revoking certificate using its private key")
+	} else {
+		log.V(logf.DebugLevel).Info("revoking certificate using the ACME account key")
+	}
+
+	err = cl.RevokeCert(ctx, key, cert.Raw, acmeapi.CRLReasonCode(code))
+	if acmeErr, ok := err.(*acmeapi.Error); ok && acmeErr.Type == errorTypeAlreadyRevoked {
+		err = nil
+	}
+	if err != nil {
+		// rate limit and server errors may be resolved by retrying
+		acmeErr, ok := err.(*acmeapi.Error)
+		if !ok || acmeErr.StatusCode == http.StatusTooManyRequests || acmeErr.StatusCode >= 500 {
+			return err
+		}
+
+		log.Error(err, "ACME server refused to revoke certificate")
+		crt.Status.RevocationSerialNumber = serialNumber
+		message := fmt.Sprintf("Failed to revoke certificate with serial number %s: %v", serialNumber, err)
+		apiutil.SetCertificateCondition(crt, cmapi.CertificateConditionRevoked, cmapi.ConditionFalse, reasonRevocationFailed, message)
+		c.recorder.Event(crt, corev1.EventTypeWarning, reasonRevocationFailed, message)
+		return nil
+	}
+
+	log.Info("revoked certificate")
+	crt.Status.RevocationSerialNumber = serialNumber
+	message := fmt.Sprintf("Certificate with serial number %s was revoked with reason %s", serialNumber, reason)
+	apiutil.SetCertificateCondition(crt, cmapi.CertificateConditionRevoked, cmapi.ConditionTrue, reasonRevoked, message)
+	c.recorder.Event(crt, corev1.EventTypeNormal, reasonRevoked, message)
+	return nil
+}
+
+// certificateKey returns the private key stored in the given Secret if it
+// corresponds to cert and can be used to sign ACME requests, otherwise nil,
+// in which case the ACME account key must be used.
+func certificateKey(secret *corev1.Secret, cert *x509.Certificate) crypto.Signer {
+	key, err := pki.DecodePrivateKeyBytes(secret.Data[corev1.TLSPrivateKeyKey])
+	if err != nil {
+		return nil
+	}
+	switch key.(type) {
+	case *rsa.PrivateKey, *ecdsa.PrivateKey:
+	default:
+		return nil
+	}
+	if matches, err := pki.PublicKeyMatchesCertificate(key.Public(), cert); err != nil || !matches {
+		return nil
+	}
+	return key
+}
+
+func (c *controller) updateCertificate(old, new *cmapi.Certificate) error {
+	if reflect.DeepEqual(old, new) {
+		return nil
+	}
+	_, err := c.cmClient.CertmanagerV1alpha1().Certificates(new.Namespace).Update(new)
+	return err
+}
+
+func hasFinalizer(crt *cmapi.Certificate) bool {
+	for _, f := range crt.Finalizers {
+		if f == cmapi.RevokeOnDeleteFinalizer {
+			return true
+		}
+	}
+	return false
+}
+
+func removeFinalizer(crt *cmapi.Certificate) {
+	var finalizers []string
+	for _, f := range crt.Finalizers {
+		if f != cmapi.RevokeOnDeleteFinalizer {
+			finalizers = append(finalizers, f)
+		}
+	}
+	crt.Finalizers = finalizers
+}
@@ -0,0 +1,403 @@
+/*
+Copyright 2019 The Jetstack cert-manager contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package acmerevocations
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	coretesting "k8s.io/client-go/testing"
+	fakeclock "k8s.io/utils/clock/testing"
+
+	acmecl "github.com/jetstack/cert-manager/pkg/acme/client"
+	acmefake "github.com/jetstack/cert-manager/pkg/acme/fake"
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha1"
+	testpkg "github.com/jetstack/cert-manager/pkg/controller/test"
+	"github.com/jetstack/cert-manager/pkg/util/pki"
+	"github.com/jetstack/cert-manager/test/unit/gen"
+	acmeapi "github.com/jetstack/cert-manager/third_party/crypto/acme"
+)
+
+var fixedClockStart = time.Date(2019, time.October, 1, 12, 30, 0, 0, time.UTC)
+
+// signingKey identifies the key a certificate is expected to be revoked with
+type signingKey int
+
+const (
+	accountKey signingKey = iota
+	secretKey
+)
+
+func generateCertificateSecret(t *testing.T, name string) (*corev1.Secret, *x509.Certificate) {
+	key, err := pki.GenerateECPrivateKey(256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(10),
+		Subject:      pkix.Name{CommonName: "example.com"},
+		NotBefore:    fixedClockStart.Add(-time.Hour),
+		NotAfter:     fixedClockStart.Add(time.Hour * 24),
+	}
+	certPEM, cert, err := pki.SignCertificate(template, template, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPEM, err := pki.EncodePrivateKey(key, cmapi.PKCS1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: gen.DefaultTestNamespace, Name: name},
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       certPEM,
+			corev1.TLSPrivateKeyKey: keyPEM,
+		},
+	}, cert
+}
+
+func TestSync(t *testing.T) {
+	nowMetaTime := metav1.NewTime(fixedClockStart)
+
+	secret, cert := generateCertificateSecret(t, "example-tls")
+	otherSecret, _ := generateCertificateSecret(t, "example-tls")
+	mismatchedKeySecret := secret.DeepCopy()
+	mismatchedKeySecret.Data[corev1.TLSPrivateKeyKey] = otherSecret.Data[corev1.TLSPrivateKeyKey]
+
+	acmeIssuer := gen.Issuer("acme", gen.SetIssuerACME(cmapi.ACMEIssuer{}))
+	caIssuer := gen.Issuer("ca", gen.SetIssuerCA(cmapi.CAIssuer{}))
+
+	baseCrt := gen.Certificate("example",
+		gen.SetCertificateIssuer(cmapi.ObjectReference{Name: "acme"}),
+		gen.SetCertificateSecretName("example-tls"),
+	)
+	revokeOnDeleteCrt := gen.CertificateFrom(baseCrt,
+		gen.SetCertificateRevokeOnDelete(true),
+	)
+	finalizedCrt := gen.CertificateFrom(revokeOnDeleteCrt,
+		gen.SetCertificateFinalizers(cmapi.RevokeOnDeleteFinalizer),
+	)
+	deletedCrt := gen.CertificateFrom(finalizedCrt,
+		gen.SetCertificateDeletionTimestamp(nowMetaTime),
+	)
+	annotatedCrt := gen.CertificateFrom(baseCrt,
+		gen.SetCertificateAnnotations(map[string]string{cmapi.RevocationReasonAnnotationKey: string(cmapi.RevocationReasonKeyCompromise)}),
+	)
+	revokedCondition := cmapi.CertificateCondition{
+		Type:               cmapi.CertificateConditionRevoked,
+		Status:             cmapi.ConditionTrue,
+		Reason:             reasonRevoked,
+		Message:            "Certificate with serial number 10 was revoked with reason keyCompromise",
+		LastTransitionTime: &nowMetaTime,
+	}
+
+	forbiddenErr := &acmeapi.Error{StatusCode: http.StatusForbidden, Type: "urn:ietf:params:acme:error:unauthorized", Detail: "not authorized"}
+
+	tests := map[string]struct {
+		certificate *cmapi.Certificate
+		builder     *testpkg.Builder
+
+		// if expectRevoke is true, the certificate is expected to be revoked
+		// with the given key and reason, returning revokeErr
+		expectRevoke   bool
+		expectedKey    signingKey
+		expectedReason acmeapi.CRLReasonCode
+		revokeErr      error
+
+		expectErr bool
+	}{
+		"do nothing if revokeOnDelete is not set and not annotated": {
+			certificate: baseCrt,
+			builder: &testpkg.Builder{
+				KubeObjects:        []runtime.Object{secret},
+				CertManagerObjects: []runtime.Object{acmeIssuer, baseCrt},
+			},
+		},
+		"add finalizer if revokeOnDelete is set": {
+			certificate: revokeOnDeleteCrt,
+			builder: &testpkg.Builder{
+				CertManagerObjects: []runtime.Object{acmeIssuer, revokeOnDeleteCrt},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateAction(cmapi.SchemeGroupVersion.WithResource("certificates"), gen.DefaultTestNamespace, finalizedCrt)),
+				},
+			},
+		},
+		"remove finalizer if revokeOnDelete is no longer set": {
+			certificate: gen.CertificateFrom(baseCrt, gen.SetCertificateFinalizers(cmapi.RevokeOnDeleteFinalizer)),
+			builder: &testpkg.Builder{
+				CertManagerObjects: []runtime.Object{acmeIssuer, gen.CertificateFrom(baseCrt, gen.SetCertificateFinalizers(cmapi.RevokeOnDeleteFinalizer))},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateAction(cmapi.SchemeGroupVersion.WithResource("certificates"), gen.DefaultTestNamespace, baseCrt)),
+				},
+			},
+		},
+		"do not add finalizer if the issuer is not an ACME issuer": {
+			certificate: gen.CertificateFrom(revokeOnDeleteCrt, gen.SetCertificateIssuer(cmapi.ObjectReference{Name: "ca"})),
+			builder: &testpkg.Builder{
+				CertManagerObjects: []runtime.Object{caIssuer, gen.CertificateFrom(revokeOnDeleteCrt, gen.SetCertificateIssuer(cmapi.ObjectReference{Name: "ca"}))},
+			},
+		},
+		"ignore annotated certificates that do not reference an ACME issuer": {
+			certificate: gen.CertificateFrom(annotatedCrt, gen.SetCertificateIssuer(cmapi.ObjectReference{Name: "ca"})),
+			builder: &testpkg.Builder{
+				KubeObjects:        []runtime.Object{secret},
+				CertManagerObjects: []runtime.Object{caIssuer, gen.CertificateFrom(annotatedCrt, gen.SetCertificateIssuer(cmapi.ObjectReference{Name: "ca"}))},
+			},
+		},
+		"revoke annotated certificate using its private key": {
+			certificate: annotatedCrt,
+			builder: &testpkg.Builder{
+				KubeObjects:        []runtime.Object{secret},
+				CertManagerObjects: []runtime.Object{acmeIssuer, annotatedCrt},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateAction(cmapi.SchemeGroupVersion.WithResource("certificates"), gen.DefaultTestNamespace,
+						gen.CertificateFrom(annotatedCrt,
+							gen.SetCertificateAnnotations(map[string]string{}),
+							gen.SetCertificateRevocationSerialNumber("10"),
+							gen.SetCertificateStatusCondition(revokedCondition),
+						),
+					)),
+				},
+				ExpectedEvents: []string{
+					"Normal Revoked Certificate with serial number 10 was revoked with reason keyCompromise",
+				},
+			},
+			expectRevoke:   true,
+			expectedKey:    secretKey,
+			expectedReason: acmeapi.CRLReasonKeyCompromise,
+		},
+		"revoke annotated certificate using the account key if the Secret does not contain its private key": {
+			certificate: annotatedCrt,
+			builder: &testpkg.Builder{
+				KubeObjects:        []runtime.Object{mismatchedKeySecret},
+				CertManagerObjects: []runtime.Object{acmeIssuer, annotatedCrt},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateAction(cmapi.SchemeGroupVersion.WithResource("certificates"), gen.DefaultTestNamespace,
+						gen.CertificateFrom(annotatedCrt,
+							gen.SetCertificateAnnotations(map[string]string{}),
+							gen.SetCertificateRevocationSerialNumber("10"),
+							gen.SetCertificateStatusCondition(revokedCondition),
+						),
+					)),
+				},
+				ExpectedEvents: []string{
+					"Normal Revoked Certificate with serial number 10 was revoked with reason keyCompromise",
+				},
+			},
+			expectRevoke:   true,
+			expectedKey:    accountKey,
+			expectedReason: acmeapi.CRLReasonKeyCompromise,
+		},
+		"treat certificates already revoked by the ACME server as revoked": {
+			certificate: annotatedCrt,
+			builder: &testpkg.Builder{
+				KubeObjects:        []runtime.Object{secret},
+				CertManagerObjects: []runtime.Object{acmeIssuer, annotatedCrt},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateAction(cmapi.SchemeGroupVersion.WithResource("certificates"), gen.DefaultTestNamespace,
+						gen.CertificateFrom(annotatedCrt,
+							gen.SetCertificateAnnotations(map[string]string{}),
+							gen.SetCertificateRevocationSerialNumber("10"),
+							gen.SetCertificateStatusCondition(revokedCondition),
+						),
+					)),
+				},
+				ExpectedEvents: []string{
+					"Normal Revoked Certificate with serial number 10 was revoked with reason keyCompromise",
+				},
+			},
+			expectRevoke:   true,
+			expectedKey:    secretKey,
+			expectedReason: acmeapi.CRLReasonKeyCompromise,
+			revokeErr:      &acmeapi.Error{StatusCode: http.StatusBadRequest, Type: errorTypeAlreadyRevoked},
+		},
+		"remove the annotation without revoking a certificate again": {
+			certificate: gen.CertificateFrom(annotatedCrt, gen.SetCertificateRevocationSerialNumber("10")),
+			builder: &testpkg.Builder{
+				KubeObjects:        []runtime.Object{secret},
+				CertManagerObjects: []runtime.Object{acmeIssuer, gen.CertificateFrom(annotatedCrt, gen.SetCertificateRevocationSerialNumber("10"))},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateAction(cmapi.SchemeGroupVersion.WithResource("certificates"), gen.DefaultTestNamespace,
+						gen.CertificateFrom(annotatedCrt,
+							gen.SetCertificateAnnotations(map[string]string{}),
+							gen.SetCertificateRevocationSerialNumber("10"),
+						),
+					)),
+				},
+			},
+		},
+		"record the failure if the ACME server refuses to revoke the certificate": {
+			certificate: annotatedCrt,
+			builder: &testpkg.Builder{
+				KubeObjects:        []runtime.Object{secret},
+				CertManagerObjects: []runtime.Object{acmeIssuer, annotatedCrt},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateAction(cmapi.SchemeGroupVersion.WithResource("certificates"), gen.DefaultTestNamespace,
+						gen.CertificateFrom(annotatedCrt,
+							gen.SetCertificateAnnotations(map[string]string{}),
+							gen.SetCertificateRevocationSerialNumber("10"),
+							gen.SetCertificateStatusCondition(cmapi.CertificateCondition{
+								Type:               cmapi.CertificateConditionRevoked,
+								Status:             cmapi.ConditionFalse,
+								Reason:             reasonRevocationFailed,
+								Message:            fmt.Sprintf("Failed to revoke certificate with serial number 10: %v", forbiddenErr),
+								LastTransitionTime: &nowMetaTime,
+							}),
+						),
+					)),
+				},
+				ExpectedEvents: []string{
+					fmt.Sprintf("Warning RevocationFailed Failed to revoke certificate with serial number 10: %v", forbiddenErr),
+				},
+			},
+			expectRevoke:   true,
+			expectedKey:    secretKey,
+			expectedReason: acmeapi.CRLReasonKeyCompromise,
+			revokeErr:      forbiddenErr,
+		},
+		"retry if the ACME server returns a server error": {
+			certificate: annotatedCrt,
+			builder: &testpkg.Builder{
+				KubeObjects:        []runtime.Object{secret},
+				CertManagerObjects: []runtime.Object{acmeIssuer, annotatedCrt},
+			},
+			expectRevoke:   true,
+			expectedKey:    secretKey,
+			expectedReason: acmeapi.CRLReasonKeyCompromise,
+			revokeErr:      &acmeapi.Error{StatusCode: http.StatusServiceUnavailable},
+			expectErr:      true,
+		},
+		"revoke certificate and remove finalizer when deleted": {
+			certificate: deletedCrt,
+			builder: &testpkg.Builder{
+				KubeObjects:        []runtime.Object{secret},
+				CertManagerObjects: []runtime.Object{acmeIssuer, deletedCrt},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateAction(cmapi.SchemeGroupVersion.WithResource("certificates"), gen.DefaultTestNamespace,
+						gen.CertificateFrom(deletedCrt,
+							gen.SetCertificateFinalizers(),
+							gen.SetCertificateRevocationSerialNumber("10"),
+							gen.SetCertificateStatusCondition(cmapi.CertificateCondition{
+								Type:               cmapi.CertificateConditionRevoked,
+								Status:             cmapi.ConditionTrue,
+								Reason:             reasonRevoked,
+								Message:            "Certificate with serial number 10 was revoked with reason cessationOfOperation",
+								LastTransitionTime: &nowMetaTime,
+							}),
+						),
+					)),
+				},
+				ExpectedEvents: []string{
+					"Normal Revoked Certificate with serial number 10 was revoked with reason cessationOfOperation",
+				},
+			},
+			expectRevoke:   true,
+			expectedKey:    secretKey,
+			expectedReason: acmeapi.CRLReasonCessationOfOperation,
+		},
+		"remove finalizer when deleted if the Secret does not exist": {
+			certificate: deletedCrt,
+			builder: &testpkg.Builder{
+				CertManagerObjects: []runtime.Object{acmeIssuer, deletedCrt},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateAction(cmapi.SchemeGroupVersion.WithResource("certificates"), gen.DefaultTestNamespace,
+						gen.CertificateFrom(deletedCrt, gen.SetCertificateFinalizers()),
+					)),
+				},
+			},
+		},
+		"remove finalizer when deleted if the issuer does not exist": {
+			certificate: deletedCrt,
+			builder: &testpkg.Builder{
+				KubeObjects:        []runtime.Object{secret},
+				CertManagerObjects: []runtime.Object{deletedCrt},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateAction(cmapi.SchemeGroupVersion.WithResource("certificates"), gen.DefaultTestNamespace,
+						gen.CertificateFrom(deletedCrt, gen.SetCertificateFinalizers()),
+					)),
+				},
+				ExpectedEvents: []string{
+					`Warning RevocationFailed Certificate was not revoked as issuer "acme" does not exist or is not an ACME issuer`,
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			revoked := false
+			acmeClient := &acmecl.FakeACME{
+				FakeRevokeCert: func(ctx context.Context, key crypto.Signer, der []byte, reason acmeapi.CRLReasonCode) error {
+					revoked = true
+					if !test.expectRevoke {
+						t.Errorf("unexpected call to RevokeCert")
+					}
+					if test.expectedKey == accountKey && key != nil {
+						t.Errorf("expected certificate to be revoked using the account key")
+					}
+					if test.expectedKey == secretKey && key == nil {
+						t.Errorf("expected certificate to be revoked using its private key")
+					}
+					if reason != test.expectedReason {
+						t.Errorf("expected revocation reason %d but got %d", test.expectedReason, reason)
+					}
+					if string(der) != string(cert.Raw) {
+						t.Errorf("unexpected certificate revoked")
+					}
+					return test.revokeErr
+				},
+			}
+
+			test.builder.T = t
+			test.builder.Clock = fakeclock.NewFakeClock(fixedClockStart)
+			test.builder.Init()
+			defer test.builder.Stop()
+
+			c := &controller{}
+			c.Register(test.builder.Context)
+			c.acmeHelper = &acmefake.Helper{
+				ClientForIssuerFunc: func(iss cmapi.GenericIssuer) (acmecl.Interface, error) {
+					return acmeClient, nil
+				},
+			}
+			test.builder.Start()
+
+			err := c.Sync(context.Background(), test.certificate)
+			if err != nil && !test.expectErr {
+				t.Errorf("Expected function to not error, but got: %v", err)
+			}
+			if err == nil && test.expectErr {
+				t.Errorf("Expected function to get an error, but got: %v", err)
+			}
+			if test.expectRevoke && !revoked {
+				t.Errorf("expected certificate to be revoked")
+			}
+
+			test.builder.CheckAndFinish(err)
+		})
+	}
+}
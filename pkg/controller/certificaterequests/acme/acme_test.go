@@ -106,7 +106,7 @@ func TestSign(t *testing.T) {
 		t.Errorf("error generating template: %v", err)
 	}
 
-	certPEM, _, err := pki.SignCSRTemplate([]*x509.Certificate{template}, sk, template)
+	certPEM, _, err := pki.SignCSRTemplate([]*x509.Certificate{template}, sk, template, nil)
 	if err != nil {
 		t.Error(err)
 		t.FailNow()
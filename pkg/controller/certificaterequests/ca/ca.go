@@ -111,7 +111,7 @@ func (c *CA) Sign(ctx context.Context, cr *cmapi.CertificateRequest, issuerObj c
 		return nil, nil
 	}
 
-	certPEM, caPEM, err := pki.SignCSRTemplate(caCerts, caKey, template)
+	certPEM, caPEM, err := pki.SignCSRTemplate(caCerts, caKey, template, issuerObj.GetSpec().CA)
 	if err != nil {
 		message := "Error signing certificate"
 		c.reporter.Failed(cr, err, "SigningError", message)
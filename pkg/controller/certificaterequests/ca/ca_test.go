@@ -142,7 +142,7 @@ func TestSign(t *testing.T) {
 		t.Error(err)
 		t.FailNow()
 	}
-	certPEM, _, err := pki.SignCSRTemplate([]*x509.Certificate{template}, skRSA, template)
+	certPEM, _, err := pki.SignCSRTemplate([]*x509.Certificate{template}, skRSA, template, nil)
 	if err != nil {
 		t.Error(err)
 		t.FailNow()
@@ -21,6 +21,7 @@ import (
 
 	"github.com/Venafi/vcert/pkg/endpoint"
 	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 	corelisters "k8s.io/client-go/listers/core/v1"
 
 	apiutil "github.com/jetstack/cert-manager/pkg/api/util"
@@ -40,6 +41,7 @@ const (
 type Venafi struct {
 	issuerOptions controllerpkg.IssuerOptions
 	secretsLister corelisters.SecretLister
+	secretsClient corev1client.SecretsGetter
 	reporter      *crutil.Reporter
 
 	clientBuilder venafiinternal.VenafiClientBuilder
@@ -58,6 +60,7 @@ func NewVenafi(ctx *controllerpkg.Context) *Venafi {
 	return &Venafi{
 		issuerOptions: ctx.IssuerOptions,
 		secretsLister: ctx.KubeSharedInformerFactory.Core().V1().Secrets().Lister(),
+		secretsClient: ctx.Client.CoreV1(),
 		reporter:      crutil.NewReporter(ctx.Clock, ctx.Recorder),
 		clientBuilder: venafiinternal.New,
 	}
@@ -67,7 +70,7 @@ func (v *Venafi) Sign(ctx context.Context, cr *cmapi.CertificateRequest, issuerO
 	log := logf.FromContext(ctx, "sign")
 	log = logf.WithRelatedResource(log, issuerObj)
 
-	client, err := v.clientBuilder(cr.Namespace, v.secretsLister, issuerObj)
+	client, err := v.clientBuilder(cr.Namespace, v.secretsLister, v.secretsClient, issuerObj)
 	if k8sErrors.IsNotFound(err) {
 		message := "Required secret resource not found"
 
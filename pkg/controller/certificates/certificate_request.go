@@ -19,6 +19,7 @@ package certificates
 import (
 	"context"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/json"
@@ -42,11 +43,13 @@ import (
 	"k8s.io/utils/clock"
 
 	apiutil "github.com/jetstack/cert-manager/pkg/api/util"
+	"github.com/jetstack/cert-manager/pkg/apis/certmanager"
 	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha1"
 	cmclient "github.com/jetstack/cert-manager/pkg/client/clientset/versioned"
 	cmlisters "github.com/jetstack/cert-manager/pkg/client/listers/certmanager/v1alpha1"
 	controllerpkg "github.com/jetstack/cert-manager/pkg/controller"
 	"github.com/jetstack/cert-manager/pkg/feature"
+	"github.com/jetstack/cert-manager/pkg/issuer"
 	logf "github.com/jetstack/cert-manager/pkg/logs"
 	"github.com/jetstack/cert-manager/pkg/metrics"
 	"github.com/jetstack/cert-manager/pkg/scheduler"
@@ -62,6 +65,11 @@ type certificateRequestManager struct {
 	certificateLister        cmlisters.CertificateLister
 	secretLister             corelisters.SecretLister
 	certificateRequestLister cmlisters.CertificateRequestLister
+	issuerLister             cmlisters.IssuerLister
+	clusterIssuerLister      cmlisters.ClusterIssuerLister
+
+	// helper is used to read the issuer referenced by a Certificate
+	helper issuer.Helper
 
 	kubeClient kubernetes.Interface
 	cmClient   cmclient.Interface
@@ -100,6 +108,9 @@ type certificateRequestManager struct {
 	// issueTemporaryCerts gates whether temporary certificates should be issued.
 	// This is defined here as a bool to make it easy to disable this behaviour.
 	issueTemporaryCerts bool
+
+	// podRefreshEnabled is true if the pod refresh controller is running
+	podRefreshEnabled bool
 }
 
 type localTemporarySignerFn func(crt *cmapi.Certificate, pk []byte) ([]byte, error)
@@ -118,6 +129,7 @@ func (c *certificateRequestManager) Register(ctx *controllerpkg.Context) (workqu
 	certificateInformer := ctx.SharedInformerFactory.Certmanager().V1alpha1().Certificates()
 	certificateRequestInformer := ctx.SharedInformerFactory.Certmanager().V1alpha1().CertificateRequests()
 	secretsInformer := ctx.KubeSharedInformerFactory.Core().V1().Secrets()
+	issuerInformer := ctx.SharedInformerFactory.Certmanager().V1alpha1().Issuers()
 
 	// build a list of InformerSynced functions that will be returned by the Register method.
 	// the controller will only begin processing items once all of these informers have synced.
@@ -125,12 +137,25 @@ func (c *certificateRequestManager) Register(ctx *controllerpkg.Context) (workqu
 		certificateRequestInformer.Informer().HasSynced,
 		secretsInformer.Informer().HasSynced,
 		certificateInformer.Informer().HasSynced,
+		issuerInformer.Informer().HasSynced,
 	}
 
 	// set all the references to the listers for used by the Sync function
 	c.certificateRequestLister = certificateRequestInformer.Lister()
 	c.secretLister = secretsInformer.Lister()
 	c.certificateLister = certificateInformer.Lister()
+	c.issuerLister = issuerInformer.Lister()
+
+	// if we are running in non-namespaced mode (i.e. --namespace=""), we also
+	// obtain a lister for clusterissuers.
+	if ctx.Namespace == "" {
+		clusterIssuerInformer := ctx.SharedInformerFactory.Certmanager().V1alpha1().ClusterIssuers()
+		c.clusterIssuerLister = clusterIssuerInformer.Lister()
+		mustSync = append(mustSync, clusterIssuerInformer.Informer().HasSynced)
+	}
+
+	// create an issuer helper for reading generic issuers
+	c.helper = issuer.NewHelper(c.issuerLister, c.clusterIssuerLister)
 
 	// register handler functions
 	certificateInformer.Informer().AddEventHandler(&controllerpkg.QueuingEventHandler{Queue: c.queue})
@@ -159,6 +184,7 @@ func (c *certificateRequestManager) Register(ctx *controllerpkg.Context) (workqu
 
 	c.cmClient = ctx.CMClient
 	c.kubeClient = ctx.Client
+	c.podRefreshEnabled = ctx.CertificateOptions.PodRefreshControllerEnabled
 
 	return c.queue, mustSync, nil, nil
 }
@@ -234,7 +260,7 @@ func (c *certificateRequestManager) updateCertificateStatus(ctx context.Context,
 	var matches bool
 	var matchErrs []string
 	if key != nil && cert != nil {
-		matches, matchErrs = certificateMatchesSpec(crt, key, cert, c.secretLister)
+		matches, matchErrs = certificateMatchesSpec(crt, c.issuerForCertificate(crt), key, cert, c.secretLister)
 	}
 
 	isTempCert := isTemporaryCertificate(cert)
@@ -309,6 +335,8 @@ func (c *certificateRequestManager) processCertificate(ctx context.Context, crt
 	log := logf.FromContext(ctx, ExperimentalControllerName)
 	dbg := log.V(logf.DebugLevel)
 
+	warnIfPodRefreshDisabled(c.recorder, crt, c.podRefreshEnabled)
+
 	// The certificate request name is a product of the certificate's spec,
 	// which makes it unique and predictable.
 	// First we compute what we expect it to be.
@@ -338,6 +366,17 @@ func (c *certificateRequestManager) processCertificate(ctx context.Context, crt
 	log = logf.WithRelatedResource(log, existingSecret)
 	ctx = logf.NewContext(ctx, log)
 
+	// If the stored certificate has been revoked, remove it along with its
+	// private key so that a new certificate is issued for a new private key
+	if cert, err := pki.DecodeX509CertificateBytes(existingSecret.Data[corev1.TLSCertKey]); err == nil && certificateRevoked(crt, cert) {
+		log.Info("existing certificate has been revoked, removing it and its private key from the Secret")
+		if err := removeRevokedKeyPair(c.kubeClient, c.secretLister, crt); err != nil {
+			return err
+		}
+		c.recorder.Eventf(crt, corev1.EventTypeNormal, reasonRevokedKeyPairRemoved, "Removed revoked certificate with serial number %s and its private key from Secret %q", cert.SerialNumber, crt.Spec.SecretName)
+		return nil
+	}
+
 	// If the Secret does not contain a private key, generate one and update
 	// the Secret resource
 	existingKey := existingSecret.Data[corev1.TLSPrivateKeyKey]
@@ -421,6 +460,11 @@ func (c *certificateRequestManager) processCertificate(ctx context.Context, crt
 
 		if updated {
 			log.Info("updated Secret resource metadata as it was out of date")
+		} else if err := updateKeystores(c.kubeClient, c.secretLister, crt); err != nil {
+			// keystores were already regenerated above if required, so
+			// this only fails if they could not be generated, which has
+			// already been reported. The error is returned to retry.
+			return fmt.Errorf("error updating keystores: %v", err)
 		}
 
 		// As the Certificate has been validated as Ready, schedule a renewal
@@ -462,7 +506,7 @@ func (c *certificateRequestManager) processCertificate(ctx context.Context, crt
 		}
 		// We don't issue a temporary certificate if the existing stored
 		// certificate already 'matches', even if it isn't a temporary certificate.
-		matches, _ := certificateMatchesSpec(crt, privateKey, existingX509Cert, c.secretLister)
+		matches, _ := certificateMatchesSpec(crt, c.issuerForCertificate(crt), privateKey, existingX509Cert, c.secretLister)
 		if !matches {
 			log.Info("existing certificate fields do not match certificate spec, issuing temporary certificate")
 			return c.issueTemporaryCertificate(ctx, existingSecret, crt, existingKey)
@@ -483,13 +527,38 @@ func (c *certificateRequestManager) processCertificate(ctx context.Context, crt
 		}
 	}
 
+	// If the Certificate requires a new private key for every issuance, the
+	// certificate is requested for the next private key instead. It is only
+	// stored in the Secret alongside the newly issued certificate, so that
+	// the existing key pair remains intact if issuance fails.
+	// The private key generated for the first issuance is used as-is.
+	requestKey, requestSigner := existingKey, privateKey
+	rotateKey := crt.Spec.RotationPolicy == cmapi.RotationPolicyAlways &&
+		existingX509Cert != nil && !isTemporaryCertificate(existingX509Cert)
+	if rotateKey {
+		dbg.Info("rotation policy is Always, using next private key for issuance")
+		requestKey, err = kube.NextPrivateKey(c.kubeClient, c.secretLister, crt, privateKey)
+		if err != nil {
+			return err
+		}
+		requestSigner, err = pki.DecodePrivateKeyBytes(requestKey)
+		if err != nil {
+			return err
+		}
+	}
+
 	if existingReq == nil {
 		// If no existing CertificateRequest resource exists, we must create one
 		log.Info("no existing CertificateRequest resource exists, creating new request...")
-		req, err := c.buildCertificateRequest(log, crt, expectedReqName, existingKey)
+		req, err := c.buildCertificateRequest(log, crt, expectedReqName, requestKey)
 		if err != nil {
 			return err
 		}
+		// issuers that need access to the private key, such as SelfSigned,
+		// must read the next private key rather than the existing one
+		if rotateKey {
+			req.Annotations[cmapi.CRPrivateKeyAnnotationKey] = kube.NextPrivateKeySecretName(crt)
+		}
 
 		req, err = c.cmClient.CertmanagerV1alpha1().CertificateRequests(crt.Namespace).Create(req)
 		if err != nil {
@@ -511,11 +580,24 @@ func (c *certificateRequestManager) processCertificate(ctx context.Context, crt
 	}
 
 	// Ensure the stored private key is a 'pair' to the CSR
-	publicKeyMatches, err := pki.PublicKeyMatchesCSR(privateKey.Public(), x509CSR)
+	publicKeyMatches, err := pki.PublicKeyMatchesCSR(requestSigner.Public(), x509CSR)
 	if err != nil {
 		return err
 	}
 
+	// if the request was created for the key that is being rotated, delete
+	// the resource so that a new request is created for the next private key
+	if !publicKeyMatches && rotateKey {
+		log.Info("CSR stored on existing CertificateRequest is not for the next private key, recreating CertificateRequest resource")
+		err := c.cmClient.CertmanagerV1alpha1().CertificateRequests(existingReq.Namespace).Delete(existingReq.Name, nil)
+		if err != nil {
+			return err
+		}
+
+		c.recorder.Eventf(crt, corev1.EventTypeNormal, "RotatingKey", "Rotating private key, deleting CertificateRequest %q for the previous key", existingReq.Name)
+		return nil
+	}
+
 	// if the stored private key does not pair with the CSR on the
 	// CertificateRequest resource, delete the resource as we won't be able to
 	// do anything with the certificate if it is issued
@@ -592,11 +674,19 @@ func (c *certificateRequestManager) processCertificate(ctx context.Context, crt
 		// across the status.certificate field into the Secret resource.
 		log.Info("CertificateRequest contains a valid certificate for issuance. Issuing certificate...")
 
-		_, err = c.updateSecretData(ctx, crt, existingSecret, secretData{pk: existingKey, cert: existingReq.Status.Certificate, ca: existingReq.Status.CA})
+		_, err = c.updateSecretData(ctx, crt, existingSecret, secretData{pk: requestKey, cert: existingReq.Status.Certificate, ca: existingReq.Status.CA})
 		if err != nil {
 			return err
 		}
 
+		// The next private key is now stored in the Secret, so it must not
+		// be used for another issuance.
+		if rotateKey {
+			if err := kube.DeleteNextPrivateKey(c.kubeClient, crt); err != nil {
+				return err
+			}
+		}
+
 		c.recorder.Eventf(crt, corev1.EventTypeNormal, "Issued", "Certificate issued successfully")
 		return nil
 
@@ -627,7 +717,7 @@ func (c *certificateRequestManager) updateSecretData(ctx context.Context, crt *c
 	}
 
 	newSecret := s.DeepCopy()
-	err := setSecretValues(ctx, crt, newSecret, secretData{pk: data.pk, cert: data.cert, ca: data.ca})
+	err := c.setSecretValues(ctx, crt, newSecret, secretData{pk: data.pk, cert: data.cert, ca: data.ca})
 	if err != nil {
 		return false, err
 	}
@@ -673,7 +763,7 @@ func (c *certificateRequestManager) issueTemporaryCertificate(ctx context.Contex
 	}
 
 	newSecret := secret.DeepCopy()
-	err = setSecretValues(ctx, crt, newSecret, secretData{pk: key, cert: tempCertData})
+	err = c.setSecretValues(ctx, crt, newSecret, secretData{pk: key, cert: tempCertData})
 	if err != nil {
 		return err
 	}
@@ -700,7 +790,7 @@ func (c *certificateRequestManager) certificateRequiresIssuance(ctx context.Cont
 	if isTemporaryCertificate(cert) {
 		return true, nil, nil
 	}
-	matches, matchErrs := certificateMatchesSpec(crt, key, cert, c.secretLister)
+	matches, matchErrs := certificateMatchesSpec(crt, c.issuerForCertificate(crt), key, cert, c.secretLister)
 	if !matches {
 		return true, matchErrs, nil
 	}
@@ -708,6 +798,20 @@ func (c *certificateRequestManager) certificateRequiresIssuance(ctx context.Cont
 	return needsRenew, []string{"Certificate is expiring soon"}, nil
 }
 
+// issuerForCertificate returns the cert-manager issuer referenced by the
+// Certificate, or nil if it is not a cert-manager issuer or cannot be
+// retrieved.
+func (c *certificateRequestManager) issuerForCertificate(crt *cmapi.Certificate) cmapi.GenericIssuer {
+	if crt.Spec.IssuerRef.Group != certmanager.GroupName {
+		return nil
+	}
+	iss, err := c.helper.GetGenericIssuer(crt.Spec.IssuerRef, crt.Namespace)
+	if err != nil {
+		return nil
+	}
+	return iss
+}
+
 func expectedCertificateRequestName(crt *cmapi.Certificate) (string, error) {
 	crt = crt.DeepCopy()
 	// clear deprecated ACME field as it is not supported with CertificateRequest
@@ -858,6 +962,12 @@ func validatePrivateKeyUpToDate(log logr.Logger, pk []byte, crt *cmapi.Certifica
 			return false, nil
 		}
 		// TODO: check keySize
+	case cmapi.Ed25519KeyAlgorithm:
+		_, ok := signer.(ed25519.PrivateKey)
+		if !ok {
+			log.Info("expected private key's algorithm to be Ed25519 but it is not")
+			return false, nil
+		}
 	}
 
 	return true, nil
@@ -908,13 +1018,14 @@ type secretData struct {
 
 // setSecretValues will update the Secret resource 's' with the data contained
 // in the given secretData.
-// It will update labels and annotations on the Secret resource appropriately.
+// It will update labels and annotations on the Secret resource appropriately,
+// as well as any keystores requested on the Certificate.
 // The Secret resource 's' must be non-nil, although may be a resource that does
 // not exist in the Kubernetes apiserver yet.
 // setSecretValues will NOT actually update the resource in the apiserver.
 // If updating an existing Secret resource returned by an api client 'lister',
 // make sure to DeepCopy the object first to avoid modifying data in-cache.
-func setSecretValues(ctx context.Context, crt *cmapi.Certificate, s *corev1.Secret, data secretData) error {
+func (c *certificateRequestManager) setSecretValues(ctx context.Context, crt *cmapi.Certificate, s *corev1.Secret, data secretData) error {
 	// initialize the `Data` field if it is nil
 	if s.Data == nil {
 		s.Data = make(map[string][]byte)
@@ -924,6 +1035,15 @@ func setSecretValues(ctx context.Context, crt *cmapi.Certificate, s *corev1.Secr
 	s.Data[corev1.TLSCertKey] = data.cert
 	s.Data[cmapi.TLSCAKey] = data.ca
 
+	// keystores are built from the PEM data set above. Failing to build them
+	// must not prevent a newly issued certificate from being stored, so the
+	// error is only reported here and the keystores are retried once the
+	// certificate no longer requires issuance.
+	if err := setKeystoreValues(c.secretLister, crt, s); err != nil {
+		logf.FromContext(ctx).Error(err, "error generating keystores")
+		c.recorder.Eventf(crt, corev1.EventTypeWarning, reasonKeystoreError, "Failed to generate keystores: %v", err)
+	}
+
 	if s.Annotations == nil {
 		s.Annotations = make(map[string]string)
 	}
@@ -377,6 +377,51 @@ func TestProcessCertificate(t *testing.T) {
 		gen.SetCertificateDNSNames("example.com"),
 		gen.SetCertificateKeyAlgorithm(cmapi.ECDSAKeyAlgorithm),
 	))
+	revokedCert := gen.CertificateFrom(exampleBundle1.certificate,
+		gen.SetCertificateStatusCondition(cmapi.CertificateCondition{
+			Type:   cmapi.CertificateConditionRevoked,
+			Status: cmapi.ConditionTrue,
+			Reason: "Revoked",
+		}),
+	)
+	revokedCert.Status.RevocationSerialNumber = exampleBundle1.cert.SerialNumber.String()
+	rotateCert := gen.CertificateFrom(baseCert,
+		gen.SetCertificateDNSNames("example.com"),
+		gen.SetCertificateRotationPolicy(cmapi.RotationPolicyAlways),
+	)
+	// rotateBundle1 contains the existing key pair, and rotateBundle2 the
+	// next private key and the certificate issued for it
+	rotateBundle1 := mustCreateCryptoBundle(t, rotateCert)
+	rotateBundle2 := mustCreateCryptoBundle(t, rotateCert)
+	rotateNextKeySecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       gen.DefaultTestNamespace,
+			Name:            "test-next-private-key",
+			Annotations:     map[string]string{cmapi.CertificateNameKey: "test"},
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(rotateCert, certificateGvk)},
+		},
+		Data: map[string][]byte{corev1.TLSPrivateKeyKey: rotateBundle2.privateKeyBytes},
+	}
+	rotateExistingSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: gen.DefaultTestNamespace,
+			Name:      "output",
+			Annotations: map[string]string{
+				cmapi.CertificateNameKey:      "test",
+				cmapi.IssuerKindAnnotationKey: rotateCert.Spec.IssuerRef.Kind,
+				cmapi.IssuerNameAnnotationKey: rotateCert.Spec.IssuerRef.Name,
+				cmapi.IPSANAnnotationKey:      "",
+				cmapi.AltNamesAnnotationKey:   "example.com",
+				cmapi.CommonNameAnnotationKey: "example.com",
+			},
+		},
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       rotateBundle1.generateCertificateExpiring1H(rotateCert),
+			corev1.TLSPrivateKeyKey: rotateBundle1.privateKeyBytes,
+			cmapi.TLSCAKey:          nil,
+		},
+		Type: corev1.SecretTypeTLS,
+	}
 
 	tests := map[string]testT{
 		"generate a private key and create a new secret if one does not exist": {
@@ -743,6 +788,47 @@ func TestProcessCertificate(t *testing.T) {
 				},
 			},
 		},
+		"remove the certificate and private key from the Secret if the existing certificate has been revoked": {
+			certificate: revokedCert,
+			builder: &testpkg.Builder{
+				KubeObjects: []runtime.Object{
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{
+							Namespace: gen.DefaultTestNamespace,
+							Name:      "output",
+						},
+						Data: map[string][]byte{
+							corev1.TLSCertKey:       exampleBundle1.certBytes,
+							corev1.TLSPrivateKeyKey: exampleBundle1.privateKeyBytes,
+							cmapi.TLSCAKey:          nil,
+						},
+						Type: corev1.SecretTypeTLS,
+					},
+				},
+				CertManagerObjects: []runtime.Object{
+					revokedCert,
+				},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateAction(
+						corev1.SchemeGroupVersion.WithResource("secrets"),
+						gen.DefaultTestNamespace,
+						&corev1.Secret{
+							ObjectMeta: metav1.ObjectMeta{
+								Namespace: gen.DefaultTestNamespace,
+								Name:      "output",
+							},
+							Data: map[string][]byte{
+								cmapi.TLSCAKey: nil,
+							},
+							Type: corev1.SecretTypeTLS,
+						},
+					)),
+				},
+				ExpectedEvents: []string{
+					fmt.Sprintf(`Normal RevokedKeyPairRemoved Removed revoked certificate with serial number %s and its private key from Secret "output"`, exampleBundle1.cert.SerialNumber),
+				},
+			},
+		},
 		"update secret resource metadata if existing certificate is valid but missing annotations": {
 			certificate: exampleBundle1.certificate,
 			builder: &testpkg.Builder{
@@ -1132,6 +1218,69 @@ func TestProcessCertificate(t *testing.T) {
 				ExpectedEvents: []string{},
 			},
 		},
+		"create a CertificateRequest for the next private key if the rotationPolicy is Always and the existing certificate expires soon": {
+			certificate: rotateCert,
+			generateCSR: testGenerateCSRFn(rotateBundle2.csrBytes),
+			builder: &testpkg.Builder{
+				KubeObjects:        []runtime.Object{rotateExistingSecret, rotateNextKeySecret},
+				CertManagerObjects: []runtime.Object{rotateCert},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewCreateAction(
+						cmapi.SchemeGroupVersion.WithResource("certificaterequests"),
+						gen.DefaultTestNamespace,
+						gen.CertificateRequestFrom(rotateBundle2.certificateRequest,
+							gen.SetCertificateRequestAnnotations(map[string]string{
+								cmapi.CRPrivateKeyAnnotationKey: rotateNextKeySecret.Name,
+							}),
+						),
+					)),
+				},
+				ExpectedEvents: []string{fmt.Sprintf(`Normal Requested Created new CertificateRequest resource %q`, rotateBundle2.expectedRequestName)},
+			},
+		},
+		"delete an existing CertificateRequest for the previous private key if the rotationPolicy is Always": {
+			certificate: rotateCert,
+			builder: &testpkg.Builder{
+				KubeObjects:        []runtime.Object{rotateExistingSecret, rotateNextKeySecret},
+				CertManagerObjects: []runtime.Object{rotateCert, rotateBundle1.certificateRequestReady},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewDeleteAction(
+						cmapi.SchemeGroupVersion.WithResource("certificaterequests"),
+						gen.DefaultTestNamespace,
+						rotateBundle1.certificateRequestReady.Name,
+					)),
+				},
+				ExpectedEvents: []string{fmt.Sprintf(`Normal RotatingKey Rotating private key, deleting CertificateRequest %q for the previous key`, rotateBundle1.expectedRequestName)},
+			},
+		},
+		"store the next private key with the issued certificate and delete it if the rotationPolicy is Always": {
+			certificate: rotateCert,
+			builder: &testpkg.Builder{
+				KubeObjects:        []runtime.Object{rotateExistingSecret, rotateNextKeySecret},
+				CertManagerObjects: []runtime.Object{rotateCert, rotateBundle2.certificateRequestReady},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateAction(
+						corev1.SchemeGroupVersion.WithResource("secrets"),
+						gen.DefaultTestNamespace,
+						&corev1.Secret{
+							ObjectMeta: rotateExistingSecret.ObjectMeta,
+							Data: map[string][]byte{
+								corev1.TLSCertKey:       rotateBundle2.certBytes,
+								corev1.TLSPrivateKeyKey: rotateBundle2.privateKeyBytes,
+								cmapi.TLSCAKey:          nil,
+							},
+							Type: corev1.SecretTypeTLS,
+						},
+					)),
+					testpkg.NewAction(coretesting.NewDeleteAction(
+						corev1.SchemeGroupVersion.WithResource("secrets"),
+						gen.DefaultTestNamespace,
+						rotateNextKeySecret.Name,
+					)),
+				},
+				ExpectedEvents: []string{"Normal Issued Certificate issued successfully"},
+			},
+		},
 	}
 
 	for name, test := range tests {
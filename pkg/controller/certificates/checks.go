@@ -95,7 +95,7 @@ func certificatesForSecret(certificateLister cmlisters.CertificateLister, secret
 		if crt.Namespace != secret.Namespace {
 			continue
 		}
-		if crt.Spec.SecretName == secret.Name {
+		if crt.Spec.SecretName == secret.Name || referencesKeystorePassword(crt, secret.Name) {
 			affected = append(affected, crt)
 		}
 	}
@@ -103,6 +103,17 @@ func certificatesForSecret(certificateLister cmlisters.CertificateLister, secret
 	return affected, nil
 }
 
+// referencesKeystorePassword returns true if the named Secret holds one of
+// the keystore passwords of the given Certificate.
+func referencesKeystorePassword(crt *cmapi.Certificate, secretName string) bool {
+	ks := crt.Spec.Keystores
+	if ks == nil {
+		return false
+	}
+	return (ks.JKS != nil && ks.JKS.PasswordSecretRef.Name == secretName) ||
+		(ks.PKCS12 != nil && ks.PKCS12.PasswordSecretRef.Name == secretName)
+}
+
 func (c *controller) certificatesForGenericIssuer(iss cmapi.GenericIssuer) ([]*cmapi.Certificate, error) {
 	crts, err := c.certificateLister.List(labels.NewSelector())
 
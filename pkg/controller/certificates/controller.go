@@ -89,9 +89,8 @@ type controller struct {
 	// to the secret resources it creates
 	addOwnerReferences bool
 
-	// if enablePodRefresh is enabled, then the controller will automatically restart all pods
-	// that use cert-manager certificates when the cert-manager certificate is refreshed
-	enablePodRefresh bool
+	// podRefreshEnabled is true if the pod refresh controller is running
+	podRefreshEnabled bool
 }
 
 type calculateDurationUntilRenewFn func(context.Context, *x509.Certificate, *v1alpha1.Certificate) time.Duration
@@ -175,7 +174,7 @@ func (c *controller) Register(ctx *controllerpkg.Context) (workqueue.RateLimitin
 	c.cmClient = ctx.CMClient
 	c.kClient = ctx.Client
 	c.addOwnerReferences = ctx.CertificateOptions.EnableOwnerRef
-	c.enablePodRefresh = ctx.CertificateOptions.EnablePodRefresh
+	c.podRefreshEnabled = ctx.CertificateOptions.PodRefreshControllerEnabled
 
 	return c.queue, mustSync, nil, nil
 }
@@ -0,0 +1,300 @@
+/*
+Copyright 2019 The Jetstack cert-manager contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificates
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/pavel-v-chernykh/keystore-go"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"software.sslmate.com/src/go-pkcs12"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha1"
+	"github.com/jetstack/cert-manager/pkg/util/pki"
+)
+
+const (
+	// aliases of the entries stored in JKS keystores and truststores
+	jksCertificateAlias = "certificate"
+	jksCAAlias          = "ca"
+
+	// keystoreHashAnnotation records a hash of the inputs the keystores in a
+	// Secret were last generated from, so that they are only regenerated
+	// when one of those inputs changes
+	keystoreHashAnnotation = "certmanager.k8s.io/keystore-hash"
+
+	reasonKeystoreError = "KeystoreError"
+)
+
+// setKeystoreValues updates the keystores requested by crt.Spec.Keystores in
+// the Secret 's' from the PEM encoded data it contains, removing any
+// keystores that are no longer requested.
+// It must be called after the PEM encoded data in 's' has been updated.
+// Keystores are only regenerated if the PEM encoded data, crt.Spec.Keystores
+// or the keystore passwords have changed since they were last generated, so
+// that repeated calls do not cause the Secret to be updated every time.
+// Keystore passwords are read from Secrets in the Certificate's namespace
+// using the given lister. If an error is returned, 's' is left unchanged.
+func setKeystoreValues(lister corelisters.SecretLister, crt *cmapi.Certificate, s *corev1.Secret) error {
+	var jks *cmapi.JKSKeystore
+	var p12 *cmapi.PKCS12Keystore
+	if crt.Spec.Keystores != nil {
+		jks = crt.Spec.Keystores.JKS
+		p12 = crt.Spec.Keystores.PKCS12
+	}
+
+	// a keystore can only be built once both a key and certificate exist
+	pk, cert, ca := s.Data[corev1.TLSPrivateKeyKey], s.Data[corev1.TLSCertKey], s.Data[cmapi.TLSCAKey]
+	complete := len(pk) > 0 && len(cert) > 0
+	createJKS := jks != nil && jks.Create && complete
+	createP12 := p12 != nil && p12.Create && complete
+
+	var jksPassword, p12Password []byte
+	var err error
+	if createJKS {
+		jksPassword, err = keystorePassword(lister, crt.Namespace, jks.PasswordSecretRef)
+		if err != nil {
+			return err
+		}
+	}
+	if createP12 {
+		p12Password, err = keystorePassword(lister, crt.Namespace, p12.PasswordSecretRef)
+		if err != nil {
+			return err
+		}
+	}
+
+	hash := ""
+	if createJKS || createP12 {
+		hash = keystoreHash(pk, cert, ca, createJKS, jksPassword, createP12, p12Password)
+		if hash == s.Annotations[keystoreHashAnnotation] &&
+			(!createJKS || len(s.Data[cmapi.JKSSecretKey]) > 0) &&
+			(!createP12 || len(s.Data[cmapi.PKCS12SecretKey]) > 0) {
+			return nil
+		}
+	}
+
+	data := make(map[string][]byte)
+	if createJKS {
+		ks, err := encodeJKSKeystore(jksPassword, pk, cert, ca)
+		if err != nil {
+			return fmt.Errorf("error encoding JKS keystore: %v", err)
+		}
+		data[cmapi.JKSSecretKey] = ks
+		if len(ca) > 0 {
+			ts, err := encodeJKSTruststore(jksPassword, ca)
+			if err != nil {
+				return fmt.Errorf("error encoding JKS truststore: %v", err)
+			}
+			data[cmapi.JKSTruststoreKey] = ts
+		}
+	}
+	if createP12 {
+		ks, err := encodePKCS12Keystore(string(p12Password), pk, cert, ca)
+		if err != nil {
+			return fmt.Errorf("error encoding PKCS#12 keystore: %v", err)
+		}
+		data[cmapi.PKCS12SecretKey] = ks
+		if len(ca) > 0 {
+			ts, err := encodePKCS12Truststore(string(p12Password), ca)
+			if err != nil {
+				return fmt.Errorf("error encoding PKCS#12 truststore: %v", err)
+			}
+			data[cmapi.PKCS12TruststoreKey] = ts
+		}
+	}
+
+	for _, k := range []string{cmapi.JKSSecretKey, cmapi.JKSTruststoreKey, cmapi.PKCS12SecretKey, cmapi.PKCS12TruststoreKey} {
+		delete(s.Data, k)
+	}
+	for k, v := range data {
+		s.Data[k] = v
+	}
+	if hash == "" {
+		delete(s.Annotations, keystoreHashAnnotation)
+		return nil
+	}
+	if s.Annotations == nil {
+		s.Annotations = make(map[string]string)
+	}
+	s.Annotations[keystoreHashAnnotation] = hash
+
+	return nil
+}
+
+// keystoreHash returns a hash of the inputs keystores are generated from.
+// The passwords are included so that keystores are regenerated when a
+// password is changed.
+func keystoreHash(pk, cert, ca []byte, createJKS bool, jksPassword []byte, createP12 bool, p12Password []byte) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "jks=%t,pkcs12=%t;", createJKS, createP12)
+	for _, b := range [][]byte{pk, cert, ca, jksPassword, p12Password} {
+		// prefix each input with its length so that the boundaries between
+		// inputs are unambiguous
+		fmt.Fprintf(h, "%d:", len(b))
+		h.Write(b)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// updateKeystores regenerates the keystores stored in the Certificate's
+// Secret if they are out of date, for example because a keystore password or
+// spec.keystores has changed, or because they could not be generated when
+// the certificate was stored.
+func updateKeystores(cl kubernetes.Interface, lister corelisters.SecretLister, crt *cmapi.Certificate) error {
+	existing, err := lister.Secrets(crt.Namespace).Get(crt.Spec.SecretName)
+	if err != nil {
+		return err
+	}
+
+	s := existing.DeepCopy()
+	if s.Data == nil {
+		s.Data = make(map[string][]byte)
+	}
+	if err := setKeystoreValues(lister, crt, s); err != nil {
+		return err
+	}
+	if reflect.DeepEqual(existing.Data, s.Data) && reflect.DeepEqual(existing.Annotations, s.Annotations) {
+		return nil
+	}
+
+	_, err = cl.CoreV1().Secrets(s.Namespace).Update(s)
+	return err
+}
+
+// keystorePassword returns the keystore password stored in the referenced
+// Secret key.
+func keystorePassword(lister corelisters.SecretLister, namespace string, ref cmapi.SecretKeySelector) ([]byte, error) {
+	secret, err := lister.Secrets(namespace).Get(ref.Name)
+	if err != nil {
+		return nil, fmt.Errorf("error getting keystore password Secret %q: %v", ref.Name, err)
+	}
+	password, ok := secret.Data[ref.Key]
+	if !ok {
+		return nil, fmt.Errorf("no keystore password found in key %q of Secret %q", ref.Key, ref.Name)
+	}
+	return password, nil
+}
+
+func encodePKCS12Keystore(password string, rawKey, certPEM, caPEM []byte) ([]byte, error) {
+	key, err := pki.DecodePrivateKeyBytes(rawKey)
+	if err != nil {
+		return nil, err
+	}
+	certs, err := pki.DecodeX509CertificateChainBytes(certPEM)
+	if err != nil {
+		return nil, err
+	}
+	chain := append([]*x509.Certificate{}, certs[1:]...)
+	if len(caPEM) > 0 {
+		cas, err := pki.DecodeX509CertificateChainBytes(caPEM)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, cas...)
+	}
+	return pkcs12.Encode(rand.Reader, key, certs[0], chain, password)
+}
+
+func encodePKCS12Truststore(password string, caPEM []byte) ([]byte, error) {
+	cas, err := pki.DecodeX509CertificateChainBytes(caPEM)
+	if err != nil {
+		return nil, err
+	}
+	return pkcs12.EncodeTrustStore(rand.Reader, cas, password)
+}
+
+func encodeJKSKeystore(password []byte, rawKey, certPEM, caPEM []byte) ([]byte, error) {
+	key, err := pki.DecodePrivateKeyBytes(rawKey)
+	if err != nil {
+		return nil, err
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	certs, err := pki.DecodeX509CertificateChainBytes(certPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	ks := keystore.KeyStore{
+		jksCertificateAlias: &keystore.PrivateKeyEntry{
+			Entry:     keystore.Entry{CreationDate: time.Now()},
+			PrivKey:   keyDER,
+			CertChain: jksCertificates(certs),
+		},
+	}
+	if len(caPEM) > 0 {
+		cas, err := pki.DecodeX509CertificateChainBytes(caPEM)
+		if err != nil {
+			return nil, err
+		}
+		ks[jksCAAlias] = &keystore.TrustedCertificateEntry{
+			Entry:       keystore.Entry{CreationDate: time.Now()},
+			Certificate: jksCertificates(cas)[0],
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	if err := keystore.Encode(buf, ks, password); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func encodeJKSTruststore(password []byte, caPEM []byte) ([]byte, error) {
+	cas, err := pki.DecodeX509CertificateChainBytes(caPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	ks := keystore.KeyStore{}
+	for i, c := range jksCertificates(cas) {
+		alias := jksCAAlias
+		if i > 0 {
+			alias = fmt.Sprintf("%s-%d", jksCAAlias, i)
+		}
+		ks[alias] = &keystore.TrustedCertificateEntry{
+			Entry:       keystore.Entry{CreationDate: time.Now()},
+			Certificate: c,
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	if err := keystore.Encode(buf, ks, password); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func jksCertificates(certs []*x509.Certificate) []keystore.Certificate {
+	out := make([]keystore.Certificate, len(certs))
+	for i, c := range certs {
+		out[i] = keystore.Certificate{Type: "X509", Content: c.Raw}
+	}
+	return out
+}
@@ -0,0 +1,287 @@
+/*
+Copyright 2019 The Jetstack cert-manager contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificates
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/pavel-v-chernykh/keystore-go"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"software.sslmate.com/src/go-pkcs12"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha1"
+	"github.com/jetstack/cert-manager/test/unit/gen"
+)
+
+func TestSetKeystoreValues(t *testing.T) {
+	passwords := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: gen.DefaultTestNamespace, Name: "passwords"},
+		Data:       map[string][]byte{"password": []byte("changeit")},
+	}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	if err := indexer.Add(passwords); err != nil {
+		t.Fatal(err)
+	}
+	lister := corelisters.NewSecretLister(indexer)
+
+	passwordRef := cmapi.SecretKeySelector{LocalObjectReference: cmapi.LocalObjectReference{Name: "passwords"}, Key: "password"}
+	crt := gen.Certificate("test",
+		gen.SetCertificateNamespace(gen.DefaultTestNamespace),
+		gen.SetCertificateSecretName("output"),
+		gen.SetCertificateCommonName("example.com"),
+	)
+	bundle := mustCreateCryptoBundle(t, crt)
+	data := secretData{pk: bundle.privateKeyBytes, cert: bundle.certBytes, ca: bundle.certBytes}
+
+	keystoreCrt := gen.CertificateFrom(crt)
+	keystoreCrt.Spec.Keystores = &cmapi.CertificateKeystores{
+		JKS:    &cmapi.JKSKeystore{Create: true, PasswordSecretRef: passwordRef},
+		PKCS12: &cmapi.PKCS12Keystore{Create: true, PasswordSecretRef: passwordRef},
+	}
+	missingPasswordCrt := gen.CertificateFrom(keystoreCrt)
+	missingPasswordCrt.Spec.Keystores.PKCS12 = &cmapi.PKCS12Keystore{
+		Create:            true,
+		PasswordSecretRef: cmapi.SecretKeySelector{LocalObjectReference: cmapi.LocalObjectReference{Name: "missing"}, Key: "password"},
+	}
+
+	issuedData := func(extra map[string][]byte) map[string][]byte {
+		m := map[string][]byte{
+			corev1.TLSPrivateKeyKey: data.pk,
+			corev1.TLSCertKey:       data.cert,
+			cmapi.TLSCAKey:          data.ca,
+		}
+		for k, v := range extra {
+			m[k] = v
+		}
+		return m
+	}
+	existingKeystores := issuedData(map[string][]byte{
+		cmapi.JKSSecretKey:    []byte("existing"),
+		cmapi.PKCS12SecretKey: []byte("existing"),
+	})
+	password := []byte("changeit")
+	currentHash := keystoreHash(data.pk, data.cert, data.ca, true, password, true, password)
+
+	tests := map[string]struct {
+		crt          *cmapi.Certificate
+		existing     map[string][]byte
+		existingHash string
+		expectedKeys []string
+		unchanged    []string
+		expectedErr  bool
+	}{
+		"create keystores and truststores for a newly issued certificate": {
+			crt:          keystoreCrt,
+			existing:     issuedData(nil),
+			expectedKeys: []string{cmapi.JKSSecretKey, cmapi.JKSTruststoreKey, cmapi.PKCS12SecretKey, cmapi.PKCS12TruststoreKey},
+		},
+		"do not regenerate keystores if nothing has changed": {
+			crt:          keystoreCrt,
+			existing:     existingKeystores,
+			existingHash: currentHash,
+			expectedKeys: []string{cmapi.JKSSecretKey, cmapi.PKCS12SecretKey},
+			unchanged:    []string{cmapi.JKSSecretKey, cmapi.PKCS12SecretKey},
+		},
+		"regenerate keystores if the certificate has changed": {
+			crt:          keystoreCrt,
+			existing:     existingKeystores,
+			existingHash: keystoreHash(data.pk, []byte("old"), data.ca, true, password, true, password),
+			expectedKeys: []string{cmapi.JKSSecretKey, cmapi.JKSTruststoreKey, cmapi.PKCS12SecretKey, cmapi.PKCS12TruststoreKey},
+		},
+		"regenerate keystores if a keystore password has changed": {
+			crt:          keystoreCrt,
+			existing:     existingKeystores,
+			existingHash: keystoreHash(data.pk, data.cert, data.ca, true, []byte("old"), true, password),
+			expectedKeys: []string{cmapi.JKSSecretKey, cmapi.JKSTruststoreKey, cmapi.PKCS12SecretKey, cmapi.PKCS12TruststoreKey},
+		},
+		"regenerate keystores if they were generated for a different configuration": {
+			crt:          keystoreCrt,
+			existing:     existingKeystores,
+			existingHash: keystoreHash(data.pk, data.cert, data.ca, true, password, false, nil),
+			expectedKeys: []string{cmapi.JKSSecretKey, cmapi.JKSTruststoreKey, cmapi.PKCS12SecretKey, cmapi.PKCS12TruststoreKey},
+		},
+		"do not create keystores until a certificate has been issued": {
+			crt:      keystoreCrt,
+			existing: map[string][]byte{corev1.TLSPrivateKeyKey: data.pk},
+		},
+		"remove keystores that are no longer requested": {
+			crt:          crt,
+			existing:     issuedData(map[string][]byte{cmapi.JKSSecretKey: []byte("existing"), cmapi.PKCS12TruststoreKey: []byte("existing")}),
+			existingHash: currentHash,
+		},
+		"fail without changing the secret if the keystore password cannot be found": {
+			crt:          missingPasswordCrt,
+			existing:     existingKeystores,
+			existingHash: keystoreHash(data.pk, []byte("old"), data.ca, true, password, true, password),
+			expectedErr:  true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := &corev1.Secret{Data: make(map[string][]byte)}
+			for k, v := range test.existing {
+				s.Data[k] = v
+			}
+			if test.existingHash != "" {
+				s.Annotations = map[string]string{keystoreHashAnnotation: test.existingHash}
+			}
+			original := s.DeepCopy()
+
+			err := setKeystoreValues(lister, test.crt, s)
+			if err != nil != test.expectedErr {
+				t.Fatalf("expected error=%t, got: %v", test.expectedErr, err)
+			}
+			if test.expectedErr {
+				if !reflect.DeepEqual(s, original) {
+					t.Errorf("expected secret to be unchanged after an error")
+				}
+				return
+			}
+
+			for _, k := range []string{cmapi.JKSSecretKey, cmapi.JKSTruststoreKey, cmapi.PKCS12SecretKey, cmapi.PKCS12TruststoreKey} {
+				_, exists := s.Data[k]
+				expected := false
+				for _, e := range test.expectedKeys {
+					expected = expected || e == k
+				}
+				if exists != expected {
+					t.Errorf("expected key %q to exist=%t, but exist=%t", k, expected, exists)
+				}
+			}
+			for _, k := range test.unchanged {
+				if !bytes.Equal(s.Data[k], test.existing[k]) {
+					t.Errorf("expected key %q to be unchanged", k)
+				}
+			}
+
+			hash, annotated := s.Annotations[keystoreHashAnnotation]
+			if annotated != (len(test.expectedKeys) > 0) {
+				t.Errorf("expected keystore hash annotation to be set=%t, got %q", len(test.expectedKeys) > 0, hash)
+			}
+			if annotated && hash != currentHash {
+				t.Errorf("expected keystore hash annotation to be updated")
+			}
+		})
+	}
+}
+
+func TestUpdateKeystores(t *testing.T) {
+	crt := gen.Certificate("test",
+		gen.SetCertificateNamespace(gen.DefaultTestNamespace),
+		gen.SetCertificateSecretName("output"),
+		gen.SetCertificateCommonName("example.com"),
+	)
+	crt.Spec.Keystores = &cmapi.CertificateKeystores{
+		PKCS12: &cmapi.PKCS12Keystore{
+			Create:            true,
+			PasswordSecretRef: cmapi.SecretKeySelector{LocalObjectReference: cmapi.LocalObjectReference{Name: "passwords"}, Key: "password"},
+		},
+	}
+	bundle := mustCreateCryptoBundle(t, crt)
+	passwords := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: gen.DefaultTestNamespace, Name: "passwords"},
+		Data:       map[string][]byte{"password": []byte("changeit")},
+	}
+	output := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: gen.DefaultTestNamespace, Name: "output"},
+		Data: map[string][]byte{
+			corev1.TLSPrivateKeyKey: bundle.privateKeyBytes,
+			corev1.TLSCertKey:       bundle.certBytes,
+		},
+	}
+
+	cl := fake.NewSimpleClientset(passwords, output)
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, obj := range []*corev1.Secret{passwords, output} {
+		if err := indexer.Add(obj); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lister := corelisters.NewSecretLister(indexer)
+
+	if err := updateKeystores(cl, lister, crt); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	updated, err := cl.CoreV1().Secrets(gen.DefaultTestNamespace).Get("output", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(updated.Data[cmapi.PKCS12SecretKey]) == 0 {
+		t.Fatalf("expected out of date keystores to be generated")
+	}
+
+	// once the keystores are up to date, the Secret is not updated again
+	if err := indexer.Update(updated); err != nil {
+		t.Fatal(err)
+	}
+	cl.ClearActions()
+	if err := updateKeystores(cl, lister, crt); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if l := len(cl.Actions()); l != 0 {
+		t.Errorf("expected up to date keystores to not be updated, got %d actions", l)
+	}
+}
+
+func TestEncodeKeystores(t *testing.T) {
+	crt := gen.Certificate("test", gen.SetCertificateCommonName("example.com"))
+	bundle := mustCreateCryptoBundle(t, crt)
+	password := "changeit"
+
+	p12, err := encodePKCS12Keystore(password, bundle.privateKeyBytes, bundle.certBytes, bundle.certBytes)
+	if err != nil {
+		t.Fatalf("unexpected error encoding PKCS#12 keystore: %v", err)
+	}
+	_, cert, cas, err := pkcs12.DecodeChain(p12, password)
+	if err != nil {
+		t.Fatalf("unexpected error decoding PKCS#12 keystore: %v", err)
+	}
+	if !cert.Equal(bundle.cert) || len(cas) != 1 {
+		t.Errorf("expected PKCS#12 keystore to contain the certificate and one CA, got %d CAs", len(cas))
+	}
+
+	jks, err := encodeJKSKeystore([]byte(password), bundle.privateKeyBytes, bundle.certBytes, bundle.certBytes)
+	if err != nil {
+		t.Fatalf("unexpected error encoding JKS keystore: %v", err)
+	}
+	ks, err := keystore.Decode(bytes.NewReader(jks), []byte(password))
+	if err != nil {
+		t.Fatalf("unexpected error decoding JKS keystore: %v", err)
+	}
+	entry, ok := ks[jksCertificateAlias].(*keystore.PrivateKeyEntry)
+	if !ok || len(entry.CertChain) != 1 || !bytes.Equal(entry.CertChain[0].Content, bundle.cert.Raw) {
+		t.Errorf("expected JKS keystore to contain the certificate, got %v", ks[jksCertificateAlias])
+	}
+	if _, ok := ks[jksCAAlias].(*keystore.TrustedCertificateEntry); !ok {
+		t.Errorf("expected JKS keystore to contain the CA")
+	}
+
+	jksTrust, err := encodeJKSTruststore([]byte(password), bundle.certBytes)
+	if err != nil {
+		t.Fatalf("unexpected error encoding JKS truststore: %v", err)
+	}
+	if ts, err := keystore.Decode(bytes.NewReader(jksTrust), []byte(password)); err != nil || len(ts) != 1 {
+		t.Errorf("expected JKS truststore with one entry, got %d entries and error %v", len(ts), err)
+	}
+}
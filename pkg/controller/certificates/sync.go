@@ -26,6 +26,7 @@ import (
 	"context"
 	"crypto"
 	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/json"
 	"fmt"
 	"math/big"
@@ -40,9 +41,10 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
-	v1 "k8s.io/client-go/kubernetes/typed/apps/v1"
+	"k8s.io/client-go/kubernetes"
 	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/klog"
 
 	apiutil "github.com/jetstack/cert-manager/pkg/api/util"
@@ -50,6 +52,7 @@ import (
 	cmclient "github.com/jetstack/cert-manager/pkg/client/clientset/versioned"
 	"github.com/jetstack/cert-manager/pkg/feature"
 	"github.com/jetstack/cert-manager/pkg/internal/apis/certmanager/validation"
+	"github.com/jetstack/cert-manager/pkg/internal/vault"
 	"github.com/jetstack/cert-manager/pkg/issuer"
 	logf "github.com/jetstack/cert-manager/pkg/logs"
 	"github.com/jetstack/cert-manager/pkg/metrics"
@@ -68,17 +71,16 @@ const (
 	errorConfig              = "ConfigError"
 	errorDuplicateSecretName = "DuplicateSecretNameError"
 
-	reasonIssuingCertificate  = "IssueCert"
-	reasonRenewingCertificate = "RenewCert"
+	reasonIssuingCertificate    = "IssueCert"
+	reasonRenewingCertificate   = "RenewCert"
+	reasonRevokedKeyPairRemoved = "RevokedKeyPairRemoved"
+	reasonPodRefreshDisabled    = "PodRefreshDisabled"
 
 	successCertificateIssued  = "CertIssued"
 	successCertificateRenewed = "CertRenewed"
 
 	messageErrorSavingCertificate = "Error saving TLS certificate: "
 
-	restartLabel        = "certmanager.k8s.io/time-restarted"
-	noRestartAnnotation = "certmanager.k8s.io/disable-auto-restart"
-
 	issuerNameLabel = "certmanager.k8s.io/issuer-name"
 	issuerKindLabel = "certmanager.k8s.io/issuer-kind"
 )
@@ -105,6 +107,8 @@ func (c *controller) Sync(ctx context.Context, crt *v1alpha1.Certificate) (err e
 		return nil
 	}
 
+	warnIfPodRefreshDisabled(c.recorder, crt, c.podRefreshEnabled)
+
 	crtCopy := crt.DeepCopy()
 	defer func() {
 		// ICP - using updateCertificate function instead to see a full update
@@ -113,8 +117,6 @@ func (c *controller) Sync(ctx context.Context, crt *v1alpha1.Certificate) (err e
 		}
 	}()
 
-	renew := len(crt.Status.Conditions)
-
 	dbg.Info("Fetching existing certificate from secret", "name", crtCopy.Spec.SecretName)
 	// grab existing certificate and validate private key
 	certs, key, err := kube.SecretTLSKeyPair(ctx, c.secretLister, crtCopy.Namespace, crtCopy.Spec.SecretName)
@@ -129,6 +131,17 @@ func (c *controller) Sync(ctx context.Context, crt *v1alpha1.Certificate) (err e
 		cert = certs[0]
 	}
 
+	if certificateRevoked(crtCopy, cert) {
+		dbg.Info("Existing certificate has been revoked, removing it and its private key from the secret")
+		if err := removeRevokedKeyPair(c.kClient, c.secretLister, crtCopy); err != nil {
+			return err
+		}
+		c.recorder.Eventf(crtCopy, corev1.EventTypeNormal, reasonRevokedKeyPairRemoved, "Removed revoked certificate with serial number %s and its private key from Secret %q", cert.SerialNumber, crtCopy.Spec.SecretName)
+		// the update to the Secret will cause a new certificate to be
+		// issued for a new private key
+		return nil
+	}
+
 	// update certificate expiry metric
 	defer c.metrics.UpdateCertificateExpiry(crtCopy, c.secretLister)
 	dbg.Info("Update certificate status if required")
@@ -202,21 +215,21 @@ func (c *controller) Sync(ctx context.Context, crt *v1alpha1.Certificate) (err e
 
 	if isTemporaryCertificate(cert) {
 		dbg.Info("Temporary certificate found - calling 'issue'")
-		return c.issue(ctx, i, crtCopy, renew)
+		return c.issue(ctx, i, crtCopy)
 	}
 
 	if key == nil || cert == nil {
 		klog.V(4).Infof("Invoking issue function as existing certificate does not exist")
 		dbg.Info("Invoking issue function as existing certificate does not exist")
-		return c.issue(ctx, i, crtCopy, renew)
+		return c.issue(ctx, i, crtCopy)
 	}
 
 	// begin checking if the TLS certificate is valid/needs a re-issue or renew
-	matches, matchErrs := certificateMatchesSpec(crtCopy, key, cert, c.secretLister)
+	matches, matchErrs := certificateMatchesSpec(crtCopy, issuerObj, key, cert, c.secretLister)
 	if !matches {
 		klog.V(4).Infof("Invoking issue function due to certificate not matching spec: %s", strings.Join(matchErrs, ", "))
 		dbg.Info("invoking issue function due to certificate not matching spec", "diff", strings.Join(matchErrs, ", "))
-		return c.issue(ctx, i, crtCopy, renew)
+		return c.issue(ctx, i, crtCopy)
 	}
 
 	klog.V(4).Infof("Issuer creation timestamp: %v\nCert not before timestamp: %v", issuerObj.GetObjectMeta().GetCreationTimestamp().Time, cert.NotBefore)
@@ -225,11 +238,17 @@ func (c *controller) Sync(ctx context.Context, crt *v1alpha1.Certificate) (err e
 	if needsRenew {
 		klog.V(4).Infof("Invoking issue function due to certificate needing renewal")
 		dbg.Info("invoking issue function due to certificate needing renewal")
-		return c.issue(ctx, i, crtCopy, renew)
+		return c.issue(ctx, i, crtCopy)
 	}
 
 	// end checking if the TLS certificate is valid/needs a re-issue or renew
 
+	if err := updateKeystores(c.kClient, c.secretLister, crtCopy); err != nil {
+		log.Error(err, "error updating keystores")
+		c.recorder.Eventf(crtCopy, corev1.EventTypeWarning, reasonKeystoreError, "Failed to update keystores: %v", err)
+		return err
+	}
+
 	dbg.Info("Certificate does not need updating. Scheduling renewal.")
 	// If the Certificate is valid and up to date, we schedule a renewal in
 	// the future.
@@ -255,7 +274,7 @@ func (c *controller) setCertificateStatus(crt *v1alpha1.Certificate, key crypto.
 	crt.Status.NotAfter = &metaNotAfter
 
 	// Derive & set 'Ready' condition on Certificate resource
-	matches, matchErrs := certificateMatchesSpec(crt, key, cert, c.secretLister)
+	matches, matchErrs := certificateMatchesSpec(crt, c.issuerForCertificate(crt), key, cert, c.secretLister)
 	ready := v1alpha1.ConditionFalse
 	reason := ""
 	message := ""
@@ -281,11 +300,23 @@ func (c *controller) setCertificateStatus(crt *v1alpha1.Certificate, key crypto.
 	return
 }
 
-func certificateMatchesSpec(crt *v1alpha1.Certificate, key crypto.Signer, cert *x509.Certificate, secretLister corelisters.SecretLister) (bool, []string) {
+// issuerForCertificate returns the issuer referenced by the Certificate, or
+// nil if it cannot be retrieved.
+func (c *controller) issuerForCertificate(crt *v1alpha1.Certificate) v1alpha1.GenericIssuer {
+	iss, err := c.helper.GetGenericIssuer(crt.Spec.IssuerRef, crt.Namespace)
+	if err != nil {
+		return nil
+	}
+	return iss
+}
+
+// certificateMatchesSpec returns true if the given certificate and private
+// key match the spec of the Certificate. iss is the issuer of the
+// certificate, which may be nil if it is not known.
+func certificateMatchesSpec(crt *v1alpha1.Certificate, iss v1alpha1.GenericIssuer, key crypto.Signer, cert *x509.Certificate, secretLister corelisters.SecretLister) (bool, []string) {
 	var errs []string
 
 	// TODO: add checks for KeySize, KeyAlgorithm fields
-	// TODO: add checks for Organization field
 	// TODO: add checks for IsCA field
 
 	// check if the private key is the corresponding pair to the certificate
@@ -313,6 +344,21 @@ func certificateMatchesSpec(crt *v1alpha1.Certificate, key crypto.Signer, cert *
 		errs = append(errs, fmt.Sprintf("IP addresses on TLS certificate not up to date: %q", pki.IPAddressesToString(cert.IPAddresses)))
 	}
 
+	// validate the uri and email SANs are correct
+	if !util.EqualUnsorted(pki.URIsToString(cert.URIs), crt.Spec.URISANs) {
+		errs = append(errs, fmt.Sprintf("URI SANs on TLS certificate not up to date: %q", pki.URIsToString(cert.URIs)))
+	}
+	if !util.EqualUnsorted(cert.EmailAddresses, crt.Spec.EmailSANs) {
+		errs = append(errs, fmt.Sprintf("Email SANs on TLS certificate not up to date: %q", cert.EmailAddresses))
+	}
+
+	// validate the requested subject fields are correct. Issuers that do not
+	// honour the requested subject would otherwise cause certificates to be
+	// reissued on every sync.
+	if issuerHonoursSubject(iss) {
+		errs = append(errs, subjectMismatches(crt, cert.Subject)...)
+	}
+
 	// get a copy of the current secret resource
 	// Note that we already know that it exists, no need to check for errors
 	// TODO: Refactor so that the secret is passed as argument?
@@ -331,6 +377,58 @@ func certificateMatchesSpec(crt *v1alpha1.Certificate, key crypto.Signer, cert *
 	return len(errs) == 0, errs
 }
 
+// issuerHonoursSubject returns true if certificates signed by the given
+// issuer contain the subject requested by a Certificate. ACME CAs drop
+// subject fields, as does Vault's sign endpoint if the sign-verbatim endpoint
+// of the role cannot be used instead. Venafi issuers validate the requested
+// subject against the zone policy and submit it as is, so requests for
+// subjects the policy would override fail rather than being altered.
+// iss may be nil if the issuer is not known.
+func issuerHonoursSubject(iss v1alpha1.GenericIssuer) bool {
+	if iss == nil {
+		return false
+	}
+	spec := iss.GetSpec()
+	switch {
+	case spec.CA != nil, spec.SelfSigned != nil, spec.Venafi != nil:
+		return true
+	case spec.Vault != nil:
+		if spec.Vault.SignMode == v1alpha1.VaultSignModeSignVerbatim {
+			return true
+		}
+		_, ok := vault.SignVerbatimPath(spec.Vault.Path)
+		return ok
+	}
+	return false
+}
+
+// subjectMismatches returns a message for each subject field explicitly set
+// on the Certificate that does not match the given subject.
+// Fields that are not set on the Certificate are not compared, as issuers may
+// populate them with defaults.
+func subjectMismatches(crt *v1alpha1.Certificate, subject pkix.Name) []string {
+	var errs []string
+	check := func(name string, expected, actual []string) {
+		if len(expected) > 0 && !util.EqualUnsorted(expected, actual) {
+			errs = append(errs, fmt.Sprintf("%s on TLS certificate not up to date: %q", name, actual))
+		}
+	}
+
+	check("Organization", crt.Spec.Organization, subject.Organization)
+	if s := crt.Spec.Subject; s != nil {
+		check("Countries", s.Countries, subject.Country)
+		check("Organizational units", s.OrganizationalUnits, subject.OrganizationalUnit)
+		check("Localities", s.Localities, subject.Locality)
+		check("Provinces", s.Provinces, subject.Province)
+		check("Street addresses", s.StreetAddresses, subject.StreetAddress)
+		check("Postal codes", s.PostalCodes, subject.PostalCode)
+		if s.SerialNumber != "" && s.SerialNumber != subject.SerialNumber {
+			errs = append(errs, fmt.Sprintf("Subject serial number on TLS certificate not up to date: %q", subject.SerialNumber))
+		}
+	}
+	return errs
+}
+
 func scheduleRenewal(ctx context.Context, lister corelisters.SecretLister, calc calculateDurationUntilRenewFn, queueFn func(interface{}, time.Duration), crt *v1alpha1.Certificate) {
 	log := logf.FromContext(ctx)
 	log = log.WithValues(
@@ -377,7 +475,7 @@ func ownerRef(crt *v1alpha1.Certificate) metav1.OwnerReference {
 // - If the provided certificate is a temporary certificate and the certificate
 //   stored in the secret is already a temporary certificate, then the Secret
 //   **will not** be updated.
-func (c *controller) updateSecret(ctx context.Context, crt *v1alpha1.Certificate, namespace string, cert, key, ca []byte, renew int) (*corev1.Secret, error) {
+func (c *controller) updateSecret(ctx context.Context, crt *v1alpha1.Certificate, namespace string, cert, key, ca []byte) (*corev1.Secret, error) {
 	log := logf.FromContext(ctx, "updateSecret")
 	log = logf.WithRelatedResourceName(log, crt.Spec.SecretName, namespace, "Secret")
 	// if the key is not set, we bail out early.
@@ -491,6 +589,15 @@ func (c *controller) updateSecret(ctx context.Context, crt *v1alpha1.Certificate
 	secret.Data[corev1.TLSPrivateKeyKey] = key
 	secret.Data[v1alpha1.TLSCAKey] = ca
 
+	// keystores are built from the PEM data set above. Failing to build them
+	// must not cause the certificate to be discarded, so the error is only
+	// reported here and the keystores are retried once the certificate is
+	// ready.
+	if err := setKeystoreValues(c.secretLister, crt, secret); err != nil {
+		log.Error(err, "error generating keystores")
+		c.recorder.Eventf(crt, corev1.EventTypeWarning, reasonKeystoreError, "Failed to generate keystores: %v", err)
+	}
+
 	// if it is a new resource
 	if secret.SelfLink == "" {
 		// ACM Uninstall support - OWNED_NAMESPACE is set to ACM install namespace
@@ -505,82 +612,12 @@ func (c *controller) updateSecret(ctx context.Context, crt *v1alpha1.Certificate
 		return nil, err
 	}
 
-	// ICP: When it's an updated certificate/secret and pod refresh is enabled, restart
-	// pods associated with the Deployments, Statefulsets, and/or Daemonsets that mount this certificate
-	if renew > 0 && c.enablePodRefresh {
-		deploymentsInterface := c.kClient.AppsV1().Deployments(namespace)
-		statefulsetsInterface := c.kClient.AppsV1().StatefulSets(namespace)
-		daemonsetsInterface := c.kClient.AppsV1().DaemonSets(namespace)
-
-		restart(deploymentsInterface, statefulsetsInterface, daemonsetsInterface, secret.Name, crt.Name)
-	}
-
 	return secret, nil
 }
 
-// ICP: Restart will run every time a secret is updated for a certificate and when
-// pod refresh is enabled. It will edit the deployments, statefulsets, and daemonsets
-// that use the secret being updated, which will trigger the pod to be restarted.
-func restart(deploymentsInterface v1.DeploymentInterface, statefulsetsInterface v1.StatefulSetInterface, daemonsetsInterface v1.DaemonSetInterface, secret, cert string) {
-	listOptions := metav1.ListOptions{}
-	deployments, _ := deploymentsInterface.List(listOptions)
-	statefulsets, _ := statefulsetsInterface.List(listOptions)
-	daemonsets, _ := daemonsetsInterface.List(listOptions)
-
-	update := time.Now().Format("2006-1-2.1504")
-NEXT_DEPLOYMENT:
-	for _, adeployment := range deployments.Items {
-		deployment := adeployment
-		for _, volume := range deployment.Spec.Template.Spec.Volumes {
-			if volume.Secret != nil && volume.Secret.SecretName != "" && volume.Secret.SecretName == secret && deployment.ObjectMeta.Annotations[noRestartAnnotation] != "true" {
-				deployment.ObjectMeta.Labels[restartLabel] = update
-				deployment.Spec.Template.ObjectMeta.Labels[restartLabel] = update
-				_, err := deploymentsInterface.Update(&deployment)
-				if err != nil {
-					fmt.Errorf("Error updating deployment: %v", err)
-				}
-				klog.Infof("%s Cert-Manager Restarting Resource: Certificate=%s, Secret=%s, Deployment=%s", update, cert, secret, deployment.ObjectMeta.Name)
-				continue NEXT_DEPLOYMENT
-			}
-		}
-	}
-NEXT_STATEFULSET:
-	for _, astatefulset := range statefulsets.Items {
-		statefulset := astatefulset
-		for _, volume := range statefulset.Spec.Template.Spec.Volumes {
-			if volume.Secret != nil && volume.Secret.SecretName != "" && volume.Secret.SecretName == secret && statefulset.ObjectMeta.Annotations[noRestartAnnotation] != "true" {
-				statefulset.ObjectMeta.Labels[restartLabel] = update
-				statefulset.Spec.Template.ObjectMeta.Labels[restartLabel] = update
-				_, err := statefulsetsInterface.Update(&statefulset)
-				if err != nil {
-					fmt.Errorf("Error updating statefulset: %v", err)
-				}
-				klog.Infof("%s Cert-Manager Restarting Resource: Certificate=%s, Secret=%s, StatefulSet=%s", update, cert, secret, statefulset.ObjectMeta.Name)
-				continue NEXT_STATEFULSET
-			}
-		}
-	}
-NEXT_DAEMONSET:
-	for _, adaemonset := range daemonsets.Items {
-		daemonset := adaemonset
-		for _, volume := range daemonset.Spec.Template.Spec.Volumes {
-			if volume.Secret != nil && volume.Secret.SecretName != "" && volume.Secret.SecretName == secret && daemonset.ObjectMeta.Annotations[noRestartAnnotation] != "true" {
-				daemonset.ObjectMeta.Labels[restartLabel] = update
-				daemonset.Spec.Template.ObjectMeta.Labels[restartLabel] = update
-				_, err := daemonsetsInterface.Update(&daemonset)
-				if err != nil {
-					fmt.Errorf("Error updating daemonset: %v", err)
-				}
-				klog.Infof("%s Cert-Manager Restarting Resource: Certificate=%s, Secret=%s, DaemonSet=%s", update, cert, secret, daemonset.ObjectMeta.Name)
-				continue NEXT_DAEMONSET
-			}
-		}
-	}
-}
-
 // return an error on failure. If retrieval is succesful, the certificate data
 // and private key will be stored in the named secret
-func (c *controller) issue(ctx context.Context, issuer issuer.Interface, crt *v1alpha1.Certificate, renew int) error {
+func (c *controller) issue(ctx context.Context, issuer issuer.Interface, crt *v1alpha1.Certificate) error {
 	log := logf.FromContext(ctx)
 	resp, err := issuer.Issue(ctx, crt)
 	if err != nil {
@@ -592,7 +629,7 @@ func (c *controller) issue(ctx context.Context, issuer issuer.Interface, crt *v1
 		return nil
 	}
 
-	if _, err := c.updateSecret(ctx, crt, crt.Namespace, resp.Certificate, resp.PrivateKey, resp.CA, renew); err != nil {
+	if _, err := c.updateSecret(ctx, crt, crt.Namespace, resp.Certificate, resp.PrivateKey, resp.CA); err != nil {
 		s := messageErrorSavingCertificate + err.Error()
 		log.Error(err, "error saving certificate")
 		c.recorder.Event(crt, corev1.EventTypeWarning, errorSavingCertificate, s)
@@ -600,6 +637,15 @@ func (c *controller) issue(ctx context.Context, issuer issuer.Interface, crt *v1
 	}
 
 	if len(resp.Certificate) > 0 {
+		// issuers that complete asynchronously store the next private key
+		// separately until a certificate has been issued for it
+		if crt.Spec.RotationPolicy == v1alpha1.RotationPolicyAlways {
+			if err := kube.DeleteNextPrivateKey(c.kClient, crt); err != nil {
+				log.Error(err, "error deleting next private key")
+				return err
+			}
+		}
+
 		c.recorder.Event(crt, corev1.EventTypeNormal, successCertificateIssued, "Certificate issued successfully")
 		// as we have just written a certificate, we should schedule it for renewal
 		scheduleRenewal(ctx, c.secretLister, c.calculateDurationUntilRenew, c.scheduledWorkQueue.Add, crt)
@@ -609,6 +655,45 @@ func (c *controller) issue(ctx context.Context, issuer issuer.Interface, crt *v1
 	return nil
 }
 
+// warnIfPodRefreshDisabled records a Warning Event if the Certificate sets
+// spec.podRefresh but the pod refresh controller is not running, in which
+// case no workloads will be refreshed when it is renewed.
+func warnIfPodRefreshDisabled(recorder record.EventRecorder, crt *v1alpha1.Certificate, podRefreshEnabled bool) {
+	if crt.Spec.PodRefresh == nil || podRefreshEnabled {
+		return
+	}
+	recorder.Event(crt, corev1.EventTypeWarning, reasonPodRefreshDisabled, "spec.podRefresh is set but the podrefresh controller is not enabled, so workloads will not be refreshed when the certificate is renewed")
+}
+
+// certificateRevoked returns true if cert is the certificate that has been
+// recorded as revoked in the status of the Certificate.
+func certificateRevoked(crt *v1alpha1.Certificate, cert *x509.Certificate) bool {
+	if cert == nil || crt.Status.RevocationSerialNumber != cert.SerialNumber.String() {
+		return false
+	}
+	return apiutil.CertificateHasCondition(crt, v1alpha1.CertificateCondition{
+		Type:   v1alpha1.CertificateConditionRevoked,
+		Status: v1alpha1.ConditionTrue,
+	})
+}
+
+// removeRevokedKeyPair removes the certificate, private key, keystores and
+// truststores from the Secret of the Certificate, so that a new private key is generated
+// and a new certificate is issued for it.
+func removeRevokedKeyPair(cl kubernetes.Interface, secretLister corelisters.SecretLister, crt *v1alpha1.Certificate) error {
+	secret, err := secretLister.Secrets(crt.Namespace).Get(crt.Spec.SecretName)
+	if err != nil {
+		return err
+	}
+	secret = secret.DeepCopy()
+	for _, k := range []string{corev1.TLSCertKey, corev1.TLSPrivateKeyKey, v1alpha1.JKSSecretKey, v1alpha1.PKCS12SecretKey, v1alpha1.JKSTruststoreKey, v1alpha1.PKCS12TruststoreKey} {
+		delete(secret.Data, k)
+	}
+	delete(secret.Annotations, keystoreHashAnnotation)
+	_, err = cl.CoreV1().Secrets(secret.Namespace).Update(secret)
+	return err
+}
+
 // staticTemporarySerialNumber is a fixed serial number we check for when
 // updating the status of a certificate.
 // It is used to identify temporarily generated certificates, so that friendly
@@ -34,6 +34,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"strings"
 	"testing"
 	"time"
 
@@ -41,6 +42,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	coretesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
 	featuregatetesting "k8s.io/component-base/featuregate/testing"
 	clock "k8s.io/utils/clock/testing"
 
@@ -179,10 +181,70 @@ func TestSync(t *testing.T) {
 
 	localTempCert := generateSelfSignedCert(t, exampleCert, big.NewInt(staticTemporarySerialNumber), pk1, nowTime, nowTime)
 
+	exampleCertRotate := gen.CertificateFrom(exampleCert,
+		gen.SetCertificateRotationPolicy(cmapi.RotationPolicyAlways),
+	)
+	exampleCertRotateNotFoundCondition := gen.CertificateFrom(exampleCertNotFoundCondition,
+		gen.SetCertificateRotationPolicy(cmapi.RotationPolicyAlways),
+	)
+
+	exampleCertRevoked := gen.CertificateFrom(exampleCert,
+		gen.SetCertificateStatusCondition(cmapi.CertificateCondition{
+			Type:               cmapi.CertificateConditionRevoked,
+			Status:             cmapi.ConditionTrue,
+			Reason:             "Revoked",
+			LastTransitionTime: &nowMetaTime,
+		}),
+	)
+	exampleCertRevoked.Status.RevocationSerialNumber = cert1.SerialNumber.String()
+
 	exampleCertWrongGroup := exampleCert.DeepCopy()
 	exampleCertWrongGroup.Spec.IssuerRef.Group = "wrong.group.io"
 
 	tests := map[string]testTDefault{
+		"should remove a revoked certificate and its private key from the secret": {
+			certificate: exampleCertRevoked,
+			issuerImpl:  &fake.Issuer{},
+			builder: &testpkg.Builder{
+				KubeObjects: []runtime.Object{
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{
+							Namespace: gen.DefaultTestNamespace,
+							Name:      "output",
+						},
+						Data: map[string][]byte{
+							corev1.TLSCertKey:         cert1PEM,
+							corev1.TLSPrivateKeyKey:   pk1PEM,
+							cmapi.TLSCAKey:            cert1PEM,
+							cmapi.JKSTruststoreKey:    []byte("truststore"),
+							cmapi.PKCS12TruststoreKey: []byte("truststore"),
+						},
+					},
+				},
+				CertManagerObjects: []runtime.Object{
+					testIssuerReady,
+					exampleCertRevoked,
+				},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateAction(
+						corev1.SchemeGroupVersion.WithResource("secrets"),
+						gen.DefaultTestNamespace,
+						&corev1.Secret{
+							ObjectMeta: metav1.ObjectMeta{
+								Namespace: gen.DefaultTestNamespace,
+								Name:      "output",
+							},
+							Data: map[string][]byte{
+								cmapi.TLSCAKey: cert1PEM,
+							},
+						},
+					)),
+				},
+				ExpectedEvents: []string{
+					fmt.Sprintf(`Normal RevokedKeyPairRemoved Removed revoked certificate with serial number %s and its private key from Secret "output"`, cert1.SerialNumber),
+				},
+			},
+		},
 		"should update certificate with NotExists if issuer does not return a keypair": {
 			certificate: exampleCert,
 			issuerImpl: &fake.Issuer{
@@ -447,6 +509,72 @@ func TestSync(t *testing.T) {
 				ExpectedEvents: []string{`Normal CertIssued Certificate issued successfully`},
 			},
 		},
+		"should delete the next private key once a certificate is issued if the rotationPolicy is Always": {
+			certificate: exampleCertRotate,
+			issuerImpl: &fake.Issuer{
+				IssueFunc: func(context.Context, *cmapi.Certificate) (*issuer.IssueResponse, error) {
+					return &issuer.IssueResponse{
+						PrivateKey:  pk1PEM,
+						Certificate: cert1PEM,
+					}, nil
+				},
+			},
+			builder: &testpkg.Builder{
+				KubeObjects: []runtime.Object{
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{
+							Namespace: gen.DefaultTestNamespace,
+							Name:      "output",
+							SelfLink:  "abc",
+						},
+					},
+				},
+				CertManagerObjects: []runtime.Object{
+					testIssuerReady,
+					exampleCertRotate,
+				},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateAction(
+						cmapi.SchemeGroupVersion.WithResource("certificates"),
+						gen.DefaultTestNamespace,
+						exampleCertRotateNotFoundCondition,
+					)),
+					testpkg.NewAction(coretesting.NewUpdateAction(
+						corev1.SchemeGroupVersion.WithResource("secrets"),
+						gen.DefaultTestNamespace,
+						&corev1.Secret{
+							ObjectMeta: metav1.ObjectMeta{
+								Namespace: gen.DefaultTestNamespace,
+								Name:      "output",
+								SelfLink:  "abc",
+								Labels: map[string]string{
+									cmapi.CertificateNameKey: "test",
+								},
+								Annotations: map[string]string{
+									cmapi.CertificateNameKey:         "test",
+									"certmanager.k8s.io/alt-names":   "example.com",
+									"certmanager.k8s.io/common-name": "example.com",
+									"certmanager.k8s.io/ip-sans":     "",
+									"certmanager.k8s.io/issuer-kind": "Issuer",
+									"certmanager.k8s.io/issuer-name": "test",
+								},
+							},
+							Data: map[string][]byte{
+								corev1.TLSCertKey:       cert1PEM,
+								corev1.TLSPrivateKeyKey: pk1PEM,
+								cmapi.TLSCAKey:          nil,
+							},
+						},
+					)),
+					testpkg.NewAction(coretesting.NewDeleteAction(
+						corev1.SchemeGroupVersion.WithResource("secrets"),
+						gen.DefaultTestNamespace,
+						"test-next-private-key",
+					)),
+				},
+				ExpectedEvents: []string{`Normal CertIssued Certificate issued successfully`},
+			},
+		},
 		"should mark certificate with invalid private key as DoesNotMatch": {
 			certificate: exampleCert,
 			issuerImpl: &fake.Issuer{
@@ -1055,3 +1183,137 @@ func runTestDefault(t *testing.T, test testTDefault) {
 
 	test.builder.CheckAndFinish(err)
 }
+
+func TestSubjectMismatches(t *testing.T) {
+	subject := pkix.Name{
+		CommonName:         "example.com",
+		Organization:       []string{"cert-manager"},
+		OrganizationalUnit: []string{"b", "a"},
+		Country:            []string{"GB"},
+		SerialNumber:       "1234",
+	}
+
+	tests := map[string]struct {
+		crt          *cmapi.Certificate
+		expectedErrs int
+	}{
+		"no subject fields requested": {
+			crt: gen.Certificate("test"),
+		},
+		"requested subject fields match in any order": {
+			crt: gen.Certificate("test",
+				gen.SetCertificateOrganization("cert-manager"),
+				func(crt *cmapi.Certificate) {
+					crt.Spec.Subject = &cmapi.X509Subject{
+						OrganizationalUnits: []string{"a", "b"},
+						SerialNumber:        "1234",
+					}
+				},
+			),
+		},
+		"requested subject fields do not match": {
+			crt: gen.Certificate("test",
+				gen.SetCertificateOrganization("other"),
+				func(crt *cmapi.Certificate) {
+					crt.Spec.Subject = &cmapi.X509Subject{
+						Countries:    []string{"US"},
+						Localities:   []string{"London"},
+						SerialNumber: "5678",
+					}
+				},
+			),
+			expectedErrs: 4,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			errs := subjectMismatches(test.crt, subject)
+			if len(errs) != test.expectedErrs {
+				t.Errorf("expected %d errors but got: %v", test.expectedErrs, errs)
+			}
+		})
+	}
+}
+
+func TestIssuerHonoursSubject(t *testing.T) {
+	tests := map[string]struct {
+		issuer   cmapi.GenericIssuer
+		expected bool
+	}{
+		"unknown issuer": {},
+		"ca issuer": {
+			issuer:   gen.Issuer("test", gen.SetIssuerCA(cmapi.CAIssuer{})),
+			expected: true,
+		},
+		"selfsigned cluster issuer": {
+			issuer:   gen.ClusterIssuer("test", gen.SetIssuerSelfSigned(cmapi.SelfSignedIssuer{})),
+			expected: true,
+		},
+		"vault issuer in sign verbatim mode": {
+			issuer:   gen.Issuer("test", gen.SetIssuerVault(cmapi.VaultIssuer{SignMode: cmapi.VaultSignModeSignVerbatim})),
+			expected: true,
+		},
+		"vault issuer in sign mode": {
+			issuer:   gen.Issuer("test", gen.SetIssuerVault(cmapi.VaultIssuer{SignMode: cmapi.VaultSignModeSign, Path: "pki/sign/role"})),
+			expected: true,
+		},
+		"vault issuer in sign mode with a path that is not a sign endpoint": {
+			issuer: gen.Issuer("test", gen.SetIssuerVault(cmapi.VaultIssuer{SignMode: cmapi.VaultSignModeSign, Path: "pki/issue/role"})),
+		},
+		"acme issuer": {
+			issuer: gen.Issuer("test", gen.SetIssuerACME(cmapi.ACMEIssuer{})),
+		},
+		"venafi issuer": {
+			issuer:   gen.Issuer("test", gen.SetIssuerVenafi(cmapi.VenafiIssuer{})),
+			expected: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if actual := issuerHonoursSubject(test.issuer); actual != test.expected {
+				t.Errorf("expected %t but got %t", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestWarnIfPodRefreshDisabled(t *testing.T) {
+	tests := map[string]struct {
+		certificate       *cmapi.Certificate
+		podRefreshEnabled bool
+		expectWarning     bool
+	}{
+		"pod refresh not requested": {
+			certificate: gen.Certificate("test"),
+		},
+		"pod refresh requested and controller enabled": {
+			certificate:       gen.Certificate("test", gen.SetCertificatePodRefresh(cmapi.PodRefreshConfig{})),
+			podRefreshEnabled: true,
+		},
+		"pod refresh requested and controller disabled": {
+			certificate:   gen.Certificate("test", gen.SetCertificatePodRefresh(cmapi.PodRefreshConfig{})),
+			expectWarning: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			recorder := record.NewFakeRecorder(1)
+			warnIfPodRefreshDisabled(recorder, test.certificate, test.podRefreshEnabled)
+			select {
+			case event := <-recorder.Events:
+				if !test.expectWarning {
+					t.Errorf("unexpected event: %s", event)
+				} else if !strings.HasPrefix(event, "Warning "+reasonPodRefreshDisabled) {
+					t.Errorf("unexpected event: %s", event)
+				}
+			default:
+				if test.expectWarning {
+					t.Errorf("expected a warning event")
+				}
+			}
+		})
+	}
+}
@@ -38,8 +38,15 @@ func (c *controller) issuersForSecret(secret *corev1.Secret) ([]*v1alpha1.Cluste
 			continue
 		}
 		if (iss.Spec.ACME != nil && iss.Spec.ACME.PrivateKey.Name == secret.Name) ||
+			(iss.Spec.ACME != nil && iss.Spec.ACME.ExternalAccountBinding != nil && iss.Spec.ACME.ExternalAccountBinding.Key.Name == secret.Name) ||
 			(iss.Spec.CA != nil && iss.Spec.CA.SecretName == secret.Name) ||
-			(iss.Spec.Vault != nil && iss.Spec.Vault.Auth.TokenSecretRef.Name == secret.Name) {
+			(iss.Spec.Vault != nil && iss.Spec.Vault.Auth.TokenSecretRef.Name == secret.Name) ||
+			(iss.Spec.Vault != nil && iss.Spec.Vault.Auth.Kubernetes != nil && iss.Spec.Vault.Auth.Kubernetes.SecretRef.Name == secret.Name) ||
+			(iss.Spec.Vault != nil && iss.Spec.Vault.Auth.ClientCertificate != nil && iss.Spec.Vault.Auth.ClientCertificate.SecretName == secret.Name) ||
+			(iss.Spec.Vault != nil && iss.Spec.Vault.CABundleSecretRef != nil && iss.Spec.Vault.CABundleSecretRef.Name == secret.Name) ||
+			(iss.Spec.Venafi != nil && iss.Spec.Venafi.TPP != nil && iss.Spec.Venafi.TPP.CredentialsRef.Name == secret.Name) ||
+			(iss.Spec.Venafi != nil && iss.Spec.Venafi.TPP != nil && iss.Spec.Venafi.TPP.AccessTokenSecretRef != nil && iss.Spec.Venafi.TPP.AccessTokenSecretRef.Name == secret.Name) ||
+			(iss.Spec.Venafi != nil && iss.Spec.Venafi.Cloud != nil && iss.Spec.Venafi.Cloud.APITokenSecretRef.Name == secret.Name) {
 			affected = append(affected, iss)
 			continue
 		}
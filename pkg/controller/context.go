@@ -27,6 +27,9 @@ import (
 	"time"
 
 	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
 	kubeinformers "k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
@@ -54,6 +57,9 @@ type Context struct {
 	Client kubernetes.Interface
 	// CMClient is a cert-manager clientset
 	CMClient clientset.Interface
+	// DynamicClient is a Kubernetes dynamic client, used to manage resources
+	// such as OpenShift Routes that have no typed clientset available
+	DynamicClient dynamic.Interface
 	// Recorder to record events to
 	Recorder record.EventRecorder
 
@@ -63,6 +69,14 @@ type Context struct {
 	// SharedInformerFactory can be used to obtain shared SharedIndexInformer
 	// instances
 	SharedInformerFactory informers.SharedInformerFactory
+	// DynamicSharedInformerFactory can be used to obtain shared
+	// SharedIndexInformer instances for resources accessed through the
+	// DynamicClient
+	DynamicSharedInformerFactory dynamicinformer.DynamicSharedInformerFactory
+
+	// IngressResource is the Ingress API version served by the apiserver,
+	// as discovered at startup
+	IngressResource schema.GroupVersionResource
 
 	// Namespace is the namespace to operate within.
 	// If unset, operates on all namespaces
@@ -140,8 +154,18 @@ type CertificateOptions struct {
 	// secret where the effective TLS certificate is stored.
 	EnableOwnerRef bool
 	// EnablePodRefresh controls whether renewing this certificate will cause all the pods that
-	// mount its secret to be refreshed by cert-manager.
+	// mount its secret to be refreshed by cert-manager. Certificates that set
+	// spec.podRefresh are refreshed regardless.
 	EnablePodRefresh bool
+	// PodRefreshMaxConcurrentRestarts is the maximum number of workloads the
+	// pod refresh controller will restart within PodRefreshRestartInterval.
+	PodRefreshMaxConcurrentRestarts int
+	// PodRefreshRestartInterval is the interval over which pod refresh
+	// restarts are staggered.
+	PodRefreshRestartInterval time.Duration
+	// PodRefreshControllerEnabled is true if the pod refresh controller is
+	// running. If it is not, spec.podRefresh on Certificates has no effect.
+	PodRefreshControllerEnabled bool
 }
 
 type SchedulerOptions struct {
@@ -0,0 +1,214 @@
+/*
+Copyright 2019 The Jetstack cert-manager contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crl
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/clock"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha1"
+	cmclient "github.com/jetstack/cert-manager/pkg/client/clientset/versioned"
+	cmlisters "github.com/jetstack/cert-manager/pkg/client/listers/certmanager/v1alpha1"
+	controllerpkg "github.com/jetstack/cert-manager/pkg/controller"
+	"github.com/jetstack/cert-manager/pkg/issuer"
+	logf "github.com/jetstack/cert-manager/pkg/logs"
+	"github.com/jetstack/cert-manager/pkg/scheduler"
+)
+
+// controller publishes the certificate revocation lists of CA Issuers and
+// ClusterIssuers that have spec.ca.crl set.
+// Each item in the queue identifies an Issuer (namespace/name) or a
+// ClusterIssuer (name). Certificates and CertificateRequests annotated with
+// a revocation reason cause the Issuer they reference to be queued.
+type controller struct {
+	issuerLister             cmlisters.IssuerLister
+	clusterIssuerLister      cmlisters.ClusterIssuerLister
+	certificateLister        cmlisters.CertificateLister
+	certificateRequestLister cmlisters.CertificateRequestLister
+	secretLister             corelisters.SecretLister
+	configMapLister          corelisters.ConfigMapLister
+
+	// helper is used to read the Issuer or ClusterIssuer for a queued key
+	helper issuer.Helper
+
+	issuerOptions controllerpkg.IssuerOptions
+
+	kClient  kubernetes.Interface
+	cmClient cmclient.Interface
+
+	// used for testing
+	clock clock.Clock
+
+	// used to record Events about resources to the API
+	recorder record.EventRecorder
+
+	// maintain a reference to the workqueue for this controller
+	queue workqueue.RateLimitingInterface
+
+	// scheduledWorkQueue re-queues Issuers when their CRL is due to be
+	// regenerated
+	scheduledWorkQueue scheduler.ScheduledWorkQueue
+
+	// logger to be used by this controller
+	log logr.Logger
+}
+
+// Register registers and constructs the controller using the provided context.
+// It returns the workqueue to be used to enqueue items, a list of
+// InformerSynced functions that must be synced, or an error.
+func (c *controller) Register(ctx *controllerpkg.Context) (workqueue.RateLimitingInterface, []cache.InformerSynced, []controllerpkg.RunFunc, error) {
+	// construct a new named logger to be reused throughout the controller
+	c.log = logf.FromContext(ctx.RootContext, ControllerName)
+
+	// create a queue used to queue up items to be processed
+	c.queue = workqueue.NewNamedRateLimitingQueue(controllerpkg.DefaultItemBasedRateLimiter(), ControllerName)
+	c.scheduledWorkQueue = scheduler.NewScheduledWorkQueue(c.queue.AddRateLimited)
+
+	// obtain references to all the informers used by this controller
+	issuerInformer := ctx.SharedInformerFactory.Certmanager().V1alpha1().Issuers()
+	certificateInformer := ctx.SharedInformerFactory.Certmanager().V1alpha1().Certificates()
+	certificateRequestInformer := ctx.SharedInformerFactory.Certmanager().V1alpha1().CertificateRequests()
+	secretInformer := ctx.KubeSharedInformerFactory.Core().V1().Secrets()
+	configMapInformer := ctx.KubeSharedInformerFactory.Core().V1().ConfigMaps()
+
+	// build a list of InformerSynced functions that will be returned by the Register method.
+	// the controller will only begin processing items once all of these informers have synced.
+	mustSync := []cache.InformerSynced{
+		issuerInformer.Informer().HasSynced,
+		certificateInformer.Informer().HasSynced,
+		certificateRequestInformer.Informer().HasSynced,
+		secretInformer.Informer().HasSynced,
+		configMapInformer.Informer().HasSynced,
+	}
+
+	// if we are running in non-namespaced mode (i.e. --namespace=""), we also
+	// register event handlers and obtain a lister for clusterissuers.
+	if ctx.Namespace == "" {
+		clusterIssuerInformer := ctx.SharedInformerFactory.Certmanager().V1alpha1().ClusterIssuers()
+		c.clusterIssuerLister = clusterIssuerInformer.Lister()
+		clusterIssuerInformer.Informer().AddEventHandler(&controllerpkg.QueuingEventHandler{Queue: c.queue})
+		mustSync = append(mustSync, clusterIssuerInformer.Informer().HasSynced)
+	}
+
+	// set all the references to the listers for used by the Sync function
+	c.issuerLister = issuerInformer.Lister()
+	c.certificateLister = certificateInformer.Lister()
+	c.certificateRequestLister = certificateRequestInformer.Lister()
+	c.secretLister = secretInformer.Lister()
+	c.configMapLister = configMapInformer.Lister()
+
+	// register handler functions
+	issuerInformer.Informer().AddEventHandler(&controllerpkg.QueuingEventHandler{Queue: c.queue})
+	certificateInformer.Informer().AddEventHandler(&controllerpkg.BlockingEventHandler{WorkFunc: c.handleRevocable})
+	certificateRequestInformer.Informer().AddEventHandler(&controllerpkg.BlockingEventHandler{WorkFunc: c.handleRevocable})
+
+	c.helper = issuer.NewHelper(c.issuerLister, c.clusterIssuerLister)
+	c.issuerOptions = ctx.IssuerOptions
+	c.kClient = ctx.Client
+	c.cmClient = ctx.CMClient
+	c.clock = ctx.Clock
+	c.recorder = ctx.Recorder
+
+	return c.queue, mustSync, nil, nil
+}
+
+// handleRevocable queues the Issuer referenced by a Certificate or
+// CertificateRequest that has been annotated with a revocation reason.
+func (c *controller) handleRevocable(obj interface{}) {
+	log := c.log.WithName("handleRevocable")
+
+	var (
+		annotations map[string]string
+		namespace   string
+		ref         cmapi.ObjectReference
+	)
+	switch o := obj.(type) {
+	case *cmapi.Certificate:
+		annotations, namespace, ref = o.Annotations, o.Namespace, o.Spec.IssuerRef
+	case *cmapi.CertificateRequest:
+		annotations, namespace, ref = o.Annotations, o.Namespace, o.Spec.IssuerRef
+	default:
+		log.Error(nil, "object is not a Certificate or CertificateRequest resource")
+		return
+	}
+
+	if _, ok := annotations[cmapi.RevocationReasonAnnotationKey]; !ok {
+		return
+	}
+	if ref.Group != "" && ref.Group != cmapi.SchemeGroupVersion.Group {
+		return
+	}
+
+	switch ref.Kind {
+	case "", cmapi.IssuerKind:
+		c.queue.Add(namespace + "/" + ref.Name)
+	case cmapi.ClusterIssuerKind:
+		// ClusterIssuers are not watched when scoped to a single namespace
+		if c.clusterIssuerLister != nil {
+			c.queue.Add(ref.Name)
+		}
+	}
+}
+
+func (c *controller) ProcessItem(ctx context.Context, key string) error {
+	log := logf.FromContext(ctx)
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		log.Error(err, "invalid resource key")
+		return nil
+	}
+
+	// ClusterIssuers are cluster scoped and so are keyed by name only
+	ref := cmapi.ObjectReference{Name: name, Kind: cmapi.IssuerKind}
+	if namespace == "" {
+		ref.Kind = cmapi.ClusterIssuerKind
+	}
+
+	iss, err := c.helper.GetGenericIssuer(ref, namespace)
+	if err != nil {
+		if k8sErrors.IsNotFound(err) {
+			log.Error(err, "issuer in work queue no longer exists")
+			c.scheduledWorkQueue.Forget(key)
+			return nil
+		}
+
+		return err
+	}
+
+	ctx = logf.NewContext(ctx, logf.WithResource(log, iss))
+	return c.Sync(ctx, key, iss)
+}
+
+const (
+	ControllerName = "crl"
+)
+
+func init() {
+	controllerpkg.Register(ControllerName, func(ctx *controllerpkg.Context) (controllerpkg.Interface, error) {
+		return controllerpkg.NewBuilder(ctx, ControllerName).
+			For(&controller{}).
+			Complete()
+	})
+}
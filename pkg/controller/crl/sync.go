@@ -0,0 +1,397 @@
+/*
+Copyright 2019 The Jetstack cert-manager contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crl
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	apiutil "github.com/jetstack/cert-manager/pkg/api/util"
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha1"
+	logf "github.com/jetstack/cert-manager/pkg/logs"
+	"github.com/jetstack/cert-manager/pkg/util/errors"
+	"github.com/jetstack/cert-manager/pkg/util/kube"
+	"github.com/jetstack/cert-manager/pkg/util/pki"
+)
+
+const (
+	reasonCRLPublished = "CRLPublished"
+	reasonCRLError     = "CRLError"
+	reasonRevoked      = "Revoked"
+
+	reasonRevocationNotPublished = "RevocationNotPublished"
+)
+
+// revocation is a certificate signed by the CA that has been marked as
+// revoked by a Certificate or CertificateRequest.
+type revocation struct {
+	serialNumber *big.Int
+	reason       cmapi.RevocationReason
+	reasonCode   int
+
+	// obj is the Certificate or CertificateRequest marking the certificate
+	// as revoked
+	obj runtime.Object
+}
+
+// Sync publishes a new CRL for the given Issuer if any certificates it has
+// signed have been newly revoked, or if the current CRL is due to be
+// renewed. It then schedules the Issuer to be synced again when the CRL is
+// next due for renewal.
+// The published CRL is the record of all revocations made so far: entries
+// are never removed from it, so revocations persist after the Certificate
+// or CertificateRequest marking them has been deleted.
+// Once the certificate stored in a Certificate's Secret has been listed in
+// the CRL, the revocation reason annotation is removed from the Certificate
+// and the serial number is recorded in its status. This causes a new
+// certificate to be issued, which is not revoked unless the Certificate is
+// annotated again.
+// Revocations are only recorded once they have been published, so a Warning
+// Event is emitted for each annotated Certificate or CertificateRequest
+// referencing a CA Issuer that does not publish a CRL.
+func (c *controller) Sync(ctx context.Context, key string, iss cmapi.GenericIssuer) error {
+	log := logf.FromContext(ctx)
+
+	spec := iss.GetSpec().CA
+	if spec == nil {
+		c.scheduledWorkQueue.Forget(key)
+		return nil
+	}
+	if spec.CRL == nil {
+		c.scheduledWorkQueue.Forget(key)
+		return c.warnRevocationsNotPublished(iss)
+	}
+
+	// the issuers controller validates the Issuer and its CA key pair, so
+	// wait until it has done so before publishing anything
+	if !apiutil.IssuerHasCondition(iss, cmapi.IssuerCondition{
+		Type:   cmapi.IssuerConditionReady,
+		Status: cmapi.ConditionTrue,
+	}) {
+		log.Info("issuer is not ready, not publishing CRL")
+		return nil
+	}
+
+	resourceNamespace := c.issuerOptions.ResourceNamespace(iss)
+	caCerts, caKey, err := kube.SecretTLSKeyPair(ctx, c.secretLister, resourceNamespace, spec.SecretName)
+	if k8sErrors.IsNotFound(err) || errors.IsInvalidData(err) {
+		log.Error(err, "error getting signing CA key pair")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	caCert := caCerts[0]
+
+	current, err := kube.PublishedCRL(c.secretLister, c.configMapLister, resourceNamespace, spec.CRL)
+	if errors.IsInvalidData(err) {
+		log.Error(err, "existing CRL is invalid and will be replaced")
+		current = nil
+	} else if err != nil {
+		return err
+	}
+
+	number := big.NewInt(1)
+	var entries []pki.CRLEntry
+	extendsCurrent := false
+	if current != nil {
+		if current.Number != nil {
+			number.Add(current.Number, number)
+		}
+		// a CRL signed by another CA, e.g. before the CA Secret was
+		// replaced, is superseded rather than extended
+		if current.CheckSignatureFrom(caCert) == nil {
+			entries = current.Entries
+			extendsCurrent = true
+		}
+	}
+
+	revoked, err := c.revokedCertificates(ctx, iss, caCert)
+	if err != nil {
+		return err
+	}
+
+	now := c.clock.Now()
+	listed := make(map[string]bool)
+	for _, e := range entries {
+		listed[e.SerialNumber.String()] = true
+	}
+	var added []revocation
+	for _, r := range revoked {
+		if listed[r.serialNumber.String()] {
+			continue
+		}
+		listed[r.serialNumber.String()] = true
+		entries = append(entries, pki.CRLEntry{
+			SerialNumber:   r.serialNumber,
+			RevocationTime: now,
+			ReasonCode:     r.reasonCode,
+		})
+		added = append(added, r)
+	}
+
+	duration, renewBefore := crlDurations(spec.CRL)
+	if extendsCurrent && len(added) == 0 {
+		renewAt := current.TBSCertList.NextUpdate.Add(-renewBefore)
+		if now.Before(renewAt) {
+			log.V(logf.DebugLevel).Info("CRL is up to date", "renew_at", renewAt)
+			if err := c.recordRevocations(iss, revoked); err != nil {
+				return err
+			}
+			c.scheduledWorkQueue.Add(key, renewAt.Sub(now))
+			return nil
+		}
+	}
+
+	crlPEM, err := pki.CreateCRL(caCert, caKey, entries, number, now, now.Add(duration))
+	if err != nil {
+		// an error here implies the CA certificate cannot be used to sign
+		// CRLs, which retrying will not fix
+		log.Error(err, "error creating CRL")
+		c.recorder.Eventf(iss, corev1.EventTypeWarning, reasonCRLError, "Error creating CRL: %v", err)
+		return nil
+	}
+
+	if err := c.publishCRL(resourceNamespace, spec.CRL, crlPEM); err != nil {
+		return err
+	}
+
+	for _, r := range added {
+		c.recorder.Eventf(r.obj, corev1.EventTypeNormal, reasonRevoked, "Certificate with serial number %s added to the CRL of %s %q", r.serialNumber, issuerKind(iss), iss.GetName())
+	}
+	c.recorder.Eventf(iss, corev1.EventTypeNormal, reasonCRLPublished, "Published CRL number %s listing %d revoked certificates", number, len(entries))
+
+	if err := c.recordRevocations(iss, revoked); err != nil {
+		return err
+	}
+
+	c.scheduledWorkQueue.Add(key, duration-renewBefore)
+	return nil
+}
+
+// recordRevocations removes the revocation reason annotation from each
+// Certificate whose certificate has been listed in the CRL, and records the
+// serial number of that certificate in its status.
+func (c *controller) recordRevocations(iss cmapi.GenericIssuer, revoked []revocation) error {
+	var errs []error
+	for _, r := range revoked {
+		crt, ok := r.obj.(*cmapi.Certificate)
+		if !ok {
+			continue
+		}
+		crt = crt.DeepCopy()
+		delete(crt.Annotations, cmapi.RevocationReasonAnnotationKey)
+		crt.Status.RevocationSerialNumber = r.serialNumber.String()
+		message := fmt.Sprintf("Certificate with serial number %s was added to the CRL of %s %q with reason %s", r.serialNumber, issuerKind(iss), iss.GetName(), r.reason)
+		apiutil.SetCertificateCondition(crt, cmapi.CertificateConditionRevoked, cmapi.ConditionTrue, reasonRevoked, message)
+		if _, err := c.cmClient.CertmanagerV1alpha1().Certificates(crt.Namespace).Update(crt); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// warnRevocationsNotPublished emits a Warning Event for each Certificate and
+// CertificateRequest referencing the given Issuer that is annotated with a
+// revocation reason, as the Issuer does not publish a CRL to list them in.
+func (c *controller) warnRevocationsNotPublished(iss cmapi.GenericIssuer) error {
+	crs, crts, err := c.revocable(iss)
+	if err != nil {
+		return err
+	}
+	for _, cr := range crs {
+		c.recorder.Eventf(cr, corev1.EventTypeWarning, reasonRevocationNotPublished, "Certificate cannot be revoked as %s %q does not publish a CRL", issuerKind(iss), iss.GetName())
+	}
+	for _, crt := range crts {
+		c.recorder.Eventf(crt, corev1.EventTypeWarning, reasonRevocationNotPublished, "Certificate cannot be revoked as %s %q does not publish a CRL", issuerKind(iss), iss.GetName())
+	}
+	return nil
+}
+
+// crlDurations returns the period of validity of each CRL published with the
+// given config, and how long before the end of that period it is renewed.
+func crlDurations(cfg *cmapi.CACRL) (duration, renewBefore time.Duration) {
+	duration = cmapi.DefaultCRLDuration
+	if cfg.Duration != nil {
+		duration = cfg.Duration.Duration
+	}
+	renewBefore = duration / 3
+	if cfg.RenewBefore != nil {
+		renewBefore = cfg.RenewBefore.Duration
+	}
+	return duration, renewBefore
+}
+
+// publishCRL stores the given PEM encoded CRL in the Secret or ConfigMap
+// named in the given config, creating it if it does not exist.
+func (c *controller) publishCRL(namespace string, cfg *cmapi.CACRL, crlPEM []byte) error {
+	if cfg.SecretName != "" {
+		secret, err := c.secretLister.Secrets(namespace).Get(cfg.SecretName)
+		if k8sErrors.IsNotFound(err) {
+			_, err = c.kClient.CoreV1().Secrets(namespace).Create(&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: cfg.SecretName, Namespace: namespace},
+				Data:       map[string][]byte{cmapi.CRLKey: crlPEM},
+			})
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		secret = secret.DeepCopy()
+		if secret.Data == nil {
+			secret.Data = make(map[string][]byte)
+		}
+		secret.Data[cmapi.CRLKey] = crlPEM
+		_, err = c.kClient.CoreV1().Secrets(namespace).Update(secret)
+		return err
+	}
+
+	cm, err := c.configMapLister.ConfigMaps(namespace).Get(cfg.ConfigMapName)
+	if k8sErrors.IsNotFound(err) {
+		_, err = c.kClient.CoreV1().ConfigMaps(namespace).Create(&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: cfg.ConfigMapName, Namespace: namespace},
+			Data:       map[string]string{cmapi.CRLKey: string(crlPEM)},
+		})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	cm = cm.DeepCopy()
+	if cm.Data == nil {
+		cm.Data = make(map[string]string)
+	}
+	cm.Data[cmapi.CRLKey] = string(crlPEM)
+	_, err = c.kClient.CoreV1().ConfigMaps(namespace).Update(cm)
+	return err
+}
+
+// revokedCertificates returns every certificate signed by caCert that is
+// marked as revoked by a Certificate or CertificateRequest referencing the
+// given Issuer, ordered by serial number.
+// For Certificates, this is the certificate stored in their Secret at the
+// time they are processed.
+func (c *controller) revokedCertificates(ctx context.Context, iss cmapi.GenericIssuer, caCert *x509.Certificate) ([]revocation, error) {
+	log := logf.FromContext(ctx)
+
+	var revoked []revocation
+	add := func(obj runtime.Object, reason string, cert *x509.Certificate) {
+		if !bytes.Equal(cert.RawIssuer, caCert.RawSubject) || cert.CheckSignatureFrom(caCert) != nil {
+			log.V(logf.DebugLevel).Info("ignoring revoked certificate not signed by this CA", "serial_number", cert.SerialNumber.String())
+			return
+		}
+		code, err := pki.RevocationReasonCode(cmapi.RevocationReason(reason))
+		if err != nil {
+			log.Error(err, "ignoring revoked certificate", "serial_number", cert.SerialNumber.String())
+			return
+		}
+		revoked = append(revoked, revocation{serialNumber: cert.SerialNumber, reason: cmapi.RevocationReason(reason), reasonCode: code, obj: obj})
+	}
+
+	crs, crts, err := c.revocable(iss)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, cr := range crs {
+		if len(cr.Status.Certificate) == 0 {
+			continue
+		}
+		cert, err := pki.DecodeX509CertificateBytes(cr.Status.Certificate)
+		if err != nil {
+			log.Error(err, "error decoding certificate of revoked certificate request", "resource_name", cr.Name, "resource_namespace", cr.Namespace)
+			continue
+		}
+		add(cr, cr.Annotations[cmapi.RevocationReasonAnnotationKey], cert)
+	}
+
+	for _, crt := range crts {
+		cert, err := kube.SecretTLSCert(ctx, c.secretLister, crt.Namespace, crt.Spec.SecretName)
+		if k8sErrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			log.Error(err, "error reading certificate of revoked certificate", "resource_name", crt.Name, "resource_namespace", crt.Namespace)
+			continue
+		}
+		add(crt, crt.Annotations[cmapi.RevocationReasonAnnotationKey], cert)
+	}
+
+	sort.Slice(revoked, func(i, j int) bool {
+		return revoked[i].serialNumber.Cmp(revoked[j].serialNumber) < 0
+	})
+	return revoked, nil
+}
+
+// revocable returns the CertificateRequests and Certificates referencing the
+// given Issuer that are annotated with a revocation reason.
+func (c *controller) revocable(iss cmapi.GenericIssuer) ([]*cmapi.CertificateRequest, []*cmapi.Certificate, error) {
+	var (
+		crs  []*cmapi.CertificateRequest
+		crts []*cmapi.Certificate
+		err  error
+	)
+	if iss.GetNamespace() == "" {
+		crs, err = c.certificateRequestLister.List(labels.Everything())
+	} else {
+		crs, err = c.certificateRequestLister.CertificateRequests(iss.GetNamespace()).List(labels.Everything())
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if iss.GetNamespace() == "" {
+		crts, err = c.certificateLister.List(labels.Everything())
+	} else {
+		crts, err = c.certificateLister.Certificates(iss.GetNamespace()).List(labels.Everything())
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var revocableCRs []*cmapi.CertificateRequest
+	for _, cr := range crs {
+		if _, ok := cr.Annotations[cmapi.RevocationReasonAnnotationKey]; ok && apiutil.IssuerRefersTo(cr.Spec.IssuerRef, cr.Namespace, iss) {
+			revocableCRs = append(revocableCRs, cr)
+		}
+	}
+	var revocableCrts []*cmapi.Certificate
+	for _, crt := range crts {
+		if _, ok := crt.Annotations[cmapi.RevocationReasonAnnotationKey]; ok && apiutil.IssuerRefersTo(crt.Spec.IssuerRef, crt.Namespace, iss) {
+			revocableCrts = append(revocableCrts, crt)
+		}
+	}
+	return revocableCRs, revocableCrts, nil
+}
+
+func issuerKind(iss cmapi.GenericIssuer) string {
+	if _, ok := iss.(*cmapi.ClusterIssuer); ok {
+		return cmapi.ClusterIssuerKind
+	}
+	return cmapi.IssuerKind
+}
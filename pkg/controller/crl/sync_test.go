@@ -0,0 +1,406 @@
+/*
+Copyright 2019 The Jetstack cert-manager contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crl
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	coretesting "k8s.io/client-go/testing"
+	fakeclock "k8s.io/utils/clock/testing"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha1"
+	testpkg "github.com/jetstack/cert-manager/pkg/controller/test"
+	"github.com/jetstack/cert-manager/pkg/util/pki"
+	"github.com/jetstack/cert-manager/test/unit/gen"
+)
+
+var fixedClockStart = time.Date(2019, time.October, 1, 12, 30, 0, 0, time.UTC)
+
+type testCA struct {
+	cert   *x509.Certificate
+	key    crypto.Signer
+	secret *corev1.Secret
+}
+
+func newTestCA(t *testing.T, name string) *testCA {
+	key, err := pki.GenerateECPrivateKey(256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             fixedClockStart.Add(-time.Hour),
+		NotAfter:              fixedClockStart.Add(time.Hour * 24 * 365),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	certPEM, cert, err := pki.SignCertificate(template, template, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPEM, err := pki.EncodePrivateKey(key, cmapi.PKCS8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCA{
+		cert: cert,
+		key:  key,
+		secret: &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: gen.DefaultTestNamespace, Name: name},
+			Data: map[string][]byte{
+				corev1.TLSCertKey:       certPEM,
+				corev1.TLSPrivateKeyKey: keyPEM,
+			},
+		},
+	}
+}
+
+// sign returns a PEM encoded leaf certificate with the given serial number
+// signed by the CA
+func (ca *testCA) sign(t *testing.T, serial int64) []byte {
+	key, err := pki.GenerateECPrivateKey(256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "leaf"},
+		NotBefore:    fixedClockStart.Add(-time.Hour),
+		NotAfter:     fixedClockStart.Add(time.Hour * 24),
+	}
+	certPEM, _, err := pki.SignCertificate(template, ca.cert, key.Public(), ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return certPEM
+}
+
+// crl returns a PEM encoded CRL signed by the CA
+func (ca *testCA) crl(t *testing.T, number int64, thisUpdate, nextUpdate time.Time, entries ...pki.CRLEntry) []byte {
+	crlPEM, err := pki.CreateCRL(ca.cert, ca.key, entries, big.NewInt(number), thisUpdate, nextUpdate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return crlPEM
+}
+
+func revokedEntry(serial int64, revokedAt time.Time, reasonCode int) pki.CRLEntry {
+	return pki.CRLEntry{SerialNumber: big.NewInt(serial), RevocationTime: revokedAt, ReasonCode: reasonCode}
+}
+
+// expectCRL returns an ActionMatchFn that checks the published Secret or
+// ConfigMap contains a CRL signed by ca with the given number, validity and
+// entries.
+func expectCRL(ca *testCA, number int64, thisUpdate, nextUpdate time.Time, entries ...pki.CRLEntry) testpkg.ActionMatchFn {
+	return func(exp, act coretesting.Action) error {
+		var crlPEM []byte
+		switch obj := act.(coretesting.CreateAction).GetObject().(type) {
+		case *corev1.Secret:
+			crlPEM = obj.Data[cmapi.CRLKey]
+		case *corev1.ConfigMap:
+			crlPEM = []byte(obj.Data[cmapi.CRLKey])
+		default:
+			return fmt.Errorf("unexpected object %T", obj)
+		}
+
+		crl, err := pki.DecodeX509CRLBytes(crlPEM)
+		if err != nil {
+			return err
+		}
+		if err := crl.CheckSignatureFrom(ca.cert); err != nil {
+			return fmt.Errorf("CRL not signed by CA: %v", err)
+		}
+		if crl.Number.Cmp(big.NewInt(number)) != 0 {
+			return fmt.Errorf("expected CRL number %d but got %s", number, crl.Number)
+		}
+		if tbs := crl.TBSCertList; !tbs.ThisUpdate.Equal(thisUpdate) || !tbs.NextUpdate.Equal(nextUpdate) {
+			return fmt.Errorf("expected CRL valid from %s to %s but got %s to %s", thisUpdate, nextUpdate, tbs.ThisUpdate, tbs.NextUpdate)
+		}
+		if len(crl.Entries) != len(entries) {
+			return fmt.Errorf("expected %d revoked certificates but got %d", len(entries), len(crl.Entries))
+		}
+		for i, e := range crl.Entries {
+			if e.SerialNumber.Cmp(entries[i].SerialNumber) != 0 || !e.RevocationTime.Equal(entries[i].RevocationTime) || e.ReasonCode != entries[i].ReasonCode {
+				return fmt.Errorf("expected revoked certificate %s at %s with reason %d but got %s at %s with reason %d",
+					entries[i].SerialNumber, entries[i].RevocationTime, entries[i].ReasonCode, e.SerialNumber, e.RevocationTime, e.ReasonCode)
+			}
+		}
+		return nil
+	}
+}
+
+func TestSync(t *testing.T) {
+	ca := newTestCA(t, "ca-key-pair")
+	otherCA := newTestCA(t, "other-ca")
+
+	crlConfig := &cmapi.CACRL{ConfigMapName: "ca-crl"}
+	readyCondition := cmapi.IssuerCondition{Type: cmapi.IssuerConditionReady, Status: cmapi.ConditionTrue}
+	issuer := gen.Issuer("ca",
+		gen.SetIssuerCA(cmapi.CAIssuer{SecretName: "ca-key-pair", CRL: crlConfig}),
+		gen.AddIssuerCondition(readyCondition),
+	)
+	secretIssuer := gen.Issuer("ca",
+		gen.SetIssuerCA(cmapi.CAIssuer{SecretName: "ca-key-pair", CRL: &cmapi.CACRL{SecretName: "ca-crl"}}),
+		gen.AddIssuerCondition(readyCondition),
+	)
+	noCRLIssuer := gen.Issuer("ca",
+		gen.SetIssuerCA(cmapi.CAIssuer{SecretName: "ca-key-pair"}),
+		gen.AddIssuerCondition(readyCondition),
+	)
+	notReadyIssuer := gen.Issuer("ca",
+		gen.SetIssuerCA(cmapi.CAIssuer{SecretName: "ca-key-pair", CRL: crlConfig}),
+	)
+
+	revokedAnnotations := map[string]string{cmapi.RevocationReasonAnnotationKey: string(cmapi.RevocationReasonKeyCompromise)}
+	revokedCR := gen.CertificateRequest("revoked",
+		gen.SetCertificateRequestIssuer(cmapi.ObjectReference{Name: "ca"}),
+		gen.SetCertificateRequestCertificate(ca.sign(t, 10)),
+		gen.SetCertificateRequestAnnotations(revokedAnnotations),
+	)
+	otherCAOfRevokedCR := gen.CertificateRequestFrom(revokedCR,
+		gen.SetCertificateRequestCertificate(otherCA.sign(t, 10)),
+	)
+	otherIssuerOfRevokedCR := gen.CertificateRequestFrom(revokedCR,
+		gen.SetCertificateRequestIssuer(cmapi.ObjectReference{Name: "ca", Kind: cmapi.ClusterIssuerKind}),
+	)
+	revokedCrt := gen.Certificate("revoked",
+		gen.SetCertificateIssuer(cmapi.ObjectReference{Name: "ca"}),
+		gen.SetCertificateSecretName("revoked-tls"),
+		gen.SetCertificateAnnotations(map[string]string{cmapi.RevocationReasonAnnotationKey: string(cmapi.RevocationReasonSuperseded)}),
+	)
+	revokedCrtSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: gen.DefaultTestNamespace, Name: "revoked-tls"},
+		Data:       map[string][]byte{corev1.TLSCertKey: ca.sign(t, 11)},
+	}
+	nowMetaTime := metav1.NewTime(fixedClockStart)
+	recordedRevokedCrt := gen.CertificateFrom(revokedCrt,
+		gen.SetCertificateAnnotations(map[string]string{}),
+		gen.SetCertificateStatusCondition(cmapi.CertificateCondition{
+			Type:               cmapi.CertificateConditionRevoked,
+			Status:             cmapi.ConditionTrue,
+			Reason:             reasonRevoked,
+			Message:            `Certificate with serial number 11 was added to the CRL of Issuer "ca" with reason superseded`,
+			LastTransitionTime: &nowMetaTime,
+		}),
+	)
+	recordedRevokedCrt.Status.RevocationSerialNumber = "11"
+	certificatesResource := cmapi.SchemeGroupVersion.WithResource("certificates")
+
+	revokedAt := fixedClockStart.Add(-time.Hour * 20)
+	crlConfigMap := func(crlPEM []byte) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: gen.DefaultTestNamespace, Name: "ca-crl"},
+			Data:       map[string]string{cmapi.CRLKey: string(crlPEM)},
+		}
+	}
+	upToDateCRL := crlConfigMap(ca.crl(t, 4, revokedAt, revokedAt.Add(time.Hour*30), revokedEntry(10, revokedAt, 1)))
+	upToDateCRLListingCrt := crlConfigMap(ca.crl(t, 4, revokedAt, revokedAt.Add(time.Hour*30), revokedEntry(10, revokedAt, 1), revokedEntry(11, revokedAt, 4)))
+	expiringCRL := crlConfigMap(ca.crl(t, 4, revokedAt, revokedAt.Add(time.Hour*24), revokedEntry(10, revokedAt, 1)))
+	otherCACRL := crlConfigMap(otherCA.crl(t, 4, revokedAt, revokedAt.Add(time.Hour*30), revokedEntry(10, revokedAt, 1)))
+
+	configMapsResource := corev1.SchemeGroupVersion.WithResource("configmaps")
+	secretsResource := corev1.SchemeGroupVersion.WithResource("secrets")
+	nextUpdate := fixedClockStart.Add(cmapi.DefaultCRLDuration)
+
+	tests := map[string]struct {
+		issuer      *cmapi.Issuer
+		builder     *testpkg.Builder
+		expectedErr bool
+	}{
+		"warn about revoked certificates if the issuer does not publish a CRL": {
+			issuer: noCRLIssuer,
+			builder: &testpkg.Builder{
+				KubeObjects:        []runtime.Object{ca.secret, revokedCrtSecret},
+				CertManagerObjects: []runtime.Object{noCRLIssuer, revokedCR, revokedCrt,
+					gen.CertificateRequestFrom(otherIssuerOfRevokedCR, gen.SetCertificateRequestName("other-issuer")),
+				},
+				ExpectedEvents: []string{
+					`Warning RevocationNotPublished Certificate cannot be revoked as Issuer "ca" does not publish a CRL`,
+					`Warning RevocationNotPublished Certificate cannot be revoked as Issuer "ca" does not publish a CRL`,
+				},
+			},
+		},
+		"do nothing if the issuer is not ready": {
+			issuer: notReadyIssuer,
+			builder: &testpkg.Builder{
+				KubeObjects:        []runtime.Object{ca.secret},
+				CertManagerObjects: []runtime.Object{notReadyIssuer, revokedCR},
+			},
+		},
+		"publish a new CRL listing a revoked certificate request": {
+			issuer: issuer,
+			builder: &testpkg.Builder{
+				KubeObjects:        []runtime.Object{ca.secret},
+				CertManagerObjects: []runtime.Object{issuer, revokedCR},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewCustomMatch(coretesting.NewCreateAction(configMapsResource, gen.DefaultTestNamespace, nil),
+						expectCRL(ca, 1, fixedClockStart, nextUpdate, revokedEntry(10, fixedClockStart, 1))),
+				},
+				ExpectedEvents: []string{
+					`Normal Revoked Certificate with serial number 10 added to the CRL of Issuer "ca"`,
+					`Normal CRLPublished Published CRL number 1 listing 1 revoked certificates`,
+				},
+			},
+		},
+		"publish a new CRL to a secret": {
+			issuer: secretIssuer,
+			builder: &testpkg.Builder{
+				KubeObjects:        []runtime.Object{ca.secret},
+				CertManagerObjects: []runtime.Object{secretIssuer, revokedCR},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewCustomMatch(coretesting.NewCreateAction(secretsResource, gen.DefaultTestNamespace, nil),
+						expectCRL(ca, 1, fixedClockStart, nextUpdate, revokedEntry(10, fixedClockStart, 1))),
+				},
+				ExpectedEvents: []string{
+					`Normal Revoked Certificate with serial number 10 added to the CRL of Issuer "ca"`,
+					`Normal CRLPublished Published CRL number 1 listing 1 revoked certificates`,
+				},
+			},
+		},
+		"ignore revoked certificates signed by another CA": {
+			issuer: issuer,
+			builder: &testpkg.Builder{
+				KubeObjects:        []runtime.Object{ca.secret},
+				CertManagerObjects: []runtime.Object{issuer, otherCAOfRevokedCR},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewCustomMatch(coretesting.NewCreateAction(configMapsResource, gen.DefaultTestNamespace, nil),
+						expectCRL(ca, 1, fixedClockStart, nextUpdate)),
+				},
+				ExpectedEvents: []string{
+					`Normal CRLPublished Published CRL number 1 listing 0 revoked certificates`,
+				},
+			},
+		},
+		"ignore revoked certificates requested from another issuer": {
+			issuer: issuer,
+			builder: &testpkg.Builder{
+				KubeObjects:        []runtime.Object{ca.secret},
+				CertManagerObjects: []runtime.Object{issuer, otherIssuerOfRevokedCR},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewCustomMatch(coretesting.NewCreateAction(configMapsResource, gen.DefaultTestNamespace, nil),
+						expectCRL(ca, 1, fixedClockStart, nextUpdate)),
+				},
+				ExpectedEvents: []string{
+					`Normal CRLPublished Published CRL number 1 listing 0 revoked certificates`,
+				},
+			},
+		},
+		"do not republish an up to date CRL": {
+			issuer: issuer,
+			builder: &testpkg.Builder{
+				KubeObjects:        []runtime.Object{ca.secret, upToDateCRL},
+				CertManagerObjects: []runtime.Object{issuer, revokedCR},
+			},
+		},
+		"republish a CRL that is due for renewal keeping existing entries": {
+			issuer: issuer,
+			builder: &testpkg.Builder{
+				KubeObjects:        []runtime.Object{ca.secret, expiringCRL},
+				CertManagerObjects: []runtime.Object{issuer, revokedCR},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewCustomMatch(coretesting.NewUpdateAction(configMapsResource, gen.DefaultTestNamespace, nil),
+						expectCRL(ca, 5, fixedClockStart, nextUpdate, revokedEntry(10, revokedAt, 1))),
+				},
+				ExpectedEvents: []string{
+					`Normal CRLPublished Published CRL number 5 listing 1 revoked certificates`,
+				},
+			},
+		},
+		"add a revoked certificate to an up to date CRL": {
+			issuer: issuer,
+			builder: &testpkg.Builder{
+				KubeObjects:        []runtime.Object{ca.secret, upToDateCRL, revokedCrtSecret},
+				CertManagerObjects: []runtime.Object{issuer, revokedCR, revokedCrt},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewCustomMatch(coretesting.NewUpdateAction(configMapsResource, gen.DefaultTestNamespace, nil),
+						expectCRL(ca, 5, fixedClockStart, nextUpdate, revokedEntry(10, revokedAt, 1), revokedEntry(11, fixedClockStart, 4))),
+					testpkg.NewAction(coretesting.NewUpdateAction(certificatesResource, gen.DefaultTestNamespace, recordedRevokedCrt)),
+				},
+				ExpectedEvents: []string{
+					`Normal Revoked Certificate with serial number 11 added to the CRL of Issuer "ca"`,
+					`Normal CRLPublished Published CRL number 5 listing 2 revoked certificates`,
+				},
+			},
+		},
+		"record the revocation of a certificate already listed in an up to date CRL": {
+			issuer: issuer,
+			builder: &testpkg.Builder{
+				KubeObjects:        []runtime.Object{ca.secret, upToDateCRLListingCrt, revokedCrtSecret},
+				CertManagerObjects: []runtime.Object{issuer, revokedCrt},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateAction(certificatesResource, gen.DefaultTestNamespace, recordedRevokedCrt)),
+				},
+			},
+		},
+		"do not revoke the certificate of a Certificate that is no longer annotated": {
+			issuer: issuer,
+			builder: &testpkg.Builder{
+				KubeObjects:        []runtime.Object{ca.secret, upToDateCRL, revokedCrtSecret},
+				CertManagerObjects: []runtime.Object{issuer, recordedRevokedCrt},
+			},
+		},
+		"replace a CRL signed by another CA": {
+			issuer: issuer,
+			builder: &testpkg.Builder{
+				KubeObjects:        []runtime.Object{ca.secret, otherCACRL},
+				CertManagerObjects: []runtime.Object{issuer},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewCustomMatch(coretesting.NewUpdateAction(configMapsResource, gen.DefaultTestNamespace, nil),
+						expectCRL(ca, 5, fixedClockStart, nextUpdate)),
+				},
+				ExpectedEvents: []string{
+					`Normal CRLPublished Published CRL number 5 listing 0 revoked certificates`,
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			test.builder.T = t
+			test.builder.Clock = fakeclock.NewFakeClock(fixedClockStart)
+			test.builder.Init()
+			defer test.builder.Stop()
+
+			c := &controller{}
+			c.Register(test.builder.Context)
+			test.builder.Start()
+
+			err := c.Sync(context.Background(), gen.DefaultTestNamespace+"/ca", test.issuer)
+			if err != nil && !test.expectedErr {
+				t.Errorf("expected to not get an error, but got: %v", err)
+			}
+			if err == nil && test.expectedErr {
+				t.Errorf("expected to get an error but did not get one")
+			}
+
+			test.builder.CheckAndFinish(err)
+		})
+	}
+}
@@ -19,21 +19,21 @@ package controller
 import (
 	"fmt"
 
-	extv1beta1 "k8s.io/api/extensions/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 
 	"github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha1"
+	ingressutil "github.com/jetstack/cert-manager/pkg/util/ingress"
 )
 
-func (c *controller) ingressesForCertificate(crt *v1alpha1.Certificate) ([]*extv1beta1.Ingress, error) {
-	ings, err := c.ingressLister.List(labels.NewSelector())
+func (c *controller) ingressesForCertificate(crt *v1alpha1.Certificate) ([]*ingressutil.Ingress, error) {
+	ings, err := c.ingressClient.List(metav1.NamespaceAll, labels.NewSelector())
 
 	if err != nil {
 		return nil, fmt.Errorf("error listing certificiates: %s", err.Error())
 	}
 
-	var affected []*extv1beta1.Ingress
+	var affected []*ingressutil.Ingress
 	for _, ing := range ings {
 		if crt.Namespace != ing.Namespace {
 			continue
@@ -22,9 +22,9 @@ import (
 
 	"github.com/go-logr/logr"
 	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/kubernetes"
-	extlisters "k8s.io/client-go/listers/extensions/v1beta1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
@@ -35,6 +35,7 @@ import (
 	controllerpkg "github.com/jetstack/cert-manager/pkg/controller"
 	"github.com/jetstack/cert-manager/pkg/issuer"
 	logf "github.com/jetstack/cert-manager/pkg/logs"
+	ingressutil "github.com/jetstack/cert-manager/pkg/util/ingress"
 )
 
 const (
@@ -60,11 +61,15 @@ type controller struct {
 	cmClient clientset.Interface
 	recorder record.EventRecorder
 
-	ingressLister       extlisters.IngressLister
+	ingressClient       *ingressutil.Client
 	certificateLister   cmlisters.CertificateLister
 	issuerLister        cmlisters.IssuerLister
 	clusterIssuerLister cmlisters.ClusterIssuerLister
 
+	// ingressGVK is the kind of the Ingress resources served by the
+	// apiserver, used when setting owner references on Certificates
+	ingressGVK schema.GroupVersionKind
+
 	helper   issuer.Helper
 	defaults defaults
 }
@@ -80,19 +85,20 @@ func (c *controller) Register(ctx *controllerpkg.Context) (workqueue.RateLimitin
 	c.queue = workqueue.NewNamedRateLimitingQueue(controllerpkg.DefaultItemBasedRateLimiter(), ControllerName)
 
 	// obtain references to all the informers used by this controller
-	ingressInformer := ctx.KubeSharedInformerFactory.Extensions().V1beta1().Ingresses()
+	c.ingressClient = ingressutil.NewClient(ctx.IngressResource, ctx.DynamicClient, ctx.DynamicSharedInformerFactory)
+	ingressInformer := c.ingressClient.Informer()
 	certificatesInformer := ctx.SharedInformerFactory.Certmanager().V1alpha1().Certificates()
 	issuerInformer := ctx.SharedInformerFactory.Certmanager().V1alpha1().Issuers()
 	// build a list of InformerSynced functions that will be returned by the Register method.
 	// the controller will only begin processing items once all of these informers have synced.
 	mustSync := []cache.InformerSynced{
-		ingressInformer.Informer().HasSynced,
+		ingressInformer.HasSynced,
 		certificatesInformer.Informer().HasSynced,
 		issuerInformer.Informer().HasSynced,
 	}
 
 	// set all the references to the listers for used by the Sync function
-	c.ingressLister = ingressInformer.Lister()
+	c.ingressGVK = c.ingressClient.GroupVersionKind()
 	c.certificateLister = certificatesInformer.Lister()
 	c.issuerLister = issuerInformer.Lister()
 
@@ -106,7 +112,7 @@ func (c *controller) Register(ctx *controllerpkg.Context) (workqueue.RateLimitin
 	}
 
 	// register handler functions
-	ingressInformer.Informer().AddEventHandler(&controllerpkg.QueuingEventHandler{Queue: c.queue})
+	ingressInformer.AddEventHandler(&controllerpkg.QueuingEventHandler{Queue: c.queue})
 	certificatesInformer.Informer().AddEventHandler(&controllerpkg.BlockingEventHandler{WorkFunc: c.certificateDeleted})
 
 	c.helper = issuer.NewHelper(c.issuerLister, c.clusterIssuerLister)
@@ -152,7 +158,7 @@ func (c *controller) ProcessItem(ctx context.Context, key string) error {
 		return nil
 	}
 
-	crt, err := c.ingressLister.Ingresses(namespace).Get(name)
+	crt, err := c.ingressClient.Get(namespace, name)
 
 	if err != nil {
 		if k8sErrors.IsNotFound(err) {
@@ -23,7 +23,6 @@ import (
 	"strconv"
 
 	corev1 "k8s.io/api/core/v1"
-	extv1beta1 "k8s.io/api/extensions/v1beta1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
@@ -32,6 +31,7 @@ import (
 	"github.com/jetstack/cert-manager/pkg/logs"
 	"github.com/jetstack/cert-manager/pkg/metrics"
 	"github.com/jetstack/cert-manager/pkg/util"
+	ingressutil "github.com/jetstack/cert-manager/pkg/util/ingress"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 )
 
@@ -59,9 +59,7 @@ const (
 	ingressClassAnnotation = util.IngressKey
 )
 
-var ingressGVK = extv1beta1.SchemeGroupVersion.WithKind("Ingress")
-
-func (c *controller) Sync(ctx context.Context, ing *extv1beta1.Ingress) error {
+func (c *controller) Sync(ctx context.Context, ing *ingressutil.Ingress) error {
 	log := logs.WithResource(logs.FromContext(ctx), ing)
 	ctx = logs.NewContext(ctx, log)
 
@@ -138,7 +136,7 @@ func (c *controller) Sync(ctx context.Context, ing *extv1beta1.Ingress) error {
 	return nil
 }
 
-func (c *controller) validateIngress(ing *extv1beta1.Ingress) []error {
+func (c *controller) validateIngress(ing *ingressutil.Ingress) []error {
 	var errs []error
 	if ing.Annotations != nil {
 		challengeType := ing.Annotations[acmeIssuerChallengeTypeAnnotation]
@@ -165,7 +163,7 @@ func (c *controller) validateIngress(ing *extv1beta1.Ingress) []error {
 	return errs
 }
 
-func (c *controller) buildCertificates(ctx context.Context, ing *extv1beta1.Ingress, issuer v1alpha1.GenericIssuer, issuerKind string) (new, update []*v1alpha1.Certificate, _ error) {
+func (c *controller) buildCertificates(ctx context.Context, ing *ingressutil.Ingress, issuer v1alpha1.GenericIssuer, issuerKind string) (new, update []*v1alpha1.Certificate, _ error) {
 	log := logs.FromContext(ctx)
 
 	var newCrts []*v1alpha1.Certificate
@@ -181,7 +179,7 @@ func (c *controller) buildCertificates(ctx context.Context, ing *extv1beta1.Ingr
 				Name:            tls.SecretName,
 				Namespace:       ing.Namespace,
 				Labels:          ing.Labels,
-				OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(ing, ingressGVK)},
+				OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(ing, c.ingressGVK)},
 			},
 			Spec: v1alpha1.CertificateSpec{
 				DNSNames:   tls.Hosts,
@@ -238,7 +236,7 @@ func (c *controller) buildCertificates(ctx context.Context, ing *extv1beta1.Ingr
 	return newCrts, updateCrts, nil
 }
 
-func (c *controller) findUnrequiredCertificates(ing *extv1beta1.Ingress) ([]*v1alpha1.Certificate, error) {
+func (c *controller) findUnrequiredCertificates(ing *ingressutil.Ingress) ([]*v1alpha1.Certificate, error) {
 	var unrequired []*v1alpha1.Certificate
 	// TODO: investigate selector which filters for certificates controlled by the ingress
 	crts, err := c.certificateLister.Certificates(ing.Namespace).List(labels.Everything())
@@ -255,7 +253,7 @@ func (c *controller) findUnrequiredCertificates(ing *extv1beta1.Ingress) ([]*v1a
 	return unrequired, nil
 }
 
-func isUnrequiredCertificate(crt *v1alpha1.Certificate, ing *extv1beta1.Ingress) bool {
+func isUnrequiredCertificate(crt *v1alpha1.Certificate, ing *ingressutil.Ingress) bool {
 	if !metav1.IsControlledBy(crt, ing) {
 		return false
 	}
@@ -321,7 +319,7 @@ func certNeedsUpdate(a, b *v1alpha1.Certificate) bool {
 	return false
 }
 
-func (c *controller) setIssuerSpecificConfig(crt *v1alpha1.Certificate, issuer v1alpha1.GenericIssuer, ing *extv1beta1.Ingress, tls extv1beta1.IngressTLS) error {
+func (c *controller) setIssuerSpecificConfig(crt *v1alpha1.Certificate, issuer v1alpha1.GenericIssuer, ing *ingressutil.Ingress, tls ingressutil.IngressTLS) error {
 	ingAnnotations := ing.Annotations
 	if ingAnnotations == nil {
 		ingAnnotations = map[string]string{}
@@ -361,6 +359,11 @@ func (c *controller) setIssuerSpecificConfig(crt *v1alpha1.Certificate, issuer v
 					ingressClass, ok := ingAnnotations[ingressClassAnnotation]
 					if ok {
 						domainCfg.HTTP01.IngressClass = &ingressClass
+					} else if ing.Spec.IngressClassName != nil {
+						// fall back to the IngressClass named on the Ingress, which
+						// is typically named after the class it replaces
+						ingressClass := *ing.Spec.IngressClassName
+						domainCfg.HTTP01.IngressClass = &ingressClass
 					}
 				}
 			}
@@ -394,7 +397,7 @@ func (c *controller) setIssuerSpecificConfig(crt *v1alpha1.Certificate, issuer v
 
 // shouldSync returns true if this ingress should have a Certificate resource
 // created for it
-func shouldSync(ing *extv1beta1.Ingress, autoCertificateAnnotations []string) bool {
+func shouldSync(ing *ingressutil.Ingress, autoCertificateAnnotations []string) bool {
 	annotations := ing.Annotations
 	if annotations == nil {
 		annotations = map[string]string{}
@@ -424,7 +427,7 @@ func shouldSync(ing *extv1beta1.Ingress, autoCertificateAnnotations []string) bo
 // issuerForIngress will determine the issuer that should be specified on a
 // Certificate created for the given Ingress resource. If one is not set, the
 // default issuer given to the controller will be used.
-func (c *controller) issuerForIngress(ing *extv1beta1.Ingress) (name string, kind string) {
+func (c *controller) issuerForIngress(ing *ingressutil.Ingress) (name string, kind string) {
 	name = c.defaults.issuerName
 	kind = c.defaults.issuerKind
 	annotations := ing.Annotations
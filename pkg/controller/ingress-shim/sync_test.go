@@ -21,7 +21,6 @@ import (
 	"fmt"
 	"testing"
 
-	extv1beta1 "k8s.io/api/extensions/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	types "k8s.io/apimachinery/pkg/types"
@@ -29,11 +28,14 @@ import (
 
 	"github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha1"
 	testpkg "github.com/jetstack/cert-manager/pkg/controller/test"
+	ingressutil "github.com/jetstack/cert-manager/pkg/util/ingress"
 	"github.com/jetstack/cert-manager/test/unit/gen"
 )
 
 const testAcmeTLSAnnotation = "kubernetes.io/tls-acme"
 
+var ingressGVK = ingressutil.ExtensionsV1beta1.GroupVersion().WithKind("Ingress")
+
 func strPtr(s string) *string {
 	return &s
 }
@@ -100,7 +102,7 @@ func TestSync(t *testing.T) {
 		}))
 	type testT struct {
 		Name                string
-		Ingress             *extv1beta1.Ingress
+		Ingress             *ingressutil.Ingress
 		Issuer              v1alpha1.GenericIssuer
 		IssuerLister        []runtime.Object
 		ClusterIssuerLister []runtime.Object
@@ -116,7 +118,7 @@ func TestSync(t *testing.T) {
 		{
 			Name:   "return a single HTTP01 Certificate for an ingress with a single valid TLS entry and HTTP01 annotations using edit-in-place",
 			Issuer: acmeClusterIssuer,
-			Ingress: &extv1beta1.Ingress{
+			Ingress: &ingressutil.Ingress{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      "ingress-name",
 					Namespace: gen.DefaultTestNamespace,
@@ -130,8 +132,8 @@ func TestSync(t *testing.T) {
 					},
 					UID: types.UID("ingress-name"),
 				},
-				Spec: extv1beta1.IngressSpec{
-					TLS: []extv1beta1.IngressTLS{
+				Spec: ingressutil.IngressSpec{
+					TLS: []ingressutil.IngressTLS{
 						{
 							Hosts:      []string{"example.com", "www.example.com"},
 							SecretName: "example-com-tls",
@@ -176,7 +178,7 @@ func TestSync(t *testing.T) {
 		{
 			Name:   "return a single HTTP01 Certificate for an ingress with a single valid TLS entry and HTTP01 annotations with no ingress class set",
 			Issuer: acmeClusterIssuer,
-			Ingress: &extv1beta1.Ingress{
+			Ingress: &ingressutil.Ingress{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      "ingress-name",
 					Namespace: gen.DefaultTestNamespace,
@@ -186,8 +188,8 @@ func TestSync(t *testing.T) {
 					},
 					UID: types.UID("ingress-name"),
 				},
-				Spec: extv1beta1.IngressSpec{
-					TLS: []extv1beta1.IngressTLS{
+				Spec: ingressutil.IngressSpec{
+					TLS: []ingressutil.IngressTLS{
 						{
 							Hosts:      []string{"example.com", "www.example.com"},
 							SecretName: "example-com-tls",
@@ -224,10 +226,64 @@ func TestSync(t *testing.T) {
 				},
 			},
 		},
+		{
+			Name:   "return a single HTTP01 Certificate for an ingress with a single valid TLS entry and HTTP01 annotations using the ingressClassName field",
+			Issuer: acmeClusterIssuer,
+			Ingress: &ingressutil.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "ingress-name",
+					Namespace: gen.DefaultTestNamespace,
+					Annotations: map[string]string{
+						clusterIssuerNameAnnotation:       "issuer-name",
+						acmeIssuerChallengeTypeAnnotation: "http01",
+					},
+					UID: types.UID("ingress-name"),
+				},
+				Spec: ingressutil.IngressSpec{
+					IngressClassName: strPtr("nginx"),
+					TLS: []ingressutil.IngressTLS{
+						{
+							Hosts:      []string{"example.com", "www.example.com"},
+							SecretName: "example-com-tls",
+						},
+					},
+				},
+			},
+			ClusterIssuerLister: []runtime.Object{acmeClusterIssuer},
+			ExpectedCreate: []*v1alpha1.Certificate{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:            "example-com-tls",
+						Namespace:       gen.DefaultTestNamespace,
+						OwnerReferences: buildOwnerReferences("ingress-name", gen.DefaultTestNamespace),
+					},
+					Spec: v1alpha1.CertificateSpec{
+						DNSNames:   []string{"example.com", "www.example.com"},
+						SecretName: "example-com-tls",
+						IssuerRef: v1alpha1.ObjectReference{
+							Name: "issuer-name",
+							Kind: "ClusterIssuer",
+						},
+						ACME: &v1alpha1.ACMECertificateConfig{
+							Config: []v1alpha1.DomainSolverConfig{
+								{
+									Domains: []string{"example.com", "www.example.com"},
+									SolverConfig: v1alpha1.SolverConfig{
+										HTTP01: &v1alpha1.HTTP01SolverConfig{
+											IngressClass: strPtr("nginx"),
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			Name:   "return a single HTTP01 Certificate for an ingress with a single valid TLS entry and HTTP01 annotations with a custom ingress class",
 			Issuer: acmeClusterIssuer,
-			Ingress: &extv1beta1.Ingress{
+			Ingress: &ingressutil.Ingress{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      "ingress-name",
 					Namespace: gen.DefaultTestNamespace,
@@ -238,8 +294,8 @@ func TestSync(t *testing.T) {
 					},
 					UID: types.UID("ingress-name"),
 				},
-				Spec: extv1beta1.IngressSpec{
-					TLS: []extv1beta1.IngressTLS{
+				Spec: ingressutil.IngressSpec{
+					TLS: []ingressutil.IngressTLS{
 						{
 							Hosts:      []string{"example.com", "www.example.com"},
 							SecretName: "example-com-tls",
@@ -281,7 +337,7 @@ func TestSync(t *testing.T) {
 		{
 			Name:   "return a single HTTP01 Certificate for an ingress with a single valid TLS entry and HTTP01 annotations with a certificate ingress class",
 			Issuer: acmeClusterIssuer,
-			Ingress: &extv1beta1.Ingress{
+			Ingress: &ingressutil.Ingress{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      "ingress-name",
 					Namespace: gen.DefaultTestNamespace,
@@ -293,8 +349,8 @@ func TestSync(t *testing.T) {
 					},
 					UID: types.UID("ingress-name"),
 				},
-				Spec: extv1beta1.IngressSpec{
-					TLS: []extv1beta1.IngressTLS{
+				Spec: ingressutil.IngressSpec{
+					TLS: []ingressutil.IngressTLS{
 						{
 							Hosts:      []string{"example.com", "www.example.com"},
 							SecretName: "example-com-tls",
@@ -336,7 +392,7 @@ func TestSync(t *testing.T) {
 		{
 			Name:   "edit-in-place set to false should not trigger editing the ingress in-place",
 			Issuer: acmeClusterIssuer,
-			Ingress: &extv1beta1.Ingress{
+			Ingress: &ingressutil.Ingress{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      "ingress-name",
 					Namespace: gen.DefaultTestNamespace,
@@ -348,8 +404,8 @@ func TestSync(t *testing.T) {
 					},
 					UID: types.UID("ingress-name"),
 				},
-				Spec: extv1beta1.IngressSpec{
-					TLS: []extv1beta1.IngressTLS{
+				Spec: ingressutil.IngressSpec{
+					TLS: []ingressutil.IngressTLS{
 						{
 							Hosts:      []string{"example.com", "www.example.com"},
 							SecretName: "example-com-tls",
@@ -392,7 +448,7 @@ func TestSync(t *testing.T) {
 			Name:   "should error when an ingress specifies dns01 challenge type but no challenge provider",
 			Issuer: acmeClusterIssuer,
 			Err:    true,
-			Ingress: &extv1beta1.Ingress{
+			Ingress: &ingressutil.Ingress{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      "ingress-name",
 					Namespace: gen.DefaultTestNamespace,
@@ -402,8 +458,8 @@ func TestSync(t *testing.T) {
 					},
 					UID: types.UID("ingress-name"),
 				},
-				Spec: extv1beta1.IngressSpec{
-					TLS: []extv1beta1.IngressTLS{
+				Spec: ingressutil.IngressSpec{
+					TLS: []ingressutil.IngressTLS{
 						{
 							Hosts:      []string{"example.com", "www.example.com"},
 							SecretName: "example-com-tls",
@@ -417,7 +473,7 @@ func TestSync(t *testing.T) {
 			Name:   "should error when an invalid ACME challenge type is specified",
 			Issuer: acmeClusterIssuer,
 			Err:    true,
-			Ingress: &extv1beta1.Ingress{
+			Ingress: &ingressutil.Ingress{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      "ingress-name",
 					Namespace: gen.DefaultTestNamespace,
@@ -427,8 +483,8 @@ func TestSync(t *testing.T) {
 					},
 					UID: types.UID("ingress-name"),
 				},
-				Spec: extv1beta1.IngressSpec{
-					TLS: []extv1beta1.IngressTLS{
+				Spec: ingressutil.IngressSpec{
+					TLS: []ingressutil.IngressTLS{
 						{
 							Hosts:      []string{"example.com", "www.example.com"},
 							SecretName: "example-com-tls",
@@ -442,7 +498,7 @@ func TestSync(t *testing.T) {
 			Name:   "return a single DNS01 Certificate for an ingress with a single valid TLS entry and DNS01 annotations",
 			Issuer: acmeClusterIssuer,
 			Err:    true,
-			Ingress: &extv1beta1.Ingress{
+			Ingress: &ingressutil.Ingress{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      "ingress-name",
 					Namespace: gen.DefaultTestNamespace,
@@ -453,8 +509,8 @@ func TestSync(t *testing.T) {
 					},
 					UID: types.UID("ingress-name"),
 				},
-				Spec: extv1beta1.IngressSpec{
-					TLS: []extv1beta1.IngressTLS{
+				Spec: ingressutil.IngressSpec{
+					TLS: []ingressutil.IngressTLS{
 						{
 							Hosts:      []string{"example.com", "www.example.com"},
 							SecretName: "example-com-tls",
@@ -496,7 +552,7 @@ func TestSync(t *testing.T) {
 		{
 			Name:   "should return a certificate without the acme field set when no challenge type is provided",
 			Issuer: acmeClusterIssuer,
-			Ingress: &extv1beta1.Ingress{
+			Ingress: &ingressutil.Ingress{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      "ingress-name",
 					Namespace: gen.DefaultTestNamespace,
@@ -505,8 +561,8 @@ func TestSync(t *testing.T) {
 					},
 					UID: types.UID("ingress-name"),
 				},
-				Spec: extv1beta1.IngressSpec{
-					TLS: []extv1beta1.IngressTLS{
+				Spec: ingressutil.IngressSpec{
+					TLS: []ingressutil.IngressTLS{
 						{
 							Hosts:      []string{"example.com", "www.example.com"},
 							SecretName: "example-com-tls",
@@ -539,7 +595,7 @@ func TestSync(t *testing.T) {
 			DefaultIssuerName:   "issuer-name",
 			DefaultIssuerKind:   "ClusterIssuer",
 			ClusterIssuerLister: []runtime.Object{clusterIssuer},
-			Ingress: &extv1beta1.Ingress{
+			Ingress: &ingressutil.Ingress{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      "ingress-name",
 					Namespace: gen.DefaultTestNamespace,
@@ -548,8 +604,8 @@ func TestSync(t *testing.T) {
 					},
 					UID: types.UID("ingress-name"),
 				},
-				Spec: extv1beta1.IngressSpec{
-					TLS: []extv1beta1.IngressTLS{
+				Spec: ingressutil.IngressSpec{
+					TLS: []ingressutil.IngressTLS{
 						{
 							Hosts:      []string{"example.com", "www.example.com"},
 							SecretName: "example-com-tls",
@@ -580,7 +636,7 @@ func TestSync(t *testing.T) {
 			Issuer:       acmeIssuer,
 			IssuerLister: []runtime.Object{acmeIssuer},
 			Err:          true,
-			Ingress: &extv1beta1.Ingress{
+			Ingress: &ingressutil.Ingress{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      "ingress-name",
 					Namespace: gen.DefaultTestNamespace,
@@ -589,8 +645,8 @@ func TestSync(t *testing.T) {
 					},
 					UID: types.UID("ingress-name"),
 				},
-				Spec: extv1beta1.IngressSpec{
-					TLS: []extv1beta1.IngressTLS{
+				Spec: ingressutil.IngressSpec{
+					TLS: []ingressutil.IngressTLS{
 						{
 							SecretName: "example-com-tls",
 						},
@@ -602,7 +658,7 @@ func TestSync(t *testing.T) {
 			Name:   "should return an error when no TLS secret name is specified",
 			Issuer: acmeIssuer,
 			Err:    true,
-			Ingress: &extv1beta1.Ingress{
+			Ingress: &ingressutil.Ingress{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      "ingress-name",
 					Namespace: gen.DefaultTestNamespace,
@@ -611,8 +667,8 @@ func TestSync(t *testing.T) {
 					},
 					UID: types.UID("ingress-name"),
 				},
-				Spec: extv1beta1.IngressSpec{
-					TLS: []extv1beta1.IngressTLS{
+				Spec: ingressutil.IngressSpec{
+					TLS: []ingressutil.IngressTLS{
 						{
 							Hosts: []string{"example.com"},
 						},
@@ -624,7 +680,7 @@ func TestSync(t *testing.T) {
 		{
 			Name: "should error if the specified issuer is not found",
 			Err:  true,
-			Ingress: &extv1beta1.Ingress{
+			Ingress: &ingressutil.Ingress{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      "ingress-name",
 					Namespace: gen.DefaultTestNamespace,
@@ -639,7 +695,7 @@ func TestSync(t *testing.T) {
 			Name:         "should not return any certificates if a correct Certificate already exists",
 			Issuer:       acmeIssuer,
 			IssuerLister: []runtime.Object{acmeIssuer},
-			Ingress: &extv1beta1.Ingress{
+			Ingress: &ingressutil.Ingress{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      "ingress-name",
 					Namespace: gen.DefaultTestNamespace,
@@ -649,8 +705,8 @@ func TestSync(t *testing.T) {
 					},
 					UID: types.UID("ingress-name"),
 				},
-				Spec: extv1beta1.IngressSpec{
-					TLS: []extv1beta1.IngressTLS{
+				Spec: ingressutil.IngressSpec{
+					TLS: []ingressutil.IngressTLS{
 						{
 							Hosts:      []string{"example.com"},
 							SecretName: "existing-crt",
@@ -692,7 +748,7 @@ func TestSync(t *testing.T) {
 			Name:         "should update a certificate if an incorrect Certificate exists",
 			Issuer:       acmeIssuer,
 			IssuerLister: []runtime.Object{acmeIssuer},
-			Ingress: &extv1beta1.Ingress{
+			Ingress: &ingressutil.Ingress{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      "ingress-name",
 					Namespace: gen.DefaultTestNamespace,
@@ -702,8 +758,8 @@ func TestSync(t *testing.T) {
 					},
 					UID: types.UID("ingress-name"),
 				},
-				Spec: extv1beta1.IngressSpec{
-					TLS: []extv1beta1.IngressTLS{
+				Spec: ingressutil.IngressSpec{
+					TLS: []ingressutil.IngressTLS{
 						{
 							Hosts:      []string{"example.com"},
 							SecretName: "existing-crt",
@@ -752,7 +808,7 @@ func TestSync(t *testing.T) {
 			Name:         "should update a certificate's config if an incorrect Certificate exists",
 			Issuer:       acmeIssuer,
 			IssuerLister: []runtime.Object{acmeIssuer},
-			Ingress: &extv1beta1.Ingress{
+			Ingress: &ingressutil.Ingress{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      "ingress-name",
 					Namespace: gen.DefaultTestNamespace,
@@ -763,8 +819,8 @@ func TestSync(t *testing.T) {
 					},
 					UID: types.UID("ingress-name"),
 				},
-				Spec: extv1beta1.IngressSpec{
-					TLS: []extv1beta1.IngressTLS{
+				Spec: ingressutil.IngressSpec{
+					TLS: []ingressutil.IngressTLS{
 						{
 							Hosts:      []string{"example.com"},
 							SecretName: "existing-crt",
@@ -836,7 +892,7 @@ func TestSync(t *testing.T) {
 			Name:         "should update a Certificate correctly if an existing one of a different type exists",
 			Issuer:       acmeIssuer,
 			IssuerLister: []runtime.Object{acmeIssuer},
-			Ingress: &extv1beta1.Ingress{
+			Ingress: &ingressutil.Ingress{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      "ingress-name",
 					Namespace: gen.DefaultTestNamespace,
@@ -847,8 +903,8 @@ func TestSync(t *testing.T) {
 					},
 					UID: types.UID("ingress-name"),
 				},
-				Spec: extv1beta1.IngressSpec{
-					TLS: []extv1beta1.IngressTLS{
+				Spec: ingressutil.IngressSpec{
+					TLS: []ingressutil.IngressTLS{
 						{
 							Hosts:      []string{"example.com"},
 							SecretName: "existing-crt",
@@ -908,7 +964,7 @@ func TestSync(t *testing.T) {
 			Name:         "should update an existing Certificate resource with new labels if they do not match those specified on the Ingress",
 			Issuer:       acmeIssuer,
 			IssuerLister: []runtime.Object{acmeIssuerNewFormat},
-			Ingress: &extv1beta1.Ingress{
+			Ingress: &ingressutil.Ingress{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      "ingress-name",
 					Namespace: gen.DefaultTestNamespace,
@@ -920,8 +976,8 @@ func TestSync(t *testing.T) {
 					},
 					UID: types.UID("ingress-name"),
 				},
-				Spec: extv1beta1.IngressSpec{
-					TLS: []extv1beta1.IngressTLS{
+				Spec: ingressutil.IngressSpec{
+					TLS: []ingressutil.IngressTLS{
 						{
 							Hosts:      []string{"example.com"},
 							SecretName: "cert-secret-name",
@@ -974,7 +1030,7 @@ func TestSync(t *testing.T) {
 			Name:         "should not update certificate if it does not belong to any ingress",
 			Issuer:       acmeIssuer,
 			IssuerLister: []runtime.Object{acmeIssuer},
-			Ingress: &extv1beta1.Ingress{
+			Ingress: &ingressutil.Ingress{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      "ingress-name",
 					Namespace: gen.DefaultTestNamespace,
@@ -985,8 +1041,8 @@ func TestSync(t *testing.T) {
 					},
 					UID: types.UID("ingress-name"),
 				},
-				Spec: extv1beta1.IngressSpec{
-					TLS: []extv1beta1.IngressTLS{
+				Spec: ingressutil.IngressSpec{
+					TLS: []ingressutil.IngressTLS{
 						{
 							Hosts:      []string{"example.com"},
 							SecretName: "existing-crt",
@@ -1028,7 +1084,7 @@ func TestSync(t *testing.T) {
 			Name:         "should not update certificate if it does not belong to the ingress",
 			Issuer:       acmeIssuer,
 			IssuerLister: []runtime.Object{acmeIssuer},
-			Ingress: &extv1beta1.Ingress{
+			Ingress: &ingressutil.Ingress{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      "ingress-name",
 					Namespace: gen.DefaultTestNamespace,
@@ -1039,8 +1095,8 @@ func TestSync(t *testing.T) {
 					},
 					UID: types.UID("ingress-name"),
 				},
-				Spec: extv1beta1.IngressSpec{
-					TLS: []extv1beta1.IngressTLS{
+				Spec: ingressutil.IngressSpec{
+					TLS: []ingressutil.IngressTLS{
 						{
 							Hosts:      []string{"example.com"},
 							SecretName: "existing-crt",
@@ -1082,7 +1138,7 @@ func TestSync(t *testing.T) {
 			Name:         "should delete a Certificate if its SecretName is not present in the ingress",
 			Issuer:       acmeIssuer,
 			IssuerLister: []runtime.Object{acmeIssuer},
-			Ingress: &extv1beta1.Ingress{
+			Ingress: &ingressutil.Ingress{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      "ingress-name",
 					Namespace: gen.DefaultTestNamespace,
@@ -1205,7 +1261,8 @@ func TestSync(t *testing.T) {
 					issuerKind:                 test.DefaultIssuerKind,
 					autoCertificateAnnotations: []string{testAcmeTLSAnnotation},
 				},
-				helper: &fakeHelper{issuer: test.Issuer},
+				ingressGVK: ingressGVK,
+				helper:     &fakeHelper{issuer: test.Issuer},
 			}
 			b.Start()
 
@@ -1240,7 +1297,7 @@ func (f *fakeHelper) GetGenericIssuer(ref v1alpha1.ObjectReference, ns string) (
 
 func TestIssuerForIngress(t *testing.T) {
 	type testT struct {
-		Ingress      *extv1beta1.Ingress
+		Ingress      *ingressutil.Ingress
 		DefaultName  string
 		DefaultKind  string
 		ExpectedName string
@@ -1318,8 +1375,8 @@ func buildACMEIssuer(name, namespace string) *v1alpha1.Issuer {
 	}
 }
 
-func buildIngress(name, namespace string, annotations map[string]string) *extv1beta1.Ingress {
-	return &extv1beta1.Ingress{
+func buildIngress(name, namespace string, annotations map[string]string) *ingressutil.Ingress {
+	return &ingressutil.Ingress{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:        name,
 			Namespace:   namespace,
@@ -0,0 +1,260 @@
+/*
+Copyright 2019 The Jetstack cert-manager contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podrefresh
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/clock"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha1"
+	cmlisters "github.com/jetstack/cert-manager/pkg/client/listers/certmanager/v1alpha1"
+	controllerpkg "github.com/jetstack/cert-manager/pkg/controller"
+	logf "github.com/jetstack/cert-manager/pkg/logs"
+	"github.com/jetstack/cert-manager/pkg/metrics"
+	"github.com/jetstack/cert-manager/pkg/util/workload"
+)
+
+// controller restarts or notifies the workloads selected for a certificate's
+// Secret whenever the certificate stored in that Secret is renewed.
+// Workloads are selected by a Certificate's spec.podRefresh or, if that is
+// not set and --enable-pod-refresh is, by referencing the Secret.
+// Each item in the queue identifies a single workload to be restarted for a
+// single Secret, so that failed restarts can be retried individually without
+// rolling the other workloads again.
+type controller struct {
+	kClient kubernetes.Interface
+
+	secretLister      corelisters.SecretLister
+	certificateLister cmlisters.CertificateLister
+
+	// workloadIndexers holds the informer indexers for each supported
+	// workload kind, indexed by workload.SecretIndex
+	workloadIndexers map[workload.Kind]cache.Indexer
+
+	// enablePodRefresh causes workloads referencing the Secret of a
+	// Certificate without a spec.podRefresh to be restarted
+	enablePodRefresh bool
+
+	metrics *metrics.Metrics
+
+	// used for testing
+	clock clock.Clock
+
+	// used to record Events about resources to the API
+	recorder record.EventRecorder
+
+	// maintain a reference to the workqueue for this controller
+	queue workqueue.RateLimitingInterface
+
+	// logger to be used by this controller
+	log logr.Logger
+
+	// restartBudget holds a token for every restart started within the last
+	// restartInterval. Once it is full, further restarts are delayed so that
+	// rollouts are staggered over time.
+	restartBudget   chan struct{}
+	restartInterval time.Duration
+}
+
+// Register registers and constructs the controller using the provided context.
+// It returns the workqueue to be used to enqueue items, a list of
+// InformerSynced functions that must be synced, or an error.
+func (c *controller) Register(ctx *controllerpkg.Context) (workqueue.RateLimitingInterface, []cache.InformerSynced, []controllerpkg.RunFunc, error) {
+	// construct a new named logger to be reused throughout the controller
+	c.log = logf.FromContext(ctx.RootContext, ControllerName)
+
+	// create a queue used to queue up items to be processed
+	c.queue = workqueue.NewNamedRateLimitingQueue(controllerpkg.DefaultItemBasedRateLimiter(), ControllerName)
+
+	// obtain references to all the informers used by this controller
+	secretsInformer := ctx.KubeSharedInformerFactory.Core().V1().Secrets()
+	certificateInformer := ctx.SharedInformerFactory.Certmanager().V1alpha1().Certificates()
+	workloadInformers := map[workload.Kind]cache.SharedIndexInformer{
+		workload.KindDeployment:  ctx.KubeSharedInformerFactory.Apps().V1().Deployments().Informer(),
+		workload.KindStatefulSet: ctx.KubeSharedInformerFactory.Apps().V1().StatefulSets().Informer(),
+		workload.KindDaemonSet:   ctx.KubeSharedInformerFactory.Apps().V1().DaemonSets().Informer(),
+		workload.KindReplicaSet:  ctx.KubeSharedInformerFactory.Apps().V1().ReplicaSets().Informer(),
+		workload.KindCronJob:     ctx.KubeSharedInformerFactory.Batch().V1beta1().CronJobs().Informer(),
+	}
+
+	// build a list of InformerSynced functions that will be returned by the Register method.
+	// the controller will only begin processing items once all of these informers have synced.
+	mustSync := []cache.InformerSynced{
+		secretsInformer.Informer().HasSynced,
+		certificateInformer.Informer().HasSynced,
+	}
+
+	c.workloadIndexers = make(map[workload.Kind]cache.Indexer)
+	for kind, informer := range workloadInformers {
+		if err := informer.AddIndexers(cache.Indexers{workload.SecretIndex: workload.SecretIndexFunc}); err != nil {
+			return nil, nil, nil, fmt.Errorf("error adding secret index to %s informer: %v", kind, err)
+		}
+		c.workloadIndexers[kind] = informer.GetIndexer()
+		mustSync = append(mustSync, informer.HasSynced)
+	}
+
+	// set all the references to the listers for used by the Sync function
+	c.secretLister = secretsInformer.Lister()
+	c.certificateLister = certificateInformer.Lister()
+
+	// register handler functions
+	secretsInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{UpdateFunc: c.handleSecretUpdate})
+
+	maxConcurrent := ctx.CertificateOptions.PodRefreshMaxConcurrentRestarts
+	if maxConcurrent < 1 {
+		maxConcurrent = 1
+	}
+	c.restartBudget = make(chan struct{}, maxConcurrent)
+	c.restartInterval = ctx.CertificateOptions.PodRefreshRestartInterval
+	c.enablePodRefresh = ctx.CertificateOptions.EnablePodRefresh
+
+	c.metrics = metrics.Default
+	c.clock = ctx.Clock
+	c.recorder = ctx.Recorder
+	c.kClient = ctx.Client
+
+	return c.queue, mustSync, nil, nil
+}
+
+// handleSecretUpdate enqueues every workload selected for the given Secret
+// if the certificate stored in a cert-manager managed Secret has changed.
+// Newly issued certificates are ignored, as no workload can have loaded them
+// yet.
+func (c *controller) handleSecretUpdate(oldObj, newObj interface{}) {
+	log := c.log.WithName("handleSecretUpdate")
+
+	oldSecret, ok := oldObj.(*corev1.Secret)
+	if !ok {
+		log.Error(nil, "object is not a Secret resource")
+		return
+	}
+	newSecret, ok := newObj.(*corev1.Secret)
+	if !ok {
+		log.Error(nil, "object is not a Secret resource")
+		return
+	}
+	log = logf.WithResource(log, newSecret)
+
+	if newSecret.Annotations[cmapi.CertificateNameKey] == "" {
+		return
+	}
+	oldCert := oldSecret.Data[corev1.TLSCertKey]
+	newCert := newSecret.Data[corev1.TLSCertKey]
+	if len(oldCert) == 0 || len(newCert) == 0 || bytes.Equal(oldCert, newCert) {
+		return
+	}
+
+	crt := c.certificateForSecret(newSecret.Namespace, newSecret.Name)
+	workloads, err := c.selectedWorkloads(crt, newSecret.Namespace, newSecret.Name)
+	if err != nil {
+		log.Error(err, "error finding workloads selected for secret")
+		return
+	}
+	for _, w := range workloads {
+		log.V(logf.DebugLevel).Info("queuing workload for refresh", "kind", w.Kind, "workload", w.Meta().GetName())
+		c.queue.Add(restartKey(newSecret.Namespace, newSecret.Name, w.Kind, w.Meta().GetName()))
+	}
+}
+
+// selectedWorkloads returns all workloads that should be refreshed when the
+// certificate in the named Secret, managed by crt, is renewed.
+// crt may be nil if the Certificate cannot be found.
+func (c *controller) selectedWorkloads(crt *cmapi.Certificate, namespace, secretName string) ([]*workload.Workload, error) {
+	// workloads explicitly selected by the Certificate need not reference
+	// the Secret, so all workloads in the namespace are candidates
+	index, value := workload.SecretIndex, workload.SecretIndexKey(namespace, secretName)
+	if cfg := podRefreshConfig(crt); cfg != nil && hasSelection(cfg) {
+		index, value = cache.NamespaceIndex, namespace
+	}
+
+	var workloads []*workload.Workload
+	for kind, indexer := range c.workloadIndexers {
+		objs, err := indexer.ByIndex(index, value)
+		if err != nil {
+			return nil, fmt.Errorf("error listing %s resources: %v", kind, err)
+		}
+		for _, obj := range objs {
+			w, ok := workload.FromObject(obj)
+			if !ok {
+				continue
+			}
+			selected, err := c.selects(crt, secretName, w)
+			if err != nil {
+				return nil, err
+			}
+			if selected {
+				workloads = append(workloads, w)
+			}
+		}
+	}
+	return workloads, nil
+}
+
+// restartKey returns the queue key used to restart the named workload for the
+// given Secret.
+func restartKey(namespace, secret string, kind workload.Kind, name string) string {
+	return strings.Join([]string{namespace, secret, string(kind), name}, "/")
+}
+
+// splitRestartKey splits a key returned by restartKey into its components.
+func splitRestartKey(key string) (namespace, secret string, kind workload.Kind, name string, err error) {
+	parts := strings.Split(key, "/")
+	if len(parts) != 4 {
+		return "", "", "", "", fmt.Errorf("unexpected key format: %q", key)
+	}
+	return parts[0], parts[1], workload.Kind(parts[2]), parts[3], nil
+}
+
+func (c *controller) ProcessItem(ctx context.Context, key string) error {
+	ctx = logf.NewContext(ctx, nil, ControllerName)
+	log := logf.FromContext(ctx)
+
+	namespace, secret, kind, name, err := splitRestartKey(key)
+	if err != nil {
+		log.Error(err, "invalid resource key")
+		return nil
+	}
+
+	if kind == replicaSetPodsKind {
+		return c.syncReplicaSetPods(ctx, namespace, secret, name)
+	}
+	return c.Sync(ctx, namespace, secret, kind, name)
+}
+
+const (
+	ControllerName = "podrefresh"
+)
+
+func init() {
+	controllerpkg.Register(ControllerName, func(ctx *controllerpkg.Context) (controllerpkg.Interface, error) {
+		return controllerpkg.NewBuilder(ctx, ControllerName).
+			For(&controller{}).
+			Complete()
+	})
+}
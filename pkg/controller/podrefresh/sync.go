@@ -0,0 +1,294 @@
+/*
+Copyright 2019 The Jetstack cert-manager contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podrefresh
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/retry"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha1"
+	logf "github.com/jetstack/cert-manager/pkg/logs"
+	"github.com/jetstack/cert-manager/pkg/util/workload"
+)
+
+const (
+	restartLabel        = "certmanager.k8s.io/time-restarted"
+	noRestartAnnotation = "certmanager.k8s.io/disable-auto-restart"
+
+	reasonRestarted     = "RestartedWorkload"
+	reasonNotified      = "NotifiedWorkload"
+	reasonRenewed       = "CertificateRenewed"
+	reasonRestartFailed = "RestartFailed"
+
+	// replicaSetPodsKind is used in place of a workload kind in queue keys
+	// to replace the pods of a restarted standalone ReplicaSet, which does
+	// not roll its pods itself.
+	replicaSetPodsKind workload.Kind = "ReplicaSetPods"
+
+	// replicaSetPodsInterval is how long to wait between checks while
+	// replacing the pods of a standalone ReplicaSet.
+	replicaSetPodsInterval = time.Second * 10
+
+	// restartBudgetRetryInterval is the minimum time to wait before retrying
+	// a restart delayed because the restart budget was exhausted.
+	restartBudgetRetryInterval = time.Second * 10
+)
+
+// Sync restarts or notifies the named workload so that it picks up the
+// renewed certificate stored in the named Secret.
+func (c *controller) Sync(ctx context.Context, namespace, secretName string, kind workload.Kind, name string) error {
+	c.metrics.IncrementSyncCallCount(ControllerName)
+
+	log := logf.FromContext(ctx)
+	log = logf.WithRelatedResourceName(log, secretName, namespace, "Secret")
+	log = log.WithValues("kind", kind, "workload", name)
+	dbg := log.V(logf.DebugLevel)
+
+	w, err := c.getWorkload(kind, namespace, name)
+	if k8sErrors.IsNotFound(err) {
+		dbg.Info("workload no longer exists, skipping restart")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	crt := c.certificateForSecret(namespace, secretName)
+	selected, err := c.selects(crt, secretName, w)
+	if err != nil {
+		return err
+	}
+	if !selected {
+		dbg.Info("workload is no longer selected for refresh, skipping restart")
+		return nil
+	}
+	if w.Meta().GetAnnotations()[noRestartAnnotation] == "true" {
+		dbg.Info("workload has opted out of automatic restarts")
+		return nil
+	}
+
+	if cfg := podRefreshConfig(crt); cfg != nil && cfg.Mode == cmapi.PodRefreshModeNotify {
+		log.Info("notifying workload of renewed certificate")
+		c.recorder.Eventf(w.Object, corev1.EventTypeNormal, reasonRenewed, "Certificate in Secret %q has been renewed", secretName)
+		c.recorder.Eventf(crt, corev1.EventTypeNormal, reasonNotified, "Notified %s %q of renewed certificate", kind, name)
+		return nil
+	}
+
+	release, ok := c.acquireRestartToken()
+	if !ok {
+		// a token is released at most restartInterval after it was
+		// acquired. The base controller forgets the key once nil is
+		// returned, so the retry must be delayed explicitly rather than
+		// rate limited.
+		delay := c.restartInterval
+		if delay < restartBudgetRetryInterval {
+			delay = restartBudgetRetryInterval
+		}
+		dbg.Info("restart budget exhausted, delaying restart", "delay", delay)
+		c.queue.AddAfter(restartKey(namespace, secretName, kind, name), delay)
+		return nil
+	}
+	defer release()
+
+	err = c.restart(w, c.clock.Now().Format("2006-1-2.1504"))
+	c.metrics.IncrementPodRefreshRestart(string(kind), namespace, err)
+	if err != nil {
+		c.recorder.Eventf(w.Object, corev1.EventTypeWarning, reasonRestartFailed, "Failed to restart to pick up renewed certificate in Secret %q: %v", secretName, err)
+		if crt != nil {
+			c.recorder.Eventf(crt, corev1.EventTypeWarning, reasonRestartFailed, "Failed to restart %s %q: %v", kind, name, err)
+		}
+		return err
+	}
+
+	log.Info("restarted workload to pick up renewed certificate")
+	c.recorder.Eventf(w.Object, corev1.EventTypeNormal, reasonRenewed, "Restarted to pick up renewed certificate in Secret %q", secretName)
+	if crt != nil {
+		c.recorder.Eventf(crt, corev1.EventTypeNormal, reasonRestarted, "Restarted %s %q to pick up renewed certificate", kind, name)
+	}
+
+	if kind == workload.KindReplicaSet {
+		c.queue.Add(restartKey(namespace, secretName, replicaSetPodsKind, name))
+	}
+
+	return nil
+}
+
+// syncReplicaSetPods replaces the pods of the named standalone ReplicaSet
+// that were created before it was restarted, one pod at a time. The next pod
+// is only replaced once all of the ReplicaSet's replicas are ready.
+func (c *controller) syncReplicaSetPods(ctx context.Context, namespace, secretName, name string) error {
+	log := logf.FromContext(ctx)
+	log = log.WithValues("kind", workload.KindReplicaSet, "workload", name)
+	dbg := log.V(logf.DebugLevel)
+
+	w, err := c.getWorkload(workload.KindReplicaSet, namespace, name)
+	if k8sErrors.IsNotFound(err) {
+		dbg.Info("replicaset no longer exists, skipping pod replacement")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	rs := w.Object.(*appsv1.ReplicaSet)
+
+	key := restartKey(namespace, secretName, replicaSetPodsKind, name)
+	replicas := int32(1)
+	if rs.Spec.Replicas != nil {
+		replicas = *rs.Spec.Replicas
+	}
+	if rs.Status.ObservedGeneration < rs.Generation || rs.Status.ReadyReplicas < replicas {
+		dbg.Info("waiting for replicaset pods to become ready before replacing the next pod")
+		c.queue.AddAfter(key, replicaSetPodsInterval)
+		return nil
+	}
+
+	stale, err := workload.ReplaceStalePod(c.kClient, rs, restartLabel)
+	if err != nil {
+		return err
+	}
+	if stale == 0 {
+		log.Info("replaced all replicaset pods to pick up renewed certificate")
+		return nil
+	}
+
+	dbg.Info("replacing replicaset pods", "remaining", stale)
+	c.queue.AddAfter(key, replicaSetPodsInterval)
+	return nil
+}
+
+// getWorkload returns a copy of the named workload from the informer cache.
+func (c *controller) getWorkload(kind workload.Kind, namespace, name string) (*workload.Workload, error) {
+	indexer, ok := c.workloadIndexers[kind]
+	if !ok {
+		return nil, fmt.Errorf("unsupported workload kind %q", kind)
+	}
+	obj, exists, err := indexer.GetByKey(namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, k8sErrors.NewNotFound(corev1.Resource(string(kind)), name)
+	}
+	w, ok := workload.FromObject(obj.(runtime.Object).DeepCopyObject())
+	if !ok {
+		return nil, fmt.Errorf("%s %s/%s is not a restartable workload", kind, namespace, name)
+	}
+	return w, nil
+}
+
+// restart persists the restart label on the workload, refetching it from the
+// apiserver and retrying if the cached copy is out of date.
+func (c *controller) restart(w *workload.Workload, value string) error {
+	first := true
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		if !first {
+			latest, err := workload.Get(c.kClient, w.Kind, w.Meta().GetNamespace(), w.Meta().GetName())
+			if err != nil {
+				return err
+			}
+			w = latest
+		}
+		first = false
+		return workload.Restart(c.kClient, w, restartLabel, value)
+	})
+}
+
+// acquireRestartToken attempts to take a token from the restart budget.
+// If successful, the returned function must be called once the restart has
+// been attempted. The token is returned to the budget after restartInterval
+// has elapsed.
+func (c *controller) acquireRestartToken() (func(), bool) {
+	select {
+	case c.restartBudget <- struct{}{}:
+	default:
+		return nil, false
+	}
+	release := func() { <-c.restartBudget }
+	if c.restartInterval <= 0 {
+		return release, true
+	}
+	return func() { time.AfterFunc(c.restartInterval, release) }, true
+}
+
+// selects returns true if the given workload should be refreshed when the
+// certificate in the named Secret, managed by crt, is renewed.
+// crt may be nil if the Certificate cannot be found.
+func (c *controller) selects(crt *cmapi.Certificate, secretName string, w *workload.Workload) (bool, error) {
+	cfg := podRefreshConfig(crt)
+	if cfg == nil {
+		return c.enablePodRefresh && w.ReferencesSecret(secretName), nil
+	}
+	if !hasSelection(cfg) {
+		return w.ReferencesSecret(secretName), nil
+	}
+
+	for _, ref := range cfg.Workloads {
+		if ref.Kind == string(w.Kind) && ref.Name == w.Meta().GetName() {
+			return true, nil
+		}
+	}
+	if cfg.Selector == nil {
+		return false, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(cfg.Selector)
+	if err != nil {
+		return false, fmt.Errorf("invalid pod refresh selector on Certificate %s/%s: %v", crt.Namespace, crt.Name, err)
+	}
+	return selector.Matches(labels.Set(w.Meta().GetLabels())), nil
+}
+
+// podRefreshConfig returns the pod refresh configuration of the given
+// Certificate, or nil if it has none.
+func podRefreshConfig(crt *cmapi.Certificate) *cmapi.PodRefreshConfig {
+	if crt == nil {
+		return nil
+	}
+	return crt.Spec.PodRefresh
+}
+
+// hasSelection returns true if cfg explicitly selects workloads, rather
+// than relying on the workloads that reference the Certificate's Secret.
+func hasSelection(cfg *cmapi.PodRefreshConfig) bool {
+	return cfg.Selector != nil || len(cfg.Workloads) > 0
+}
+
+// certificateForSecret returns the Certificate that manages the named Secret,
+// or nil if it cannot be found.
+func (c *controller) certificateForSecret(namespace, secretName string) *cmapi.Certificate {
+	secret, err := c.secretLister.Secrets(namespace).Get(secretName)
+	if err != nil {
+		return nil
+	}
+	crtName := secret.Annotations[cmapi.CertificateNameKey]
+	if crtName == "" {
+		return nil
+	}
+	crt, err := c.certificateLister.Certificates(namespace).Get(crtName)
+	if err != nil {
+		return nil
+	}
+	return crt
+}
@@ -0,0 +1,388 @@
+/*
+Copyright 2019 The Jetstack cert-manager contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podrefresh
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	coretesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/util/workqueue"
+	fakeclock "k8s.io/utils/clock/testing"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha1"
+	testpkg "github.com/jetstack/cert-manager/pkg/controller/test"
+	"github.com/jetstack/cert-manager/pkg/util/workload"
+	"github.com/jetstack/cert-manager/test/unit/gen"
+)
+
+var fixedClockStart = time.Date(2019, time.October, 1, 12, 30, 0, 0, time.UTC)
+
+func deploymentUsingSecret(name, secretName string, annotations map[string]string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   gen.DefaultTestNamespace,
+			Name:        name,
+			Labels:      map[string]string{"app": name},
+			Annotations: annotations,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name: "app",
+						EnvFrom: []corev1.EnvFromSource{{
+							SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: secretName}},
+						}},
+					}},
+				},
+			},
+		},
+	}
+}
+
+func TestSync(t *testing.T) {
+	crt := gen.Certificate("test", gen.SetCertificateNamespace(gen.DefaultTestNamespace), gen.SetCertificateSecretName("output"))
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   gen.DefaultTestNamespace,
+			Name:        "output",
+			Annotations: map[string]string{cmapi.CertificateNameKey: "test"},
+		},
+	}
+	selectingCrt := gen.CertificateFrom(crt, gen.SetCertificatePodRefresh(cmapi.PodRefreshConfig{
+		Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "app"}},
+	}))
+	notifyingCrt := gen.CertificateFrom(crt, gen.SetCertificatePodRefresh(cmapi.PodRefreshConfig{
+		Workloads: []cmapi.WorkloadReference{{Kind: "Deployment", Name: "app"}},
+		Mode:      cmapi.PodRefreshModeNotify,
+	}))
+	restartValue := fixedClockStart.Format("2006-1-2.1504")
+	restartedDeployment := func(secretName string) *appsv1.Deployment {
+		d := deploymentUsingSecret("app", secretName, nil)
+		d.Labels[restartLabel] = restartValue
+		d.Spec.Template.Labels = map[string]string{restartLabel: restartValue}
+		return d
+	}
+
+	tests := map[string]struct {
+		builder           *testpkg.Builder
+		workload          string
+		podRefreshEnabled bool
+		exhaustedBudget   bool
+		restartInterval   time.Duration
+		expectedErr       bool
+	}{
+		"restart a deployment that consumes the secret": {
+			workload:          "app",
+			podRefreshEnabled: true,
+			builder: &testpkg.Builder{
+				KubeObjects:        []runtime.Object{secret, deploymentUsingSecret("app", "output", nil)},
+				CertManagerObjects: []runtime.Object{crt},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateAction(appsv1.SchemeGroupVersion.WithResource("deployments"), gen.DefaultTestNamespace, restartedDeployment("output"))),
+				},
+				ExpectedEvents: []string{
+					`Normal CertificateRenewed Restarted to pick up renewed certificate in Secret "output"`,
+					`Normal RestartedWorkload Restarted Deployment "app" to pick up renewed certificate`,
+				},
+			},
+		},
+		"do not restart a deployment if pod refresh is disabled and the certificate has not opted in": {
+			workload: "app",
+			builder: &testpkg.Builder{
+				KubeObjects:        []runtime.Object{secret, deploymentUsingSecret("app", "output", nil)},
+				CertManagerObjects: []runtime.Object{crt},
+			},
+		},
+		"restart a deployment selected by the certificate that does not consume the secret": {
+			workload: "app",
+			builder: &testpkg.Builder{
+				KubeObjects:        []runtime.Object{secret, deploymentUsingSecret("app", "other", nil)},
+				CertManagerObjects: []runtime.Object{selectingCrt},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateAction(appsv1.SchemeGroupVersion.WithResource("deployments"), gen.DefaultTestNamespace, restartedDeployment("other"))),
+				},
+				ExpectedEvents: []string{
+					`Normal CertificateRenewed Restarted to pick up renewed certificate in Secret "output"`,
+					`Normal RestartedWorkload Restarted Deployment "app" to pick up renewed certificate`,
+				},
+			},
+		},
+		"do not restart a deployment that is not selected by the certificate": {
+			workload:          "other",
+			podRefreshEnabled: true,
+			builder: &testpkg.Builder{
+				KubeObjects:        []runtime.Object{secret, deploymentUsingSecret("other", "output", nil)},
+				CertManagerObjects: []runtime.Object{selectingCrt},
+			},
+		},
+		"only notify a deployment if the certificate requests notifications": {
+			workload: "app",
+			builder: &testpkg.Builder{
+				KubeObjects:        []runtime.Object{secret, deploymentUsingSecret("app", "output", nil)},
+				CertManagerObjects: []runtime.Object{notifyingCrt},
+				ExpectedEvents: []string{
+					`Normal CertificateRenewed Certificate in Secret "output" has been renewed`,
+					`Normal NotifiedWorkload Notified Deployment "app" of renewed certificate`,
+				},
+			},
+		},
+		"do not restart a deployment that has opted out": {
+			workload:          "app",
+			podRefreshEnabled: true,
+			builder: &testpkg.Builder{
+				KubeObjects:        []runtime.Object{secret, deploymentUsingSecret("app", "output", map[string]string{noRestartAnnotation: "true"})},
+				CertManagerObjects: []runtime.Object{crt},
+			},
+		},
+		"do not restart a deployment that no longer references the secret": {
+			workload:          "app",
+			podRefreshEnabled: true,
+			builder: &testpkg.Builder{
+				KubeObjects:        []runtime.Object{secret, deploymentUsingSecret("app", "other", nil)},
+				CertManagerObjects: []runtime.Object{crt},
+			},
+		},
+		"do nothing if the deployment has been deleted": {
+			workload:          "app",
+			podRefreshEnabled: true,
+			builder: &testpkg.Builder{
+				KubeObjects:        []runtime.Object{secret},
+				CertManagerObjects: []runtime.Object{crt},
+			},
+		},
+		"delay the restart if the restart budget is exhausted": {
+			workload:          "app",
+			podRefreshEnabled: true,
+			exhaustedBudget:   true,
+			restartInterval:   time.Hour,
+			builder: &testpkg.Builder{
+				KubeObjects:        []runtime.Object{secret, deploymentUsingSecret("app", "output", nil)},
+				CertManagerObjects: []runtime.Object{crt},
+			},
+		},
+		"delay the restart if the restart budget is exhausted and there is no restart interval": {
+			workload:          "app",
+			podRefreshEnabled: true,
+			exhaustedBudget:   true,
+			builder: &testpkg.Builder{
+				KubeObjects:        []runtime.Object{secret, deploymentUsingSecret("app", "output", nil)},
+				CertManagerObjects: []runtime.Object{crt},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			test.builder.T = t
+			test.builder.Clock = fakeclock.NewFakeClock(fixedClockStart)
+			test.builder.Init()
+			test.builder.PodRefreshMaxConcurrentRestarts = 1
+			test.builder.PodRefreshRestartInterval = test.restartInterval
+			test.builder.EnablePodRefresh = test.podRefreshEnabled
+			defer test.builder.Stop()
+
+			c := &controller{}
+			c.Register(test.builder.Context)
+			test.builder.Start()
+
+			if test.exhaustedBudget {
+				c.restartBudget <- struct{}{}
+			}
+
+			err := c.Sync(context.Background(), gen.DefaultTestNamespace, "output", workload.KindDeployment, test.workload)
+			if err != nil && !test.expectedErr {
+				t.Errorf("expected to not get an error, but got: %v", err)
+			}
+			if err == nil && test.expectedErr {
+				t.Errorf("expected to get an error but did not get one")
+			}
+
+			// delayed restarts are added to the queue after a delay rather
+			// than immediately
+			if l := c.queue.Len(); l != 0 {
+				t.Errorf("expected no items to be immediately queued, got %d", l)
+			}
+
+			test.builder.CheckAndFinish(err)
+		})
+	}
+}
+
+func TestSyncReplicaSetPods(t *testing.T) {
+	replicas := int32(1)
+	replicaSet := func(readyReplicas int32) *appsv1.ReplicaSet {
+		return &appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{Namespace: gen.DefaultTestNamespace, Name: "app", UID: types.UID("app-uid")},
+			Spec: appsv1.ReplicaSetSpec{
+				Replicas: &replicas,
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "app"}},
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "app", restartLabel: "now"}},
+				},
+			},
+			Status: appsv1.ReplicaSetStatus{ReadyReplicas: readyReplicas},
+		}
+	}
+	isController := true
+	pod := func(restarted string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Namespace: gen.DefaultTestNamespace,
+			Name:      "app-pod",
+			Labels:    map[string]string{"app": "app", restartLabel: restarted},
+			OwnerReferences: []metav1.OwnerReference{
+				{Kind: "ReplicaSet", Name: "app", UID: types.UID("app-uid"), Controller: &isController},
+			},
+		}}
+	}
+
+	tests := map[string]struct {
+		builder       *testpkg.Builder
+		expectRequeue bool
+	}{
+		"replace a pod created before the replicaset was restarted": {
+			builder: &testpkg.Builder{
+				KubeObjects: []runtime.Object{replicaSet(1), pod("earlier")},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewDeleteAction(corev1.SchemeGroupVersion.WithResource("pods"), gen.DefaultTestNamespace, "app-pod")),
+				},
+			},
+			expectRequeue: true,
+		},
+		"wait for the replicaset's pods to become ready before replacing a pod": {
+			builder: &testpkg.Builder{
+				KubeObjects: []runtime.Object{replicaSet(0), pod("earlier")},
+			},
+			expectRequeue: true,
+		},
+		"stop once all pods have been replaced": {
+			builder: &testpkg.Builder{
+				KubeObjects: []runtime.Object{replicaSet(1), pod("now")},
+			},
+		},
+		"do nothing if the replicaset has been deleted": {
+			builder: &testpkg.Builder{},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			test.builder.T = t
+			test.builder.Init()
+			defer test.builder.Stop()
+
+			c := &controller{}
+			c.Register(test.builder.Context)
+			// record delayed requeues rather than waiting for them
+			queue := &delayRecordingQueue{RateLimitingInterface: c.queue}
+			c.queue = queue
+			test.builder.Start()
+
+			err := c.syncReplicaSetPods(context.Background(), gen.DefaultTestNamespace, "output", "app")
+			if err != nil {
+				t.Errorf("expected to not get an error, but got: %v", err)
+			}
+			if requeued := len(queue.delayed) > 0; requeued != test.expectRequeue {
+				t.Errorf("expected requeue to be %t, got %t", test.expectRequeue, requeued)
+			}
+
+			test.builder.CheckAndFinish(err)
+		})
+	}
+}
+
+// delayRecordingQueue records items added to the queue with a delay.
+type delayRecordingQueue struct {
+	workqueue.RateLimitingInterface
+	delayed []interface{}
+}
+
+func (q *delayRecordingQueue) AddAfter(item interface{}, duration time.Duration) {
+	q.delayed = append(q.delayed, item)
+}
+
+func TestHandleSecretUpdate(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   gen.DefaultTestNamespace,
+			Name:        "output",
+			Annotations: map[string]string{cmapi.CertificateNameKey: "test"},
+		},
+		Data: map[string][]byte{corev1.TLSCertKey: []byte("old")},
+	}
+	renewed := secret.DeepCopy()
+	renewed.Data[corev1.TLSCertKey] = []byte("new")
+	unmanaged := renewed.DeepCopy()
+	unmanaged.Annotations = nil
+	issued := secret.DeepCopy()
+	issued.Data = nil
+	crt := gen.Certificate("test", gen.SetCertificateNamespace(gen.DefaultTestNamespace), gen.SetCertificateSecretName("output"))
+	selectingCrt := gen.CertificateFrom(crt, gen.SetCertificatePodRefresh(cmapi.PodRefreshConfig{
+		Selector:  &metav1.LabelSelector{MatchLabels: map[string]string{"app": "a"}},
+		Workloads: []cmapi.WorkloadReference{{Kind: "Deployment", Name: "c"}},
+	}))
+	optInCrt := gen.CertificateFrom(crt, gen.SetCertificatePodRefresh(cmapi.PodRefreshConfig{}))
+
+	tests := map[string]struct {
+		old, new          *corev1.Secret
+		crt               *cmapi.Certificate
+		podRefreshEnabled bool
+		expectedLen       int
+	}{
+		"enqueue workloads when the certificate is renewed":   {old: secret, new: renewed, crt: crt, podRefreshEnabled: true, expectedLen: 2},
+		"ignore certificates that have not opted in":          {old: secret, new: renewed, crt: crt},
+		"enqueue workloads if the certificate has opted in":   {old: secret, new: renewed, crt: optInCrt, expectedLen: 2},
+		"enqueue workloads selected by the certificate":       {old: secret, new: renewed, crt: selectingCrt, podRefreshEnabled: true, expectedLen: 2},
+		"ignore secrets that are not managed by cert-manager": {old: secret, new: unmanaged, crt: crt, podRefreshEnabled: true},
+		"ignore updates that do not change the certificate":   {old: secret, new: secret, crt: crt, podRefreshEnabled: true},
+		"ignore newly issued certificates":                    {old: issued, new: renewed, crt: crt, podRefreshEnabled: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			builder := &testpkg.Builder{
+				T: t,
+				KubeObjects: []runtime.Object{
+					test.new,
+					deploymentUsingSecret("a", "output", nil),
+					deploymentUsingSecret("b", "output", nil),
+					deploymentUsingSecret("c", "other", nil),
+				},
+				CertManagerObjects: []runtime.Object{test.crt},
+			}
+			builder.Init()
+			builder.EnablePodRefresh = test.podRefreshEnabled
+			defer builder.Stop()
+
+			c := &controller{}
+			c.Register(builder.Context)
+			builder.Start()
+
+			c.handleSecretUpdate(test.old, test.new)
+			if l := c.queue.Len(); l != test.expectedLen {
+				t.Errorf("expected %d items to be queued, got %d", test.expectedLen, l)
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+/*
+Copyright 2019 The Jetstack cert-manager contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+
+	clientset "github.com/jetstack/cert-manager/pkg/client/clientset/versioned"
+	cmlisters "github.com/jetstack/cert-manager/pkg/client/listers/certmanager/v1alpha1"
+	controllerpkg "github.com/jetstack/cert-manager/pkg/controller"
+	"github.com/jetstack/cert-manager/pkg/issuer"
+	logf "github.com/jetstack/cert-manager/pkg/logs"
+	"github.com/jetstack/cert-manager/pkg/util/route"
+)
+
+const (
+	ControllerName = "route-shim"
+)
+
+type defaults struct {
+	autoCertificateAnnotations []string
+	issuerName, issuerKind     string
+}
+
+// controller creates Certificate resources for annotated OpenShift Routes,
+// and copies the issued certificate and private key into the TLS
+// configuration of the Route once they are available.
+type controller struct {
+	queue workqueue.RateLimitingInterface
+
+	// logger to be used by this controller
+	log logr.Logger
+
+	dynamicClient dynamic.Interface
+	cmClient      clientset.Interface
+	recorder      record.EventRecorder
+
+	routeLister         cache.GenericLister
+	certificateLister   cmlisters.CertificateLister
+	secretLister        corelisters.SecretLister
+	issuerLister        cmlisters.IssuerLister
+	clusterIssuerLister cmlisters.ClusterIssuerLister
+
+	helper   issuer.Helper
+	defaults defaults
+}
+
+// Register registers and constructs the controller using the provided context.
+// It returns the workqueue to be used to enqueue items, a list of
+// InformerSynced functions that must be synced, or an error.
+func (c *controller) Register(ctx *controllerpkg.Context) (workqueue.RateLimitingInterface, []cache.InformerSynced, []controllerpkg.RunFunc, error) {
+	// construct a new named logger to be reused throughout the controller
+	c.log = logf.FromContext(ctx.RootContext, ControllerName)
+
+	// create a queue used to queue up items to be processed
+	c.queue = workqueue.NewNamedRateLimitingQueue(controllerpkg.DefaultItemBasedRateLimiter(), ControllerName)
+
+	// Routes are not part of the Kubernetes API, so they are watched with a
+	// dynamic informer that is started alongside this controller rather
+	// than by the shared informer factories.
+	routeInformer := dynamicinformer.NewFilteredDynamicInformer(ctx.DynamicClient, route.GroupVersionResource, ctx.Namespace, time.Minute*5, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, nil)
+	certificatesInformer := ctx.SharedInformerFactory.Certmanager().V1alpha1().Certificates()
+	secretsInformer := ctx.KubeSharedInformerFactory.Core().V1().Secrets()
+	issuerInformer := ctx.SharedInformerFactory.Certmanager().V1alpha1().Issuers()
+	// build a list of InformerSynced functions that will be returned by the Register method.
+	// the controller will only begin processing items once all of these informers have synced.
+	mustSync := []cache.InformerSynced{
+		routeInformer.Informer().HasSynced,
+		certificatesInformer.Informer().HasSynced,
+		secretsInformer.Informer().HasSynced,
+		issuerInformer.Informer().HasSynced,
+	}
+
+	// set all the references to the listers for used by the Sync function
+	c.routeLister = routeInformer.Lister()
+	c.certificateLister = certificatesInformer.Lister()
+	c.secretLister = secretsInformer.Lister()
+	c.issuerLister = issuerInformer.Lister()
+
+	// if we are running in non-namespaced mode (i.e. --namespace=""), we also
+	// register event handlers and obtain a lister for clusterissuers.
+	if ctx.Namespace == "" {
+		clusterIssuerInformer := ctx.SharedInformerFactory.Certmanager().V1alpha1().ClusterIssuers()
+		mustSync = append(mustSync, clusterIssuerInformer.Informer().HasSynced)
+		c.clusterIssuerLister = clusterIssuerInformer.Lister()
+	}
+
+	// register handler functions
+	routeInformer.Informer().AddEventHandler(&controllerpkg.QueuingEventHandler{Queue: c.queue})
+	certificatesInformer.Informer().AddEventHandler(&controllerpkg.BlockingEventHandler{
+		WorkFunc: controllerpkg.HandleOwnedResourceNamespacedFunc(c.log, c.queue, route.GroupVersionKind, c.routeGetter),
+	})
+	secretsInformer.Informer().AddEventHandler(&controllerpkg.BlockingEventHandler{WorkFunc: c.secretUpdated})
+
+	c.helper = issuer.NewHelper(c.issuerLister, c.clusterIssuerLister)
+	c.dynamicClient = ctx.DynamicClient
+	c.cmClient = ctx.CMClient
+	c.recorder = ctx.Recorder
+	c.defaults = defaults{
+		ctx.DefaultAutoCertificateAnnotations,
+		ctx.DefaultIssuerName,
+		ctx.DefaultIssuerKind,
+	}
+
+	return c.queue, mustSync, []controllerpkg.RunFunc{routeInformer.Informer().Run}, nil
+}
+
+func (c *controller) routeGetter(namespace, name string) (interface{}, error) {
+	return c.routeLister.ByNamespace(namespace).Get(name)
+}
+
+// secretUpdated enqueues the Routes owning any Certificates that store their
+// certificate in the given Secret, so that the Route TLS configuration is
+// updated when a certificate is issued or renewed.
+func (c *controller) secretUpdated(obj interface{}) {
+	secret, ok := obj.(*corev1.Secret)
+	if !ok {
+		runtime.HandleError(fmt.Errorf("Object is not a secret object %#v", obj))
+		return
+	}
+	crts, err := c.certificateLister.Certificates(secret.Namespace).List(labels.Everything())
+	if err != nil {
+		runtime.HandleError(fmt.Errorf("Error listing certificates: %v", err))
+		return
+	}
+	for _, crt := range crts {
+		if crt.Spec.SecretName != secret.Name {
+			continue
+		}
+		ref := metav1.GetControllerOf(crt)
+		if ref == nil || ref.Kind != route.GroupVersionKind.Kind {
+			continue
+		}
+		c.queue.Add(crt.Namespace + "/" + ref.Name)
+	}
+}
+
+func (c *controller) ProcessItem(ctx context.Context, key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		runtime.HandleError(fmt.Errorf("invalid resource key: %s", key))
+		return nil
+	}
+
+	obj, err := c.routeLister.ByNamespace(namespace).Get(name)
+	if err != nil {
+		if k8sErrors.IsNotFound(err) {
+			runtime.HandleError(fmt.Errorf("route '%s' in work queue no longer exists", key))
+			return nil
+		}
+
+		return err
+	}
+
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		runtime.HandleError(fmt.Errorf("Object is not an unstructured object %#v", obj))
+		return nil
+	}
+
+	return c.Sync(ctx, u.DeepCopy())
+}
+
+func init() {
+	controllerpkg.Register(ControllerName, func(ctx *controllerpkg.Context) (controllerpkg.Interface, error) {
+		return controllerpkg.NewBuilder(ctx, ControllerName).
+			For(&controller{}).
+			Complete()
+	})
+}
@@ -0,0 +1,301 @@
+/*
+Copyright 2019 The Jetstack cert-manager contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha1"
+	"github.com/jetstack/cert-manager/pkg/logs"
+	"github.com/jetstack/cert-manager/pkg/metrics"
+	"github.com/jetstack/cert-manager/pkg/util/route"
+)
+
+const (
+	// issuerNameAnnotation can be used to override the issuer specified on the
+	// created Certificate resource.
+	issuerNameAnnotation = "certmanager.k8s.io/issuer"
+	// clusterIssuerNameAnnotation can be used to override the issuer specified on the
+	// created Certificate resource. The Certificate will reference the
+	// specified *ClusterIssuer* instead of normal issuer.
+	clusterIssuerNameAnnotation = "certmanager.k8s.io/cluster-issuer"
+)
+
+func (c *controller) Sync(ctx context.Context, u *unstructured.Unstructured) error {
+	log := logs.WithResource(logs.FromContext(ctx), u)
+	ctx = logs.NewContext(ctx, log)
+
+	metrics.Default.IncrementSyncCallCount(ControllerName)
+
+	rt, err := route.FromUnstructured(u)
+	if err != nil {
+		log.Error(err, "failed to decode route resource")
+		return nil
+	}
+
+	if !shouldSync(rt, c.defaults.autoCertificateAnnotations) {
+		log.Info(fmt.Sprintf("not syncing route resource as it does not contain a %q or %q annotation", issuerNameAnnotation, clusterIssuerNameAnnotation))
+		return nil
+	}
+
+	issuerName, issuerKind := c.issuerForRoute(rt)
+	if issuerName == "" {
+		log.Info("failed to determine issuer to be used for route resource")
+		c.recorder.Eventf(u, corev1.EventTypeWarning, "BadConfig", "Issuer name annotation is not set and a default issuer has not been configured")
+		return nil
+	}
+
+	issuer, err := c.helper.GetGenericIssuer(v1alpha1.ObjectReference{
+		Name: issuerName,
+		Kind: issuerKind,
+	}, rt.Namespace)
+	if apierrors.IsNotFound(err) {
+		c.recorder.Eventf(u, corev1.EventTypeWarning, "BadConfig", "%s resource %q not found", issuerKind, issuerName)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if rt.Spec.Host == "" {
+		c.recorder.Eventf(u, corev1.EventTypeWarning, "BadConfig", "Route does not have a host set")
+		return nil
+	}
+
+	if rt.Spec.TLS != nil && rt.Spec.TLS.Termination == route.TLSTerminationPassthrough {
+		c.recorder.Eventf(u, corev1.EventTypeWarning, "Unsupported", "A certificate cannot be configured on a Route using %s TLS termination", route.TLSTerminationPassthrough)
+		return nil
+	}
+
+	crt, err := c.ensureCertificate(ctx, u, rt, issuer, issuerKind)
+	if err != nil || crt == nil {
+		return err
+	}
+
+	return c.ensureRouteTLS(ctx, u, rt, crt)
+}
+
+// ensureCertificate creates or updates the Certificate for the given Route.
+// It returns nil if a Certificate with the same name exists but is not
+// owned by the Route.
+func (c *controller) ensureCertificate(ctx context.Context, u *unstructured.Unstructured, rt *route.Route, issuer v1alpha1.GenericIssuer, issuerKind string) (*v1alpha1.Certificate, error) {
+	log := logs.FromContext(ctx)
+
+	crt := buildCertificate(rt, issuer, issuerKind)
+
+	existingCrt, err := c.certificateLister.Certificates(rt.Namespace).Get(crt.Name)
+	if apierrors.IsNotFound(err) {
+		crt, err = c.cmClient.CertmanagerV1alpha1().Certificates(crt.Namespace).Create(crt)
+		if err != nil {
+			return nil, err
+		}
+		c.recorder.Eventf(u, corev1.EventTypeNormal, "CreateCertificate", "Successfully created Certificate %q", crt.Name)
+		return crt, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	log = logs.WithRelatedResource(log, existingCrt)
+	if !metav1.IsControlledBy(existingCrt, rt) {
+		log.Info("certificate resource is not owned by this route. refusing to update non-owned certificate resource for route")
+		return nil, nil
+	}
+
+	if !certNeedsUpdate(existingCrt, crt) {
+		log.V(logs.DebugLevel).Info("certificate resource is already up to date for route")
+		return existingCrt, nil
+	}
+
+	updateCrt := existingCrt.DeepCopy()
+	updateCrt.Labels = crt.Labels
+	updateCrt.Spec.DNSNames = crt.Spec.DNSNames
+	updateCrt.Spec.SecretName = crt.Spec.SecretName
+	updateCrt.Spec.IssuerRef = crt.Spec.IssuerRef
+	updateCrt, err = c.cmClient.CertmanagerV1alpha1().Certificates(updateCrt.Namespace).Update(updateCrt)
+	if err != nil {
+		return nil, err
+	}
+	c.recorder.Eventf(u, corev1.EventTypeNormal, "UpdateCertificate", "Successfully updated Certificate %q", updateCrt.Name)
+	return updateCrt, nil
+}
+
+// ensureRouteTLS copies the certificate, private key and CA stored in the
+// Certificate's Secret into the TLS configuration of the Route. The Route
+// is left unchanged until the Secret contains a certificate and key.
+func (c *controller) ensureRouteTLS(ctx context.Context, u *unstructured.Unstructured, rt *route.Route, crt *v1alpha1.Certificate) error {
+	log := logs.WithRelatedResourceName(logs.FromContext(ctx), crt.Spec.SecretName, crt.Namespace, "Secret")
+
+	secret, err := c.secretLister.Secrets(crt.Namespace).Get(crt.Spec.SecretName)
+	if apierrors.IsNotFound(err) {
+		log.V(logs.DebugLevel).Info("waiting for certificate to be issued")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	certPEM := string(secret.Data[corev1.TLSCertKey])
+	keyPEM := string(secret.Data[corev1.TLSPrivateKeyKey])
+	caPEM := string(secret.Data[v1alpha1.TLSCAKey])
+	if certPEM == "" || keyPEM == "" {
+		log.V(logs.DebugLevel).Info("waiting for certificate to be issued")
+		return nil
+	}
+
+	termination := route.TLSTerminationEdge
+	if tls := rt.Spec.TLS; tls != nil {
+		if tls.Certificate == certPEM && tls.Key == keyPEM && tls.CACertificate == caPEM {
+			log.V(logs.DebugLevel).Info("route TLS configuration is already up to date")
+			return nil
+		}
+		if tls.Termination != "" {
+			termination = tls.Termination
+		}
+	}
+
+	// only the certificate fields are written to the unstructured object, so
+	// that any other Route fields are preserved as they are
+	fields := map[string]string{
+		"termination":   string(termination),
+		"certificate":   certPEM,
+		"key":           keyPEM,
+		"caCertificate": caPEM,
+	}
+	for name, value := range fields {
+		if value == "" {
+			unstructured.RemoveNestedField(u.Object, "spec", "tls", name)
+			continue
+		}
+		if err := unstructured.SetNestedField(u.Object, value, "spec", "tls", name); err != nil {
+			return err
+		}
+	}
+
+	_, err = c.dynamicClient.Resource(route.GroupVersionResource).Namespace(u.GetNamespace()).Update(u, metav1.UpdateOptions{})
+	if err != nil {
+		return err
+	}
+	c.recorder.Eventf(u, corev1.EventTypeNormal, "UpdateRoute", "Successfully updated Route TLS configuration from Secret %q", secret.Name)
+
+	return nil
+}
+
+func buildCertificate(rt *route.Route, issuer v1alpha1.GenericIssuer, issuerKind string) *v1alpha1.Certificate {
+	name := certificateName(rt)
+	return &v1alpha1.Certificate{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       rt.Namespace,
+			Labels:          rt.Labels,
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(rt, route.GroupVersionKind)},
+		},
+		Spec: v1alpha1.CertificateSpec{
+			DNSNames:   []string{rt.Spec.Host},
+			SecretName: name,
+			IssuerRef: v1alpha1.ObjectReference{
+				Name: issuer.GetObjectMeta().Name,
+				Kind: issuerKind,
+			},
+		},
+	}
+}
+
+// certificateName returns the name of the Certificate and Secret created for
+// the given Route.
+func certificateName(rt *route.Route) string {
+	return rt.Name + "-tls"
+}
+
+// certNeedsUpdate checks and returns true if two Certificates differ
+func certNeedsUpdate(a, b *v1alpha1.Certificate) bool {
+	// TODO: we may need to allow users to edit the managed Certificate resources
+	// to add their own labels directly.
+	if !reflect.DeepEqual(a.Labels, b.Labels) {
+		return true
+	}
+
+	if !reflect.DeepEqual(a.Spec.DNSNames, b.Spec.DNSNames) {
+		return true
+	}
+
+	if a.Spec.SecretName != b.Spec.SecretName {
+		return true
+	}
+
+	if a.Spec.IssuerRef.Name != b.Spec.IssuerRef.Name {
+		return true
+	}
+
+	if a.Spec.IssuerRef.Kind != b.Spec.IssuerRef.Kind {
+		return true
+	}
+
+	return false
+}
+
+// shouldSync returns true if this route should have a Certificate resource
+// created for it
+func shouldSync(rt *route.Route, autoCertificateAnnotations []string) bool {
+	annotations := rt.Annotations
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	if _, ok := annotations[issuerNameAnnotation]; ok {
+		return true
+	}
+	if _, ok := annotations[clusterIssuerNameAnnotation]; ok {
+		return true
+	}
+	for _, x := range autoCertificateAnnotations {
+		if s, ok := annotations[x]; ok {
+			if b, _ := strconv.ParseBool(s); b {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// issuerForRoute will determine the issuer that should be specified on a
+// Certificate created for the given Route resource. If one is not set, the
+// default issuer given to the controller will be used.
+func (c *controller) issuerForRoute(rt *route.Route) (name string, kind string) {
+	name = c.defaults.issuerName
+	kind = c.defaults.issuerKind
+	annotations := rt.Annotations
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	if issuerName, ok := annotations[issuerNameAnnotation]; ok {
+		name = issuerName
+		kind = v1alpha1.IssuerKind
+	}
+	if issuerName, ok := annotations[clusterIssuerNameAnnotation]; ok {
+		name = issuerName
+		kind = v1alpha1.ClusterIssuerKind
+	}
+	return name, kind
+}
@@ -0,0 +1,295 @@
+/*
+Copyright 2019 The Jetstack cert-manager contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	coretesting "k8s.io/client-go/testing"
+
+	"github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha1"
+	testpkg "github.com/jetstack/cert-manager/pkg/controller/test"
+	"github.com/jetstack/cert-manager/pkg/util/route"
+	"github.com/jetstack/cert-manager/test/unit/gen"
+)
+
+const testAcmeTLSAnnotation = "kubernetes.io/tls-acme"
+
+func buildRoute(annotations map[string]string, tls *route.TLSConfig) *route.Route {
+	return &route.Route{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "route-name",
+			Namespace:   gen.DefaultTestNamespace,
+			Labels:      map[string]string{"my-test-label": "should be copied"},
+			Annotations: annotations,
+			UID:         types.UID("route-name"),
+		},
+		Spec: route.RouteSpec{
+			Host: "example.com",
+			To:   route.RouteTargetReference{Kind: "Service", Name: "example"},
+			TLS:  tls,
+		},
+	}
+}
+
+func toUnstructured(t *testing.T, rt *route.Route) *unstructured.Unstructured {
+	u, err := route.ToUnstructured(rt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
+
+func expectedCertificate(rt *route.Route, dnsNames ...string) *v1alpha1.Certificate {
+	return &v1alpha1.Certificate{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "route-name-tls",
+			Namespace:       gen.DefaultTestNamespace,
+			Labels:          map[string]string{"my-test-label": "should be copied"},
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(rt, route.GroupVersionKind)},
+		},
+		Spec: v1alpha1.CertificateSpec{
+			DNSNames:   dnsNames,
+			SecretName: "route-name-tls",
+			IssuerRef: v1alpha1.ObjectReference{
+				Name: "issuer-name",
+				Kind: "Issuer",
+			},
+		},
+	}
+}
+
+func TestShouldSync(t *testing.T) {
+	tests := []struct {
+		Annotations map[string]string
+		ShouldSync  bool
+	}{
+		{
+			Annotations: map[string]string{issuerNameAnnotation: ""},
+			ShouldSync:  true,
+		},
+		{
+			Annotations: map[string]string{clusterIssuerNameAnnotation: ""},
+			ShouldSync:  true,
+		},
+		{
+			Annotations: map[string]string{testAcmeTLSAnnotation: "true"},
+			ShouldSync:  true,
+		},
+		{
+			Annotations: map[string]string{testAcmeTLSAnnotation: "false"},
+			ShouldSync:  false,
+		},
+		{
+			ShouldSync: false,
+		},
+	}
+	for _, test := range tests {
+		shouldSync := shouldSync(buildRoute(test.Annotations, nil), []string{testAcmeTLSAnnotation})
+		if shouldSync != test.ShouldSync {
+			t.Errorf("Expected shouldSync=%v for annotations %#v", test.ShouldSync, test.Annotations)
+		}
+	}
+}
+
+func TestSync(t *testing.T) {
+	issuer := gen.Issuer("issuer-name", gen.SetIssuerACME(v1alpha1.ACMEIssuer{}))
+	annotations := map[string]string{issuerNameAnnotation: "issuer-name"}
+	rt := buildRoute(annotations, nil)
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "route-name-tls",
+			Namespace: gen.DefaultTestNamespace,
+		},
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       []byte("cert"),
+			corev1.TLSPrivateKeyKey: []byte("key"),
+			v1alpha1.TLSCAKey:       []byte("ca"),
+		},
+	}
+
+	// routeWithTLS returns the expected unstructured Route after the TLS
+	// configuration has been written to it.
+	routeWithTLS := func(t *testing.T, rt *route.Route, termination string) *unstructured.Unstructured {
+		u := toUnstructured(t, rt)
+		for name, value := range map[string]string{
+			"termination":   termination,
+			"certificate":   "cert",
+			"key":           "key",
+			"caCertificate": "ca",
+		} {
+			if err := unstructured.SetNestedField(u.Object, value, "spec", "tls", name); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return u
+	}
+
+	tests := map[string]struct {
+		Route              *route.Route
+		KubeObjects        []runtime.Object
+		CertManagerObjects []runtime.Object
+		ExpectedActions    func(t *testing.T) []testpkg.Action
+		ExpectedEvents     []string
+	}{
+		"should not sync a route without annotations": {
+			Route: buildRoute(nil, nil),
+		},
+		"should create a Certificate for an annotated route": {
+			Route: rt,
+			ExpectedActions: func(t *testing.T) []testpkg.Action {
+				return []testpkg.Action{
+					testpkg.NewAction(coretesting.NewCreateAction(
+						v1alpha1.SchemeGroupVersion.WithResource("certificates"),
+						gen.DefaultTestNamespace,
+						expectedCertificate(rt, "example.com"),
+					)),
+				}
+			},
+			ExpectedEvents: []string{`Normal CreateCertificate Successfully created Certificate "route-name-tls"`},
+		},
+		"should update a Certificate if the route host has changed": {
+			Route:              rt,
+			CertManagerObjects: []runtime.Object{expectedCertificate(rt, "old.example.com")},
+			ExpectedActions: func(t *testing.T) []testpkg.Action {
+				return []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateAction(
+						v1alpha1.SchemeGroupVersion.WithResource("certificates"),
+						gen.DefaultTestNamespace,
+						expectedCertificate(rt, "example.com"),
+					)),
+				}
+			},
+			ExpectedEvents: []string{`Normal UpdateCertificate Successfully updated Certificate "route-name-tls"`},
+		},
+		"should not update a Certificate that is not owned by the route": {
+			Route:              rt,
+			KubeObjects:        []runtime.Object{secret},
+			CertManagerObjects: []runtime.Object{gen.Certificate("route-name-tls", gen.SetCertificateNamespace(gen.DefaultTestNamespace))},
+		},
+		"should set the TLS configuration on the route once the certificate is issued": {
+			Route:              rt,
+			KubeObjects:        []runtime.Object{secret},
+			CertManagerObjects: []runtime.Object{expectedCertificate(rt, "example.com")},
+			ExpectedActions: func(t *testing.T) []testpkg.Action {
+				return []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateAction(
+						route.GroupVersionResource,
+						gen.DefaultTestNamespace,
+						routeWithTLS(t, rt, "edge"),
+					)),
+				}
+			},
+			ExpectedEvents: []string{`Normal UpdateRoute Successfully updated Route TLS configuration from Secret "route-name-tls"`},
+		},
+		"should preserve the existing TLS termination of the route": {
+			Route:              buildRoute(annotations, &route.TLSConfig{Termination: route.TLSTerminationReencrypt}),
+			KubeObjects:        []runtime.Object{secret},
+			CertManagerObjects: []runtime.Object{expectedCertificate(rt, "example.com")},
+			ExpectedActions: func(t *testing.T) []testpkg.Action {
+				return []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateAction(
+						route.GroupVersionResource,
+						gen.DefaultTestNamespace,
+						routeWithTLS(t, rt, "reencrypt"),
+					)),
+				}
+			},
+			ExpectedEvents: []string{`Normal UpdateRoute Successfully updated Route TLS configuration from Secret "route-name-tls"`},
+		},
+		"should not update a route that is already up to date": {
+			Route: buildRoute(annotations, &route.TLSConfig{
+				Termination:   route.TLSTerminationEdge,
+				Certificate:   "cert",
+				Key:           "key",
+				CACertificate: "ca",
+			}),
+			KubeObjects:        []runtime.Object{secret},
+			CertManagerObjects: []runtime.Object{expectedCertificate(rt, "example.com")},
+		},
+		"should not update a route until the certificate has been issued": {
+			Route:              rt,
+			CertManagerObjects: []runtime.Object{expectedCertificate(rt, "example.com")},
+		},
+		"should not sync a route using passthrough termination": {
+			Route:          buildRoute(annotations, &route.TLSConfig{Termination: route.TLSTerminationPassthrough}),
+			ExpectedEvents: []string{`Warning Unsupported A certificate cannot be configured on a Route using passthrough TLS termination`},
+		},
+		"should not sync a route without a host": {
+			Route: func() *route.Route {
+				rt := buildRoute(annotations, nil)
+				rt.Spec.Host = ""
+				return rt
+			}(),
+			ExpectedEvents: []string{`Warning BadConfig Route does not have a host set`},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			u := toUnstructured(t, test.Route)
+			b := &testpkg.Builder{
+				T:                  t,
+				KubeObjects:        test.KubeObjects,
+				CertManagerObjects: test.CertManagerObjects,
+				DynamicObjects:     []runtime.Object{u.DeepCopy()},
+				ExpectedEvents:     test.ExpectedEvents,
+			}
+			if test.ExpectedActions != nil {
+				b.ExpectedActions = test.ExpectedActions(t)
+			}
+			b.Init()
+			c := &controller{
+				dynamicClient:     b.DynamicClient,
+				cmClient:          b.CMClient,
+				recorder:          b.Recorder,
+				certificateLister: b.SharedInformerFactory.Certmanager().V1alpha1().Certificates().Lister(),
+				secretLister:      b.KubeSharedInformerFactory.Core().V1().Secrets().Lister(),
+				defaults: defaults{
+					autoCertificateAnnotations: []string{testAcmeTLSAnnotation},
+				},
+				helper: &fakeHelper{issuer: issuer},
+			}
+			b.Start()
+
+			err := c.Sync(context.Background(), u)
+			if err != nil {
+				t.Errorf("Expected no error, but got: %s", err)
+			}
+
+			b.CheckAndFinish()
+		})
+	}
+}
+
+type fakeHelper struct {
+	issuer v1alpha1.GenericIssuer
+}
+
+func (f *fakeHelper) GetGenericIssuer(ref v1alpha1.ObjectReference, ns string) (v1alpha1.GenericIssuer, error) {
+	if f.issuer == nil {
+		return nil, fmt.Errorf("no issuer specified on fake helper")
+	}
+	return f.issuer, nil
+}
@@ -31,8 +31,12 @@ import (
 	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
 	kubeinformers "k8s.io/client-go/informers"
 	kubefake "k8s.io/client-go/kubernetes/fake"
 	coretesting "k8s.io/client-go/testing"
@@ -46,6 +50,7 @@ import (
 	"github.com/jetstack/cert-manager/pkg/controller"
 	"github.com/jetstack/cert-manager/pkg/logs"
 	"github.com/jetstack/cert-manager/pkg/util"
+	"github.com/jetstack/cert-manager/pkg/util/ingress"
 )
 
 func init() {
@@ -55,14 +60,16 @@ func init() {
 }
 
 // Builder is a structure used to construct new Contexts for use during tests.
-// Currently, only KubeObjects and CertManagerObjects can be specified.
-// These will be auto loaded into the constructed fake Clientsets.
+// Currently, only KubeObjects, CertManagerObjects and DynamicObjects can be
+// specified. These will be auto loaded into the constructed fake Clientsets.
+// DynamicObjects must be *unstructured.Unstructured objects.
 // Call ToContext() to construct a new context using the given values.
 type Builder struct {
 	T *testing.T
 
 	KubeObjects        []runtime.Object
 	CertManagerObjects []runtime.Object
+	DynamicObjects     []runtime.Object
 	ExpectedActions    []Action
 	ExpectedEvents     []string
 	StringGenerator    StringGenerator
@@ -119,12 +126,18 @@ func (b *Builder) Init() {
 	b.requiredReactors = make(map[string]bool)
 	b.Client = kubefake.NewSimpleClientset(b.KubeObjects...)
 	b.CMClient = cmfake.NewSimpleClientset(b.CertManagerObjects...)
+	b.DynamicClient = dynamicfake.NewSimpleDynamicClient(dynamicScheme(), b.DynamicObjects...)
 	b.Recorder = new(FakeRecorder)
 
 	b.FakeKubeClient().PrependReactor("create", "*", b.generateNameReactor)
 	b.FakeCMClient().PrependReactor("create", "*", b.generateNameReactor)
+	b.FakeDynamicClient().PrependReactor("create", "*", b.generateNameReactor)
 	b.KubeSharedInformerFactory = kubeinformers.NewSharedInformerFactory(b.Client, informerResyncPeriod)
 	b.SharedInformerFactory = informers.NewSharedInformerFactory(b.CMClient, informerResyncPeriod)
+	b.DynamicSharedInformerFactory = dynamicinformer.NewDynamicSharedInformerFactory(b.DynamicClient, informerResyncPeriod)
+	if b.IngressResource.Empty() {
+		b.IngressResource = ingress.ExtensionsV1beta1
+	}
 	b.stopCh = make(chan struct{})
 
 	// set the Clock on the context
@@ -154,6 +167,19 @@ func (b *Builder) FakeCMInformerFactory() informers.SharedInformerFactory {
 	return b.Context.SharedInformerFactory
 }
 
+func (b *Builder) FakeDynamicClient() *dynamicfake.FakeDynamicClient {
+	return b.Context.DynamicClient.(*dynamicfake.FakeDynamicClient)
+}
+
+// dynamicScheme returns the scheme used by the fake dynamic client. The fake
+// client lists all resources using the same placeholder list kind, which
+// must be registered for lists to succeed.
+func dynamicScheme() *runtime.Scheme {
+	s := runtime.NewScheme()
+	s.AddKnownTypeWithName(schema.GroupVersionKind{Group: "fake-dynamic-client-group", Version: "v1", Kind: "List"}, &unstructured.UnstructuredList{})
+	return s
+}
+
 func (b *Builder) EnsureReactorCalled(testName string, fn coretesting.ReactionFunc) coretesting.ReactionFunc {
 	b.requiredReactors[testName] = false
 	return func(action coretesting.Action) (handled bool, ret runtime.Object, err error) {
@@ -212,6 +238,7 @@ func (b *Builder) AllEventsCalled() error {
 func (b *Builder) AllActionsExecuted() error {
 	firedActions := b.FakeCMClient().Actions()
 	firedActions = append(firedActions, b.FakeKubeClient().Actions()...)
+	firedActions = append(firedActions, b.FakeDynamicClient().Actions()...)
 
 	var unexpectedActions []coretesting.Action
 	var errs []error
@@ -280,6 +307,7 @@ func (b *Builder) Stop() {
 func (b *Builder) Start(additional ...controller.RunFunc) {
 	b.KubeSharedInformerFactory.Start(b.stopCh)
 	b.SharedInformerFactory.Start(b.stopCh)
+	b.DynamicSharedInformerFactory.Start(b.stopCh)
 	for _, fn := range additional {
 		go fn(b.stopCh)
 	}
@@ -294,6 +322,11 @@ func (b *Builder) Sync() {
 	if err := mustAllSync(b.SharedInformerFactory.WaitForCacheSync(b.stopCh)); err != nil {
 		panic("Error waiting for SharedInformerFactory to sync: " + err.Error())
 	}
+	for gvr, synced := range b.DynamicSharedInformerFactory.WaitForCacheSync(b.stopCh) {
+		if !synced {
+			panic(fmt.Sprintf("Error waiting for DynamicSharedInformerFactory to sync: informer for %v not synced", gvr))
+		}
+	}
 	if b.additionalSyncFuncs != nil {
 		cache.WaitForCacheSync(b.stopCh, b.additionalSyncFuncs...)
 	}
@@ -42,8 +42,9 @@ type CertificateList struct {
 type KeyAlgorithm string
 
 const (
-	RSAKeyAlgorithm   KeyAlgorithm = "rsa"
-	ECDSAKeyAlgorithm KeyAlgorithm = "ecdsa"
+	RSAKeyAlgorithm     KeyAlgorithm = "rsa"
+	ECDSAKeyAlgorithm   KeyAlgorithm = "ecdsa"
+	Ed25519KeyAlgorithm KeyAlgorithm = "ed25519"
 )
 
 type KeyEncoding string
@@ -53,9 +54,26 @@ const (
 	PKCS8 KeyEncoding = "pkcs8"
 )
 
+// PrivateKeyRotationPolicy denotes how private keys should be generated or
+// sourced when a Certificate is being issued.
+type PrivateKeyRotationPolicy string
+
+const (
+	// RotationPolicyNever means a private key will only be generated if one
+	// does not already exist in the target Secret resource, or if the
+	// existing key does not match the keyAlgorithm or keySize.
+	RotationPolicyNever PrivateKeyRotationPolicy = "Never"
+
+	// RotationPolicyAlways means a new private key will be generated each
+	// time a certificate is issued. The existing private key is kept in the
+	// target Secret resource until the new certificate has been issued.
+	RotationPolicyAlways PrivateKeyRotationPolicy = "Always"
+)
+
 // CertificateSpec defines the desired state of Certificate
 type CertificateSpec struct {
 	// Full X509 name specification (https://golang.org/pkg/crypto/x509/pkix/#Name).
+	// Not all issuers honour the requested subject: ACME issuers ignore it.
 	// +optional
 	Subject *X509Subject `json:"subject,omitempty"`
 
@@ -91,6 +109,16 @@ type CertificateSpec struct {
 	// +optional
 	IPAddresses []string `json:"ipAddresses,omitempty"`
 
+	// URISANs is a list of URI subject alt names to be used on the Certificate,
+	// for example SPIFFE IDs such as "spiffe://cluster.local/ns/default/sa/app".
+	// +optional
+	URISANs []string `json:"uriSANs,omitempty"`
+
+	// EmailSANs is a list of email subject alt names to be used on the
+	// Certificate.
+	// +optional
+	EmailSANs []string `json:"emailSANs,omitempty"`
+
 	// SecretName is the name of the secret resource to store this secret in
 	SecretName string `json:"secretName"`
 
@@ -121,12 +149,15 @@ type CertificateSpec struct {
 	// KeySize is the key bit size of the corresponding private key for this certificate.
 	// If provided, value must be between 2048 and 8192 inclusive when KeyAlgorithm is
 	// empty or is set to "rsa", and value must be one of (256, 384, 521) when
-	// KeyAlgorithm is set to "ecdsa".
+	// KeyAlgorithm is set to "ecdsa". It must not be provided when KeyAlgorithm
+	// is set to "ed25519", as Ed25519 keys have a fixed size.
 	// +optional
 	KeySize int `json:"keySize,omitempty"`
 
 	// KeyAlgorithm is the private key algorithm of the corresponding private key
-	// for this certificate. If provided, allowed values are either "rsa" or "ecdsa"
+	// for this certificate. If provided, allowed values are "rsa", "ecdsa" or
+	// "ed25519". Ed25519 keys are always encoded as PKCS#8 and cannot be
+	// requested from ACME, Vault or Venafi issuers.
 	// If KeyAlgorithm is specified and KeySize is not provided,
 	// key size of 256 will be used for "ecdsa" key algorithm and
 	// key size of 2048 will be used for "rsa" key algorithm.
@@ -138,8 +169,120 @@ type CertificateSpec struct {
 	// values are "pkcs1" and "pkcs8" standing for PKCS#1 and PKCS#8, respectively.
 	// If KeyEncoding is not specified, then PKCS#1 will be used by default.
 	KeyEncoding KeyEncoding `json:"keyEncoding,omitempty"`
+
+	// RotationPolicy controls how private keys should be regenerated when a
+	// re-issuance is being processed.
+	// If set to Never, a private key will only be generated if one does not
+	// already exist in the target SecretName, and the existing key is reused
+	// on renewal.
+	// If set to Always, a new private key will be generated each time a
+	// certificate is issued, and the existing key is only replaced once the
+	// new certificate has been issued.
+	// If not set, Never will be used.
+	// +optional
+	RotationPolicy PrivateKeyRotationPolicy `json:"rotationPolicy,omitempty"`
+
+	// PodRefresh opts this Certificate in to having workloads restarted or
+	// notified when the certificate stored in SecretName is renewed.
+	// This requires the podrefresh controller to be enabled, which it is by
+	// default only if the controller is started with --enable-pod-refresh.
+	// If not set, workloads consuming SecretName are only restarted if the
+	// controller is started with --enable-pod-refresh.
+	// +optional
+	PodRefresh *PodRefreshConfig `json:"podRefresh,omitempty"`
+
+	// Keystores configures additional keystore output formats to be stored
+	// in the SecretName Secret alongside the PEM encoded data.
+	// +optional
+	Keystores *CertificateKeystores `json:"keystores,omitempty"`
+
+	// RevokeOnDelete will cause the certificate stored in SecretName to be
+	// revoked when this Certificate is deleted. Deletion of the Certificate
+	// is blocked by a finalizer until the certificate has been revoked.
+	// This is currently only supported by ACME issuers.
+	// +optional
+	RevokeOnDelete bool `json:"revokeOnDelete,omitempty"`
+}
+
+// CertificateKeystores configures additional keystore output formats to be
+// stored in a Certificate's Secret.
+type CertificateKeystores struct {
+	// JKS configures a JKS keystore to be stored in the Secret under
+	// 'keystore.jks', along with a 'truststore.jks' containing the CA.
+	// +optional
+	JKS *JKSKeystore `json:"jks,omitempty"`
+
+	// PKCS12 configures a PKCS#12 keystore to be stored in the Secret under
+	// 'keystore.p12', along with a 'truststore.p12' containing the CA.
+	// +optional
+	PKCS12 *PKCS12Keystore `json:"pkcs12,omitempty"`
+}
+
+// JKSKeystore configures a JKS keystore for a Certificate.
+type JKSKeystore struct {
+	// Create enables JKS keystore creation for the Certificate.
+	// The keystore is regenerated whenever the certificate is issued.
+	Create bool `json:"create"`
+
+	// PasswordSecretRef is a reference to a key in a Secret resource in the
+	// Certificate's namespace containing the password used to encrypt the
+	// keystore.
+	PasswordSecretRef SecretKeySelector `json:"passwordSecretRef"`
+}
+
+// PKCS12Keystore configures a PKCS#12 keystore for a Certificate.
+type PKCS12Keystore struct {
+	// Create enables PKCS#12 keystore creation for the Certificate.
+	// The keystore is regenerated whenever the certificate is issued.
+	Create bool `json:"create"`
+
+	// PasswordSecretRef is a reference to a key in a Secret resource in the
+	// Certificate's namespace containing the password used to encrypt the
+	// keystore.
+	PasswordSecretRef SecretKeySelector `json:"passwordSecretRef"`
+}
+
+// PodRefreshConfig selects the workloads to be refreshed when a Certificate
+// is renewed, and how they are refreshed.
+type PodRefreshConfig struct {
+	// Selector selects workloads in the Certificate's namespace by their
+	// labels.
+	// If neither Selector nor Workloads are set, all workloads whose pod
+	// template references SecretName are selected.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// Workloads is an explicit list of workloads in the Certificate's
+	// namespace to be refreshed.
+	// +optional
+	Workloads []WorkloadReference `json:"workloads,omitempty"`
+
+	// Mode controls how selected workloads are refreshed. 'Restart' rolls
+	// the workload by updating a label on its pod template, and 'Notify'
+	// only records an Event against the workload.
+	// Defaults to 'Restart'.
+	// +optional
+	Mode PodRefreshMode `json:"mode,omitempty"`
 }
 
+// WorkloadReference is a reference to a workload in the same namespace as
+// the referrer.
+type WorkloadReference struct {
+	// Kind of the workload, one of Deployment, StatefulSet, DaemonSet,
+	// ReplicaSet or CronJob.
+	Kind string `json:"kind"`
+
+	// Name of the workload.
+	Name string `json:"name"`
+}
+
+type PodRefreshMode string
+
+const (
+	PodRefreshModeRestart PodRefreshMode = "Restart"
+	PodRefreshModeNotify  PodRefreshMode = "Notify"
+)
+
 // X509Subject Full X509 name specification
 type X509Subject struct {
 	// Countries to be used on the Certificate.
@@ -182,6 +325,12 @@ type CertificateStatus struct {
 	// by this resource in spec.secretName.
 	// +optional
 	NotAfter *metav1.Time `json:"notAfter,omitempty"`
+
+	// RevocationSerialNumber is the serial number of the last certificate
+	// stored in spec.secretName that cert-manager attempted to revoke. The
+	// outcome is recorded in the Revoked condition.
+	// +optional
+	RevocationSerialNumber string `json:"revocationSerialNumber,omitempty"`
 }
 
 // CertificateCondition contains condition information for an Certificate.
@@ -219,4 +368,14 @@ const (
 	// - The target secret contains a private key valid for the certificate
 	// - The commonName and dnsNames attributes match those specified on the Certificate
 	CertificateConditionReady CertificateConditionType = "Ready"
+
+	// CertificateConditionRevoked indicates whether the certificate with the
+	// serial number recorded in status.revocationSerialNumber has been
+	// revoked by the issuer.
+	CertificateConditionRevoked CertificateConditionType = "Revoked"
+
+	// CertificateConditionRateLimited indicates that the ACME server has rate
+	// limited requests to issue the certificate. The condition's message
+	// includes the time after which issuance will be retried.
+	CertificateConditionRateLimited CertificateConditionType = "RateLimited"
 )
@@ -109,6 +109,36 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha1.ACMEChallengeSolverHTTP01Route)(nil), (*certmanager.ACMEChallengeSolverHTTP01Route)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_ACMEChallengeSolverHTTP01Route_To_certmanager_ACMEChallengeSolverHTTP01Route(a.(*v1alpha1.ACMEChallengeSolverHTTP01Route), b.(*certmanager.ACMEChallengeSolverHTTP01Route), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*certmanager.ACMEChallengeSolverHTTP01Route)(nil), (*v1alpha1.ACMEChallengeSolverHTTP01Route)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_certmanager_ACMEChallengeSolverHTTP01Route_To_v1alpha1_ACMEChallengeSolverHTTP01Route(a.(*certmanager.ACMEChallengeSolverHTTP01Route), b.(*v1alpha1.ACMEChallengeSolverHTTP01Route), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha1.ACMEChallengeSolverTLSALPN01)(nil), (*certmanager.ACMEChallengeSolverTLSALPN01)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_ACMEChallengeSolverTLSALPN01_To_certmanager_ACMEChallengeSolverTLSALPN01(a.(*v1alpha1.ACMEChallengeSolverTLSALPN01), b.(*certmanager.ACMEChallengeSolverTLSALPN01), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*certmanager.ACMEChallengeSolverTLSALPN01)(nil), (*v1alpha1.ACMEChallengeSolverTLSALPN01)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_certmanager_ACMEChallengeSolverTLSALPN01_To_v1alpha1_ACMEChallengeSolverTLSALPN01(a.(*certmanager.ACMEChallengeSolverTLSALPN01), b.(*v1alpha1.ACMEChallengeSolverTLSALPN01), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha1.ACMEExternalAccountBinding)(nil), (*certmanager.ACMEExternalAccountBinding)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_ACMEExternalAccountBinding_To_certmanager_ACMEExternalAccountBinding(a.(*v1alpha1.ACMEExternalAccountBinding), b.(*certmanager.ACMEExternalAccountBinding), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*certmanager.ACMEExternalAccountBinding)(nil), (*v1alpha1.ACMEExternalAccountBinding)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_certmanager_ACMEExternalAccountBinding_To_v1alpha1_ACMEExternalAccountBinding(a.(*certmanager.ACMEExternalAccountBinding), b.(*v1alpha1.ACMEExternalAccountBinding), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*v1alpha1.ACMEIssuer)(nil), (*certmanager.ACMEIssuer)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_ACMEIssuer_To_certmanager_ACMEIssuer(a.(*v1alpha1.ACMEIssuer), b.(*certmanager.ACMEIssuer), scope)
 	}); err != nil {
@@ -249,6 +279,26 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha1.ACMERateLimit)(nil), (*certmanager.ACMERateLimit)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_ACMERateLimit_To_certmanager_ACMERateLimit(a.(*v1alpha1.ACMERateLimit), b.(*certmanager.ACMERateLimit), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*certmanager.ACMERateLimit)(nil), (*v1alpha1.ACMERateLimit)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_certmanager_ACMERateLimit_To_v1alpha1_ACMERateLimit(a.(*certmanager.ACMERateLimit), b.(*v1alpha1.ACMERateLimit), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha1.CACRL)(nil), (*certmanager.CACRL)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_CACRL_To_certmanager_CACRL(a.(*v1alpha1.CACRL), b.(*certmanager.CACRL), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*certmanager.CACRL)(nil), (*v1alpha1.CACRL)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_certmanager_CACRL_To_v1alpha1_CACRL(a.(*certmanager.CACRL), b.(*v1alpha1.CACRL), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*v1alpha1.CAIssuer)(nil), (*certmanager.CAIssuer)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_CAIssuer_To_certmanager_CAIssuer(a.(*v1alpha1.CAIssuer), b.(*certmanager.CAIssuer), scope)
 	}); err != nil {
@@ -289,6 +339,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha1.CertificateKeystores)(nil), (*certmanager.CertificateKeystores)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_CertificateKeystores_To_certmanager_CertificateKeystores(a.(*v1alpha1.CertificateKeystores), b.(*certmanager.CertificateKeystores), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*certmanager.CertificateKeystores)(nil), (*v1alpha1.CertificateKeystores)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_certmanager_CertificateKeystores_To_v1alpha1_CertificateKeystores(a.(*certmanager.CertificateKeystores), b.(*v1alpha1.CertificateKeystores), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*v1alpha1.CertificateList)(nil), (*certmanager.CertificateList)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_CertificateList_To_certmanager_CertificateList(a.(*v1alpha1.CertificateList), b.(*certmanager.CertificateList), scope)
 	}); err != nil {
@@ -519,6 +579,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha1.JKSKeystore)(nil), (*certmanager.JKSKeystore)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_JKSKeystore_To_certmanager_JKSKeystore(a.(*v1alpha1.JKSKeystore), b.(*certmanager.JKSKeystore), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*certmanager.JKSKeystore)(nil), (*v1alpha1.JKSKeystore)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_certmanager_JKSKeystore_To_v1alpha1_JKSKeystore(a.(*certmanager.JKSKeystore), b.(*v1alpha1.JKSKeystore), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*v1alpha1.LocalObjectReference)(nil), (*certmanager.LocalObjectReference)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_LocalObjectReference_To_certmanager_LocalObjectReference(a.(*v1alpha1.LocalObjectReference), b.(*certmanager.LocalObjectReference), scope)
 	}); err != nil {
@@ -579,6 +649,26 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha1.PKCS12Keystore)(nil), (*certmanager.PKCS12Keystore)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_PKCS12Keystore_To_certmanager_PKCS12Keystore(a.(*v1alpha1.PKCS12Keystore), b.(*certmanager.PKCS12Keystore), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*certmanager.PKCS12Keystore)(nil), (*v1alpha1.PKCS12Keystore)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_certmanager_PKCS12Keystore_To_v1alpha1_PKCS12Keystore(a.(*certmanager.PKCS12Keystore), b.(*v1alpha1.PKCS12Keystore), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha1.PodRefreshConfig)(nil), (*certmanager.PodRefreshConfig)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_PodRefreshConfig_To_certmanager_PodRefreshConfig(a.(*v1alpha1.PodRefreshConfig), b.(*certmanager.PodRefreshConfig), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*certmanager.PodRefreshConfig)(nil), (*v1alpha1.PodRefreshConfig)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_certmanager_PodRefreshConfig_To_v1alpha1_PodRefreshConfig(a.(*certmanager.PodRefreshConfig), b.(*v1alpha1.PodRefreshConfig), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*v1alpha1.SecretKeySelector)(nil), (*certmanager.SecretKeySelector)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_SecretKeySelector_To_certmanager_SecretKeySelector(a.(*v1alpha1.SecretKeySelector), b.(*certmanager.SecretKeySelector), scope)
 	}); err != nil {
@@ -629,6 +719,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha1.VaultClientCertificateAuth)(nil), (*certmanager.VaultClientCertificateAuth)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_VaultClientCertificateAuth_To_certmanager_VaultClientCertificateAuth(a.(*v1alpha1.VaultClientCertificateAuth), b.(*certmanager.VaultClientCertificateAuth), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*certmanager.VaultClientCertificateAuth)(nil), (*v1alpha1.VaultClientCertificateAuth)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_certmanager_VaultClientCertificateAuth_To_v1alpha1_VaultClientCertificateAuth(a.(*certmanager.VaultClientCertificateAuth), b.(*v1alpha1.VaultClientCertificateAuth), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*v1alpha1.VaultIssuer)(nil), (*certmanager.VaultIssuer)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_VaultIssuer_To_certmanager_VaultIssuer(a.(*v1alpha1.VaultIssuer), b.(*certmanager.VaultIssuer), scope)
 	}); err != nil {
@@ -639,6 +739,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha1.VaultKubernetesAuth)(nil), (*certmanager.VaultKubernetesAuth)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_VaultKubernetesAuth_To_certmanager_VaultKubernetesAuth(a.(*v1alpha1.VaultKubernetesAuth), b.(*certmanager.VaultKubernetesAuth), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*certmanager.VaultKubernetesAuth)(nil), (*v1alpha1.VaultKubernetesAuth)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_certmanager_VaultKubernetesAuth_To_v1alpha1_VaultKubernetesAuth(a.(*certmanager.VaultKubernetesAuth), b.(*v1alpha1.VaultKubernetesAuth), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*v1alpha1.VenafiCloud)(nil), (*certmanager.VenafiCloud)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_VenafiCloud_To_certmanager_VenafiCloud(a.(*v1alpha1.VenafiCloud), b.(*certmanager.VenafiCloud), scope)
 	}); err != nil {
@@ -669,6 +779,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha1.WorkloadReference)(nil), (*certmanager.WorkloadReference)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_WorkloadReference_To_certmanager_WorkloadReference(a.(*v1alpha1.WorkloadReference), b.(*certmanager.WorkloadReference), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*certmanager.WorkloadReference)(nil), (*v1alpha1.WorkloadReference)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_certmanager_WorkloadReference_To_v1alpha1_WorkloadReference(a.(*certmanager.WorkloadReference), b.(*v1alpha1.WorkloadReference), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*v1alpha1.X509Subject)(nil), (*certmanager.X509Subject)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_X509Subject_To_certmanager_X509Subject(a.(*v1alpha1.X509Subject), b.(*certmanager.X509Subject), scope)
 	}); err != nil {
@@ -706,6 +826,7 @@ func autoConvert_v1alpha1_ACMEChallengeSolver_To_certmanager_ACMEChallengeSolver
 	out.Selector = (*certmanager.CertificateDNSNameSelector)(unsafe.Pointer(in.Selector))
 	out.HTTP01 = (*certmanager.ACMEChallengeSolverHTTP01)(unsafe.Pointer(in.HTTP01))
 	out.DNS01 = (*certmanager.ACMEChallengeSolverDNS01)(unsafe.Pointer(in.DNS01))
+	out.TLSALPN01 = (*certmanager.ACMEChallengeSolverTLSALPN01)(unsafe.Pointer(in.TLSALPN01))
 	return nil
 }
 
@@ -718,6 +839,7 @@ func autoConvert_certmanager_ACMEChallengeSolver_To_v1alpha1_ACMEChallengeSolver
 	out.Selector = (*v1alpha1.CertificateDNSNameSelector)(unsafe.Pointer(in.Selector))
 	out.HTTP01 = (*v1alpha1.ACMEChallengeSolverHTTP01)(unsafe.Pointer(in.HTTP01))
 	out.DNS01 = (*v1alpha1.ACMEChallengeSolverDNS01)(unsafe.Pointer(in.DNS01))
+	out.TLSALPN01 = (*v1alpha1.ACMEChallengeSolverTLSALPN01)(unsafe.Pointer(in.TLSALPN01))
 	return nil
 }
 
@@ -766,6 +888,7 @@ func Convert_certmanager_ACMEChallengeSolverDNS01_To_v1alpha1_ACMEChallengeSolve
 
 func autoConvert_v1alpha1_ACMEChallengeSolverHTTP01_To_certmanager_ACMEChallengeSolverHTTP01(in *v1alpha1.ACMEChallengeSolverHTTP01, out *certmanager.ACMEChallengeSolverHTTP01, s conversion.Scope) error {
 	out.Ingress = (*certmanager.ACMEChallengeSolverHTTP01Ingress)(unsafe.Pointer(in.Ingress))
+	out.Route = (*certmanager.ACMEChallengeSolverHTTP01Route)(unsafe.Pointer(in.Route))
 	return nil
 }
 
@@ -776,6 +899,7 @@ func Convert_v1alpha1_ACMEChallengeSolverHTTP01_To_certmanager_ACMEChallengeSolv
 
 func autoConvert_certmanager_ACMEChallengeSolverHTTP01_To_v1alpha1_ACMEChallengeSolverHTTP01(in *certmanager.ACMEChallengeSolverHTTP01, out *v1alpha1.ACMEChallengeSolverHTTP01, s conversion.Scope) error {
 	out.Ingress = (*v1alpha1.ACMEChallengeSolverHTTP01Ingress)(unsafe.Pointer(in.Ingress))
+	out.Route = (*v1alpha1.ACMEChallengeSolverHTTP01Route)(unsafe.Pointer(in.Route))
 	return nil
 }
 
@@ -787,6 +911,7 @@ func Convert_certmanager_ACMEChallengeSolverHTTP01_To_v1alpha1_ACMEChallengeSolv
 func autoConvert_v1alpha1_ACMEChallengeSolverHTTP01Ingress_To_certmanager_ACMEChallengeSolverHTTP01Ingress(in *v1alpha1.ACMEChallengeSolverHTTP01Ingress, out *certmanager.ACMEChallengeSolverHTTP01Ingress, s conversion.Scope) error {
 	out.ServiceType = v1.ServiceType(in.ServiceType)
 	out.Class = (*string)(unsafe.Pointer(in.Class))
+	out.IngressClassName = (*string)(unsafe.Pointer(in.IngressClassName))
 	out.Name = in.Name
 	out.PodTemplate = (*certmanager.ACMEChallengeSolverHTTP01IngressPodTemplate)(unsafe.Pointer(in.PodTemplate))
 	return nil
@@ -800,6 +925,7 @@ func Convert_v1alpha1_ACMEChallengeSolverHTTP01Ingress_To_certmanager_ACMEChalle
 func autoConvert_certmanager_ACMEChallengeSolverHTTP01Ingress_To_v1alpha1_ACMEChallengeSolverHTTP01Ingress(in *certmanager.ACMEChallengeSolverHTTP01Ingress, out *v1alpha1.ACMEChallengeSolverHTTP01Ingress, s conversion.Scope) error {
 	out.ServiceType = v1.ServiceType(in.ServiceType)
 	out.Class = (*string)(unsafe.Pointer(in.Class))
+	out.IngressClassName = (*string)(unsafe.Pointer(in.IngressClassName))
 	out.Name = in.Name
 	out.PodTemplate = (*v1alpha1.ACMEChallengeSolverHTTP01IngressPodTemplate)(unsafe.Pointer(in.PodTemplate))
 	return nil
@@ -860,13 +986,91 @@ func Convert_certmanager_ACMEChallengeSolverHTTP01IngressPodTemplate_To_v1alpha1
 	return autoConvert_certmanager_ACMEChallengeSolverHTTP01IngressPodTemplate_To_v1alpha1_ACMEChallengeSolverHTTP01IngressPodTemplate(in, out, s)
 }
 
+func autoConvert_v1alpha1_ACMEChallengeSolverHTTP01Route_To_certmanager_ACMEChallengeSolverHTTP01Route(in *v1alpha1.ACMEChallengeSolverHTTP01Route, out *certmanager.ACMEChallengeSolverHTTP01Route, s conversion.Scope) error {
+	out.ServiceType = v1.ServiceType(in.ServiceType)
+	out.Labels = *(*map[string]string)(unsafe.Pointer(&in.Labels))
+	out.PodTemplate = (*certmanager.ACMEChallengeSolverHTTP01IngressPodTemplate)(unsafe.Pointer(in.PodTemplate))
+	return nil
+}
+
+// Convert_v1alpha1_ACMEChallengeSolverHTTP01Route_To_certmanager_ACMEChallengeSolverHTTP01Route is an autogenerated conversion function.
+func Convert_v1alpha1_ACMEChallengeSolverHTTP01Route_To_certmanager_ACMEChallengeSolverHTTP01Route(in *v1alpha1.ACMEChallengeSolverHTTP01Route, out *certmanager.ACMEChallengeSolverHTTP01Route, s conversion.Scope) error {
+	return autoConvert_v1alpha1_ACMEChallengeSolverHTTP01Route_To_certmanager_ACMEChallengeSolverHTTP01Route(in, out, s)
+}
+
+func autoConvert_certmanager_ACMEChallengeSolverHTTP01Route_To_v1alpha1_ACMEChallengeSolverHTTP01Route(in *certmanager.ACMEChallengeSolverHTTP01Route, out *v1alpha1.ACMEChallengeSolverHTTP01Route, s conversion.Scope) error {
+	out.ServiceType = v1.ServiceType(in.ServiceType)
+	out.Labels = *(*map[string]string)(unsafe.Pointer(&in.Labels))
+	out.PodTemplate = (*v1alpha1.ACMEChallengeSolverHTTP01IngressPodTemplate)(unsafe.Pointer(in.PodTemplate))
+	return nil
+}
+
+// Convert_certmanager_ACMEChallengeSolverHTTP01Route_To_v1alpha1_ACMEChallengeSolverHTTP01Route is an autogenerated conversion function.
+func Convert_certmanager_ACMEChallengeSolverHTTP01Route_To_v1alpha1_ACMEChallengeSolverHTTP01Route(in *certmanager.ACMEChallengeSolverHTTP01Route, out *v1alpha1.ACMEChallengeSolverHTTP01Route, s conversion.Scope) error {
+	return autoConvert_certmanager_ACMEChallengeSolverHTTP01Route_To_v1alpha1_ACMEChallengeSolverHTTP01Route(in, out, s)
+}
+
+func autoConvert_v1alpha1_ACMEChallengeSolverTLSALPN01_To_certmanager_ACMEChallengeSolverTLSALPN01(in *v1alpha1.ACMEChallengeSolverTLSALPN01, out *certmanager.ACMEChallengeSolverTLSALPN01, s conversion.Scope) error {
+	out.ServiceType = v1.ServiceType(in.ServiceType)
+	out.PodTemplate = (*certmanager.ACMEChallengeSolverHTTP01IngressPodTemplate)(unsafe.Pointer(in.PodTemplate))
+	return nil
+}
+
+// Convert_v1alpha1_ACMEChallengeSolverTLSALPN01_To_certmanager_ACMEChallengeSolverTLSALPN01 is an autogenerated conversion function.
+func Convert_v1alpha1_ACMEChallengeSolverTLSALPN01_To_certmanager_ACMEChallengeSolverTLSALPN01(in *v1alpha1.ACMEChallengeSolverTLSALPN01, out *certmanager.ACMEChallengeSolverTLSALPN01, s conversion.Scope) error {
+	return autoConvert_v1alpha1_ACMEChallengeSolverTLSALPN01_To_certmanager_ACMEChallengeSolverTLSALPN01(in, out, s)
+}
+
+func autoConvert_certmanager_ACMEChallengeSolverTLSALPN01_To_v1alpha1_ACMEChallengeSolverTLSALPN01(in *certmanager.ACMEChallengeSolverTLSALPN01, out *v1alpha1.ACMEChallengeSolverTLSALPN01, s conversion.Scope) error {
+	out.ServiceType = v1.ServiceType(in.ServiceType)
+	out.PodTemplate = (*v1alpha1.ACMEChallengeSolverHTTP01IngressPodTemplate)(unsafe.Pointer(in.PodTemplate))
+	return nil
+}
+
+// Convert_certmanager_ACMEChallengeSolverTLSALPN01_To_v1alpha1_ACMEChallengeSolverTLSALPN01 is an autogenerated conversion function.
+func Convert_certmanager_ACMEChallengeSolverTLSALPN01_To_v1alpha1_ACMEChallengeSolverTLSALPN01(in *certmanager.ACMEChallengeSolverTLSALPN01, out *v1alpha1.ACMEChallengeSolverTLSALPN01, s conversion.Scope) error {
+	return autoConvert_certmanager_ACMEChallengeSolverTLSALPN01_To_v1alpha1_ACMEChallengeSolverTLSALPN01(in, out, s)
+}
+
+func autoConvert_v1alpha1_ACMEExternalAccountBinding_To_certmanager_ACMEExternalAccountBinding(in *v1alpha1.ACMEExternalAccountBinding, out *certmanager.ACMEExternalAccountBinding, s conversion.Scope) error {
+	out.KeyID = in.KeyID
+	if err := Convert_v1alpha1_SecretKeySelector_To_certmanager_SecretKeySelector(&in.Key, &out.Key, s); err != nil {
+		return err
+	}
+	out.KeyAlgorithm = certmanager.HMACKeyAlgorithm(in.KeyAlgorithm)
+	return nil
+}
+
+// Convert_v1alpha1_ACMEExternalAccountBinding_To_certmanager_ACMEExternalAccountBinding is an autogenerated conversion function.
+func Convert_v1alpha1_ACMEExternalAccountBinding_To_certmanager_ACMEExternalAccountBinding(in *v1alpha1.ACMEExternalAccountBinding, out *certmanager.ACMEExternalAccountBinding, s conversion.Scope) error {
+	return autoConvert_v1alpha1_ACMEExternalAccountBinding_To_certmanager_ACMEExternalAccountBinding(in, out, s)
+}
+
+func autoConvert_certmanager_ACMEExternalAccountBinding_To_v1alpha1_ACMEExternalAccountBinding(in *certmanager.ACMEExternalAccountBinding, out *v1alpha1.ACMEExternalAccountBinding, s conversion.Scope) error {
+	out.KeyID = in.KeyID
+	if err := Convert_certmanager_SecretKeySelector_To_v1alpha1_SecretKeySelector(&in.Key, &out.Key, s); err != nil {
+		return err
+	}
+	out.KeyAlgorithm = v1alpha1.HMACKeyAlgorithm(in.KeyAlgorithm)
+	return nil
+}
+
+// Convert_certmanager_ACMEExternalAccountBinding_To_v1alpha1_ACMEExternalAccountBinding is an autogenerated conversion function.
+func Convert_certmanager_ACMEExternalAccountBinding_To_v1alpha1_ACMEExternalAccountBinding(in *certmanager.ACMEExternalAccountBinding, out *v1alpha1.ACMEExternalAccountBinding, s conversion.Scope) error {
+	return autoConvert_certmanager_ACMEExternalAccountBinding_To_v1alpha1_ACMEExternalAccountBinding(in, out, s)
+}
+
 func autoConvert_v1alpha1_ACMEIssuer_To_certmanager_ACMEIssuer(in *v1alpha1.ACMEIssuer, out *certmanager.ACMEIssuer, s conversion.Scope) error {
 	out.Email = in.Email
 	out.Server = in.Server
 	out.SkipTLSVerify = in.SkipTLSVerify
+	out.PreferredChain = in.PreferredChain
 	if err := Convert_v1alpha1_SecretKeySelector_To_certmanager_SecretKeySelector(&in.PrivateKey, &out.PrivateKey, s); err != nil {
 		return err
 	}
+	out.PrivateKeyAlgorithm = certmanager.KeyAlgorithm(in.PrivateKeyAlgorithm)
+	out.PrivateKeySize = in.PrivateKeySize
+	out.ExternalAccountBinding = (*certmanager.ACMEExternalAccountBinding)(unsafe.Pointer(in.ExternalAccountBinding))
 	out.Solvers = *(*[]certmanager.ACMEChallengeSolver)(unsafe.Pointer(&in.Solvers))
 	out.HTTP01 = (*certmanager.ACMEIssuerHTTP01Config)(unsafe.Pointer(in.HTTP01))
 	out.DNS01 = (*certmanager.ACMEIssuerDNS01Config)(unsafe.Pointer(in.DNS01))
@@ -882,9 +1086,13 @@ func autoConvert_certmanager_ACMEIssuer_To_v1alpha1_ACMEIssuer(in *certmanager.A
 	out.Email = in.Email
 	out.Server = in.Server
 	out.SkipTLSVerify = in.SkipTLSVerify
+	out.PreferredChain = in.PreferredChain
 	if err := Convert_certmanager_SecretKeySelector_To_v1alpha1_SecretKeySelector(&in.PrivateKey, &out.PrivateKey, s); err != nil {
 		return err
 	}
+	out.PrivateKeyAlgorithm = v1alpha1.KeyAlgorithm(in.PrivateKeyAlgorithm)
+	out.PrivateKeySize = in.PrivateKeySize
+	out.ExternalAccountBinding = (*v1alpha1.ACMEExternalAccountBinding)(unsafe.Pointer(in.ExternalAccountBinding))
 	out.Solvers = *(*[]v1alpha1.ACMEChallengeSolver)(unsafe.Pointer(&in.Solvers))
 	out.HTTP01 = (*v1alpha1.ACMEIssuerHTTP01Config)(unsafe.Pointer(in.HTTP01))
 	out.DNS01 = (*v1alpha1.ACMEIssuerDNS01Config)(unsafe.Pointer(in.DNS01))
@@ -1241,6 +1449,7 @@ func Convert_certmanager_ACMEIssuerHTTP01Config_To_v1alpha1_ACMEIssuerHTTP01Conf
 func autoConvert_v1alpha1_ACMEIssuerStatus_To_certmanager_ACMEIssuerStatus(in *v1alpha1.ACMEIssuerStatus, out *certmanager.ACMEIssuerStatus, s conversion.Scope) error {
 	out.URI = in.URI
 	out.LastRegisteredEmail = in.LastRegisteredEmail
+	out.RateLimits = *(*[]certmanager.ACMERateLimit)(unsafe.Pointer(&in.RateLimits))
 	return nil
 }
 
@@ -1252,6 +1461,7 @@ func Convert_v1alpha1_ACMEIssuerStatus_To_certmanager_ACMEIssuerStatus(in *v1alp
 func autoConvert_certmanager_ACMEIssuerStatus_To_v1alpha1_ACMEIssuerStatus(in *certmanager.ACMEIssuerStatus, out *v1alpha1.ACMEIssuerStatus, s conversion.Scope) error {
 	out.URI = in.URI
 	out.LastRegisteredEmail = in.LastRegisteredEmail
+	out.RateLimits = *(*[]v1alpha1.ACMERateLimit)(unsafe.Pointer(&in.RateLimits))
 	return nil
 }
 
@@ -1260,8 +1470,60 @@ func Convert_certmanager_ACMEIssuerStatus_To_v1alpha1_ACMEIssuerStatus(in *certm
 	return autoConvert_certmanager_ACMEIssuerStatus_To_v1alpha1_ACMEIssuerStatus(in, out, s)
 }
 
+func autoConvert_v1alpha1_ACMERateLimit_To_certmanager_ACMERateLimit(in *v1alpha1.ACMERateLimit, out *certmanager.ACMERateLimit, s conversion.Scope) error {
+	out.Domain = in.Domain
+	out.RetryAfter = in.RetryAfter
+	return nil
+}
+
+// Convert_v1alpha1_ACMERateLimit_To_certmanager_ACMERateLimit is an autogenerated conversion function.
+func Convert_v1alpha1_ACMERateLimit_To_certmanager_ACMERateLimit(in *v1alpha1.ACMERateLimit, out *certmanager.ACMERateLimit, s conversion.Scope) error {
+	return autoConvert_v1alpha1_ACMERateLimit_To_certmanager_ACMERateLimit(in, out, s)
+}
+
+func autoConvert_certmanager_ACMERateLimit_To_v1alpha1_ACMERateLimit(in *certmanager.ACMERateLimit, out *v1alpha1.ACMERateLimit, s conversion.Scope) error {
+	out.Domain = in.Domain
+	out.RetryAfter = in.RetryAfter
+	return nil
+}
+
+// Convert_certmanager_ACMERateLimit_To_v1alpha1_ACMERateLimit is an autogenerated conversion function.
+func Convert_certmanager_ACMERateLimit_To_v1alpha1_ACMERateLimit(in *certmanager.ACMERateLimit, out *v1alpha1.ACMERateLimit, s conversion.Scope) error {
+	return autoConvert_certmanager_ACMERateLimit_To_v1alpha1_ACMERateLimit(in, out, s)
+}
+
+func autoConvert_v1alpha1_CACRL_To_certmanager_CACRL(in *v1alpha1.CACRL, out *certmanager.CACRL, s conversion.Scope) error {
+	out.SecretName = in.SecretName
+	out.ConfigMapName = in.ConfigMapName
+	out.Duration = (*metav1.Duration)(unsafe.Pointer(in.Duration))
+	out.RenewBefore = (*metav1.Duration)(unsafe.Pointer(in.RenewBefore))
+	return nil
+}
+
+// Convert_v1alpha1_CACRL_To_certmanager_CACRL is an autogenerated conversion function.
+func Convert_v1alpha1_CACRL_To_certmanager_CACRL(in *v1alpha1.CACRL, out *certmanager.CACRL, s conversion.Scope) error {
+	return autoConvert_v1alpha1_CACRL_To_certmanager_CACRL(in, out, s)
+}
+
+func autoConvert_certmanager_CACRL_To_v1alpha1_CACRL(in *certmanager.CACRL, out *v1alpha1.CACRL, s conversion.Scope) error {
+	out.SecretName = in.SecretName
+	out.ConfigMapName = in.ConfigMapName
+	out.Duration = (*metav1.Duration)(unsafe.Pointer(in.Duration))
+	out.RenewBefore = (*metav1.Duration)(unsafe.Pointer(in.RenewBefore))
+	return nil
+}
+
+// Convert_certmanager_CACRL_To_v1alpha1_CACRL is an autogenerated conversion function.
+func Convert_certmanager_CACRL_To_v1alpha1_CACRL(in *certmanager.CACRL, out *v1alpha1.CACRL, s conversion.Scope) error {
+	return autoConvert_certmanager_CACRL_To_v1alpha1_CACRL(in, out, s)
+}
+
 func autoConvert_v1alpha1_CAIssuer_To_certmanager_CAIssuer(in *v1alpha1.CAIssuer, out *certmanager.CAIssuer, s conversion.Scope) error {
 	out.SecretName = in.SecretName
+	out.ChainLayout = certmanager.CAChainLayout(in.ChainLayout)
+	out.CRLDistributionPoints = *(*[]string)(unsafe.Pointer(&in.CRLDistributionPoints))
+	out.OCSPServers = *(*[]string)(unsafe.Pointer(&in.OCSPServers))
+	out.CRL = (*certmanager.CACRL)(unsafe.Pointer(in.CRL))
 	return nil
 }
 
@@ -1272,6 +1534,10 @@ func Convert_v1alpha1_CAIssuer_To_certmanager_CAIssuer(in *v1alpha1.CAIssuer, ou
 
 func autoConvert_certmanager_CAIssuer_To_v1alpha1_CAIssuer(in *certmanager.CAIssuer, out *v1alpha1.CAIssuer, s conversion.Scope) error {
 	out.SecretName = in.SecretName
+	out.ChainLayout = v1alpha1.CAChainLayout(in.ChainLayout)
+	out.CRLDistributionPoints = *(*[]string)(unsafe.Pointer(&in.CRLDistributionPoints))
+	out.OCSPServers = *(*[]string)(unsafe.Pointer(&in.OCSPServers))
+	out.CRL = (*v1alpha1.CACRL)(unsafe.Pointer(in.CRL))
 	return nil
 }
 
@@ -1364,6 +1630,28 @@ func Convert_certmanager_CertificateDNSNameSelector_To_v1alpha1_CertificateDNSNa
 	return autoConvert_certmanager_CertificateDNSNameSelector_To_v1alpha1_CertificateDNSNameSelector(in, out, s)
 }
 
+func autoConvert_v1alpha1_CertificateKeystores_To_certmanager_CertificateKeystores(in *v1alpha1.CertificateKeystores, out *certmanager.CertificateKeystores, s conversion.Scope) error {
+	out.JKS = (*certmanager.JKSKeystore)(unsafe.Pointer(in.JKS))
+	out.PKCS12 = (*certmanager.PKCS12Keystore)(unsafe.Pointer(in.PKCS12))
+	return nil
+}
+
+// Convert_v1alpha1_CertificateKeystores_To_certmanager_CertificateKeystores is an autogenerated conversion function.
+func Convert_v1alpha1_CertificateKeystores_To_certmanager_CertificateKeystores(in *v1alpha1.CertificateKeystores, out *certmanager.CertificateKeystores, s conversion.Scope) error {
+	return autoConvert_v1alpha1_CertificateKeystores_To_certmanager_CertificateKeystores(in, out, s)
+}
+
+func autoConvert_certmanager_CertificateKeystores_To_v1alpha1_CertificateKeystores(in *certmanager.CertificateKeystores, out *v1alpha1.CertificateKeystores, s conversion.Scope) error {
+	out.JKS = (*v1alpha1.JKSKeystore)(unsafe.Pointer(in.JKS))
+	out.PKCS12 = (*v1alpha1.PKCS12Keystore)(unsafe.Pointer(in.PKCS12))
+	return nil
+}
+
+// Convert_certmanager_CertificateKeystores_To_v1alpha1_CertificateKeystores is an autogenerated conversion function.
+func Convert_certmanager_CertificateKeystores_To_v1alpha1_CertificateKeystores(in *certmanager.CertificateKeystores, out *v1alpha1.CertificateKeystores, s conversion.Scope) error {
+	return autoConvert_certmanager_CertificateKeystores_To_v1alpha1_CertificateKeystores(in, out, s)
+}
+
 func autoConvert_v1alpha1_CertificateList_To_certmanager_CertificateList(in *v1alpha1.CertificateList, out *certmanager.CertificateList, s conversion.Scope) error {
 	out.ListMeta = in.ListMeta
 	out.Items = *(*[]certmanager.Certificate)(unsafe.Pointer(&in.Items))
@@ -1534,6 +1822,8 @@ func autoConvert_v1alpha1_CertificateSpec_To_certmanager_CertificateSpec(in *v1a
 	out.RenewBefore = (*metav1.Duration)(unsafe.Pointer(in.RenewBefore))
 	out.DNSNames = *(*[]string)(unsafe.Pointer(&in.DNSNames))
 	out.IPAddresses = *(*[]string)(unsafe.Pointer(&in.IPAddresses))
+	out.URISANs = *(*[]string)(unsafe.Pointer(&in.URISANs))
+	out.EmailSANs = *(*[]string)(unsafe.Pointer(&in.EmailSANs))
 	out.SecretName = in.SecretName
 	if err := Convert_v1alpha1_ObjectReference_To_certmanager_ObjectReference(&in.IssuerRef, &out.IssuerRef, s); err != nil {
 		return err
@@ -1544,6 +1834,10 @@ func autoConvert_v1alpha1_CertificateSpec_To_certmanager_CertificateSpec(in *v1a
 	out.KeySize = in.KeySize
 	out.KeyAlgorithm = certmanager.KeyAlgorithm(in.KeyAlgorithm)
 	out.KeyEncoding = certmanager.KeyEncoding(in.KeyEncoding)
+	out.RotationPolicy = certmanager.PrivateKeyRotationPolicy(in.RotationPolicy)
+	out.PodRefresh = (*certmanager.PodRefreshConfig)(unsafe.Pointer(in.PodRefresh))
+	out.Keystores = (*certmanager.CertificateKeystores)(unsafe.Pointer(in.Keystores))
+	out.RevokeOnDelete = in.RevokeOnDelete
 	return nil
 }
 
@@ -1560,6 +1854,8 @@ func autoConvert_certmanager_CertificateSpec_To_v1alpha1_CertificateSpec(in *cer
 	out.RenewBefore = (*metav1.Duration)(unsafe.Pointer(in.RenewBefore))
 	out.DNSNames = *(*[]string)(unsafe.Pointer(&in.DNSNames))
 	out.IPAddresses = *(*[]string)(unsafe.Pointer(&in.IPAddresses))
+	out.URISANs = *(*[]string)(unsafe.Pointer(&in.URISANs))
+	out.EmailSANs = *(*[]string)(unsafe.Pointer(&in.EmailSANs))
 	out.SecretName = in.SecretName
 	if err := Convert_certmanager_ObjectReference_To_v1alpha1_ObjectReference(&in.IssuerRef, &out.IssuerRef, s); err != nil {
 		return err
@@ -1570,6 +1866,10 @@ func autoConvert_certmanager_CertificateSpec_To_v1alpha1_CertificateSpec(in *cer
 	out.KeySize = in.KeySize
 	out.KeyAlgorithm = v1alpha1.KeyAlgorithm(in.KeyAlgorithm)
 	out.KeyEncoding = v1alpha1.KeyEncoding(in.KeyEncoding)
+	out.RotationPolicy = v1alpha1.PrivateKeyRotationPolicy(in.RotationPolicy)
+	out.PodRefresh = (*v1alpha1.PodRefreshConfig)(unsafe.Pointer(in.PodRefresh))
+	out.Keystores = (*v1alpha1.CertificateKeystores)(unsafe.Pointer(in.Keystores))
+	out.RevokeOnDelete = in.RevokeOnDelete
 	return nil
 }
 
@@ -1582,6 +1882,7 @@ func autoConvert_v1alpha1_CertificateStatus_To_certmanager_CertificateStatus(in
 	out.Conditions = *(*[]certmanager.CertificateCondition)(unsafe.Pointer(&in.Conditions))
 	out.LastFailureTime = (*metav1.Time)(unsafe.Pointer(in.LastFailureTime))
 	out.NotAfter = (*metav1.Time)(unsafe.Pointer(in.NotAfter))
+	out.RevocationSerialNumber = in.RevocationSerialNumber
 	return nil
 }
 
@@ -1594,6 +1895,7 @@ func autoConvert_certmanager_CertificateStatus_To_v1alpha1_CertificateStatus(in
 	out.Conditions = *(*[]v1alpha1.CertificateCondition)(unsafe.Pointer(&in.Conditions))
 	out.LastFailureTime = (*metav1.Time)(unsafe.Pointer(in.LastFailureTime))
 	out.NotAfter = (*metav1.Time)(unsafe.Pointer(in.NotAfter))
+	out.RevocationSerialNumber = in.RevocationSerialNumber
 	return nil
 }
 
@@ -2002,6 +2304,32 @@ func Convert_certmanager_IssuerStatus_To_v1alpha1_IssuerStatus(in *certmanager.I
 	return autoConvert_certmanager_IssuerStatus_To_v1alpha1_IssuerStatus(in, out, s)
 }
 
+func autoConvert_v1alpha1_JKSKeystore_To_certmanager_JKSKeystore(in *v1alpha1.JKSKeystore, out *certmanager.JKSKeystore, s conversion.Scope) error {
+	out.Create = in.Create
+	if err := Convert_v1alpha1_SecretKeySelector_To_certmanager_SecretKeySelector(&in.PasswordSecretRef, &out.PasswordSecretRef, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1alpha1_JKSKeystore_To_certmanager_JKSKeystore is an autogenerated conversion function.
+func Convert_v1alpha1_JKSKeystore_To_certmanager_JKSKeystore(in *v1alpha1.JKSKeystore, out *certmanager.JKSKeystore, s conversion.Scope) error {
+	return autoConvert_v1alpha1_JKSKeystore_To_certmanager_JKSKeystore(in, out, s)
+}
+
+func autoConvert_certmanager_JKSKeystore_To_v1alpha1_JKSKeystore(in *certmanager.JKSKeystore, out *v1alpha1.JKSKeystore, s conversion.Scope) error {
+	out.Create = in.Create
+	if err := Convert_certmanager_SecretKeySelector_To_v1alpha1_SecretKeySelector(&in.PasswordSecretRef, &out.PasswordSecretRef, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_certmanager_JKSKeystore_To_v1alpha1_JKSKeystore is an autogenerated conversion function.
+func Convert_certmanager_JKSKeystore_To_v1alpha1_JKSKeystore(in *certmanager.JKSKeystore, out *v1alpha1.JKSKeystore, s conversion.Scope) error {
+	return autoConvert_certmanager_JKSKeystore_To_v1alpha1_JKSKeystore(in, out, s)
+}
+
 func autoConvert_v1alpha1_LocalObjectReference_To_certmanager_LocalObjectReference(in *v1alpha1.LocalObjectReference, out *certmanager.LocalObjectReference, s conversion.Scope) error {
 	out.Name = in.Name
 	return nil
@@ -2164,6 +2492,56 @@ func Convert_certmanager_OrderStatus_To_v1alpha1_OrderStatus(in *certmanager.Ord
 	return autoConvert_certmanager_OrderStatus_To_v1alpha1_OrderStatus(in, out, s)
 }
 
+func autoConvert_v1alpha1_PKCS12Keystore_To_certmanager_PKCS12Keystore(in *v1alpha1.PKCS12Keystore, out *certmanager.PKCS12Keystore, s conversion.Scope) error {
+	out.Create = in.Create
+	if err := Convert_v1alpha1_SecretKeySelector_To_certmanager_SecretKeySelector(&in.PasswordSecretRef, &out.PasswordSecretRef, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1alpha1_PKCS12Keystore_To_certmanager_PKCS12Keystore is an autogenerated conversion function.
+func Convert_v1alpha1_PKCS12Keystore_To_certmanager_PKCS12Keystore(in *v1alpha1.PKCS12Keystore, out *certmanager.PKCS12Keystore, s conversion.Scope) error {
+	return autoConvert_v1alpha1_PKCS12Keystore_To_certmanager_PKCS12Keystore(in, out, s)
+}
+
+func autoConvert_certmanager_PKCS12Keystore_To_v1alpha1_PKCS12Keystore(in *certmanager.PKCS12Keystore, out *v1alpha1.PKCS12Keystore, s conversion.Scope) error {
+	out.Create = in.Create
+	if err := Convert_certmanager_SecretKeySelector_To_v1alpha1_SecretKeySelector(&in.PasswordSecretRef, &out.PasswordSecretRef, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_certmanager_PKCS12Keystore_To_v1alpha1_PKCS12Keystore is an autogenerated conversion function.
+func Convert_certmanager_PKCS12Keystore_To_v1alpha1_PKCS12Keystore(in *certmanager.PKCS12Keystore, out *v1alpha1.PKCS12Keystore, s conversion.Scope) error {
+	return autoConvert_certmanager_PKCS12Keystore_To_v1alpha1_PKCS12Keystore(in, out, s)
+}
+
+func autoConvert_v1alpha1_PodRefreshConfig_To_certmanager_PodRefreshConfig(in *v1alpha1.PodRefreshConfig, out *certmanager.PodRefreshConfig, s conversion.Scope) error {
+	out.Selector = (*metav1.LabelSelector)(unsafe.Pointer(in.Selector))
+	out.Workloads = *(*[]certmanager.WorkloadReference)(unsafe.Pointer(&in.Workloads))
+	out.Mode = certmanager.PodRefreshMode(in.Mode)
+	return nil
+}
+
+// Convert_v1alpha1_PodRefreshConfig_To_certmanager_PodRefreshConfig is an autogenerated conversion function.
+func Convert_v1alpha1_PodRefreshConfig_To_certmanager_PodRefreshConfig(in *v1alpha1.PodRefreshConfig, out *certmanager.PodRefreshConfig, s conversion.Scope) error {
+	return autoConvert_v1alpha1_PodRefreshConfig_To_certmanager_PodRefreshConfig(in, out, s)
+}
+
+func autoConvert_certmanager_PodRefreshConfig_To_v1alpha1_PodRefreshConfig(in *certmanager.PodRefreshConfig, out *v1alpha1.PodRefreshConfig, s conversion.Scope) error {
+	out.Selector = (*metav1.LabelSelector)(unsafe.Pointer(in.Selector))
+	out.Workloads = *(*[]v1alpha1.WorkloadReference)(unsafe.Pointer(&in.Workloads))
+	out.Mode = v1alpha1.PodRefreshMode(in.Mode)
+	return nil
+}
+
+// Convert_certmanager_PodRefreshConfig_To_v1alpha1_PodRefreshConfig is an autogenerated conversion function.
+func Convert_certmanager_PodRefreshConfig_To_v1alpha1_PodRefreshConfig(in *certmanager.PodRefreshConfig, out *v1alpha1.PodRefreshConfig, s conversion.Scope) error {
+	return autoConvert_certmanager_PodRefreshConfig_To_v1alpha1_PodRefreshConfig(in, out, s)
+}
+
 func autoConvert_v1alpha1_SecretKeySelector_To_certmanager_SecretKeySelector(in *v1alpha1.SecretKeySelector, out *certmanager.SecretKeySelector, s conversion.Scope) error {
 	if err := Convert_v1alpha1_LocalObjectReference_To_certmanager_LocalObjectReference(&in.LocalObjectReference, &out.LocalObjectReference, s); err != nil {
 		return err
@@ -2265,6 +2643,8 @@ func autoConvert_v1alpha1_VaultAuth_To_certmanager_VaultAuth(in *v1alpha1.VaultA
 	if err := Convert_v1alpha1_VaultAppRole_To_certmanager_VaultAppRole(&in.AppRole, &out.AppRole, s); err != nil {
 		return err
 	}
+	out.Kubernetes = (*certmanager.VaultKubernetesAuth)(unsafe.Pointer(in.Kubernetes))
+	out.ClientCertificate = (*certmanager.VaultClientCertificateAuth)(unsafe.Pointer(in.ClientCertificate))
 	return nil
 }
 
@@ -2280,6 +2660,8 @@ func autoConvert_certmanager_VaultAuth_To_v1alpha1_VaultAuth(in *certmanager.Vau
 	if err := Convert_certmanager_VaultAppRole_To_v1alpha1_VaultAppRole(&in.AppRole, &out.AppRole, s); err != nil {
 		return err
 	}
+	out.Kubernetes = (*v1alpha1.VaultKubernetesAuth)(unsafe.Pointer(in.Kubernetes))
+	out.ClientCertificate = (*v1alpha1.VaultClientCertificateAuth)(unsafe.Pointer(in.ClientCertificate))
 	return nil
 }
 
@@ -2288,13 +2670,40 @@ func Convert_certmanager_VaultAuth_To_v1alpha1_VaultAuth(in *certmanager.VaultAu
 	return autoConvert_certmanager_VaultAuth_To_v1alpha1_VaultAuth(in, out, s)
 }
 
+func autoConvert_v1alpha1_VaultClientCertificateAuth_To_certmanager_VaultClientCertificateAuth(in *v1alpha1.VaultClientCertificateAuth, out *certmanager.VaultClientCertificateAuth, s conversion.Scope) error {
+	out.Path = in.Path
+	out.Name = in.Name
+	out.SecretName = in.SecretName
+	return nil
+}
+
+// Convert_v1alpha1_VaultClientCertificateAuth_To_certmanager_VaultClientCertificateAuth is an autogenerated conversion function.
+func Convert_v1alpha1_VaultClientCertificateAuth_To_certmanager_VaultClientCertificateAuth(in *v1alpha1.VaultClientCertificateAuth, out *certmanager.VaultClientCertificateAuth, s conversion.Scope) error {
+	return autoConvert_v1alpha1_VaultClientCertificateAuth_To_certmanager_VaultClientCertificateAuth(in, out, s)
+}
+
+func autoConvert_certmanager_VaultClientCertificateAuth_To_v1alpha1_VaultClientCertificateAuth(in *certmanager.VaultClientCertificateAuth, out *v1alpha1.VaultClientCertificateAuth, s conversion.Scope) error {
+	out.Path = in.Path
+	out.Name = in.Name
+	out.SecretName = in.SecretName
+	return nil
+}
+
+// Convert_certmanager_VaultClientCertificateAuth_To_v1alpha1_VaultClientCertificateAuth is an autogenerated conversion function.
+func Convert_certmanager_VaultClientCertificateAuth_To_v1alpha1_VaultClientCertificateAuth(in *certmanager.VaultClientCertificateAuth, out *v1alpha1.VaultClientCertificateAuth, s conversion.Scope) error {
+	return autoConvert_certmanager_VaultClientCertificateAuth_To_v1alpha1_VaultClientCertificateAuth(in, out, s)
+}
+
 func autoConvert_v1alpha1_VaultIssuer_To_certmanager_VaultIssuer(in *v1alpha1.VaultIssuer, out *certmanager.VaultIssuer, s conversion.Scope) error {
 	if err := Convert_v1alpha1_VaultAuth_To_certmanager_VaultAuth(&in.Auth, &out.Auth, s); err != nil {
 		return err
 	}
 	out.Server = in.Server
 	out.Path = in.Path
+	out.Namespace = in.Namespace
+	out.SignMode = certmanager.VaultSignMode(in.SignMode)
 	out.CABundle = *(*[]byte)(unsafe.Pointer(&in.CABundle))
+	out.CABundleSecretRef = (*certmanager.SecretKeySelector)(unsafe.Pointer(in.CABundleSecretRef))
 	return nil
 }
 
@@ -2309,7 +2718,10 @@ func autoConvert_certmanager_VaultIssuer_To_v1alpha1_VaultIssuer(in *certmanager
 	}
 	out.Server = in.Server
 	out.Path = in.Path
+	out.Namespace = in.Namespace
+	out.SignMode = v1alpha1.VaultSignMode(in.SignMode)
 	out.CABundle = *(*[]byte)(unsafe.Pointer(&in.CABundle))
+	out.CABundleSecretRef = (*v1alpha1.SecretKeySelector)(unsafe.Pointer(in.CABundleSecretRef))
 	return nil
 }
 
@@ -2318,6 +2730,34 @@ func Convert_certmanager_VaultIssuer_To_v1alpha1_VaultIssuer(in *certmanager.Vau
 	return autoConvert_certmanager_VaultIssuer_To_v1alpha1_VaultIssuer(in, out, s)
 }
 
+func autoConvert_v1alpha1_VaultKubernetesAuth_To_certmanager_VaultKubernetesAuth(in *v1alpha1.VaultKubernetesAuth, out *certmanager.VaultKubernetesAuth, s conversion.Scope) error {
+	out.Path = in.Path
+	out.Role = in.Role
+	if err := Convert_v1alpha1_SecretKeySelector_To_certmanager_SecretKeySelector(&in.SecretRef, &out.SecretRef, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1alpha1_VaultKubernetesAuth_To_certmanager_VaultKubernetesAuth is an autogenerated conversion function.
+func Convert_v1alpha1_VaultKubernetesAuth_To_certmanager_VaultKubernetesAuth(in *v1alpha1.VaultKubernetesAuth, out *certmanager.VaultKubernetesAuth, s conversion.Scope) error {
+	return autoConvert_v1alpha1_VaultKubernetesAuth_To_certmanager_VaultKubernetesAuth(in, out, s)
+}
+
+func autoConvert_certmanager_VaultKubernetesAuth_To_v1alpha1_VaultKubernetesAuth(in *certmanager.VaultKubernetesAuth, out *v1alpha1.VaultKubernetesAuth, s conversion.Scope) error {
+	out.Path = in.Path
+	out.Role = in.Role
+	if err := Convert_certmanager_SecretKeySelector_To_v1alpha1_SecretKeySelector(&in.SecretRef, &out.SecretRef, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_certmanager_VaultKubernetesAuth_To_v1alpha1_VaultKubernetesAuth is an autogenerated conversion function.
+func Convert_certmanager_VaultKubernetesAuth_To_v1alpha1_VaultKubernetesAuth(in *certmanager.VaultKubernetesAuth, out *v1alpha1.VaultKubernetesAuth, s conversion.Scope) error {
+	return autoConvert_certmanager_VaultKubernetesAuth_To_v1alpha1_VaultKubernetesAuth(in, out, s)
+}
+
 func autoConvert_v1alpha1_VenafiCloud_To_certmanager_VenafiCloud(in *v1alpha1.VenafiCloud, out *certmanager.VenafiCloud, s conversion.Scope) error {
 	out.URL = in.URL
 	if err := Convert_v1alpha1_SecretKeySelector_To_certmanager_SecretKeySelector(&in.APITokenSecretRef, &out.APITokenSecretRef, s); err != nil {
@@ -2373,6 +2813,7 @@ func autoConvert_v1alpha1_VenafiTPP_To_certmanager_VenafiTPP(in *v1alpha1.Venafi
 	if err := Convert_v1alpha1_LocalObjectReference_To_certmanager_LocalObjectReference(&in.CredentialsRef, &out.CredentialsRef, s); err != nil {
 		return err
 	}
+	out.AccessTokenSecretRef = (*certmanager.LocalObjectReference)(unsafe.Pointer(in.AccessTokenSecretRef))
 	out.CABundle = *(*[]byte)(unsafe.Pointer(&in.CABundle))
 	return nil
 }
@@ -2387,6 +2828,7 @@ func autoConvert_certmanager_VenafiTPP_To_v1alpha1_VenafiTPP(in *certmanager.Ven
 	if err := Convert_certmanager_LocalObjectReference_To_v1alpha1_LocalObjectReference(&in.CredentialsRef, &out.CredentialsRef, s); err != nil {
 		return err
 	}
+	out.AccessTokenSecretRef = (*v1alpha1.LocalObjectReference)(unsafe.Pointer(in.AccessTokenSecretRef))
 	out.CABundle = *(*[]byte)(unsafe.Pointer(&in.CABundle))
 	return nil
 }
@@ -2396,6 +2838,28 @@ func Convert_certmanager_VenafiTPP_To_v1alpha1_VenafiTPP(in *certmanager.VenafiT
 	return autoConvert_certmanager_VenafiTPP_To_v1alpha1_VenafiTPP(in, out, s)
 }
 
+func autoConvert_v1alpha1_WorkloadReference_To_certmanager_WorkloadReference(in *v1alpha1.WorkloadReference, out *certmanager.WorkloadReference, s conversion.Scope) error {
+	out.Kind = in.Kind
+	out.Name = in.Name
+	return nil
+}
+
+// Convert_v1alpha1_WorkloadReference_To_certmanager_WorkloadReference is an autogenerated conversion function.
+func Convert_v1alpha1_WorkloadReference_To_certmanager_WorkloadReference(in *v1alpha1.WorkloadReference, out *certmanager.WorkloadReference, s conversion.Scope) error {
+	return autoConvert_v1alpha1_WorkloadReference_To_certmanager_WorkloadReference(in, out, s)
+}
+
+func autoConvert_certmanager_WorkloadReference_To_v1alpha1_WorkloadReference(in *certmanager.WorkloadReference, out *v1alpha1.WorkloadReference, s conversion.Scope) error {
+	out.Kind = in.Kind
+	out.Name = in.Name
+	return nil
+}
+
+// Convert_certmanager_WorkloadReference_To_v1alpha1_WorkloadReference is an autogenerated conversion function.
+func Convert_certmanager_WorkloadReference_To_v1alpha1_WorkloadReference(in *certmanager.WorkloadReference, out *v1alpha1.WorkloadReference, s conversion.Scope) error {
+	return autoConvert_certmanager_WorkloadReference_To_v1alpha1_WorkloadReference(in, out, s)
+}
+
 func autoConvert_v1alpha1_X509Subject_To_certmanager_X509Subject(in *v1alpha1.X509Subject, out *certmanager.X509Subject, s conversion.Scope) error {
 	out.Countries = *(*[]string)(unsafe.Pointer(&in.Countries))
 	out.OrganizationalUnits = *(*[]string)(unsafe.Pointer(&in.OrganizationalUnits))
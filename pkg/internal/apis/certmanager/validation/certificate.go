@@ -19,7 +19,10 @@ package validation
 import (
 	"fmt"
 	"net"
+	"net/mail"
+	"net/url"
 
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 
@@ -37,8 +40,8 @@ func ValidateCertificateSpec(crt *v1alpha1.CertificateSpec, fldPath *field.Path)
 
 	el = append(el, validateIssuerRef(crt.IssuerRef, fldPath)...)
 
-	if len(crt.CommonName) == 0 && len(crt.DNSNames) == 0 {
-		el = append(el, field.Required(fldPath.Child("dnsNames"), "at least one dnsName is required if commonName is not set"))
+	if len(crt.CommonName) == 0 && len(crt.DNSNames) == 0 && len(crt.URISANs) == 0 && len(crt.EmailSANs) == 0 {
+		el = append(el, field.Required(fldPath.Child("dnsNames"), "at least one dnsName, uriSAN or emailSAN is required if commonName is not set"))
 	}
 	// if a common name has been specified, ensure it is no longer than 64 chars
 	if len(crt.CommonName) > 64 {
@@ -53,6 +56,12 @@ func ValidateCertificateSpec(crt *v1alpha1.CertificateSpec, fldPath *field.Path)
 	if len(crt.IPAddresses) > 0 {
 		el = append(el, validateIPAddresses(crt, fldPath)...)
 	}
+	if len(crt.URISANs) > 0 {
+		el = append(el, validateURISANs(crt, fldPath)...)
+	}
+	if len(crt.EmailSANs) > 0 {
+		el = append(el, validateEmailSANs(crt, fldPath)...)
+	}
 	if crt.ACME != nil {
 		el = append(el, validateACMEConfigForAllDNSNames(crt, fldPath)...)
 		el = append(el, ValidateACMECertificateConfig(crt.ACME, fldPath.Child("acme"))...)
@@ -70,8 +79,20 @@ func ValidateCertificateSpec(crt *v1alpha1.CertificateSpec, fldPath *field.Path)
 		if crt.KeySize > 0 && crt.KeySize != 256 && crt.KeySize != 384 && crt.KeySize != 521 {
 			el = append(el, field.NotSupported(fldPath.Child("keySize"), crt.KeySize, []string{"256", "384", "521"}))
 		}
+	case v1alpha1.Ed25519KeyAlgorithm:
+		if crt.KeySize > 0 {
+			el = append(el, field.Invalid(fldPath.Child("keySize"), crt.KeySize, "cannot be set for ed25519 keyAlgorithm"))
+		}
+		if crt.KeyEncoding == v1alpha1.PKCS1 {
+			el = append(el, field.Invalid(fldPath.Child("keyEncoding"), crt.KeyEncoding, "ed25519 keys can only be encoded as pkcs8"))
+		}
+		// spec.acme is only used with ACME issuers, which cannot request
+		// certificates for ed25519 keys
+		if crt.ACME != nil {
+			el = append(el, field.Invalid(fldPath.Child("keyAlgorithm"), crt.KeyAlgorithm, "ACME does not support ed25519 keys"))
+		}
 	default:
-		el = append(el, field.Invalid(fldPath.Child("keyAlgorithm"), crt.KeyAlgorithm, "must be either empty or one of rsa or ecdsa"))
+		el = append(el, field.Invalid(fldPath.Child("keyAlgorithm"), crt.KeyAlgorithm, "must be either empty or one of rsa, ecdsa or ed25519"))
 	}
 
 	if crt.Duration != nil || crt.RenewBefore != nil {
@@ -85,6 +106,63 @@ func ValidateCertificateSpec(crt *v1alpha1.CertificateSpec, fldPath *field.Path)
 	default:
 		el = append(el, field.Invalid(fldPath.Child("keyEncoding"), crt.KeyEncoding, "must be either empty or one of pkcs1 or pkcs8"))
 	}
+	switch crt.RotationPolicy {
+	case v1alpha1.PrivateKeyRotationPolicy(""), v1alpha1.RotationPolicyNever, v1alpha1.RotationPolicyAlways:
+	default:
+		el = append(el, field.Invalid(fldPath.Child("rotationPolicy"), crt.RotationPolicy, "must be either empty or one of Never or Always"))
+	}
+	if crt.PodRefresh != nil {
+		el = append(el, validatePodRefreshConfig(crt.PodRefresh, fldPath.Child("podRefresh"))...)
+	}
+	if crt.Keystores != nil {
+		el = append(el, validateKeystores(crt.Keystores, fldPath.Child("keystores"))...)
+	}
+	return el
+}
+
+func validateKeystores(ks *v1alpha1.CertificateKeystores, fldPath *field.Path) field.ErrorList {
+	el := field.ErrorList{}
+	if ks.JKS != nil && ks.JKS.Create {
+		el = append(el, validateKeystorePasswordRef(ks.JKS.PasswordSecretRef, fldPath.Child("jks", "passwordSecretRef"))...)
+	}
+	if ks.PKCS12 != nil && ks.PKCS12.Create {
+		el = append(el, validateKeystorePasswordRef(ks.PKCS12.PasswordSecretRef, fldPath.Child("pkcs12", "passwordSecretRef"))...)
+	}
+	return el
+}
+
+func validateKeystorePasswordRef(ref v1alpha1.SecretKeySelector, fldPath *field.Path) field.ErrorList {
+	el := field.ErrorList{}
+	if ref.Name == "" {
+		el = append(el, field.Required(fldPath.Child("name"), "must be specified"))
+	}
+	if ref.Key == "" {
+		el = append(el, field.Required(fldPath.Child("key"), "must be specified"))
+	}
+	return el
+}
+
+func validatePodRefreshConfig(cfg *v1alpha1.PodRefreshConfig, fldPath *field.Path) field.ErrorList {
+	el := field.ErrorList{}
+	if cfg.Selector != nil {
+		el = append(el, metav1validation.ValidateLabelSelector(cfg.Selector, fldPath.Child("selector"))...)
+	}
+	for i, w := range cfg.Workloads {
+		wPath := fldPath.Child("workloads").Index(i)
+		switch w.Kind {
+		case "Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "CronJob":
+		default:
+			el = append(el, field.Invalid(wPath.Child("kind"), w.Kind, "must be one of Deployment, StatefulSet, DaemonSet, ReplicaSet or CronJob"))
+		}
+		if w.Name == "" {
+			el = append(el, field.Required(wPath.Child("name"), "must be specified"))
+		}
+	}
+	switch cfg.Mode {
+	case v1alpha1.PodRefreshMode(""), v1alpha1.PodRefreshModeRestart, v1alpha1.PodRefreshModeNotify:
+	default:
+		el = append(el, field.Invalid(fldPath.Child("mode"), cfg.Mode, "must be either empty or one of Restart or Notify"))
+	}
 	return el
 }
 
@@ -118,9 +196,37 @@ func validateACMEConfigForAllDNSNames(a *v1alpha1.CertificateSpec, fldPath *fiel
 func ValidateCertificate(obj runtime.Object) field.ErrorList {
 	crt := obj.(*v1alpha1.Certificate)
 	allErrs := ValidateCertificateSpec(&crt.Spec, field.NewPath("spec"))
+	allErrs = append(allErrs, validateRevocationReasonAnnotation(crt.Annotations, field.NewPath("metadata", "annotations"))...)
 	return allErrs
 }
 
+var supportedRevocationReasons = []string{
+	string(v1alpha1.RevocationReasonUnspecified),
+	string(v1alpha1.RevocationReasonKeyCompromise),
+	string(v1alpha1.RevocationReasonCACompromise),
+	string(v1alpha1.RevocationReasonAffiliationChanged),
+	string(v1alpha1.RevocationReasonSuperseded),
+	string(v1alpha1.RevocationReasonCessationOfOperation),
+	string(v1alpha1.RevocationReasonCertificateHold),
+	string(v1alpha1.RevocationReasonPrivilegeWithdrawn),
+	string(v1alpha1.RevocationReasonAACompromise),
+}
+
+// validateRevocationReasonAnnotation validates the revocation reason
+// annotation shared by Certificates and CertificateRequests, if it is set.
+func validateRevocationReasonAnnotation(annotations map[string]string, fldPath *field.Path) field.ErrorList {
+	reason, ok := annotations[v1alpha1.RevocationReasonAnnotationKey]
+	if !ok {
+		return nil
+	}
+	for _, r := range supportedRevocationReasons {
+		if r == reason {
+			return nil
+		}
+	}
+	return field.ErrorList{field.NotSupported(fldPath.Key(v1alpha1.RevocationReasonAnnotationKey), reason, supportedRevocationReasons)}
+}
+
 func validateIssuerRef(issuerRef v1alpha1.ObjectReference, fldPath *field.Path) field.ErrorList {
 	el := field.ErrorList{}
 
@@ -153,6 +259,30 @@ func validateIPAddresses(a *v1alpha1.CertificateSpec, fldPath *field.Path) field
 	return el
 }
 
+func validateURISANs(a *v1alpha1.CertificateSpec, fldPath *field.Path) field.ErrorList {
+	el := field.ErrorList{}
+	for i, u := range a.URISANs {
+		uri, err := url.Parse(u)
+		if err != nil || !uri.IsAbs() {
+			el = append(el, field.Invalid(fldPath.Child("uriSANs").Index(i), u, "must be an absolute URI"))
+		}
+	}
+	return el
+}
+
+func validateEmailSANs(a *v1alpha1.CertificateSpec, fldPath *field.Path) field.ErrorList {
+	el := field.ErrorList{}
+	for i, e := range a.EmailSANs {
+		// reject addresses with display names, e.g. "Name <name@example.com>",
+		// as only the address itself can be encoded in the SAN extension
+		addr, err := mail.ParseAddress(e)
+		if err != nil || addr.Address != e {
+			el = append(el, field.Invalid(fldPath.Child("emailSANs").Index(i), e, "invalid email address"))
+		}
+	}
+	return el
+}
+
 func validateUsages(a *v1alpha1.CertificateSpec, fldPath *field.Path) field.ErrorList {
 	el := field.ErrorList{}
 	for i, u := range a.Usages {
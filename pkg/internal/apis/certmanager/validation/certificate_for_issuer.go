@@ -69,6 +69,18 @@ func ValidateCertificateForACMEIssuer(crt *v1alpha1.CertificateSpec, issuer *v1a
 		el = append(el, field.Invalid(specPath.Child("ipAddresses"), crt.IPAddresses, "ACME does not support certificate ip addresses"))
 	}
 
+	if len(crt.URISANs) != 0 {
+		el = append(el, field.Invalid(specPath.Child("uriSANs"), crt.URISANs, "ACME does not support certificate uri SANs"))
+	}
+
+	if len(crt.EmailSANs) != 0 {
+		el = append(el, field.Invalid(specPath.Child("emailSANs"), crt.EmailSANs, "ACME does not support certificate email SANs"))
+	}
+
+	if crt.KeyAlgorithm == v1alpha1.Ed25519KeyAlgorithm {
+		el = append(el, field.Invalid(specPath.Child("keyAlgorithm"), crt.KeyAlgorithm, "ACME does not support ed25519 keys"))
+	}
+
 	return el
 }
 
@@ -85,8 +97,8 @@ func ValidateCertificateForVaultIssuer(crt *v1alpha1.CertificateSpec, issuer *v1
 		el = append(el, field.Invalid(specPath.Child("isCA"), crt.KeyAlgorithm, "Vault issuer does not currently support CA certificates"))
 	}
 
-	if len(crt.Organization) != 0 {
-		el = append(el, field.Invalid(specPath.Child("organization"), crt.Organization, "Vault issuer does not currently support setting the organization name"))
+	if crt.KeyAlgorithm == v1alpha1.Ed25519KeyAlgorithm {
+		el = append(el, field.Invalid(specPath.Child("keyAlgorithm"), crt.KeyAlgorithm, "Vault issuer does not currently support ed25519 keys"))
 	}
 
 	return el
@@ -101,5 +113,9 @@ func ValidateCertificateForSelfSignedIssuer(crt *v1alpha1.CertificateSpec, issue
 func ValidateCertificateForVenafiIssuer(crt *v1alpha1.CertificateSpec, issuer *v1alpha1.IssuerSpec, specPath *field.Path) field.ErrorList {
 	el := field.ErrorList{}
 
+	if crt.KeyAlgorithm == v1alpha1.Ed25519KeyAlgorithm {
+		el = append(el, field.Invalid(specPath.Child("keyAlgorithm"), crt.KeyAlgorithm, "Venafi issuer does not support ed25519 keys"))
+	}
+
 	return el
 }
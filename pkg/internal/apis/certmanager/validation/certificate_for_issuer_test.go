@@ -206,6 +206,144 @@ func TestValidateCertificateForIssuer(t *testing.T) {
 			}),
 			errs: []*field.Error{},
 		},
+		"acme certificate with uriSANs and emailSANs set": {
+			crt: &v1alpha1.Certificate{
+				Spec: v1alpha1.CertificateSpec{
+					URISANs:   []string{"spiffe://cluster.local/ns/default/sa/app"},
+					EmailSANs: []string{"app@example.com"},
+					IssuerRef: validIssuerRef,
+				},
+			},
+			issuer: generate.Issuer(generate.IssuerConfig{
+				Name:      defaultTestIssuerName,
+				Namespace: defaultTestNamespace,
+			}),
+			errs: []*field.Error{
+				field.Invalid(fldPath.Child("uriSANs"), []string{"spiffe://cluster.local/ns/default/sa/app"}, "ACME does not support certificate uri SANs"),
+				field.Invalid(fldPath.Child("emailSANs"), []string{"app@example.com"}, "ACME does not support certificate email SANs"),
+			},
+		},
+		"acme certificate with subject set": {
+			crt: &v1alpha1.Certificate{
+				Spec: v1alpha1.CertificateSpec{
+					Subject:   &v1alpha1.X509Subject{Countries: []string{"GB"}},
+					IssuerRef: validIssuerRef,
+				},
+			},
+			issuer: generate.Issuer(generate.IssuerConfig{
+				Name:      defaultTestIssuerName,
+				Namespace: defaultTestNamespace,
+			}),
+		},
+		"vault certificate with subject set": {
+			crt: &v1alpha1.Certificate{
+				Spec: v1alpha1.CertificateSpec{
+					Subject:   &v1alpha1.X509Subject{Countries: []string{"GB"}, SerialNumber: "1234"},
+					IssuerRef: validIssuerRef,
+				},
+			},
+			issuer: &v1alpha1.Issuer{
+				Spec: v1alpha1.IssuerSpec{
+					IssuerConfig: v1alpha1.IssuerConfig{
+						Vault: &v1alpha1.VaultIssuer{},
+					},
+				},
+			},
+		},
+		"vault certificate with organization set": {
+			crt: &v1alpha1.Certificate{
+				Spec: v1alpha1.CertificateSpec{
+					Organization: []string{"cert-manager"},
+					IssuerRef:    validIssuerRef,
+				},
+			},
+			issuer: &v1alpha1.Issuer{
+				Spec: v1alpha1.IssuerSpec{
+					IssuerConfig: v1alpha1.IssuerConfig{
+						Vault: &v1alpha1.VaultIssuer{SignMode: v1alpha1.VaultSignModeSign},
+					},
+				},
+			},
+		},
+		"vault certificate with subject and organization set in sign verbatim mode": {
+			crt: &v1alpha1.Certificate{
+				Spec: v1alpha1.CertificateSpec{
+					Organization: []string{"cert-manager"},
+					Subject:      &v1alpha1.X509Subject{Countries: []string{"GB"}, SerialNumber: "1234"},
+					IssuerRef:    validIssuerRef,
+				},
+			},
+			issuer: &v1alpha1.Issuer{
+				Spec: v1alpha1.IssuerSpec{
+					IssuerConfig: v1alpha1.IssuerConfig{
+						Vault: &v1alpha1.VaultIssuer{SignMode: v1alpha1.VaultSignModeSignVerbatim},
+					},
+				},
+			},
+		},
+		"vault certificate with subject serial number set": {
+			crt: &v1alpha1.Certificate{
+				Spec: v1alpha1.CertificateSpec{
+					Subject:   &v1alpha1.X509Subject{SerialNumber: "1234"},
+					IssuerRef: validIssuerRef,
+				},
+			},
+			issuer: &v1alpha1.Issuer{
+				Spec: v1alpha1.IssuerSpec{
+					IssuerConfig: v1alpha1.IssuerConfig{
+						Vault: &v1alpha1.VaultIssuer{},
+					},
+				},
+			},
+		},
+		"certificate with Ed25519 keyAlgorithm for ACME": {
+			crt: &v1alpha1.Certificate{
+				Spec: v1alpha1.CertificateSpec{
+					KeyAlgorithm: v1alpha1.Ed25519KeyAlgorithm,
+					IssuerRef:    validIssuerRef,
+				},
+			},
+			issuer: generate.Issuer(generate.IssuerConfig{
+				Name:      defaultTestIssuerName,
+				Namespace: defaultTestNamespace,
+			}),
+			errs: []*field.Error{
+				field.Invalid(fldPath.Child("keyAlgorithm"), v1alpha1.Ed25519KeyAlgorithm, "ACME does not support ed25519 keys"),
+			},
+		},
+		"certificate with Ed25519 keyAlgorithm for Vault": {
+			crt: &v1alpha1.Certificate{
+				Spec: v1alpha1.CertificateSpec{
+					KeyAlgorithm: v1alpha1.Ed25519KeyAlgorithm,
+					IssuerRef:    validIssuerRef,
+				},
+			},
+			issuer: &v1alpha1.Issuer{
+				Spec: v1alpha1.IssuerSpec{
+					IssuerConfig: v1alpha1.IssuerConfig{
+						Vault: &v1alpha1.VaultIssuer{},
+					},
+				},
+			},
+			errs: []*field.Error{
+				field.Invalid(fldPath.Child("keyAlgorithm"), v1alpha1.Ed25519KeyAlgorithm, "Vault issuer does not currently support ed25519 keys"),
+			},
+		},
+		"certificate with Ed25519 keyAlgorithm for CA": {
+			crt: &v1alpha1.Certificate{
+				Spec: v1alpha1.CertificateSpec{
+					KeyAlgorithm: v1alpha1.Ed25519KeyAlgorithm,
+					IssuerRef:    validIssuerRef,
+				},
+			},
+			issuer: &v1alpha1.Issuer{
+				Spec: v1alpha1.IssuerSpec{
+					IssuerConfig: v1alpha1.IssuerConfig{
+						CA: &v1alpha1.CAIssuer{},
+					},
+				},
+			},
+		},
 		"certificate with unspecified issuer type": {
 			crt: &v1alpha1.Certificate{
 				Spec: v1alpha1.CertificateSpec{
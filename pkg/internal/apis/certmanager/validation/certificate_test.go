@@ -121,7 +121,7 @@ func TestValidateCertificate(t *testing.T) {
 				},
 			},
 			errs: []*field.Error{
-				field.Required(fldPath.Child("dnsNames"), "at least one dnsName is required if commonName is not set"),
+				field.Required(fldPath.Child("dnsNames"), "at least one dnsName, uriSAN or emailSAN is required if commonName is not set"),
 			},
 		},
 		"certificate with no issuerRef": {
@@ -359,6 +359,32 @@ func TestValidateCertificate(t *testing.T) {
 				field.NotSupported(fldPath.Child("keySize"), 100, []string{"256", "384", "521"}),
 			},
 		},
+		"valid certificate with ed25519 keyAlgorithm": {
+			cfg: &v1alpha1.Certificate{
+				Spec: v1alpha1.CertificateSpec{
+					CommonName:   "testcn",
+					SecretName:   "abc",
+					IssuerRef:    validIssuerRef,
+					KeyAlgorithm: v1alpha1.Ed25519KeyAlgorithm,
+				},
+			},
+		},
+		"certificate with ed25519 keyAlgorithm and keySize and pkcs1 keyEncoding": {
+			cfg: &v1alpha1.Certificate{
+				Spec: v1alpha1.CertificateSpec{
+					CommonName:   "testcn",
+					SecretName:   "abc",
+					IssuerRef:    validIssuerRef,
+					KeyAlgorithm: v1alpha1.Ed25519KeyAlgorithm,
+					KeySize:      256,
+					KeyEncoding:  v1alpha1.PKCS1,
+				},
+			},
+			errs: []*field.Error{
+				field.Invalid(fldPath.Child("keySize"), 256, "cannot be set for ed25519 keyAlgorithm"),
+				field.Invalid(fldPath.Child("keyEncoding"), v1alpha1.PKCS1, "ed25519 keys can only be encoded as pkcs8"),
+			},
+		},
 		"certificate with invalid keyAlgorithm": {
 			cfg: &v1alpha1.Certificate{
 				Spec: v1alpha1.CertificateSpec{
@@ -369,7 +395,7 @@ func TestValidateCertificate(t *testing.T) {
 				},
 			},
 			errs: []*field.Error{
-				field.Invalid(fldPath.Child("keyAlgorithm"), v1alpha1.KeyAlgorithm("blah"), "must be either empty or one of rsa or ecdsa"),
+				field.Invalid(fldPath.Child("keyAlgorithm"), v1alpha1.KeyAlgorithm("blah"), "must be either empty or one of rsa, ecdsa or ed25519"),
 			},
 		},
 		"valid certificate with ipAddresses": {
@@ -395,6 +421,32 @@ func TestValidateCertificate(t *testing.T) {
 				field.Invalid(fldPath.Child("ipAddresses").Index(0), "blah", "invalid IP address"),
 			},
 		},
+		"valid certificate with only uriSANs and emailSANs": {
+			cfg: &v1alpha1.Certificate{
+				Spec: v1alpha1.CertificateSpec{
+					URISANs:    []string{"spiffe://cluster.local/ns/default/sa/app"},
+					EmailSANs:  []string{"app@example.com"},
+					SecretName: "abc",
+					IssuerRef:  validIssuerRef,
+				},
+			},
+		},
+		"certificate with invalid uriSANs and emailSANs": {
+			cfg: &v1alpha1.Certificate{
+				Spec: v1alpha1.CertificateSpec{
+					CommonName: "testcn",
+					URISANs:    []string{"not-absolute", "spiffe://cluster.local/ns/default/sa/app"},
+					EmailSANs:  []string{"App <app@example.com>", "blah"},
+					SecretName: "abc",
+					IssuerRef:  validIssuerRef,
+				},
+			},
+			errs: []*field.Error{
+				field.Invalid(fldPath.Child("uriSANs").Index(0), "not-absolute", "must be an absolute URI"),
+				field.Invalid(fldPath.Child("emailSANs").Index(0), "App <app@example.com>", "invalid email address"),
+				field.Invalid(fldPath.Child("emailSANs").Index(1), "blah", "invalid email address"),
+			},
+		},
 		"valid certificate with commonName exactly 64 bytes": {
 			cfg: &v1alpha1.Certificate{
 				Spec: v1alpha1.CertificateSpec{
@@ -490,6 +542,122 @@ func TestValidateCertificate(t *testing.T) {
 				field.Invalid(fldPath.Child("usages").Index(0), v1alpha1.KeyUsage("nonexistant"), "unknown keyusage"),
 			},
 		},
+		"valid certificate with podRefresh selector and workloads": {
+			cfg: &v1alpha1.Certificate{
+				Spec: v1alpha1.CertificateSpec{
+					CommonName: "testcn",
+					SecretName: "abc",
+					IssuerRef:  validIssuerRef,
+					PodRefresh: &v1alpha1.PodRefreshConfig{
+						Selector:  &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+						Workloads: []v1alpha1.WorkloadReference{{Kind: "Deployment", Name: "web"}},
+						Mode:      v1alpha1.PodRefreshModeNotify,
+					},
+				},
+			},
+		},
+		"invalid certificate with unsupported podRefresh workload and mode": {
+			cfg: &v1alpha1.Certificate{
+				Spec: v1alpha1.CertificateSpec{
+					CommonName: "testcn",
+					SecretName: "abc",
+					IssuerRef:  validIssuerRef,
+					PodRefresh: &v1alpha1.PodRefreshConfig{
+						Workloads: []v1alpha1.WorkloadReference{{Kind: "Pod"}},
+						Mode:      v1alpha1.PodRefreshMode("Delete"),
+					},
+				},
+			},
+			errs: []*field.Error{
+				field.Invalid(fldPath.Child("podRefresh", "workloads").Index(0).Child("kind"), "Pod", "must be one of Deployment, StatefulSet, DaemonSet, ReplicaSet or CronJob"),
+				field.Required(fldPath.Child("podRefresh", "workloads").Index(0).Child("name"), "must be specified"),
+				field.Invalid(fldPath.Child("podRefresh", "mode"), v1alpha1.PodRefreshMode("Delete"), "must be either empty or one of Restart or Notify"),
+			},
+		},
+		"valid certificate with keystores": {
+			cfg: &v1alpha1.Certificate{
+				Spec: v1alpha1.CertificateSpec{
+					CommonName: "testcn",
+					SecretName: "abc",
+					IssuerRef:  validIssuerRef,
+					Keystores: &v1alpha1.CertificateKeystores{
+						JKS: &v1alpha1.JKSKeystore{
+							Create:            true,
+							PasswordSecretRef: v1alpha1.SecretKeySelector{LocalObjectReference: v1alpha1.LocalObjectReference{Name: "passwords"}, Key: "jks"},
+						},
+						PKCS12: &v1alpha1.PKCS12Keystore{},
+					},
+				},
+			},
+		},
+		"invalid certificate with keystore missing password reference": {
+			cfg: &v1alpha1.Certificate{
+				Spec: v1alpha1.CertificateSpec{
+					CommonName: "testcn",
+					SecretName: "abc",
+					IssuerRef:  validIssuerRef,
+					Keystores: &v1alpha1.CertificateKeystores{
+						PKCS12: &v1alpha1.PKCS12Keystore{
+							Create:            true,
+							PasswordSecretRef: v1alpha1.SecretKeySelector{LocalObjectReference: v1alpha1.LocalObjectReference{Name: "passwords"}},
+						},
+					},
+				},
+			},
+			errs: []*field.Error{
+				field.Required(fldPath.Child("keystores", "pkcs12", "passwordSecretRef", "key"), "must be specified"),
+			},
+		},
+		"valid certificate with rotationPolicy": {
+			cfg: &v1alpha1.Certificate{
+				Spec: v1alpha1.CertificateSpec{
+					CommonName:     "testcn",
+					SecretName:     "abc",
+					IssuerRef:      validIssuerRef,
+					RotationPolicy: v1alpha1.RotationPolicyAlways,
+				},
+			},
+		},
+		"invalid certificate with unknown rotationPolicy": {
+			cfg: &v1alpha1.Certificate{
+				Spec: v1alpha1.CertificateSpec{
+					CommonName:     "testcn",
+					SecretName:     "abc",
+					IssuerRef:      validIssuerRef,
+					RotationPolicy: v1alpha1.PrivateKeyRotationPolicy("Sometimes"),
+				},
+			},
+			errs: []*field.Error{
+				field.Invalid(fldPath.Child("rotationPolicy"), v1alpha1.PrivateKeyRotationPolicy("Sometimes"), "must be either empty or one of Never or Always"),
+			},
+		},
+		"valid certificate with revocation reason": {
+			cfg: &v1alpha1.Certificate{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{v1alpha1.RevocationReasonAnnotationKey: "keyCompromise"},
+				},
+				Spec: v1alpha1.CertificateSpec{
+					CommonName: "testcn",
+					SecretName: "abc",
+					IssuerRef:  validIssuerRef,
+				},
+			},
+		},
+		"invalid certificate with unknown revocation reason": {
+			cfg: &v1alpha1.Certificate{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{v1alpha1.RevocationReasonAnnotationKey: "removeFromCRL"},
+				},
+				Spec: v1alpha1.CertificateSpec{
+					CommonName: "testcn",
+					SecretName: "abc",
+					IssuerRef:  validIssuerRef,
+				},
+			},
+			errs: []*field.Error{
+				field.NotSupported(field.NewPath("metadata", "annotations").Key(v1alpha1.RevocationReasonAnnotationKey), "removeFromCRL", supportedRevocationReasons),
+			},
+		},
 	}
 	for n, s := range scenarios {
 		t.Run(n, func(t *testing.T) {
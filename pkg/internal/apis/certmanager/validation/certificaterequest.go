@@ -29,6 +29,7 @@ import (
 func ValidateCertificateRequest(obj runtime.Object) field.ErrorList {
 	cr := obj.(*v1alpha1.CertificateRequest)
 	allErrs := ValidateCertificateRequestSpec(&cr.Spec, field.NewPath("spec"))
+	allErrs = append(allErrs, validateRevocationReasonAnnotation(cr.Annotations, field.NewPath("metadata", "annotations"))...)
 	return allErrs
 }
 
@@ -19,6 +19,7 @@ package validation
 import (
 	"crypto/x509"
 	"fmt"
+	"net/url"
 	"reflect"
 	"strings"
 
@@ -103,6 +104,10 @@ func ValidateACMEIssuerConfig(iss *v1alpha1.ACMEIssuer, fldPath *field.Path) fie
 	if len(iss.Server) == 0 {
 		el = append(el, field.Required(fldPath.Child("server"), "acme server URL is a required field"))
 	}
+	el = append(el, validateACMEPrivateKey(iss, fldPath)...)
+	if iss.ExternalAccountBinding != nil {
+		el = append(el, ValidateACMEExternalAccountBinding(iss.ExternalAccountBinding, fldPath.Child("externalAccountBinding"))...)
+	}
 	if iss.HTTP01 != nil {
 		el = append(el, ValidateACMEIssuerHTTP01Config(iss.HTTP01, fldPath.Child("http01"))...)
 	}
@@ -117,12 +122,69 @@ func ValidateACMEIssuerConfig(iss *v1alpha1.ACMEIssuer, fldPath *field.Path) fie
 	return el
 }
 
+// validateACMEPrivateKey validates the algorithm and size of the ACME
+// account private key. Ed25519 keys cannot be used to sign ACME requests.
+func validateACMEPrivateKey(iss *v1alpha1.ACMEIssuer, fldPath *field.Path) field.ErrorList {
+	el := field.ErrorList{}
+	if iss.PrivateKeySize < 0 {
+		el = append(el, field.Invalid(fldPath.Child("privateKeySize"), iss.PrivateKeySize, "cannot be less than zero"))
+	}
+	switch iss.PrivateKeyAlgorithm {
+	case v1alpha1.KeyAlgorithm(""):
+		if iss.PrivateKeySize > 0 {
+			el = append(el, field.Invalid(fldPath.Child("privateKeySize"), iss.PrivateKeySize, "cannot be set without privateKeyAlgorithm"))
+		}
+	case v1alpha1.RSAKeyAlgorithm:
+		if iss.PrivateKeySize > 0 && (iss.PrivateKeySize < 2048 || iss.PrivateKeySize > 8192) {
+			el = append(el, field.Invalid(fldPath.Child("privateKeySize"), iss.PrivateKeySize, "must be between 2048 & 8192 for rsa privateKeyAlgorithm"))
+		}
+	case v1alpha1.ECDSAKeyAlgorithm:
+		if iss.PrivateKeySize > 0 && iss.PrivateKeySize != 256 && iss.PrivateKeySize != 384 {
+			el = append(el, field.NotSupported(fldPath.Child("privateKeySize"), iss.PrivateKeySize, []string{"256", "384"}))
+		}
+	default:
+		el = append(el, field.NotSupported(fldPath.Child("privateKeyAlgorithm"), iss.PrivateKeyAlgorithm, []string{string(v1alpha1.RSAKeyAlgorithm), string(v1alpha1.ECDSAKeyAlgorithm)}))
+	}
+	return el
+}
+
+func ValidateACMEExternalAccountBinding(eab *v1alpha1.ACMEExternalAccountBinding, fldPath *field.Path) field.ErrorList {
+	el := field.ErrorList{}
+	if len(eab.KeyID) == 0 {
+		el = append(el, field.Required(fldPath.Child("keyID"), "key ID is a required field"))
+	}
+	if len(eab.Key.Name) == 0 {
+		el = append(el, field.Required(fldPath.Child("keySecretRef", "name"), "key secret name is a required field"))
+	}
+	switch eab.KeyAlgorithm {
+	case v1alpha1.HS256, v1alpha1.HS384, v1alpha1.HS512:
+	case "":
+		el = append(el, field.Required(fldPath.Child("keyAlgorithm"), "key algorithm is a required field"))
+	default:
+		el = append(el, field.NotSupported(fldPath.Child("keyAlgorithm"), eab.KeyAlgorithm, []string{string(v1alpha1.HS256), string(v1alpha1.HS384), string(v1alpha1.HS512)}))
+	}
+	return el
+}
+
 func ValidateACMEIssuerChallengeSolverConfig(sol *v1alpha1.ACMEChallengeSolver, fldPath *field.Path) field.ErrorList {
 	el := field.ErrorList{}
 
 	if sol.HTTP01 != nil {
 		el = append(el, ValidateACMEIssuerChallengeSolverHTTP01Config(sol.HTTP01, fldPath.Child("http01"))...)
 	}
+	if sol.TLSALPN01 != nil {
+		el = append(el, ValidateACMEIssuerChallengeSolverTLSALPN01Config(sol.TLSALPN01, fldPath.Child("tlsALPN01"))...)
+	}
+
+	return el
+}
+
+func ValidateACMEIssuerChallengeSolverTLSALPN01Config(tlsALPN01 *v1alpha1.ACMEChallengeSolverTLSALPN01, fldPath *field.Path) field.ErrorList {
+	el := field.ErrorList{}
+
+	if tlsALPN01.PodTemplate != nil {
+		el = append(el, ValidateACMEIssuerChallengeSolverHTTP01IngressPodTemplateConfig(tlsALPN01.PodTemplate, fldPath.Child("podTemplate"))...)
+	}
 
 	return el
 }
@@ -130,9 +192,25 @@ func ValidateACMEIssuerChallengeSolverConfig(sol *v1alpha1.ACMEChallengeSolver,
 func ValidateACMEIssuerChallengeSolverHTTP01Config(http01 *v1alpha1.ACMEChallengeSolverHTTP01, fldPath *field.Path) field.ErrorList {
 	el := field.ErrorList{}
 
+	if http01.Ingress != nil && http01.Route != nil {
+		el = append(el, field.Forbidden(fldPath.Child("route"), "only one of 'ingress' or 'route' may be specified"))
+	}
 	if http01.Ingress != nil {
 		el = append(el, ValidateACMEIssuerChallengeSolverHTTP01IngressConfig(http01.Ingress, fldPath.Child("ingress"))...)
 	}
+	if http01.Route != nil {
+		el = append(el, ValidateACMEIssuerChallengeSolverHTTP01RouteConfig(http01.Route, fldPath.Child("route"))...)
+	}
+
+	return el
+}
+
+func ValidateACMEIssuerChallengeSolverHTTP01RouteConfig(route *v1alpha1.ACMEChallengeSolverHTTP01Route, fldPath *field.Path) field.ErrorList {
+	el := field.ErrorList{}
+
+	if route.PodTemplate != nil {
+		el = append(el, ValidateACMEIssuerChallengeSolverHTTP01IngressPodTemplateConfig(route.PodTemplate, fldPath.Child("podTemplate"))...)
+	}
 
 	return el
 }
@@ -140,6 +218,10 @@ func ValidateACMEIssuerChallengeSolverHTTP01Config(http01 *v1alpha1.ACMEChalleng
 func ValidateACMEIssuerChallengeSolverHTTP01IngressConfig(ingress *v1alpha1.ACMEChallengeSolverHTTP01Ingress, fldPath *field.Path) field.ErrorList {
 	el := field.ErrorList{}
 
+	if ingress.IngressClassName != nil && (ingress.Class != nil || ingress.Name != "") {
+		el = append(el, field.Forbidden(fldPath.Child("ingressClassName"), "only one of 'class', 'ingressClassName' or 'name' may be specified"))
+	}
+
 	if ingress.PodTemplate != nil {
 		el = append(el, ValidateACMEIssuerChallengeSolverHTTP01IngressPodTemplateConfig(ingress.PodTemplate, fldPath.Child("podTemplate"))...)
 	}
@@ -166,6 +248,58 @@ func ValidateCAIssuerConfig(iss *v1alpha1.CAIssuer, fldPath *field.Path) field.E
 	if len(iss.SecretName) == 0 {
 		el = append(el, field.Required(fldPath.Child("secretName"), ""))
 	}
+	switch iss.ChainLayout {
+	case "", v1alpha1.CAChainLayoutLeaf, v1alpha1.CAChainLayoutLeafAndIntermediates, v1alpha1.CAChainLayoutFullChain:
+	default:
+		el = append(el, field.NotSupported(fldPath.Child("chainLayout"), iss.ChainLayout, []string{
+			string(v1alpha1.CAChainLayoutLeaf),
+			string(v1alpha1.CAChainLayoutLeafAndIntermediates),
+			string(v1alpha1.CAChainLayoutFullChain),
+		}))
+	}
+	el = append(el, validateAbsoluteURLs(iss.CRLDistributionPoints, fldPath.Child("crlDistributionPoints"))...)
+	el = append(el, validateAbsoluteURLs(iss.OCSPServers, fldPath.Child("ocspServers"))...)
+	if iss.CRL != nil {
+		el = append(el, validateCACRLConfig(iss.CRL, iss.SecretName, fldPath.Child("crl"))...)
+	}
+	return el
+}
+
+func validateCACRLConfig(crl *v1alpha1.CACRL, caSecretName string, fldPath *field.Path) field.ErrorList {
+	el := field.ErrorList{}
+	switch {
+	case crl.SecretName == "" && crl.ConfigMapName == "":
+		el = append(el, field.Required(fldPath, "one of secretName or configMapName must be specified"))
+	case crl.SecretName != "" && crl.ConfigMapName != "":
+		el = append(el, field.Forbidden(fldPath.Child("configMapName"), "may not be specified along with secretName"))
+	case crl.SecretName != "" && crl.SecretName == caSecretName:
+		el = append(el, field.Invalid(fldPath.Child("secretName"), crl.SecretName, "must not be the CA secret"))
+	}
+
+	duration := v1alpha1.DefaultCRLDuration
+	if crl.Duration != nil {
+		duration = crl.Duration.Duration
+		if duration <= 0 {
+			el = append(el, field.Invalid(fldPath.Child("duration"), duration, "CRL duration must be greater than zero"))
+		}
+	}
+	if crl.RenewBefore != nil {
+		renewBefore := crl.RenewBefore.Duration
+		if renewBefore <= 0 || renewBefore >= duration {
+			el = append(el, field.Invalid(fldPath.Child("renewBefore"), renewBefore, fmt.Sprintf("CRL renewBefore must be greater than zero and less than duration %s", duration)))
+		}
+	}
+	return el
+}
+
+func validateAbsoluteURLs(urls []string, fldPath *field.Path) field.ErrorList {
+	el := field.ErrorList{}
+	for i, u := range urls {
+		parsed, err := url.Parse(u)
+		if err != nil || !parsed.IsAbs() {
+			el = append(el, field.Invalid(fldPath.Index(i), u, "must be an absolute URL"))
+		}
+	}
 	return el
 }
 
@@ -182,6 +316,19 @@ func ValidateVaultIssuerConfig(iss *v1alpha1.VaultIssuer, fldPath *field.Path) f
 		el = append(el, field.Required(fldPath.Child("path"), ""))
 	}
 
+	switch iss.SignMode {
+	case "", v1alpha1.VaultSignModeSign:
+	case v1alpha1.VaultSignModeSignVerbatim:
+		if len(iss.Path) > 0 && !hasPathSegment(iss.Path, "sign-verbatim") {
+			el = append(el, field.Invalid(fldPath.Child("path"), iss.Path, "path must reference the 'sign-verbatim' endpoint when signMode is SignVerbatim"))
+		}
+	default:
+		el = append(el, field.NotSupported(fldPath.Child("signMode"), iss.SignMode, []string{
+			string(v1alpha1.VaultSignModeSign),
+			string(v1alpha1.VaultSignModeSignVerbatim),
+		}))
+	}
+
 	// check if caBundle is valid
 	certs := iss.CABundle
 	if len(certs) > 0 {
@@ -192,13 +339,70 @@ func ValidateVaultIssuerConfig(iss *v1alpha1.VaultIssuer, fldPath *field.Path) f
 		}
 	}
 
+	if ref := iss.CABundleSecretRef; ref != nil {
+		if len(certs) > 0 {
+			el = append(el, field.Forbidden(fldPath.Child("caBundleSecretRef"), "only one of 'caBundle' or 'caBundleSecretRef' may be specified"))
+		}
+		if len(ref.Name) == 0 {
+			el = append(el, field.Required(fldPath.Child("caBundleSecretRef", "name"), ""))
+		}
+	}
+
+	if kubernetes := iss.Auth.Kubernetes; kubernetes != nil {
+		kubernetesPath := fldPath.Child("auth", "kubernetes")
+		if len(kubernetes.Role) == 0 {
+			el = append(el, field.Required(kubernetesPath.Child("role"), ""))
+		}
+		if len(kubernetes.SecretRef.Name) == 0 {
+			el = append(el, field.Required(kubernetesPath.Child("secretRef", "name"), ""))
+		}
+	}
+
+	if clientCertificate := iss.Auth.ClientCertificate; clientCertificate != nil {
+		if len(clientCertificate.SecretName) == 0 {
+			el = append(el, field.Required(fldPath.Child("auth", "clientCertificate", "secretName"), ""))
+		}
+	}
+
 	return el
 	// TODO: add validation for Vault authentication types
 }
 
+// hasPathSegment returns true if segment is one of the '/' separated segments
+// of path.
+func hasPathSegment(path, segment string) bool {
+	for _, s := range strings.Split(path, "/") {
+		if s == segment {
+			return true
+		}
+	}
+	return false
+}
+
 func ValidateVenafiIssuerConfig(iss *v1alpha1.VenafiIssuer, fldPath *field.Path) field.ErrorList {
 	//TODO: make extended validation fro fake\tpp\cloud modes
-	return nil
+	el := field.ErrorList{}
+	if iss.TPP != nil {
+		el = append(el, ValidateVenafiTPP(iss.TPP, fldPath.Child("tpp"))...)
+	}
+	return el
+}
+
+// ValidateVenafiTPP ensures exactly one of username and password
+// credentials or an OAuth access token is configured for TPP.
+func ValidateVenafiTPP(tpp *v1alpha1.VenafiTPP, fldPath *field.Path) field.ErrorList {
+	el := field.ErrorList{}
+	switch {
+	case len(tpp.CredentialsRef.Name) > 0 && tpp.AccessTokenSecretRef != nil:
+		el = append(el, field.Forbidden(fldPath.Child("accessTokenSecretRef"), "may not be specified together with credentialsRef"))
+	case tpp.AccessTokenSecretRef != nil:
+		if len(tpp.AccessTokenSecretRef.Name) == 0 {
+			el = append(el, field.Required(fldPath.Child("accessTokenSecretRef", "name"), "secret name is required"))
+		}
+	case len(tpp.CredentialsRef.Name) == 0:
+		el = append(el, field.Required(fldPath.Child("credentialsRef", "name"), "one of credentialsRef or accessTokenSecretRef must be specified"))
+	}
+	return el
 }
 
 func ValidateACMEIssuerHTTP01Config(iss *v1alpha1.ACMEIssuerHTTP01Config, fldPath *field.Path) field.ErrorList {
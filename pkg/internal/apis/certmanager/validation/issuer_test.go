@@ -19,6 +19,7 @@ package validation
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -82,6 +83,77 @@ func TestValidateVaultIssuerConfig(t *testing.T) {
 				field.Invalid(fldPath.Child("caBundle"), "", "Specified CA bundle is invalid"),
 			},
 		},
+		"vault issuer with kubernetes auth missing fields": {
+			spec: &v1alpha1.VaultIssuer{
+				Server: "something",
+				Path:   "a/b/c",
+				Auth: v1alpha1.VaultAuth{
+					Kubernetes: &v1alpha1.VaultKubernetesAuth{},
+				},
+			},
+			errs: []*field.Error{
+				field.Required(fldPath.Child("auth", "kubernetes", "role"), ""),
+				field.Required(fldPath.Child("auth", "kubernetes", "secretRef", "name"), ""),
+			},
+		},
+		"vault issuer using sign-verbatim": {
+			spec: &v1alpha1.VaultIssuer{
+				Auth:     validVaultIssuer.Auth,
+				Server:   "something",
+				Path:     "pki/sign-verbatim/my-role",
+				SignMode: v1alpha1.VaultSignModeSignVerbatim,
+			},
+		},
+		"vault issuer using sign-verbatim with a sign path": {
+			spec: &v1alpha1.VaultIssuer{
+				Auth:     validVaultIssuer.Auth,
+				Server:   "something",
+				Path:     "pki/sign/my-role",
+				SignMode: v1alpha1.VaultSignModeSignVerbatim,
+			},
+			errs: []*field.Error{
+				field.Invalid(fldPath.Child("path"), "pki/sign/my-role", "path must reference the 'sign-verbatim' endpoint when signMode is SignVerbatim"),
+			},
+		},
+		"vault issuer with an unknown sign mode": {
+			spec: &v1alpha1.VaultIssuer{
+				Auth:     validVaultIssuer.Auth,
+				Server:   "something",
+				Path:     "a/b/c",
+				SignMode: "Issue",
+			},
+			errs: []*field.Error{
+				field.NotSupported(fldPath.Child("signMode"), v1alpha1.VaultSignMode("Issue"), []string{"Sign", "SignVerbatim"}),
+			},
+		},
+		"vault issuer with client certificate auth missing fields": {
+			spec: &v1alpha1.VaultIssuer{
+				Server: "something",
+				Path:   "a/b/c",
+				Auth: v1alpha1.VaultAuth{
+					ClientCertificate: &v1alpha1.VaultClientCertificateAuth{},
+				},
+			},
+			errs: []*field.Error{
+				field.Required(fldPath.Child("auth", "clientCertificate", "secretName"), ""),
+			},
+		},
+		"vault issuer with both caBundle and caBundleSecretRef": {
+			spec: &v1alpha1.VaultIssuer{
+				Server:   "something",
+				Path:     "a/b/c",
+				CABundle: []byte("invalid"),
+				CABundleSecretRef: &v1alpha1.SecretKeySelector{
+					LocalObjectReference: v1alpha1.LocalObjectReference{
+						Name: "vault-ca",
+					},
+				},
+			},
+			errs: []*field.Error{
+				field.Invalid(fldPath.Child("caBundle"), "", "Specified CA bundle is invalid"),
+				field.Forbidden(fldPath.Child("caBundleSecretRef"), "only one of 'caBundle' or 'caBundleSecretRef' may be specified"),
+			},
+		},
 	}
 	for n, s := range scenarios {
 		t.Run(n, func(t *testing.T) {
@@ -116,6 +188,101 @@ func TestValidateACMEIssuerConfig(t *testing.T) {
 				field.Required(fldPath.Child("server"), "acme server URL is a required field"),
 			},
 		},
+		"acme issuer with ecdsa private key": {
+			spec: &v1alpha1.ACMEIssuer{
+				Email:               "valid-email",
+				Server:              "valid-server",
+				PrivateKey:          validSecretKeyRef,
+				PrivateKeyAlgorithm: v1alpha1.ECDSAKeyAlgorithm,
+				PrivateKeySize:      384,
+			},
+		},
+		"acme issuer with unsupported ecdsa private key size": {
+			spec: &v1alpha1.ACMEIssuer{
+				Email:               "valid-email",
+				Server:              "valid-server",
+				PrivateKey:          validSecretKeyRef,
+				PrivateKeyAlgorithm: v1alpha1.ECDSAKeyAlgorithm,
+				PrivateKeySize:      521,
+			},
+			errs: []*field.Error{
+				field.NotSupported(fldPath.Child("privateKeySize"), 521, []string{"256", "384"}),
+			},
+		},
+		"acme issuer with invalid rsa private key size": {
+			spec: &v1alpha1.ACMEIssuer{
+				Email:               "valid-email",
+				Server:              "valid-server",
+				PrivateKey:          validSecretKeyRef,
+				PrivateKeyAlgorithm: v1alpha1.RSAKeyAlgorithm,
+				PrivateKeySize:      1024,
+			},
+			errs: []*field.Error{
+				field.Invalid(fldPath.Child("privateKeySize"), 1024, "must be between 2048 & 8192 for rsa privateKeyAlgorithm"),
+			},
+		},
+		"acme issuer with private key size but no algorithm": {
+			spec: &v1alpha1.ACMEIssuer{
+				Email:          "valid-email",
+				Server:         "valid-server",
+				PrivateKey:     validSecretKeyRef,
+				PrivateKeySize: 2048,
+			},
+			errs: []*field.Error{
+				field.Invalid(fldPath.Child("privateKeySize"), 2048, "cannot be set without privateKeyAlgorithm"),
+			},
+		},
+		"acme issuer with ed25519 private key": {
+			spec: &v1alpha1.ACMEIssuer{
+				Email:               "valid-email",
+				Server:              "valid-server",
+				PrivateKey:          validSecretKeyRef,
+				PrivateKeyAlgorithm: v1alpha1.Ed25519KeyAlgorithm,
+			},
+			errs: []*field.Error{
+				field.NotSupported(fldPath.Child("privateKeyAlgorithm"), v1alpha1.Ed25519KeyAlgorithm, []string{"rsa", "ecdsa"}),
+			},
+		},
+		"acme issuer with valid external account binding": {
+			spec: &v1alpha1.ACMEIssuer{
+				Email:      "valid-email",
+				Server:     "valid-server",
+				PrivateKey: validSecretKeyRef,
+				ExternalAccountBinding: &v1alpha1.ACMEExternalAccountBinding{
+					KeyID:        "valid-key-id",
+					Key:          validSecretKeyRef,
+					KeyAlgorithm: v1alpha1.HS256,
+				},
+			},
+		},
+		"acme issuer with external account binding missing fields": {
+			spec: &v1alpha1.ACMEIssuer{
+				Email:                  "valid-email",
+				Server:                 "valid-server",
+				PrivateKey:             validSecretKeyRef,
+				ExternalAccountBinding: &v1alpha1.ACMEExternalAccountBinding{},
+			},
+			errs: []*field.Error{
+				field.Required(fldPath.Child("externalAccountBinding", "keyID"), "key ID is a required field"),
+				field.Required(fldPath.Child("externalAccountBinding", "keySecretRef", "name"), "key secret name is a required field"),
+				field.Required(fldPath.Child("externalAccountBinding", "keyAlgorithm"), "key algorithm is a required field"),
+			},
+		},
+		"acme issuer with external account binding with invalid key algorithm": {
+			spec: &v1alpha1.ACMEIssuer{
+				Email:      "valid-email",
+				Server:     "valid-server",
+				PrivateKey: validSecretKeyRef,
+				ExternalAccountBinding: &v1alpha1.ACMEExternalAccountBinding{
+					KeyID:        "valid-key-id",
+					Key:          validSecretKeyRef,
+					KeyAlgorithm: v1alpha1.HMACKeyAlgorithm("HS1"),
+				},
+			},
+			errs: []*field.Error{
+				field.NotSupported(fldPath.Child("externalAccountBinding", "keyAlgorithm"), v1alpha1.HMACKeyAlgorithm("HS1"), []string{"HS256", "HS384", "HS512"}),
+			},
+		},
 		"acme issuer with invalid dns01 config": {
 			spec: &v1alpha1.ACMEIssuer{
 				Email:      "valid-email",
@@ -255,6 +422,66 @@ func TestValidateACMEIssuerConfig(t *testing.T) {
 					"", "only labels and annotations may be set on podTemplate metadata"),
 			},
 		},
+		"acme issue with invalid tls-alpn-01 pod template ObjectMeta attributes": {
+			spec: &v1alpha1.ACMEIssuer{
+				Email:      "valid-email",
+				Server:     "valid-server",
+				PrivateKey: validSecretKeyRef,
+				Solvers: []v1alpha1.ACMEChallengeSolver{
+					{
+						TLSALPN01: &v1alpha1.ACMEChallengeSolverTLSALPN01{
+							PodTemplate: &v1alpha1.ACMEChallengeSolverHTTP01IngressPodTemplate{
+								ObjectMeta: metav1.ObjectMeta{
+									Name: "unable-to-change-name",
+								},
+							},
+						},
+					},
+				},
+			},
+			errs: []*field.Error{
+				field.Invalid(fldPath.Child("solver", "tlsALPN01", "podTemplate", "metadata"),
+					"", "only labels and annotations may be set on podTemplate metadata"),
+			},
+		},
+		"acme issue with both ingress and route http01 solvers": {
+			spec: &v1alpha1.ACMEIssuer{
+				Email:      "valid-email",
+				Server:     "valid-server",
+				PrivateKey: validSecretKeyRef,
+				Solvers: []v1alpha1.ACMEChallengeSolver{
+					{
+						HTTP01: &v1alpha1.ACMEChallengeSolverHTTP01{
+							Ingress: &v1alpha1.ACMEChallengeSolverHTTP01Ingress{},
+							Route:   &v1alpha1.ACMEChallengeSolverHTTP01Route{},
+						},
+					},
+				},
+			},
+			errs: []*field.Error{
+				field.Forbidden(fldPath.Child("solver", "http01", "route"), "only one of 'ingress' or 'route' may be specified"),
+			},
+		},
+		"acme issuer with both ingress class and ingressClassName": {
+			spec: &v1alpha1.ACMEIssuer{
+				Email:      "valid-email",
+				Server:     "valid-server",
+				PrivateKey: validSecretKeyRef,
+				Solvers: []v1alpha1.ACMEChallengeSolver{
+					{
+						HTTP01: &v1alpha1.ACMEChallengeSolverHTTP01{
+							Ingress: &v1alpha1.ACMEChallengeSolverHTTP01Ingress{
+								Class:            strPtr("nginx"),
+								IngressClassName: strPtr("nginx"),
+							},
+						},
+					},
+				},
+			},
+			errs: []*field.Error{
+				field.Forbidden(fldPath.Child("solver", "http01", "ingress", "ingressClassName"), "only one of 'class', 'ingressClassName' or 'name' may be specified"),
+			},
+		},
 		"acme issue with valid pod template PodSpec attributes": {
 			spec: &v1alpha1.ACMEIssuer{
 				Email:      "valid-email",
@@ -358,6 +585,124 @@ func TestValidateIssuerSpec(t *testing.T) {
 			},
 			errs: []*field.Error{field.Required(fldPath.Child("ca", "secretName"), "")},
 		},
+		"valid ca issuer with chain layout": {
+			spec: &v1alpha1.IssuerSpec{
+				IssuerConfig: v1alpha1.IssuerConfig{
+					CA: &v1alpha1.CAIssuer{
+						SecretName:  "valid",
+						ChainLayout: v1alpha1.CAChainLayoutLeafAndIntermediates,
+					},
+				},
+			},
+		},
+		"valid ca issuer with crl distribution points and ocsp servers": {
+			spec: &v1alpha1.IssuerSpec{
+				IssuerConfig: v1alpha1.IssuerConfig{
+					CA: &v1alpha1.CAIssuer{
+						SecretName:            "valid",
+						CRLDistributionPoints: []string{"http://crl.example.com/ca.crl"},
+						OCSPServers:           []string{"http://ocsp.example.com"},
+					},
+				},
+			},
+		},
+		"ca issuer with relative crl distribution point and ocsp server": {
+			spec: &v1alpha1.IssuerSpec{
+				IssuerConfig: v1alpha1.IssuerConfig{
+					CA: &v1alpha1.CAIssuer{
+						SecretName:            "valid",
+						CRLDistributionPoints: []string{"http://crl.example.com/ca.crl", "ca.crl"},
+						OCSPServers:           []string{"ocsp.example.com"},
+					},
+				},
+			},
+			errs: []*field.Error{
+				field.Invalid(fldPath.Child("ca", "crlDistributionPoints").Index(1), "ca.crl", "must be an absolute URL"),
+				field.Invalid(fldPath.Child("ca", "ocspServers").Index(0), "ocsp.example.com", "must be an absolute URL"),
+			},
+		},
+		"valid ca issuer publishing a crl": {
+			spec: &v1alpha1.IssuerSpec{
+				IssuerConfig: v1alpha1.IssuerConfig{
+					CA: &v1alpha1.CAIssuer{
+						SecretName: "valid",
+						CRL: &v1alpha1.CACRL{
+							ConfigMapName: "crl",
+							Duration:      &metav1.Duration{Duration: time.Hour * 12},
+							RenewBefore:   &metav1.Duration{Duration: time.Hour},
+						},
+					},
+				},
+			},
+		},
+		"ca issuer crl without a target": {
+			spec: &v1alpha1.IssuerSpec{
+				IssuerConfig: v1alpha1.IssuerConfig{
+					CA: &v1alpha1.CAIssuer{
+						SecretName: "valid",
+						CRL:        &v1alpha1.CACRL{},
+					},
+				},
+			},
+			errs: []*field.Error{
+				field.Required(fldPath.Child("ca", "crl"), "one of secretName or configMapName must be specified"),
+			},
+		},
+		"ca issuer crl with both a secret and configmap": {
+			spec: &v1alpha1.IssuerSpec{
+				IssuerConfig: v1alpha1.IssuerConfig{
+					CA: &v1alpha1.CAIssuer{
+						SecretName: "valid",
+						CRL:        &v1alpha1.CACRL{SecretName: "crl", ConfigMapName: "crl"},
+					},
+				},
+			},
+			errs: []*field.Error{
+				field.Forbidden(fldPath.Child("ca", "crl", "configMapName"), "may not be specified along with secretName"),
+			},
+		},
+		"ca issuer crl published to the ca secret": {
+			spec: &v1alpha1.IssuerSpec{
+				IssuerConfig: v1alpha1.IssuerConfig{
+					CA: &v1alpha1.CAIssuer{
+						SecretName: "valid",
+						CRL:        &v1alpha1.CACRL{SecretName: "valid"},
+					},
+				},
+			},
+			errs: []*field.Error{
+				field.Invalid(fldPath.Child("ca", "crl", "secretName"), "valid", "must not be the CA secret"),
+			},
+		},
+		"ca issuer crl with renewBefore greater than the default duration": {
+			spec: &v1alpha1.IssuerSpec{
+				IssuerConfig: v1alpha1.IssuerConfig{
+					CA: &v1alpha1.CAIssuer{
+						SecretName: "valid",
+						CRL: &v1alpha1.CACRL{
+							SecretName:  "crl",
+							RenewBefore: &metav1.Duration{Duration: time.Hour * 48},
+						},
+					},
+				},
+			},
+			errs: []*field.Error{
+				field.Invalid(fldPath.Child("ca", "crl", "renewBefore"), time.Hour*48, "CRL renewBefore must be greater than zero and less than duration 24h0m0s"),
+			},
+		},
+		"ca issuer with unknown chain layout": {
+			spec: &v1alpha1.IssuerSpec{
+				IssuerConfig: v1alpha1.IssuerConfig{
+					CA: &v1alpha1.CAIssuer{
+						SecretName:  "valid",
+						ChainLayout: "RootOnly",
+					},
+				},
+			},
+			errs: []*field.Error{
+				field.NotSupported(fldPath.Child("ca", "chainLayout"), v1alpha1.CAChainLayout("RootOnly"), []string{"Leaf", "LeafAndIntermediates", "FullChain"}),
+			},
+		},
 		"valid self signed issuer": {
 			spec: &v1alpha1.IssuerSpec{
 				IssuerConfig: v1alpha1.IssuerConfig{
@@ -829,3 +1174,66 @@ func TestValidateSecretKeySelector(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateVenafiTPP(t *testing.T) {
+	fldPath := field.NewPath("")
+	scenarios := map[string]struct {
+		tpp  *v1alpha1.VenafiTPP
+		errs []*field.Error
+	}{
+		"valid credentialsRef": {
+			tpp: &v1alpha1.VenafiTPP{
+				URL:            "https://tpp.example.com/vedsdk",
+				CredentialsRef: v1alpha1.LocalObjectReference{Name: "tpp-credentials"},
+			},
+		},
+		"valid accessTokenSecretRef": {
+			tpp: &v1alpha1.VenafiTPP{
+				URL:                  "https://tpp.example.com/vedsdk",
+				AccessTokenSecretRef: &v1alpha1.LocalObjectReference{Name: "tpp-token"},
+			},
+		},
+		"missing credentials": {
+			tpp: &v1alpha1.VenafiTPP{
+				URL: "https://tpp.example.com/vedsdk",
+			},
+			errs: []*field.Error{
+				field.Required(fldPath.Child("credentialsRef", "name"), "one of credentialsRef or accessTokenSecretRef must be specified"),
+			},
+		},
+		"missing accessTokenSecretRef name": {
+			tpp: &v1alpha1.VenafiTPP{
+				URL:                  "https://tpp.example.com/vedsdk",
+				AccessTokenSecretRef: &v1alpha1.LocalObjectReference{},
+			},
+			errs: []*field.Error{
+				field.Required(fldPath.Child("accessTokenSecretRef", "name"), "secret name is required"),
+			},
+		},
+		"both credentialsRef and accessTokenSecretRef": {
+			tpp: &v1alpha1.VenafiTPP{
+				URL:                  "https://tpp.example.com/vedsdk",
+				CredentialsRef:       v1alpha1.LocalObjectReference{Name: "tpp-credentials"},
+				AccessTokenSecretRef: &v1alpha1.LocalObjectReference{Name: "tpp-token"},
+			},
+			errs: []*field.Error{
+				field.Forbidden(fldPath.Child("accessTokenSecretRef"), "may not be specified together with credentialsRef"),
+			},
+		},
+	}
+	for n, s := range scenarios {
+		t.Run(n, func(t *testing.T) {
+			errs := ValidateVenafiTPP(s.tpp, fldPath)
+			if len(errs) != len(s.errs) {
+				t.Errorf("Expected %v but got %v", s.errs, errs)
+				return
+			}
+			for i, e := range errs {
+				expectedErr := s.errs[i]
+				if !reflect.DeepEqual(e, expectedErr) {
+					t.Errorf("Expected %v but got %v", expectedErr, e)
+				}
+			}
+		})
+	}
+}
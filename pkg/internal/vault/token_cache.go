@@ -0,0 +1,154 @@
+/*
+Copyright 2019 The Jetstack cert-manager contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vault
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/utils/clock"
+
+	"github.com/jetstack/cert-manager/pkg/metrics"
+)
+
+const (
+	// renewAfter is the fraction of a cached token's TTL after which the
+	// token is renewed, or replaced if it cannot be renewed.
+	renewAfter = 2.0 / 3.0
+
+	operationLogin = "login"
+	operationRenew = "renew"
+)
+
+// loginFunc logs in to Vault and returns the issued token.
+type loginFunc func(client Client) (*token, error)
+
+// defaultTokenCache is shared by all clients built using New, so that Vault
+// issuers log in once and reuse their token across certificate requests.
+var defaultTokenCache = newTokenCache(clock.RealClock{})
+
+// tokenCache holds a Vault token for each issuer that authenticates by
+// logging in to Vault.
+type tokenCache struct {
+	clock clock.Clock
+
+	lock    sync.Mutex
+	entries map[string]*cachedToken
+}
+
+type cachedToken struct {
+	// lock is held while logging in or renewing, so that concurrent callers
+	// for the same issuer wait for and then share the resulting token.
+	lock sync.Mutex
+
+	// fingerprint identifies the issuer configuration and credentials that
+	// the token was issued for.
+	fingerprint string
+	token       *token
+	// refreshed is the time at which the token was issued or last renewed.
+	refreshed time.Time
+}
+
+func newTokenCache(clock clock.Clock) *tokenCache {
+	return &tokenCache{
+		clock:   clock,
+		entries: make(map[string]*cachedToken),
+	}
+}
+
+// token returns a Vault token for the issuer identified by key. A cached token
+// is returned if it was issued for the given fingerprint and is not due for
+// renewal. Tokens due for renewal are renewed if possible, and otherwise a new
+// token is obtained by calling login. A nil cache always calls login.
+func (c *tokenCache) token(key, fingerprint string, client Client, login loginFunc) (string, error) {
+	if c == nil {
+		tok, err := requestLogin(client, login)
+		if err != nil {
+			return "", err
+		}
+		return tok.id, nil
+	}
+
+	entry := c.entry(key)
+	entry.lock.Lock()
+	defer entry.lock.Unlock()
+
+	now := c.clock.Now()
+	if entry.token != nil && entry.fingerprint == fingerprint {
+		tok := entry.token
+		switch {
+		// tokens without a TTL, such as root tokens, never expire.
+		case tok.ttl == 0:
+			return tok.id, nil
+		case now.Before(entry.refreshed.Add(time.Duration(float64(tok.ttl) * renewAfter))):
+			return tok.id, nil
+		case tok.renewable && now.Before(entry.refreshed.Add(tok.ttl)):
+			renewed, err := renewToken(client, tok.id)
+			metrics.Default.IncrementVaultTokenRequest(operationRenew, err)
+			// a renewed token without a TTL has reached its maximum TTL, so
+			// fall through and log in again.
+			if err == nil && renewed.ttl > 0 {
+				entry.token = &token{id: tok.id, ttl: renewed.ttl, renewable: renewed.renewable}
+				entry.refreshed = now
+				return tok.id, nil
+			}
+		}
+	}
+
+	tok, err := requestLogin(client, login)
+	if err != nil {
+		entry.token = nil
+		return "", err
+	}
+
+	entry.fingerprint = fingerprint
+	entry.token = tok
+	entry.refreshed = now
+
+	return tok.id, nil
+}
+
+// invalidate discards the token cached for the issuer identified by key.
+func (c *tokenCache) invalidate(key string) {
+	if c == nil {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	delete(c.entries, key)
+}
+
+func (c *tokenCache) entry(key string) *cachedToken {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		entry = &cachedToken{}
+		c.entries[key] = entry
+	}
+
+	return entry
+}
+
+func requestLogin(client Client, login loginFunc) (*token, error) {
+	tok, err := login(client)
+	metrics.Default.IncrementVaultTokenRequest(operationLogin, err)
+	return tok, err
+}
@@ -0,0 +1,226 @@
+/*
+Copyright 2019 The Jetstack cert-manager contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vault
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	vault "github.com/hashicorp/vault/api"
+	fakeclock "k8s.io/utils/clock/testing"
+)
+
+func TestTokenCache(t *testing.T) {
+	type step struct {
+		// advance is how far to move the clock before requesting a token
+		advance     time.Duration
+		fingerprint string
+		invalidate  bool
+
+		expectedToken    string
+		expectedErr      bool
+		expectedLogins   int
+		expectedRenewals int
+	}
+
+	tests := map[string]struct {
+		ttl        time.Duration
+		renewable  bool
+		renewTTL   time.Duration
+		renewFails bool
+		loginFails bool
+		steps      []step
+	}{
+		"should reuse a token until it is due for renewal": {
+			ttl: time.Hour,
+			steps: []step{
+				{expectedToken: "token-1", expectedLogins: 1},
+				{advance: 30 * time.Minute, expectedToken: "token-1", expectedLogins: 1},
+			},
+		},
+		"should never refresh a token without a TTL": {
+			steps: []step{
+				{expectedToken: "token-1", expectedLogins: 1},
+				{advance: 24 * time.Hour, expectedToken: "token-1", expectedLogins: 1},
+			},
+		},
+		"should renew a renewable token that is due for renewal": {
+			ttl:       time.Hour,
+			renewable: true,
+			renewTTL:  time.Hour,
+			steps: []step{
+				{expectedToken: "token-1", expectedLogins: 1},
+				{advance: 45 * time.Minute, expectedToken: "token-1", expectedLogins: 1, expectedRenewals: 1},
+				{advance: 30 * time.Minute, expectedToken: "token-1", expectedLogins: 1, expectedRenewals: 1},
+				{advance: 15 * time.Minute, expectedToken: "token-1", expectedLogins: 1, expectedRenewals: 2},
+			},
+		},
+		"should log in again once a renewed token reaches its maximum TTL": {
+			ttl:       time.Hour,
+			renewable: true,
+			steps: []step{
+				{expectedToken: "token-1", expectedLogins: 1},
+				{advance: 45 * time.Minute, expectedToken: "token-2", expectedLogins: 2, expectedRenewals: 1},
+			},
+		},
+		"should log in again if renewing a token fails": {
+			ttl:        time.Hour,
+			renewable:  true,
+			renewFails: true,
+			steps: []step{
+				{expectedToken: "token-1", expectedLogins: 1},
+				{advance: 45 * time.Minute, expectedToken: "token-2", expectedLogins: 2, expectedRenewals: 1},
+			},
+		},
+		"should log in again when a non-renewable token is due for renewal": {
+			ttl: time.Hour,
+			steps: []step{
+				{expectedToken: "token-1", expectedLogins: 1},
+				{advance: 45 * time.Minute, expectedToken: "token-2", expectedLogins: 2},
+			},
+		},
+		"should log in again instead of renewing an expired token": {
+			ttl:       time.Hour,
+			renewable: true,
+			renewTTL:  time.Hour,
+			steps: []step{
+				{expectedToken: "token-1", expectedLogins: 1},
+				{advance: 2 * time.Hour, expectedToken: "token-2", expectedLogins: 2},
+			},
+		},
+		"should log in again when the fingerprint changes": {
+			ttl: time.Hour,
+			steps: []step{
+				{fingerprint: "a", expectedToken: "token-1", expectedLogins: 1},
+				{fingerprint: "b", expectedToken: "token-2", expectedLogins: 2},
+				{fingerprint: "b", expectedToken: "token-2", expectedLogins: 2},
+			},
+		},
+		"should log in again after the token is invalidated": {
+			ttl: time.Hour,
+			steps: []step{
+				{expectedToken: "token-1", expectedLogins: 1},
+				{invalidate: true, expectedToken: "token-2", expectedLogins: 2},
+			},
+		},
+		"should not cache a failed login": {
+			ttl:        time.Hour,
+			loginFails: true,
+			steps: []step{
+				{expectedErr: true, expectedLogins: 1},
+				{expectedErr: true, expectedLogins: 2},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var logins, renewals int
+
+			// the fake Vault server implements the renew-self endpoint of the
+			// token auth method.
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPut || r.URL.Path != "/v1/auth/token/renew-self" {
+					w.WriteHeader(http.StatusNotFound)
+					fmt.Fprint(w, `{"errors":["no handler for route"]}`)
+					return
+				}
+
+				renewals++
+				if test.renewFails {
+					w.WriteHeader(http.StatusForbidden)
+					fmt.Fprint(w, `{"errors":["permission denied"]}`)
+					return
+				}
+
+				fmt.Fprintf(w, `{"auth":{"client_token":%q,"lease_duration":%d,"renewable":true}}`,
+					r.Header.Get("X-Vault-Token"), int(test.renewTTL.Seconds()))
+			}))
+			defer server.Close()
+
+			cfg := vault.DefaultConfig()
+			cfg.Address = server.URL
+			client, err := vault.NewClient(cfg)
+			if err != nil {
+				t.Fatalf("failed to build vault client: %v", err)
+			}
+
+			login := func(Client) (*token, error) {
+				logins++
+				if test.loginFails {
+					return nil, errors.New("permission denied")
+				}
+				return &token{
+					id:        fmt.Sprintf("token-%d", logins),
+					ttl:       test.ttl,
+					renewable: test.renewable,
+				}, nil
+			}
+
+			clock := fakeclock.NewFakeClock(time.Now())
+			cache := newTokenCache(clock)
+
+			for i, step := range test.steps {
+				clock.Step(step.advance)
+				if step.invalidate {
+					cache.invalidate("test-namespace/vault-issuer")
+				}
+
+				tok, err := cache.token("test-namespace/vault-issuer", step.fingerprint, client, login)
+				if step.expectedErr != (err != nil) {
+					t.Errorf("step %d: expected error %t, got: %v", i, step.expectedErr, err)
+				}
+				if tok != step.expectedToken {
+					t.Errorf("step %d: expected token %q, got %q", i, step.expectedToken, tok)
+				}
+				if logins != step.expectedLogins {
+					t.Errorf("step %d: expected %d logins, got %d", i, step.expectedLogins, logins)
+				}
+				if renewals != step.expectedRenewals {
+					t.Errorf("step %d: expected %d renewals, got %d", i, step.expectedRenewals, renewals)
+				}
+			}
+		})
+	}
+}
+
+func TestNilTokenCache(t *testing.T) {
+	var cache *tokenCache
+	logins := 0
+	login := func(Client) (*token, error) {
+		logins++
+		return &token{id: "my-token", ttl: time.Hour}, nil
+	}
+
+	for i := 0; i < 2; i++ {
+		tok, err := cache.token("test-namespace/vault-issuer", "", nil, login)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if tok != "my-token" {
+			t.Errorf("expected token %q, got %q", "my-token", tok)
+		}
+	}
+
+	if logins != 2 {
+		t.Errorf("expected a nil cache to log in every time, got %d logins", logins)
+	}
+}
@@ -17,7 +17,12 @@ limitations under the License.
 package vault
 
 import (
+	"crypto/sha256"
+	"crypto/tls"
 	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
@@ -27,6 +32,7 @@ import (
 
 	vault "github.com/hashicorp/vault/api"
 	"github.com/hashicorp/vault/sdk/helper/certutil"
+	corev1 "k8s.io/api/core/v1"
 	corelisters "k8s.io/client-go/listers/core/v1"
 
 	"github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha1"
@@ -56,15 +62,28 @@ type Vault struct {
 	issuer        v1alpha1.GenericIssuer
 	namespace     string
 
+	// tokens caches the tokens obtained by logging in to Vault. If nil, the
+	// client logs in every time it is built.
+	tokens *tokenCache
+
 	client Client
 }
 
+// token is a Vault token issued by logging in to an auth method or by
+// renewing an existing token.
+type token struct {
+	id        string
+	ttl       time.Duration
+	renewable bool
+}
+
 func New(namespace string, secretsLister corelisters.SecretLister,
 	issuer v1alpha1.GenericIssuer) (Interface, error) {
 	v := &Vault{
 		secretsLister: secretsLister,
 		namespace:     namespace,
 		issuer:        issuer,
+		tokens:        defaultTokenCache,
 	}
 
 	cfg, err := v.newConfig()
@@ -77,6 +96,10 @@ func New(namespace string, secretsLister corelisters.SecretLister,
 		return nil, fmt.Errorf("error initializing Vault client: %s", err.Error())
 	}
 
+	if namespace := issuer.GetSpec().Vault.Namespace; namespace != "" {
+		client.SetNamespace(namespace)
+	}
+
 	if err := v.setToken(client); err != nil {
 		return nil, err
 	}
@@ -92,17 +115,13 @@ func (v *Vault) Sign(csrPEM []byte, duration time.Duration) (cert []byte, ca []b
 		return nil, nil, fmt.Errorf("faild to decode CSR for signing: %s", err)
 	}
 
-	parameters := map[string]string{
-		"common_name": csr.Subject.CommonName,
-		"alt_names":   strings.Join(csr.DNSNames, ","),
-		"ip_sans":     strings.Join(pki.IPAddressesToString(csr.IPAddresses), ","),
-		"ttl":         duration.String(),
-		"csr":         string(csrPEM),
-
-		"exclude_cn_from_sans": "true",
+	mode, signPath := signEndpoint(v.issuer.GetSpec().Vault, csr)
+	parameters, err := signParameters(mode, csr, csrPEM, duration)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	url := path.Join("/v1", v.issuer.GetSpec().Vault.Path)
+	url := path.Join("/v1", signPath)
 
 	request := v.client.NewRequest("POST", url)
 
@@ -112,6 +131,11 @@ func (v *Vault) Sign(csrPEM []byte, duration time.Duration) (cert []byte, ca []b
 
 	resp, err := v.client.RawRequest(request)
 	if err != nil {
+		// The cached token may have been revoked, so log in again the next
+		// time a client is built for this issuer.
+		if respErr, ok := err.(*vault.ResponseError); ok && respErr.StatusCode == http.StatusForbidden {
+			v.tokens.invalidate(issuerKey(v.issuer))
+		}
 		return nil, nil, fmt.Errorf("failed to sign certificate by vault: %s", err)
 	}
 
@@ -135,12 +159,171 @@ func (v *Vault) Sign(csrPEM []byte, duration time.Duration) (cert []byte, ca []b
 
 	var caPem []byte = nil
 	if len(bundle.CAChain) > 0 {
-		caPem = []byte(bundle.CAChain[0])
+		caPem = []byte(strings.Join(bundle.CAChain, "\n"))
 	}
 
 	return []byte(bundle.ToPEMBundle()), caPem, nil
 }
 
+// signEndpoint returns the sign mode and path of the Vault PKI endpoint used
+// to sign the given CSR.
+// The sign endpoint can only set the common name and serial number of the
+// subject, so CSRs requesting any other subject fields are signed using the
+// sign-verbatim endpoint of the same role instead, if it can be derived from
+// the configured path.
+func signEndpoint(spec *v1alpha1.VaultIssuer, csr *x509.CertificateRequest) (v1alpha1.VaultSignMode, string) {
+	if spec.SignMode == v1alpha1.VaultSignModeSignVerbatim || !requestsSubjectFields(csr.Subject) {
+		return spec.SignMode, spec.Path
+	}
+	if verbatimPath, ok := SignVerbatimPath(spec.Path); ok {
+		return v1alpha1.VaultSignModeSignVerbatim, verbatimPath
+	}
+	return spec.SignMode, spec.Path
+}
+
+// SignVerbatimPath returns the path of the sign-verbatim endpoint of the role
+// whose sign endpoint is at signPath, or false if signPath does not
+// reference a sign endpoint.
+func SignVerbatimPath(signPath string) (string, bool) {
+	segments := strings.Split(signPath, "/")
+	// the role name follows the endpoint, so search from the end in case
+	// the secrets engine is mounted at a path containing 'sign'
+	for i := len(segments) - 2; i >= 0; i-- {
+		if segments[i] == "sign" {
+			segments[i] = "sign-verbatim"
+			return strings.Join(segments, "/"), true
+		}
+	}
+	return "", false
+}
+
+// requestsSubjectFields returns true if the subject sets any fields other
+// than the common name and serial number.
+func requestsSubjectFields(subject pkix.Name) bool {
+	return len(subject.Organization) != 0 || len(subject.OrganizationalUnit) != 0 ||
+		len(subject.Country) != 0 || len(subject.Locality) != 0 || len(subject.Province) != 0 ||
+		len(subject.StreetAddress) != 0 || len(subject.PostalCode) != 0
+}
+
+// signParameters returns the parameters sent to Vault to sign the given CSR
+// using the PKI endpoint selected by mode.
+func signParameters(mode v1alpha1.VaultSignMode, csr *x509.CertificateRequest, csrPEM []byte, duration time.Duration) (map[string]string, error) {
+	otherSANs, err := otherSANsForCSR(csr)
+	if err != nil {
+		return nil, err
+	}
+
+	parameters := map[string]string{
+		"other_sans": strings.Join(otherSANs, ","),
+		"ttl":        duration.String(),
+		"csr":        string(csrPEM),
+	}
+
+	if mode == v1alpha1.VaultSignModeSignVerbatim {
+		// The sign-verbatim endpoint takes the subject and subject
+		// alternative names from the CSR, but not its key usages.
+		ku, eku, err := pki.KeyUsagesForCSR(csr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read key usages from CSR: %s", err)
+		}
+		if names := keyUsageNames(ku); len(names) > 0 {
+			parameters["key_usage"] = strings.Join(names, ",")
+		}
+		if names := extKeyUsageNames(eku); len(names) > 0 {
+			parameters["ext_key_usage"] = strings.Join(names, ",")
+		}
+
+		return parameters, nil
+	}
+
+	// The key usages requested in the CSR cannot be set when using the sign
+	// endpoint and are instead taken from the Vault role. Other subject
+	// fields are only requested from the sign-verbatim endpoint.
+	parameters["common_name"] = csr.Subject.CommonName
+	parameters["serial_number"] = csr.Subject.SerialNumber
+	parameters["alt_names"] = strings.Join(append(csr.DNSNames, csr.EmailAddresses...), ",")
+	parameters["ip_sans"] = strings.Join(pki.IPAddressesToString(csr.IPAddresses), ",")
+	parameters["uri_sans"] = strings.Join(pki.URIsToString(csr.URIs), ",")
+	parameters["exclude_cn_from_sans"] = "true"
+
+	return parameters, nil
+}
+
+// otherSANsForCSR returns the otherName subject alternative names in the CSR
+// in the '<oid>;UTF8:<value>' format used by Vault's other_sans parameter.
+func otherSANsForCSR(csr *x509.CertificateRequest) ([]string, error) {
+	otherNames, err := pki.OtherNamesForCSR(csr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read otherName subject alternative names from CSR: %s", err)
+	}
+
+	var otherSANs []string
+	for _, on := range otherNames {
+		// other_sans is a comma separated list, so values containing a
+		// comma cannot be passed to Vault.
+		if strings.Contains(on.Value, ",") {
+			return nil, fmt.Errorf("otherName subject alternative name %q cannot contain a comma", on.Value)
+		}
+		otherSANs = append(otherSANs, fmt.Sprintf("%s;UTF8:%s", on.TypeID, on.Value))
+	}
+
+	return otherSANs, nil
+}
+
+// vaultKeyUsages maps key usages to the names accepted by Vault's key_usage
+// parameter.
+var vaultKeyUsages = []struct {
+	usage x509.KeyUsage
+	name  string
+}{
+	{x509.KeyUsageDigitalSignature, "DigitalSignature"},
+	{x509.KeyUsageContentCommitment, "ContentCommitment"},
+	{x509.KeyUsageKeyEncipherment, "KeyEncipherment"},
+	{x509.KeyUsageDataEncipherment, "DataEncipherment"},
+	{x509.KeyUsageKeyAgreement, "KeyAgreement"},
+	{x509.KeyUsageCertSign, "CertSign"},
+	{x509.KeyUsageCRLSign, "CRLSign"},
+	{x509.KeyUsageEncipherOnly, "EncipherOnly"},
+	{x509.KeyUsageDecipherOnly, "DecipherOnly"},
+}
+
+// vaultExtKeyUsages maps extended key usages to the names accepted by Vault's
+// ext_key_usage parameter.
+var vaultExtKeyUsages = map[x509.ExtKeyUsage]string{
+	x509.ExtKeyUsageAny:                        "Any",
+	x509.ExtKeyUsageServerAuth:                 "ServerAuth",
+	x509.ExtKeyUsageClientAuth:                 "ClientAuth",
+	x509.ExtKeyUsageCodeSigning:                "CodeSigning",
+	x509.ExtKeyUsageEmailProtection:            "EmailProtection",
+	x509.ExtKeyUsageIPSECEndSystem:             "IPSECEndSystem",
+	x509.ExtKeyUsageIPSECTunnel:                "IPSECTunnel",
+	x509.ExtKeyUsageIPSECUser:                  "IPSECUser",
+	x509.ExtKeyUsageTimeStamping:               "TimeStamping",
+	x509.ExtKeyUsageOCSPSigning:                "OCSPSigning",
+	x509.ExtKeyUsageMicrosoftServerGatedCrypto: "MicrosoftServerGatedCrypto",
+	x509.ExtKeyUsageNetscapeServerGatedCrypto:  "NetscapeServerGatedCrypto",
+}
+
+func keyUsageNames(ku x509.KeyUsage) []string {
+	var names []string
+	for _, u := range vaultKeyUsages {
+		if ku&u.usage != 0 {
+			names = append(names, u.name)
+		}
+	}
+	return names
+}
+
+func extKeyUsageNames(eku []x509.ExtKeyUsage) []string {
+	var names []string
+	for _, u := range eku {
+		if name, ok := vaultExtKeyUsages[u]; ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
 func (v *Vault) setToken(client Client) error {
 	tokenRef := v.issuer.GetSpec().Vault.Auth.TokenSecretRef
 	if tokenRef.Name != "" {
@@ -153,25 +336,111 @@ func (v *Vault) setToken(client Client) error {
 		return nil
 	}
 
-	appRole := v.issuer.GetSpec().Vault.Auth.AppRole
-	if appRole.RoleId != "" {
-		token, err := v.requestTokenWithAppRoleRef(client, &appRole)
+	login := v.loginFunc()
+	if login == nil {
+		return fmt.Errorf("error initializing Vault client: tokenSecretRef, appRoleSecretRef, kubernetes or clientCertificate auth not set")
+	}
+
+	var fingerprint string
+	if v.tokens != nil {
+		var err error
+		fingerprint, err = v.credentialsFingerprint()
 		if err != nil {
 			return err
 		}
-		client.SetToken(token)
+	}
 
-		return nil
+	token, err := v.tokens.token(issuerKey(v.issuer), fingerprint, client, login)
+	if err != nil {
+		return err
 	}
+	client.SetToken(token)
+
+	return nil
+}
+
+// loginFunc returns a function that logs in to Vault using the auth method
+// configured on the issuer, or nil if no such auth method is configured.
+func (v *Vault) loginFunc() loginFunc {
+	auth := v.issuer.GetSpec().Vault.Auth
+
+	switch {
+	case auth.AppRole.RoleId != "":
+		return func(client Client) (*token, error) {
+			return v.requestTokenWithAppRoleRef(client, &auth.AppRole)
+		}
+	case auth.Kubernetes != nil:
+		return func(client Client) (*token, error) {
+			return v.requestTokenWithKubernetesAuth(client, auth.Kubernetes)
+		}
+	case auth.ClientCertificate != nil:
+		return func(client Client) (*token, error) {
+			return v.requestTokenWithClientCertificate(client, auth.ClientCertificate)
+		}
+	}
+
+	return nil
+}
+
+// credentialsFingerprint returns a digest of the issuer's Vault configuration
+// and of the versions of the Secrets holding its login credentials, so that a
+// cached token is discarded if either of them changes.
+func (v *Vault) credentialsFingerprint() (string, error) {
+	vaultIssuer := v.issuer.GetSpec().Vault
+
+	h := sha256.New()
+	if err := json.NewEncoder(h).Encode(vaultIssuer); err != nil {
+		return "", fmt.Errorf("error encoding Vault issuer config: %s", err)
+	}
+
+	var secretNames []string
+	switch {
+	case vaultIssuer.Auth.AppRole.RoleId != "":
+		secretNames = append(secretNames, vaultIssuer.Auth.AppRole.SecretRef.Name)
+	case vaultIssuer.Auth.Kubernetes != nil:
+		secretNames = append(secretNames, vaultIssuer.Auth.Kubernetes.SecretRef.Name)
+	case vaultIssuer.Auth.ClientCertificate != nil:
+		secretNames = append(secretNames, vaultIssuer.Auth.ClientCertificate.SecretName)
+	}
+
+	for _, name := range secretNames {
+		secret, err := v.secretsLister.Secrets(v.namespace).Get(name)
+		if err != nil {
+			return "", err
+		}
+
+		fmt.Fprintf(h, "%s=%s\n", name, secret.ResourceVersion)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
 
-	return fmt.Errorf("error initializing Vault client tokenSecretRef or appRoleSecretRef not set")
+// issuerKey returns the key identifying the issuer in the token cache.
+func issuerKey(issuer v1alpha1.GenericIssuer) string {
+	meta := issuer.GetObjectMeta()
+	return meta.Namespace + "/" + meta.Name
 }
 
 func (v *Vault) newConfig() (*vault.Config, error) {
 	cfg := vault.DefaultConfig()
 	cfg.Address = v.issuer.GetSpec().Vault.Server
 
-	certs := v.issuer.GetSpec().Vault.CABundle
+	tlsConfig := cfg.HttpClient.Transport.(*http.Transport).TLSClientConfig
+
+	if clientCertificateAuth := v.issuer.GetSpec().Vault.Auth.ClientCertificate; clientCertificateAuth != nil {
+		cert, err := v.clientCertificateRef(clientCertificateAuth.SecretName)
+		if err != nil {
+			return nil, err
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	certs, err := v.caBundle()
+	if err != nil {
+		return nil, err
+	}
+
 	if len(certs) == 0 {
 		return cfg, nil
 	}
@@ -182,11 +451,67 @@ func (v *Vault) newConfig() (*vault.Config, error) {
 		return nil, fmt.Errorf("error loading Vault CA bundle")
 	}
 
-	cfg.HttpClient.Transport.(*http.Transport).TLSClientConfig.RootCAs = caCertPool
+	tlsConfig.RootCAs = caCertPool
 
 	return cfg, nil
 }
 
+// caBundle returns the PEM encoded CA bundle used to validate the Vault
+// server, read either inline from the issuer or from the referenced Secret.
+func (v *Vault) caBundle() ([]byte, error) {
+	vaultIssuer := v.issuer.GetSpec().Vault
+
+	ref := vaultIssuer.CABundleSecretRef
+	if ref == nil {
+		return vaultIssuer.CABundle, nil
+	}
+
+	if len(vaultIssuer.CABundle) > 0 {
+		return nil, fmt.Errorf("only one of caBundle or caBundleSecretRef may be set")
+	}
+
+	secret, err := v.secretsLister.Secrets(v.namespace).Get(ref.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	key := ref.Key
+	if key == "" {
+		key = "ca.crt"
+	}
+
+	certs, ok := secret.Data[key]
+	if !ok {
+		return nil, fmt.Errorf("no data for %q in secret '%s/%s'", key, v.namespace, ref.Name)
+	}
+
+	return certs, nil
+}
+
+func (v *Vault) clientCertificateRef(name string) (tls.Certificate, error) {
+	secret, err := v.secretsLister.Secrets(v.namespace).Get(name)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	certPEM, ok := secret.Data[corev1.TLSCertKey]
+	if !ok {
+		return tls.Certificate{}, fmt.Errorf("no data for %q in secret '%s/%s'", corev1.TLSCertKey, v.namespace, name)
+	}
+
+	keyPEM, ok := secret.Data[corev1.TLSPrivateKeyKey]
+	if !ok {
+		return tls.Certificate{}, fmt.Errorf("no data for %q in secret '%s/%s'", corev1.TLSPrivateKeyKey, v.namespace, name)
+	}
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("error loading Vault client certificate from secret '%s/%s': %s", v.namespace, name, err)
+	}
+
+	return cert, nil
+}
+
 func (v *Vault) tokenRef(name, namespace, key string) (string, error) {
 	secret, err := v.secretsLister.Secrets(namespace).Get(name)
 	if err != nil {
@@ -229,10 +554,10 @@ func (v *Vault) appRoleRef(appRole *v1alpha1.VaultAppRole) (roleId, secretId str
 	return roleId, secretId, nil
 }
 
-func (v *Vault) requestTokenWithAppRoleRef(client Client, appRole *v1alpha1.VaultAppRole) (string, error) {
+func (v *Vault) requestTokenWithAppRoleRef(client Client, appRole *v1alpha1.VaultAppRole) (*token, error) {
 	roleId, secretId, err := v.appRoleRef(appRole)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	parameters := map[string]string{
@@ -245,38 +570,110 @@ func (v *Vault) requestTokenWithAppRoleRef(client Client, appRole *v1alpha1.Vaul
 		authPath = "approle"
 	}
 
+	return requestToken(client, authPath, parameters)
+}
+
+func (v *Vault) requestTokenWithKubernetesAuth(client Client, kubernetesAuth *v1alpha1.VaultKubernetesAuth) (*token, error) {
+	jwt, err := v.tokenRef(kubernetesAuth.SecretRef.Name, v.namespace, kubernetesAuth.SecretRef.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	parameters := map[string]string{
+		"role": kubernetesAuth.Role,
+		"jwt":  jwt,
+	}
+
+	authPath := kubernetesAuth.Path
+	if authPath == "" {
+		authPath = "kubernetes"
+	}
+
+	return requestToken(client, authPath, parameters)
+}
+
+// requestTokenWithClientCertificate logs in using the TLS certificate auth
+// method. The client certificate itself is presented during the TLS handshake,
+// having been configured on the client's transport by newConfig.
+func (v *Vault) requestTokenWithClientCertificate(client Client, clientCertificateAuth *v1alpha1.VaultClientCertificateAuth) (*token, error) {
+	parameters := map[string]string{}
+	if clientCertificateAuth.Name != "" {
+		parameters["name"] = clientCertificateAuth.Name
+	}
+
+	authPath := clientCertificateAuth.Path
+	if authPath == "" {
+		authPath = "cert"
+	}
+
+	return requestToken(client, authPath, parameters)
+}
+
+// requestToken logs in to the auth method mounted at authPath using the given
+// parameters, and returns the issued Vault token.
+func requestToken(client Client, authPath string, parameters map[string]string) (*token, error) {
 	url := path.Join("/v1", "auth", authPath, "login")
 
 	request := client.NewRequest("POST", url)
 
-	err = request.SetJSONBody(parameters)
+	err := request.SetJSONBody(parameters)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding Vault parameters: %s", err.Error())
+	}
+
+	resp, err := client.RawRequest(request)
 	if err != nil {
-		return "", fmt.Errorf("error encoding Vault parameters: %s", err.Error())
+		return nil, fmt.Errorf("error logging in to Vault server: %s", err.Error())
 	}
 
+	defer resp.Body.Close()
+
+	return decodeToken(resp)
+}
+
+// renewToken renews the given token using the token auth method's renew-self
+// endpoint, and returns the renewed Vault token.
+func renewToken(client Client, id string) (*token, error) {
+	request := client.NewRequest("PUT", "/v1/auth/token/renew-self")
+	request.ClientToken = id
+
 	resp, err := client.RawRequest(request)
 	if err != nil {
-		return "", fmt.Errorf("error logging in to Vault server: %s", err.Error())
+		return nil, fmt.Errorf("error renewing Vault token: %s", err.Error())
 	}
 
 	defer resp.Body.Close()
 
+	return decodeToken(resp)
+}
+
+func decodeToken(resp *vault.Response) (*token, error) {
 	vaultResult := vault.Secret{}
-	resp.DecodeJSON(&vaultResult)
+	err := resp.DecodeJSON(&vaultResult)
 	if err != nil {
-		return "", fmt.Errorf("unable to decode JSON payload: %s", err.Error())
+		return nil, fmt.Errorf("unable to decode JSON payload: %s", err.Error())
 	}
 
-	token, err := vaultResult.TokenID()
+	id, err := vaultResult.TokenID()
 	if err != nil {
-		return "", fmt.Errorf("unable to read token: %s", err.Error())
+		return nil, fmt.Errorf("unable to read token: %s", err.Error())
 	}
 
-	if token == "" {
-		return "", errors.New("no token returned")
+	if id == "" {
+		return nil, errors.New("no token returned")
 	}
 
-	return token, nil
+	ttl, err := vaultResult.TokenTTL()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read token TTL: %s", err.Error())
+	}
+
+	renewable, err := vaultResult.TokenIsRenewable()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read token renewability: %s", err.Error())
+	}
+
+	return &token{id: id, ttl: ttl, renewable: renewable}, nil
 }
 
 func (v *Vault) Sys() *vault.Sys {
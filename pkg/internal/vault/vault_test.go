@@ -21,6 +21,7 @@ import (
 	"crypto"
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/tls"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/asn1"
@@ -29,6 +30,8 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
+	"path"
 	"reflect"
 	"strings"
 	"testing"
@@ -97,6 +100,25 @@ func generateCSR(t *testing.T, secretKey crypto.Signer) []byte {
 	return csr
 }
 
+// generateClientCertificate returns a PEM encoded self signed certificate and
+// private key suitable for use as a TLS client certificate.
+func generateClientCertificate(t *testing.T) (certPEM, keyPEM []byte) {
+	privatekey := generateRSAPrivateKey(t)
+
+	template, err := pki.GenerateTemplateFromCSRPEMWithUsages(generateCSR(t, privatekey), time.Hour, false,
+		x509.KeyUsageDigitalSignature, []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth})
+	if err != nil {
+		t.Fatalf("failed to generate certificate template: %s", err)
+	}
+
+	certPEM, _, err = pki.SignCertificate(template, template, privatekey.Public(), privatekey)
+	if err != nil {
+		t.Fatalf("failed to sign client certificate: %s", err)
+	}
+
+	return certPEM, pki.EncodePKCS1PrivateKey(privatekey)
+}
+
 type testSignT struct {
 	issuer     *v1alpha1.Issuer
 	fakeLister *listers.FakeSecretLister
@@ -213,7 +235,7 @@ func TestSetToken(t *testing.T) {
 			fakeClient:    vaultfake.NewFakeClient(),
 			expectedToken: "",
 			expectedErr: errors.New(
-				"error initializing Vault client tokenSecretRef or appRoleSecretRef not set"),
+				"error initializing Vault client: tokenSecretRef, appRoleSecretRef, kubernetes or clientCertificate auth not set"),
 		},
 
 		"if token secret ref is set but secret doesn't exist should error": {
@@ -560,10 +582,32 @@ func TestTokenRef(t *testing.T) {
 type testNewConfigT struct {
 	expectedErr error
 	issuer      *v1alpha1.Issuer
+	fakeLister  *listers.FakeSecretLister
 	checkFunc   func(cfg *vault.Config) error
 }
 
 func TestNewConfig(t *testing.T) {
+	clientCertPEM, clientKeyPEM := generateClientCertificate(t)
+
+	checkRootCAs := func(cfg *vault.Config) error {
+		testCA := x509.NewCertPool()
+		testCA.AppendCertsFromPEM([]byte(testCertBundle))
+		subs := cfg.HttpClient.Transport.(*http.Transport).TLSClientConfig.RootCAs.Subjects()
+
+		err := fmt.Errorf("got unexpected root CAs in config, exp=%s got=%s",
+			testCA.Subjects(), subs)
+		if len(subs) != len(testCA.Subjects()) {
+			return err
+		}
+		for i := range subs {
+			if !bytes.Equal(subs[i], testCA.Subjects()[i]) {
+				return err
+			}
+		}
+
+		return nil
+	}
+
 	tests := map[string]testNewConfigT{
 		"no CA bundle set in issuer should return nil": {
 			issuer: gen.Issuer("vault-issuer",
@@ -590,31 +634,124 @@ func TestNewConfig(t *testing.T) {
 				}),
 			),
 			expectedErr: nil,
+			checkFunc:   checkRootCAs,
+		},
+
+		"a cert bundle in a secret should be added to the config": {
+			issuer: gen.Issuer("vault-issuer",
+				gen.SetIssuerVault(v1alpha1.VaultIssuer{
+					CABundleSecretRef: &v1alpha1.SecretKeySelector{
+						LocalObjectReference: v1alpha1.LocalObjectReference{
+							Name: "vault-ca",
+						},
+					},
+				}),
+			),
+			fakeLister: listers.FakeSecretListerFrom(listers.NewFakeSecretLister(),
+				listers.SetFakeSecretNamespaceListerGet(&corev1.Secret{
+					Data: map[string][]byte{
+						"ca.crt": []byte(testCertBundle),
+					},
+				}, nil),
+			),
+			expectedErr: nil,
+			checkFunc:   checkRootCAs,
+		},
+
+		"a cert bundle secret missing the referenced key should error": {
+			issuer: gen.Issuer("vault-issuer",
+				gen.SetIssuerVault(v1alpha1.VaultIssuer{
+					CABundleSecretRef: &v1alpha1.SecretKeySelector{
+						LocalObjectReference: v1alpha1.LocalObjectReference{
+							Name: "vault-ca",
+						},
+						Key: "my-ca.crt",
+					},
+				}),
+			),
+			fakeLister: listers.FakeSecretListerFrom(listers.NewFakeSecretLister(),
+				listers.SetFakeSecretNamespaceListerGet(&corev1.Secret{
+					Data: map[string][]byte{
+						"ca.crt": []byte(testCertBundle),
+					},
+				}, nil),
+			),
+			expectedErr: errors.New(`no data for "my-ca.crt" in secret 'test-namespace/vault-ca'`),
+		},
+
+		"setting both an inline cert bundle and a secret reference should error": {
+			issuer: gen.Issuer("vault-issuer",
+				gen.SetIssuerVault(v1alpha1.VaultIssuer{
+					CABundle: []byte(testCertBundle),
+					CABundleSecretRef: &v1alpha1.SecretKeySelector{
+						LocalObjectReference: v1alpha1.LocalObjectReference{
+							Name: "vault-ca",
+						},
+					},
+				}),
+			),
+			expectedErr: errors.New("only one of caBundle or caBundleSecretRef may be set"),
+		},
+
+		"a client certificate should be added to the config": {
+			issuer: gen.Issuer("vault-issuer",
+				gen.SetIssuerVault(v1alpha1.VaultIssuer{
+					Auth: v1alpha1.VaultAuth{
+						ClientCertificate: &v1alpha1.VaultClientCertificateAuth{
+							SecretName: "vault-client",
+						},
+					},
+				}),
+			),
+			fakeLister: listers.FakeSecretListerFrom(listers.NewFakeSecretLister(),
+				listers.SetFakeSecretNamespaceListerGet(&corev1.Secret{
+					Data: map[string][]byte{
+						corev1.TLSCertKey:       clientCertPEM,
+						corev1.TLSPrivateKeyKey: clientKeyPEM,
+					},
+				}, nil),
+			),
+			expectedErr: nil,
 			checkFunc: func(cfg *vault.Config) error {
-				testCA := x509.NewCertPool()
-				testCA.AppendCertsFromPEM([]byte(testCertBundle))
-				subs := cfg.HttpClient.Transport.(*http.Transport).TLSClientConfig.RootCAs.Subjects()
-
-				err := fmt.Errorf("got unexpected root CAs in config, exp=%s got=%s",
-					testCA.Subjects(), subs)
-				if len(subs) != len(testCA.Subjects()) {
-					return err
+				certs := cfg.HttpClient.Transport.(*http.Transport).TLSClientConfig.Certificates
+				if len(certs) != 1 {
+					return fmt.Errorf("expected 1 client certificate in config, got %d", len(certs))
 				}
-				for i := range subs {
-					if !bytes.Equal(subs[i], testCA.Subjects()[i]) {
-						return err
-					}
+
+				block, _ := pem.Decode(clientCertPEM)
+				if !bytes.Equal(certs[0].Certificate[0], block.Bytes) {
+					return errors.New("got unexpected client certificate in config")
 				}
 
 				return nil
 			},
 		},
+
+		"a client certificate secret missing the private key should error": {
+			issuer: gen.Issuer("vault-issuer",
+				gen.SetIssuerVault(v1alpha1.VaultIssuer{
+					Auth: v1alpha1.VaultAuth{
+						ClientCertificate: &v1alpha1.VaultClientCertificateAuth{
+							SecretName: "vault-client",
+						},
+					},
+				}),
+			),
+			fakeLister: listers.FakeSecretListerFrom(listers.NewFakeSecretLister(),
+				listers.SetFakeSecretNamespaceListerGet(&corev1.Secret{
+					Data: map[string][]byte{
+						corev1.TLSCertKey: clientCertPEM,
+					},
+				}, nil),
+			),
+			expectedErr: errors.New(`no data for "tls.key" in secret 'test-namespace/vault-client'`),
+		},
 	}
 
 	for name, test := range tests {
 		v := &Vault{
 			namespace:     "test-namespace",
-			secretsLister: nil,
+			secretsLister: test.fakeLister,
 			issuer:        test.issuer,
 		}
 
@@ -742,15 +879,576 @@ func TestRequestTokenWithAppRoleRef(t *testing.T) {
 			issuer:        nil,
 		}
 
-		token, err := v.requestTokenWithAppRoleRef(test.client, test.appRole)
+		tok, err := v.requestTokenWithAppRoleRef(test.client, test.appRole)
 		if !reflect.DeepEqual(test.expectedErr, err) {
 			t.Errorf("%s: unexpected error, exp=%v got=%v",
 				name, test.expectedErr, err)
 		}
 
+		var token string
+		if tok != nil {
+			token = tok.id
+		}
 		if test.expectedToken != token {
 			t.Errorf("%s: got unexpected token, exp=%s got=%s",
 				name, test.expectedToken, token)
 		}
 	}
 }
+
+func TestRequestTokenWithKubernetesAuth(t *testing.T) {
+	// fakeVault implements the login endpoint of Vault's Kubernetes auth
+	// method, issuing a token if the given role and jwt are valid.
+	fakeVault := func(t *testing.T, mountPath string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost || r.URL.Path != path.Join("/v1/auth", mountPath, "login") {
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"errors":["no handler for route"]}`)
+				return
+			}
+
+			var params map[string]string
+			if err := jsonutil.DecodeJSONFromReader(r.Body, &params); err != nil {
+				t.Errorf("failed to decode login request: %v", err)
+			}
+			if params["role"] != "my-role" || params["jwt"] != "my-service-account-token" {
+				w.WriteHeader(http.StatusForbidden)
+				fmt.Fprint(w, `{"errors":["permission denied"]}`)
+				return
+			}
+
+			fmt.Fprint(w, `{"auth":{"client_token":"my-vault-token","lease_duration":3600,"renewable":true}}`)
+		}))
+	}
+
+	serviceAccountSecretLister := listers.FakeSecretListerFrom(listers.NewFakeSecretLister(),
+		listers.SetFakeSecretNamespaceListerGet(
+			&corev1.Secret{
+				Data: map[string][]byte{
+					"token": []byte("my-service-account-token"),
+				},
+			}, nil),
+	)
+
+	tests := map[string]struct {
+		mountPath      string
+		kubernetesAuth *v1alpha1.VaultKubernetesAuth
+		fakeLister     *listers.FakeSecretLister
+
+		expectedToken string
+		expectedErr   string
+	}{
+		"should log in using the default mount path": {
+			mountPath: "kubernetes",
+			kubernetesAuth: &v1alpha1.VaultKubernetesAuth{
+				Role: "my-role",
+				SecretRef: v1alpha1.SecretKeySelector{
+					LocalObjectReference: v1alpha1.LocalObjectReference{Name: "my-service-account-token"},
+				},
+			},
+			fakeLister:    serviceAccountSecretLister,
+			expectedToken: "my-vault-token",
+		},
+		"should log in using a custom mount path": {
+			mountPath: "my-cluster",
+			kubernetesAuth: &v1alpha1.VaultKubernetesAuth{
+				Path: "my-cluster",
+				Role: "my-role",
+				SecretRef: v1alpha1.SecretKeySelector{
+					LocalObjectReference: v1alpha1.LocalObjectReference{Name: "my-service-account-token"},
+				},
+			},
+			fakeLister:    serviceAccountSecretLister,
+			expectedToken: "my-vault-token",
+		},
+		"should error if the service account token secret does not exist": {
+			mountPath: "kubernetes",
+			kubernetesAuth: &v1alpha1.VaultKubernetesAuth{
+				Role: "my-role",
+				SecretRef: v1alpha1.SecretKeySelector{
+					LocalObjectReference: v1alpha1.LocalObjectReference{Name: "my-service-account-token"},
+				},
+			},
+			fakeLister: listers.FakeSecretListerFrom(listers.NewFakeSecretLister(),
+				listers.SetFakeSecretNamespaceListerGet(nil, errors.New("secret not found")),
+			),
+			expectedErr: "secret not found",
+		},
+		"should error if the service account token key does not exist": {
+			mountPath: "kubernetes",
+			kubernetesAuth: &v1alpha1.VaultKubernetesAuth{
+				Role: "my-role",
+				SecretRef: v1alpha1.SecretKeySelector{
+					LocalObjectReference: v1alpha1.LocalObjectReference{Name: "my-service-account-token"},
+					Key:                  "jwt",
+				},
+			},
+			fakeLister:  serviceAccountSecretLister,
+			expectedErr: `no data for "jwt" in secret 'my-service-account-token/test-namespace'`,
+		},
+		"should error if Vault rejects the login": {
+			mountPath: "kubernetes",
+			kubernetesAuth: &v1alpha1.VaultKubernetesAuth{
+				Role: "not-my-role",
+				SecretRef: v1alpha1.SecretKeySelector{
+					LocalObjectReference: v1alpha1.LocalObjectReference{Name: "my-service-account-token"},
+				},
+			},
+			fakeLister:  serviceAccountSecretLister,
+			expectedErr: "error logging in to Vault server: ",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			server := fakeVault(t, test.mountPath)
+			defer server.Close()
+
+			cfg := vault.DefaultConfig()
+			cfg.Address = server.URL
+			client, err := vault.NewClient(cfg)
+			if err != nil {
+				t.Fatalf("failed to build vault client: %v", err)
+			}
+
+			v := &Vault{
+				namespace:     "test-namespace",
+				secretsLister: test.fakeLister,
+			}
+
+			tok, err := v.requestTokenWithKubernetesAuth(client, test.kubernetesAuth)
+			if test.expectedErr == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if test.expectedErr != "" && (err == nil || !strings.HasPrefix(err.Error(), test.expectedErr)) {
+				t.Errorf("expected error starting with %q, got: %v", test.expectedErr, err)
+			}
+			var token string
+			if tok != nil {
+				token = tok.id
+			}
+			if token != test.expectedToken {
+				t.Errorf("expected token %q, got %q", test.expectedToken, token)
+			}
+		})
+	}
+}
+
+func TestRequestTokenWithClientCertificate(t *testing.T) {
+	clientCertPEM, clientKeyPEM := generateClientCertificate(t)
+
+	// fakeVault implements the login endpoint of Vault's TLS certificate auth
+	// method, issuing a token if a client certificate was presented and the
+	// given role name is valid.
+	fakeVault := func(t *testing.T, mountPath string) *httptest.Server {
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost || r.URL.Path != path.Join("/v1/auth", mountPath, "login") {
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"errors":["no handler for route"]}`)
+				return
+			}
+
+			var params map[string]string
+			if err := jsonutil.DecodeJSONFromReader(r.Body, &params); err != nil {
+				t.Errorf("failed to decode login request: %v", err)
+			}
+			if len(r.TLS.PeerCertificates) == 0 || (params["name"] != "" && params["name"] != "my-role") {
+				w.WriteHeader(http.StatusForbidden)
+				fmt.Fprint(w, `{"errors":["permission denied"]}`)
+				return
+			}
+
+			fmt.Fprint(w, `{"auth":{"client_token":"my-vault-token","lease_duration":3600,"renewable":true}}`)
+		}))
+		server.TLS = &tls.Config{ClientAuth: tls.RequestClientCert}
+		server.StartTLS()
+		return server
+	}
+
+	tests := map[string]struct {
+		mountPath             string
+		clientCertificateAuth *v1alpha1.VaultClientCertificateAuth
+
+		expectedToken string
+		expectedErr   string
+	}{
+		"should log in using the default mount path": {
+			mountPath: "cert",
+			clientCertificateAuth: &v1alpha1.VaultClientCertificateAuth{
+				SecretName: "vault-client",
+			},
+			expectedToken: "my-vault-token",
+		},
+		"should log in using a custom mount path and role name": {
+			mountPath: "my-cert",
+			clientCertificateAuth: &v1alpha1.VaultClientCertificateAuth{
+				Path:       "my-cert",
+				Name:       "my-role",
+				SecretName: "vault-client",
+			},
+			expectedToken: "my-vault-token",
+		},
+		"should error if Vault rejects the login": {
+			mountPath: "cert",
+			clientCertificateAuth: &v1alpha1.VaultClientCertificateAuth{
+				Name:       "not-my-role",
+				SecretName: "vault-client",
+			},
+			expectedErr: "error logging in to Vault server: ",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			server := fakeVault(t, test.mountPath)
+			defer server.Close()
+
+			caPEM, err := pki.EncodeX509(server.Certificate())
+			if err != nil {
+				t.Fatalf("failed to encode server certificate: %v", err)
+			}
+
+			v := &Vault{
+				namespace: "test-namespace",
+				secretsLister: listers.FakeSecretListerFrom(listers.NewFakeSecretLister(),
+					listers.SetFakeSecretNamespaceListerGet(&corev1.Secret{
+						Data: map[string][]byte{
+							corev1.TLSCertKey:       clientCertPEM,
+							corev1.TLSPrivateKeyKey: clientKeyPEM,
+						},
+					}, nil),
+				),
+				issuer: gen.Issuer("vault-issuer",
+					gen.SetIssuerVault(v1alpha1.VaultIssuer{
+						Server:   server.URL,
+						CABundle: caPEM,
+						Auth: v1alpha1.VaultAuth{
+							ClientCertificate: test.clientCertificateAuth,
+						},
+					}),
+				),
+			}
+
+			cfg, err := v.newConfig()
+			if err != nil {
+				t.Fatalf("failed to build vault config: %v", err)
+			}
+
+			client, err := vault.NewClient(cfg)
+			if err != nil {
+				t.Fatalf("failed to build vault client: %v", err)
+			}
+
+			tok, err := v.requestTokenWithClientCertificate(client, test.clientCertificateAuth)
+			if test.expectedErr == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if test.expectedErr != "" && (err == nil || !strings.HasPrefix(err.Error(), test.expectedErr)) {
+				t.Errorf("expected error starting with %q, got: %v", test.expectedErr, err)
+			}
+			var token string
+			if tok != nil {
+				token = tok.id
+			}
+			if token != test.expectedToken {
+				t.Errorf("expected token %q, got %q", test.expectedToken, token)
+			}
+		})
+	}
+}
+
+func TestCredentialsFingerprint(t *testing.T) {
+	kubernetesIssuer := func(role string) *v1alpha1.Issuer {
+		return gen.Issuer("vault-issuer",
+			gen.SetIssuerVault(v1alpha1.VaultIssuer{
+				Auth: v1alpha1.VaultAuth{
+					Kubernetes: &v1alpha1.VaultKubernetesAuth{
+						Role: role,
+						SecretRef: v1alpha1.SecretKeySelector{
+							LocalObjectReference: v1alpha1.LocalObjectReference{Name: "my-service-account-token"},
+						},
+					},
+				},
+			}),
+		)
+	}
+
+	secretLister := func(resourceVersion string) *listers.FakeSecretLister {
+		secret := &corev1.Secret{}
+		secret.ResourceVersion = resourceVersion
+		return listers.FakeSecretListerFrom(listers.NewFakeSecretLister(),
+			listers.SetFakeSecretNamespaceListerGet(secret, nil),
+		)
+	}
+
+	fingerprint := func(issuer *v1alpha1.Issuer, lister *listers.FakeSecretLister) string {
+		v := &Vault{
+			namespace:     "test-namespace",
+			secretsLister: lister,
+			issuer:        issuer,
+		}
+
+		fingerprint, err := v.credentialsFingerprint()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return fingerprint
+	}
+
+	base := fingerprint(kubernetesIssuer("my-role"), secretLister("1"))
+
+	if got := fingerprint(kubernetesIssuer("my-role"), secretLister("1")); got != base {
+		t.Errorf("expected fingerprint to be stable, got %q and %q", base, got)
+	}
+	if got := fingerprint(kubernetesIssuer("my-role"), secretLister("2")); got == base {
+		t.Errorf("expected fingerprint to change when the credentials secret changes")
+	}
+	if got := fingerprint(kubernetesIssuer("my-other-role"), secretLister("1")); got == base {
+		t.Errorf("expected fingerprint to change when the issuer config changes")
+	}
+}
+
+// generateCSRWithOtherName returns a PEM encoded CSR requesting the digital
+// signature, key encipherment and server auth key usages, with a DNS name and
+// an otherName subject alternative name.
+func generateCSRWithOtherName(t *testing.T, secretKey crypto.Signer, otherName string) []byte {
+	template, err := pki.GenerateCSR(&v1alpha1.Certificate{
+		Spec: v1alpha1.CertificateSpec{
+			CommonName: "example.com",
+			Usages: []v1alpha1.KeyUsage{
+				v1alpha1.UsageDigitalSignature,
+				v1alpha1.UsageKeyEncipherment,
+				v1alpha1.UsageServerAuth,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to generate CSR template: %s", err)
+	}
+
+	dnsName, err := asn1.MarshalWithParams("example.com", "tag:2,ia5")
+	if err != nil {
+		t.Fatalf("failed to marshal DNS name: %s", err)
+	}
+	value, err := asn1.MarshalWithParams(otherName, "utf8")
+	if err != nil {
+		t.Fatalf("failed to marshal otherName value: %s", err)
+	}
+	on, err := asn1.MarshalWithParams(struct {
+		TypeID asn1.ObjectIdentifier
+		Value  asn1.RawValue
+	}{
+		TypeID: asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 20, 2, 3},
+		Value:  asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: value},
+	}, "tag:0")
+	if err != nil {
+		t.Fatalf("failed to marshal otherName: %s", err)
+	}
+	sans, err := asn1.Marshal([]asn1.RawValue{{FullBytes: dnsName}, {FullBytes: on}})
+	if err != nil {
+		t.Fatalf("failed to marshal subject alternative names: %s", err)
+	}
+	template.ExtraExtensions = append(template.ExtraExtensions, pkix.Extension{
+		Id:    asn1.ObjectIdentifier{2, 5, 29, 17},
+		Value: sans,
+	})
+
+	der, err := pki.EncodeCSR(template, secretKey)
+	if err != nil {
+		t.Fatalf("failed to create CSR: %s", err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der})
+}
+
+func TestSignParameters(t *testing.T) {
+	privatekey := generateRSAPrivateKey(t)
+	csrPEM := generateCSRWithOtherName(t, privatekey, "user@example.com")
+
+	tests := map[string]struct {
+		mode   v1alpha1.VaultSignMode
+		csrPEM []byte
+
+		expectedParameters map[string]string
+		expectedErr        error
+	}{
+		"sign mode should send the common name and subject alternative names": {
+			csrPEM: csrPEM,
+			expectedParameters: map[string]string{
+				"common_name":          "example.com",
+				"serial_number":        "",
+				"alt_names":            "example.com",
+				"ip_sans":              "",
+				"uri_sans":             "",
+				"other_sans":           "1.3.6.1.4.1.311.20.2.3;UTF8:user@example.com",
+				"ttl":                  "1m0s",
+				"csr":                  string(csrPEM),
+				"exclude_cn_from_sans": "true",
+			},
+		},
+		"sign-verbatim mode should send the key usages requested in the CSR": {
+			mode:   v1alpha1.VaultSignModeSignVerbatim,
+			csrPEM: csrPEM,
+			expectedParameters: map[string]string{
+				"key_usage":     "DigitalSignature,KeyEncipherment",
+				"ext_key_usage": "ServerAuth",
+				"other_sans":    "1.3.6.1.4.1.311.20.2.3;UTF8:user@example.com",
+				"ttl":           "1m0s",
+				"csr":           string(csrPEM),
+			},
+		},
+		"an otherName containing a comma should error": {
+			csrPEM:      generateCSRWithOtherName(t, privatekey, "a,b"),
+			expectedErr: errors.New(`otherName subject alternative name "a,b" cannot contain a comma`),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			csr, err := pki.DecodeX509CertificateRequestBytes(test.csrPEM)
+			if err != nil {
+				t.Fatalf("failed to decode CSR: %s", err)
+			}
+
+			parameters, err := signParameters(test.mode, csr, test.csrPEM, time.Minute)
+			if !reflect.DeepEqual(test.expectedErr, err) {
+				t.Errorf("unexpected error, exp=%v got=%v", test.expectedErr, err)
+			}
+			if !reflect.DeepEqual(test.expectedParameters, parameters) {
+				t.Errorf("unexpected parameters, exp=%v got=%v", test.expectedParameters, parameters)
+			}
+		})
+	}
+}
+
+func TestSignEndpoint(t *testing.T) {
+	privatekey := generateRSAPrivateKey(t)
+	csrFor := func(subject pkix.Name) *x509.CertificateRequest {
+		der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{Subject: subject}, privatekey)
+		if err != nil {
+			t.Fatalf("failed to create CSR: %s", err)
+		}
+		csr, err := x509.ParseCertificateRequest(der)
+		if err != nil {
+			t.Fatalf("failed to parse CSR: %s", err)
+		}
+		return csr
+	}
+
+	tests := map[string]struct {
+		spec    v1alpha1.VaultIssuer
+		subject pkix.Name
+
+		expectedMode v1alpha1.VaultSignMode
+		expectedPath string
+	}{
+		"sign mode should use the sign endpoint for a common name and serial number": {
+			spec:         v1alpha1.VaultIssuer{Path: "pki/sign/my-role"},
+			subject:      pkix.Name{CommonName: "example.com", SerialNumber: "1234"},
+			expectedPath: "pki/sign/my-role",
+		},
+		"sign mode should use the sign-verbatim endpoint of the role for other subject fields": {
+			spec:         v1alpha1.VaultIssuer{Path: "sign/sign/my-role"},
+			subject:      pkix.Name{CommonName: "example.com", Organization: []string{"cert-manager"}},
+			expectedMode: v1alpha1.VaultSignModeSignVerbatim,
+			expectedPath: "sign/sign-verbatim/my-role",
+		},
+		"sign mode should use the configured path if it is not a sign endpoint": {
+			spec:         v1alpha1.VaultIssuer{SignMode: v1alpha1.VaultSignModeSign, Path: "pki/issue/my-role"},
+			subject:      pkix.Name{Country: []string{"GB"}},
+			expectedMode: v1alpha1.VaultSignModeSign,
+			expectedPath: "pki/issue/my-role",
+		},
+		"sign-verbatim mode should use the configured path": {
+			spec:         v1alpha1.VaultIssuer{SignMode: v1alpha1.VaultSignModeSignVerbatim, Path: "pki/sign-verbatim/my-role"},
+			subject:      pkix.Name{Country: []string{"GB"}},
+			expectedMode: v1alpha1.VaultSignModeSignVerbatim,
+			expectedPath: "pki/sign-verbatim/my-role",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mode, signPath := signEndpoint(&test.spec, csrFor(test.subject))
+			if mode != test.expectedMode {
+				t.Errorf("unexpected sign mode, exp=%q got=%q", test.expectedMode, mode)
+			}
+			if signPath != test.expectedPath {
+				t.Errorf("unexpected path, exp=%q got=%q", test.expectedPath, signPath)
+			}
+		})
+	}
+}
+
+func TestSignWithNamespaceAndCAChain(t *testing.T) {
+	privatekey := generateRSAPrivateKey(t)
+	csrPEM := generateCSR(t, privatekey)
+	intermediatePEM, _ := generateClientCertificate(t)
+
+	// fakeVault implements the sign-verbatim endpoint of a PKI secrets engine
+	// mounted in the 'team-a' namespace, returning the issuing CA followed by
+	// its parent in the CA chain.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Namespace") != "team-a" {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"errors":["permission denied"]}`)
+			return
+		}
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/pki/sign-verbatim/my-role" {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"errors":["no handler for route"]}`)
+			return
+		}
+
+		bundleData, err := jsonutil.EncodeJSON(&certutil.Secret{
+			Data: map[string]interface{}{
+				"certificate": testCertBundle,
+				"ca_chain":    []string{testCertBundle, string(intermediatePEM)},
+			},
+		})
+		if err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+		w.Write(bundleData)
+	}))
+	defer server.Close()
+
+	issuer := gen.Issuer("vault-issuer",
+		gen.SetIssuerVault(v1alpha1.VaultIssuer{
+			Server:    server.URL,
+			Path:      "pki/sign-verbatim/my-role",
+			Namespace: "team-a",
+			SignMode:  v1alpha1.VaultSignModeSignVerbatim,
+			Auth: v1alpha1.VaultAuth{
+				TokenSecretRef: v1alpha1.SecretKeySelector{
+					LocalObjectReference: v1alpha1.LocalObjectReference{Name: "vault-token"},
+				},
+			},
+		}),
+	)
+	lister := listers.FakeSecretListerFrom(listers.NewFakeSecretLister(),
+		listers.SetFakeSecretNamespaceListerGet(&corev1.Secret{
+			Data: map[string][]byte{
+				"token": []byte("my-token"),
+			},
+		}, nil),
+	)
+
+	v, err := New("test-namespace", lister, issuer)
+	if err != nil {
+		t.Fatalf("failed to build vault client: %v", err)
+	}
+
+	cert, ca, err := v.Sign(csrPEM, time.Minute)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expectedChain := testCertBundle + "\n" + strings.TrimSpace(string(intermediatePEM))
+	if expectedCert := testCertBundle + "\n" + expectedChain; string(cert) != expectedCert {
+		t.Errorf("unexpected certificate, exp=%s got=%s", expectedCert, cert)
+	}
+	if string(ca) != expectedChain {
+		t.Errorf("expected the full CA chain, exp=%s got=%s", expectedChain, ca)
+	}
+}
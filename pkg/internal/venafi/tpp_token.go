@@ -0,0 +1,479 @@
+/*
+Copyright 2019 The Jetstack cert-manager contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package venafi
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Venafi/vcert"
+	"github.com/Venafi/vcert/pkg/certificate"
+	"github.com/Venafi/vcert/pkg/endpoint"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	tppAccessTokenKey  = "access-token"
+	tppRefreshTokenKey = "refresh-token"
+	tppExpiresKey      = "expires"
+	tppClientIDKey     = "client-id"
+
+	defaultTPPClientID = "cert-manager"
+
+	// tppTokenRefreshWindow is how long before it expires that an access
+	// token will be refreshed.
+	tppTokenRefreshWindow = time.Hour * 24
+
+	// tppRetrievePollInterval is how often a pending certificate is polled
+	// for whilst waiting for it to be issued.
+	tppRetrievePollInterval = time.Second * 2
+)
+
+// ErrAuthentication is returned when the TPP server rejects an access token,
+// or fails to refresh it.
+type ErrAuthentication struct {
+	Err error
+}
+
+func (e ErrAuthentication) Error() string {
+	return fmt.Sprintf("failed to authenticate with Venafi TPP: %v", e.Err)
+}
+
+// tppTokenLock serialises token refreshes, as each refresh invalidates the
+// refresh token that was used to perform it.
+var tppTokenLock sync.Mutex
+
+// tppTokenConnector is a connector for Venafi TPP that authenticates using
+// an OAuth access token, which the vcert TPP connector does not support.
+type tppTokenConnector struct {
+	// sdkURL is the base URL of the TPP WebSDK, ending in '/vedsdk/'.
+	sdkURL string
+	// authURL is the base URL of the TPP token endpoints, ending in '/vedauth/'.
+	authURL string
+
+	accessToken string
+	zone        string
+	client      *http.Client
+}
+
+// newTPPTokenConnector returns a connector for the TPP server described by
+// cfg, authenticating with the access token in cfg.Credentials.APIKey.
+func newTPPTokenConnector(cfg *vcert.Config) (*tppTokenConnector, error) {
+	sdkURL, authURL, err := tppURLs(cfg.BaseUrl)
+	if err != nil {
+		return nil, err
+	}
+	client, err := tppHTTPClient(cfg.ConnectionTrust)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tppTokenConnector{
+		sdkURL:      sdkURL,
+		authURL:     authURL,
+		accessToken: cfg.Credentials.APIKey,
+		zone:        cfg.Zone,
+		client:      client,
+	}, nil
+}
+
+// tppURLs returns the WebSDK and token endpoint base URLs for the given TPP
+// URL.
+func tppURLs(rawURL string) (sdkURL, authURL string, err error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid TPP URL %q: %v", rawURL, err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return "", "", fmt.Errorf("invalid TPP URL %q: expected a URL of the form 'https://tpp.example.com/vedsdk'", rawURL)
+	}
+
+	path := strings.TrimSuffix(u.Path, "/")
+	if !strings.HasSuffix(strings.ToLower(path), "/vedsdk") {
+		path += "/vedsdk"
+	}
+
+	host := u.Scheme + "://" + u.Host
+	return host + path + "/", host + "/vedauth/", nil
+}
+
+// tppHTTPClient returns a HTTP client that trusts caBundle, or the system
+// roots if caBundle is empty.
+func tppHTTPClient(caBundle string) (*http.Client, error) {
+	client := &http.Client{Timeout: time.Second * 30}
+	if caBundle == "" {
+		return client, nil
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM([]byte(caBundle)) {
+		return nil, fmt.Errorf("failed to parse TPP CA bundle")
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	client.Transport = transport
+
+	return client, nil
+}
+
+type tppTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	Expires      int64  `json:"expires"`
+}
+
+// tppAccessToken returns the access token stored in secret. If the access
+// token expires within tppTokenRefreshWindow and a refresh token is present,
+// the access token is first refreshed and the rotated tokens written back to
+// the Secret.
+func tppAccessToken(client *http.Client, authURL string, secretsClient corev1client.SecretsGetter, secret *corev1.Secret) (string, error) {
+	if !tppTokenNeedsRefresh(secret) {
+		return tppSecretAccessToken(secret)
+	}
+
+	tppTokenLock.Lock()
+	defer tppTokenLock.Unlock()
+
+	// The lister may not yet have observed tokens that were rotated by an
+	// earlier refresh, so read the Secret again before using its refresh
+	// token.
+	secret, err := secretsClient.Secrets(secret.Namespace).Get(secret.Name, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	if !tppTokenNeedsRefresh(secret) {
+		return tppSecretAccessToken(secret)
+	}
+
+	clientID := defaultTPPClientID
+	if id := string(secret.Data[tppClientIDKey]); id != "" {
+		clientID = id
+	}
+
+	tokens, err := refreshTPPAccessToken(client, authURL, clientID, string(secret.Data[tppRefreshTokenKey]))
+	if err != nil {
+		return "", err
+	}
+
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest, err := secretsClient.Secrets(secret.Namespace).Get(secret.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		latest = latest.DeepCopy()
+		if latest.Data == nil {
+			latest.Data = make(map[string][]byte)
+		}
+		latest.Data[tppAccessTokenKey] = []byte(tokens.AccessToken)
+		latest.Data[tppRefreshTokenKey] = []byte(tokens.RefreshToken)
+		latest.Data[tppExpiresKey] = []byte(time.Unix(tokens.Expires, 0).UTC().Format(time.RFC3339))
+		_, err = secretsClient.Secrets(latest.Namespace).Update(latest)
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to store refreshed TPP access token in secret %s/%s: %v", secret.Namespace, secret.Name, err)
+	}
+
+	return tokens.AccessToken, nil
+}
+
+// tppTokenNeedsRefresh returns true if secret contains a refresh token and
+// its access token is missing, has no recorded expiry, or is due to expire.
+func tppTokenNeedsRefresh(secret *corev1.Secret) bool {
+	if len(secret.Data[tppRefreshTokenKey]) == 0 {
+		return false
+	}
+	if len(secret.Data[tppAccessTokenKey]) == 0 {
+		return true
+	}
+	expires, err := time.Parse(time.RFC3339, string(secret.Data[tppExpiresKey]))
+	if err != nil {
+		return true
+	}
+	return time.Now().Add(tppTokenRefreshWindow).After(expires)
+}
+
+func tppSecretAccessToken(secret *corev1.Secret) (string, error) {
+	token := string(secret.Data[tppAccessTokenKey])
+	if token == "" {
+		return "", fmt.Errorf("no %q key found in secret %s/%s", tppAccessTokenKey, secret.Namespace, secret.Name)
+	}
+	return token, nil
+}
+
+// refreshTPPAccessToken exchanges a refresh token for a new access and
+// refresh token.
+func refreshTPPAccessToken(client *http.Client, authURL, clientID, refreshToken string) (*tppTokenResponse, error) {
+	body, err := json.Marshal(map[string]string{
+		"client_id":     clientID,
+		"refresh_token": refreshToken,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Post(authURL+"authorize/token", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh TPP access token: %v", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusUnauthorized:
+		return nil, ErrAuthentication{Err: fmt.Errorf("refresh token rejected: %s: %s", resp.Status, respBody)}
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("unexpected response refreshing TPP access token: %s: %s", resp.Status, respBody)
+	}
+
+	tokens := &tppTokenResponse{}
+	if err := json.Unmarshal(respBody, tokens); err != nil {
+		return nil, fmt.Errorf("failed to decode TPP token response: %v", err)
+	}
+	if tokens.AccessToken == "" {
+		return nil, fmt.Errorf("TPP token response did not contain an access token")
+	}
+
+	return tokens, nil
+}
+
+// request sends a request with the access token to the given URL, and decodes
+// the JSON response into out.
+func (c *tppTokenConnector) request(method, url string, in, out interface{}) error {
+	var body []byte
+	if in != nil {
+		var err error
+		body, err = json.Marshal(in)
+		if err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequest(method, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.accessToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusAccepted:
+	case http.StatusUnauthorized:
+		return ErrAuthentication{Err: fmt.Errorf("access token rejected: %s", resp.Status)}
+	default:
+		return fmt.Errorf("unexpected response from %s: %s: %s", url, resp.Status, respBody)
+	}
+
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(respBody, out)
+}
+
+// Ping verifies the access token with the TPP server.
+func (c *tppTokenConnector) Ping() error {
+	return c.request(http.MethodGet, c.authURL+"authorize/verify", nil, nil)
+}
+
+type tppLockedValue struct {
+	Locked bool
+	Value  string
+}
+
+// tppPolicy is the subset of the TPP certificates/checkpolicy response that
+// is used to default and validate requests.
+type tppPolicy struct {
+	Subject struct {
+		City               tppLockedValue
+		Country            tppLockedValue
+		Organization       tppLockedValue
+		OrganizationalUnit struct {
+			Locked bool
+			Values []string
+		}
+		State tppLockedValue
+	}
+	WhitelistedDomains []string
+	WildcardsAllowed   bool
+}
+
+// ReadZoneConfiguration reads the policy of the issuer's zone.
+// Key constraints are left for the TPP server to enforce.
+func (c *tppTokenConnector) ReadZoneConfiguration() (*endpoint.ZoneConfiguration, error) {
+	var resp struct {
+		Error  string
+		Policy tppPolicy
+	}
+	err := c.request(http.MethodPost, c.sdkURL+"certificates/checkpolicy", map[string]string{"PolicyDN": tppPolicyDN(c.zone)}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("failed to read policy for zone %q: %s", c.zone, resp.Error)
+	}
+
+	p := resp.Policy
+	zc := endpoint.NewZoneConfiguration()
+	zc.HashAlgorithm = x509.SHA256WithRSA
+	zc.Country = p.Subject.Country.Value
+	zc.Organization = p.Subject.Organization.Value
+	zc.OrganizationalUnit = p.Subject.OrganizationalUnit.Values
+	zc.Province = p.Subject.State.Value
+	zc.Locality = p.Subject.City.Value
+
+	zc.Policy.SubjectCNRegexes = tppDomainRegexes(p.WhitelistedDomains, p.WildcardsAllowed)
+	zc.Policy.DnsSanRegExs = zc.Policy.SubjectCNRegexes
+	zc.Policy.SubjectCRegexes = tppLockedRegexes(p.Subject.Country)
+	zc.Policy.SubjectORegexes = tppLockedRegexes(p.Subject.Organization)
+	zc.Policy.SubjectSTRegexes = tppLockedRegexes(p.Subject.State)
+	zc.Policy.SubjectLRegexes = tppLockedRegexes(p.Subject.City)
+	if p.Subject.OrganizationalUnit.Locked {
+		for _, ou := range p.Subject.OrganizationalUnit.Values {
+			zc.Policy.SubjectOURegexes = append(zc.Policy.SubjectOURegexes, "^"+regexp.QuoteMeta(ou)+"$")
+		}
+	}
+	zc.Policy.AllowWildcards = p.WildcardsAllowed
+
+	return zc, nil
+}
+
+// tppDomainRegexes returns regexes matching names in the given domains, or
+// nil to allow any name if there are none.
+func tppDomainRegexes(domains []string, wildcards bool) []string {
+	var regexes []string
+	for _, d := range domains {
+		if wildcards {
+			regexes = append(regexes, "^.*"+regexp.QuoteMeta("."+d)+"$")
+		} else {
+			regexes = append(regexes, "^"+regexp.QuoteMeta(d)+"$")
+		}
+	}
+	return regexes
+}
+
+func tppLockedRegexes(v tppLockedValue) []string {
+	if !v.Locked {
+		return nil
+	}
+	return []string{"^" + regexp.QuoteMeta(v.Value) + "$"}
+}
+
+// tppPolicyDN returns the policy folder DN for the given zone.
+func tppPolicyDN(zone string) string {
+	if strings.HasPrefix(zone, `\VED\Policy`) {
+		return zone
+	}
+	if !strings.HasPrefix(zone, `\`) {
+		zone = `\` + zone
+	}
+	return `\VED\Policy` + zone
+}
+
+// RequestCertificate submits the request's CSR to TPP, returning the DN of the
+// requested certificate.
+func (c *tppTokenConnector) RequestCertificate(req *certificate.Request) (string, error) {
+	var resp struct {
+		CertificateDN string
+		Error         string
+	}
+	err := c.request(http.MethodPost, c.sdkURL+"certificates/request", map[string]interface{}{
+		"PolicyDN":                tppPolicyDN(c.zone),
+		"PKCS10":                  string(req.GetCSR()),
+		"ObjectName":              req.FriendlyName,
+		"DisableAutomaticRenewal": true,
+	}, &resp)
+	if err != nil {
+		return "", err
+	}
+	if resp.CertificateDN == "" {
+		return "", fmt.Errorf("failed to request certificate: %s", resp.Error)
+	}
+
+	req.PickupID = resp.CertificateDN
+	return resp.CertificateDN, nil
+}
+
+// RetrieveCertificate polls TPP for the certificate requested by req until it
+// is issued or req.Timeout expires.
+func (c *tppTokenConnector) RetrieveCertificate(req *certificate.Request) (*certificate.PEMCollection, error) {
+	includeChain := req.ChainOption != certificate.ChainOptionIgnore
+	body := map[string]interface{}{
+		"CertificateDN":  req.PickupID,
+		"Format":         "base64",
+		"IncludeChain":   includeChain,
+		"RootFirstOrder": includeChain && req.ChainOption == certificate.ChainOptionRootFirst,
+	}
+
+	start := time.Now()
+	for {
+		var resp struct {
+			CertificateData string
+			Status          string
+		}
+		if err := c.request(http.MethodPost, c.sdkURL+"certificates/retrieve", body, &resp); err != nil {
+			return nil, fmt.Errorf("unable to retrieve: %v", err)
+		}
+
+		if resp.CertificateData != "" {
+			certBytes, err := base64.StdEncoding.DecodeString(resp.CertificateData)
+			if err != nil {
+				return nil, err
+			}
+			pems, err := certificate.PEMCollectionFromBytes(certBytes, req.ChainOption)
+			if err != nil {
+				return nil, err
+			}
+			return pems, req.CheckCertificate(pems.Certificate)
+		}
+
+		if req.Timeout == 0 {
+			return nil, endpoint.ErrCertificatePending{CertificateID: req.PickupID, Status: resp.Status}
+		}
+		if time.Now().After(start.Add(req.Timeout)) {
+			return nil, endpoint.ErrRetrieveCertificateTimeout{CertificateID: req.PickupID}
+		}
+		time.Sleep(tppRetrievePollInterval)
+	}
+}
@@ -0,0 +1,348 @@
+/*
+Copyright 2019 The Jetstack cert-manager contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package venafi
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Venafi/vcert"
+	"github.com/Venafi/vcert/pkg/certificate"
+	"github.com/Venafi/vcert/pkg/endpoint"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// newTPPTestServer returns a TLS server that serves handler, along with the
+// PEM encoded CA bundle that trusts it.
+func newTPPTestServer(handler http.HandlerFunc) (*httptest.Server, string) {
+	server := httptest.NewTLSServer(handler)
+	caBundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	return server, string(caBundle)
+}
+
+func TestTPPURLs(t *testing.T) {
+	tests := map[string]struct {
+		url             string
+		expectedSDKURL  string
+		expectedAuthURL string
+		expectedErr     bool
+	}{
+		"url with vedsdk path": {
+			url:             "https://tpp.example.com/vedsdk",
+			expectedSDKURL:  "https://tpp.example.com/vedsdk/",
+			expectedAuthURL: "https://tpp.example.com/vedauth/",
+		},
+		"url with vedsdk path and trailing slash": {
+			url:             "https://tpp.example.com:8443/vedsdk/",
+			expectedSDKURL:  "https://tpp.example.com:8443/vedsdk/",
+			expectedAuthURL: "https://tpp.example.com:8443/vedauth/",
+		},
+		"url without vedsdk path": {
+			url:             "https://tpp.example.com",
+			expectedSDKURL:  "https://tpp.example.com/vedsdk/",
+			expectedAuthURL: "https://tpp.example.com/vedauth/",
+		},
+		"url without scheme": {
+			url:         "tpp.example.com/vedsdk",
+			expectedErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			sdkURL, authURL, err := tppURLs(test.url)
+			if err != nil && !test.expectedErr {
+				t.Errorf("expected to not get an error, but got: %v", err)
+			}
+			if err == nil && test.expectedErr {
+				t.Errorf("expected to get an error but did not get one")
+			}
+			if sdkURL != test.expectedSDKURL {
+				t.Errorf("got unexpected sdk url, exp=%s got=%s", test.expectedSDKURL, sdkURL)
+			}
+			if authURL != test.expectedAuthURL {
+				t.Errorf("got unexpected auth url, exp=%s got=%s", test.expectedAuthURL, authURL)
+			}
+		})
+	}
+}
+
+func TestTPPAccessToken(t *testing.T) {
+	expired := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	valid := time.Now().Add(time.Hour * 24 * 30).UTC().Format(time.RFC3339)
+	newExpiry := time.Now().Add(time.Hour * 24 * 90).Unix()
+
+	tests := map[string]struct {
+		data            map[string][]byte
+		refreshStatus   int
+		expectedToken   string
+		expectedData    map[string][]byte
+		expectedErr     bool
+		expectedAuthErr bool
+		expectRefresh   bool
+	}{
+		"if no refresh token then return stored access token": {
+			data: map[string][]byte{
+				tppAccessTokenKey: []byte("access"),
+				tppExpiresKey:     []byte(expired),
+			},
+			expectedToken: "access",
+		},
+		"if no access or refresh token then error": {
+			data:        map[string][]byte{},
+			expectedErr: true,
+		},
+		"if access token is not due to expire then return it": {
+			data: map[string][]byte{
+				tppAccessTokenKey:  []byte("access"),
+				tppRefreshTokenKey: []byte("refresh"),
+				tppExpiresKey:      []byte(valid),
+			},
+			expectedToken: "access",
+		},
+		"if access token has expired then refresh and store rotated tokens": {
+			data: map[string][]byte{
+				tppAccessTokenKey:  []byte("access"),
+				tppRefreshTokenKey: []byte("refresh"),
+				tppExpiresKey:      []byte(expired),
+			},
+			refreshStatus: http.StatusOK,
+			expectRefresh: true,
+			expectedToken: "new-access",
+			expectedData: map[string][]byte{
+				tppAccessTokenKey:  []byte("new-access"),
+				tppRefreshTokenKey: []byte("new-refresh"),
+				tppExpiresKey:      []byte(time.Unix(newExpiry, 0).UTC().Format(time.RFC3339)),
+			},
+		},
+		"if access token has no expiry then refresh": {
+			data: map[string][]byte{
+				tppAccessTokenKey:  []byte("access"),
+				tppRefreshTokenKey: []byte("refresh"),
+			},
+			refreshStatus: http.StatusOK,
+			expectRefresh: true,
+			expectedToken: "new-access",
+		},
+		"if refresh token is rejected then return authentication error": {
+			data: map[string][]byte{
+				tppAccessTokenKey:  []byte("access"),
+				tppRefreshTokenKey: []byte("refresh"),
+				tppExpiresKey:      []byte(expired),
+			},
+			refreshStatus:   http.StatusBadRequest,
+			expectRefresh:   true,
+			expectedErr:     true,
+			expectedAuthErr: true,
+			expectedData: map[string][]byte{
+				tppAccessTokenKey:  []byte("access"),
+				tppRefreshTokenKey: []byte("refresh"),
+				tppExpiresKey:      []byte(expired),
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			refreshed := false
+			server, caBundle := newTPPTestServer(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/vedauth/authorize/token" {
+					t.Errorf("unexpected request to %s", r.URL.Path)
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				refreshed = true
+
+				var req map[string]string
+				if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+					t.Errorf("failed to decode refresh request: %v", err)
+				}
+				if req["client_id"] != defaultTPPClientID || req["refresh_token"] != "refresh" {
+					t.Errorf("got unexpected refresh request: %v", req)
+				}
+
+				w.WriteHeader(test.refreshStatus)
+				if test.refreshStatus == http.StatusOK {
+					json.NewEncoder(w).Encode(tppTokenResponse{
+						AccessToken:  "new-access",
+						RefreshToken: "new-refresh",
+						Expires:      newExpiry,
+					})
+				}
+			})
+			defer server.Close()
+
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "tpp-token", Namespace: "test-namespace"},
+				Data:       test.data,
+			}
+			cl := fake.NewSimpleClientset(secret)
+
+			client, err := tppHTTPClient(caBundle)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			token, err := tppAccessToken(client, server.URL+"/vedauth/", cl.CoreV1(), secret)
+			if err != nil && !test.expectedErr {
+				t.Errorf("expected to not get an error, but got: %v", err)
+			}
+			if err == nil && test.expectedErr {
+				t.Errorf("expected to get an error but did not get one")
+			}
+			if _, ok := err.(ErrAuthentication); ok != test.expectedAuthErr {
+				t.Errorf("expected authentication error=%t but got: %v", test.expectedAuthErr, err)
+			}
+			if token != test.expectedToken {
+				t.Errorf("got unexpected token, exp=%s got=%s", test.expectedToken, token)
+			}
+			if refreshed != test.expectRefresh {
+				t.Errorf("expected refresh=%t but got refresh=%t", test.expectRefresh, refreshed)
+			}
+
+			if test.expectedData == nil {
+				return
+			}
+			stored, err := cl.CoreV1().Secrets("test-namespace").Get("tpp-token", metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for k, v := range test.expectedData {
+				if string(stored.Data[k]) != string(v) {
+					t.Errorf("got unexpected secret data for key %q, exp=%s got=%s", k, v, stored.Data[k])
+				}
+			}
+		})
+	}
+}
+
+func TestTPPTokenConnector(t *testing.T) {
+	var certPEM string
+	issued := false
+	server, caBundle := newTPPTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer access" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var req map[string]interface{}
+		if r.Method == http.MethodPost {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Errorf("failed to decode request: %v", err)
+			}
+		}
+
+		switch r.URL.Path {
+		case "/vedauth/authorize/verify":
+		case "/vedsdk/certificates/checkpolicy":
+			if req["PolicyDN"] != `\VED\Policy\test-zone` {
+				t.Errorf("got unexpected policy DN: %v", req["PolicyDN"])
+			}
+			w.Write([]byte(`{"Policy": {
+				"Subject": {"Organization": {"Locked": true, "Value": "test-org"}},
+				"WhitelistedDomains": ["example.com"],
+				"WildcardsAllowed": true
+			}}`))
+		case "/vedsdk/certificates/request":
+			json.NewEncoder(w).Encode(map[string]string{"CertificateDN": `\VED\Policy\test-zone\example.com`})
+		case "/vedsdk/certificates/retrieve":
+			if req["CertificateDN"] != `\VED\Policy\test-zone\example.com` {
+				t.Errorf("got unexpected certificate DN: %v", req["CertificateDN"])
+			}
+			if !issued {
+				w.WriteHeader(http.StatusAccepted)
+				json.NewEncoder(w).Encode(map[string]string{"Status": "Issuing"})
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]string{
+				"CertificateData": base64.StdEncoding.EncodeToString([]byte(certPEM)),
+			})
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	defer server.Close()
+	certPEM = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+
+	newConnector := func(accessToken string) *tppTokenConnector {
+		c, err := newTPPTokenConnector(&vcert.Config{
+			BaseUrl:         server.URL + "/vedsdk",
+			Zone:            "test-zone",
+			ConnectionTrust: caBundle,
+			Credentials:     &endpoint.Authentication{APIKey: accessToken},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+
+	if err := newConnector("access").Ping(); err != nil {
+		t.Errorf("expected ping to succeed, but got: %v", err)
+	}
+	if _, ok := newConnector("expired").Ping().(ErrAuthentication); !ok {
+		t.Errorf("expected ping with a rejected token to return an authentication error")
+	}
+
+	c := newConnector("access")
+
+	zoneCfg, err := c.ReadZoneConfiguration()
+	if err != nil {
+		t.Fatalf("failed to read zone configuration: %v", err)
+	}
+	if zoneCfg.Organization != "test-org" {
+		t.Errorf("got unexpected default organization: %s", zoneCfg.Organization)
+	}
+	req := &certificate.Request{}
+	req.Subject.CommonName = "foo.example.com"
+	req.Subject.Organization = []string{"test-org"}
+	if err := zoneCfg.ValidateCertificateRequest(req); err != nil {
+		t.Errorf("expected request to satisfy zone policy, but got: %v", err)
+	}
+	req.Subject.CommonName = "foo.example.org"
+	if err := zoneCfg.ValidateCertificateRequest(req); err == nil {
+		t.Errorf("expected request for a domain outside the zone policy to fail validation")
+	}
+
+	req = &certificate.Request{}
+	if _, err := c.RequestCertificate(req); err != nil {
+		t.Fatalf("failed to request certificate: %v", err)
+	}
+
+	if _, err := c.RetrieveCertificate(req); err == nil {
+		t.Errorf("expected retrieving a pending certificate to fail")
+	} else if _, ok := err.(endpoint.ErrCertificatePending); !ok {
+		t.Errorf("expected a pending error, but got: %v", err)
+	}
+
+	issued = true
+	pems, err := c.RetrieveCertificate(req)
+	if err != nil {
+		t.Fatalf("failed to retrieve certificate: %v", err)
+	}
+	if pems.Certificate != certPEM {
+		t.Errorf("got unexpected certificate: %s", pems.Certificate)
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/Venafi/vcert"
 	"github.com/Venafi/vcert/pkg/certificate"
 	"github.com/Venafi/vcert/pkg/endpoint"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 	corelisters "k8s.io/client-go/listers/core/v1"
 
 	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha1"
@@ -36,7 +37,7 @@ const (
 )
 
 type VenafiClientBuilder func(namespace string, secretsLister corelisters.SecretLister,
-	issuer cmapi.GenericIssuer) (Interface, error)
+	secretsClient corev1client.SecretsGetter, issuer cmapi.GenericIssuer) (Interface, error)
 
 type Interface interface {
 	Sign(csrPEM []byte, duration time.Duration) (cert []byte, err error)
@@ -65,18 +66,22 @@ type connector interface {
 	ReadZoneConfiguration() (config *endpoint.ZoneConfiguration, err error)
 	RequestCertificate(req *certificate.Request) (requestID string, err error)
 	RetrieveCertificate(req *certificate.Request) (certificates *certificate.PEMCollection, err error)
-	RenewCertificate(req *certificate.RenewalRequest) (requestID string, err error)
 }
 
 func New(namespace string, secretsLister corelisters.SecretLister,
-	issuer cmapi.GenericIssuer) (Interface, error) {
+	secretsClient corev1client.SecretsGetter, issuer cmapi.GenericIssuer) (Interface, error) {
 
-	cfg, err := configForIssuer(issuer, secretsLister, namespace)
+	cfg, err := configForIssuer(issuer, secretsLister, secretsClient, namespace)
 	if err != nil {
 		return nil, err
 	}
 
-	client, err := vcert.NewClient(cfg)
+	var client connector
+	if cfg.ConnectorType == endpoint.ConnectorTypeTPP && cfg.Credentials.APIKey != "" {
+		client, err = newTPPTokenConnector(cfg)
+	} else {
+		client, err = vcert.NewClient(cfg)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("error creating Venafi client: %s", err.Error())
 	}
@@ -90,9 +95,44 @@ func New(namespace string, secretsLister corelisters.SecretLister,
 
 // configForIssuer will convert a cert-manager Venafi issuer into a vcert.Config
 // that can be used to instantiate an API client.
-func configForIssuer(iss cmapi.GenericIssuer, secretsLister corelisters.SecretLister, namespace string) (*vcert.Config, error) {
+// If a TPP issuer uses an access token, the token is refreshed if needed and
+// returned as the config's APIKey.
+func configForIssuer(iss cmapi.GenericIssuer, secretsLister corelisters.SecretLister,
+	secretsClient corev1client.SecretsGetter, namespace string) (*vcert.Config, error) {
 	venCfg := iss.GetSpec().Venafi
 	switch {
+	case venCfg.TPP != nil && venCfg.TPP.AccessTokenSecretRef != nil:
+		tpp := venCfg.TPP
+		tokenSecret, err := secretsLister.Secrets(namespace).Get(tpp.AccessTokenSecretRef.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		caBundle := string(tpp.CABundle)
+		client, err := tppHTTPClient(caBundle)
+		if err != nil {
+			return nil, err
+		}
+		_, authURL, err := tppURLs(tpp.URL)
+		if err != nil {
+			return nil, err
+		}
+
+		accessToken, err := tppAccessToken(client, authURL, secretsClient, tokenSecret)
+		if err != nil {
+			return nil, err
+		}
+
+		return &vcert.Config{
+			ConnectorType:   endpoint.ConnectorTypeTPP,
+			BaseUrl:         tpp.URL,
+			Zone:            venCfg.Zone,
+			ConnectionTrust: caBundle,
+			Credentials: &endpoint.Authentication{
+				APIKey: accessToken,
+			},
+		}, nil
+
 	case venCfg.TPP != nil:
 		tpp := venCfg.TPP
 		tppSecret, err := secretsLister.Secrets(namespace).Get(tpp.CredentialsRef.Name)
@@ -64,6 +64,7 @@ func TestConfigForIssuerT(t *testing.T) {
 	username := "test-username"
 	password := "test-password"
 	apiKey := "test-api-key"
+	accessToken := "test-access-token"
 	customKey := "test-custom-key"
 
 	baseIssuer := gen.Issuer("non-venafi-issue",
@@ -126,6 +127,32 @@ func TestConfigForIssuerT(t *testing.T) {
 			},
 			expectedErr: false,
 		},
+		"if TPP with access token secret ref, should return config with access token as API key": {
+			iss: gen.IssuerFrom(baseIssuer,
+				gen.SetIssuerVenafi(cmapi.VenafiIssuer{
+					Zone: zone,
+					TPP: &cmapi.VenafiTPP{
+						URL:                  "https://tpp.example.com/vedsdk",
+						AccessTokenSecretRef: &cmapi.LocalObjectReference{Name: "tpp-token"},
+					},
+				}),
+			),
+			secretsLister: generateSecretLister(&corev1.Secret{
+				Data: map[string][]byte{
+					tppAccessTokenKey: []byte(accessToken),
+				},
+			}, nil),
+			CheckFn: func(t *testing.T, cnf *vcert.Config) {
+				if key := cnf.Credentials.APIKey; key != accessToken {
+					t.Errorf("got unexpected access token: %s", key)
+				}
+				if user := cnf.Credentials.User; user != "" {
+					t.Errorf("expected no username but got: %s", user)
+				}
+				checkZone(t, zone, cnf)
+			},
+			expectedErr: false,
+		},
 		"if Cloud but getting secret fails, should error": {
 			iss:           cloudIssuer,
 			secretsLister: generateSecretLister(nil, errors.New("this is a network error")),
@@ -206,7 +233,7 @@ type testConfigForIssuerT struct {
 }
 
 func (c *testConfigForIssuerT) runTest(t *testing.T) {
-	resp, err := configForIssuer(c.iss, c.secretsLister, "test-namespace")
+	resp, err := configForIssuer(c.iss, c.secretsLister, nil, "test-namespace")
 	if err != nil && !c.expectedErr {
 		t.Errorf("expected to not get an error, but got: %v", err)
 	}
@@ -26,13 +26,14 @@ import (
 	"time"
 
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/dynamic"
 	corev1listers "k8s.io/client-go/listers/core/v1"
-	extv1beta1listers "k8s.io/client-go/listers/extensions/v1beta1"
 
 	"github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha1"
 	"github.com/jetstack/cert-manager/pkg/controller"
 	"github.com/jetstack/cert-manager/pkg/issuer/acme/http/solver"
 	logf "github.com/jetstack/cert-manager/pkg/logs"
+	ingressutil "github.com/jetstack/cert-manager/pkg/util/ingress"
 )
 
 const (
@@ -41,6 +42,14 @@ const (
 	HTTP01Timeout = time.Minute * 15
 	// acmeSolverListenPort is the port acmesolver should listen on
 	acmeSolverListenPort = 8089
+	// acmeSolverTLSListenPort is the port acmesolver should listen on when
+	// solving tls-alpn-01 challenges
+	acmeSolverTLSListenPort = 8443
+	// acmeSolverTLSServicePort is the port the solver service exposes for
+	// tls-alpn-01 challenges, which are always validated on port 443
+	acmeSolverTLSServicePort = 443
+
+	tlsALPN01ChallengeType = "tls-alpn-01"
 
 	domainLabelKey               = "certmanager.k8s.io/acme-http-domain"
 	tokenLabelKey                = "certmanager.k8s.io/acme-http-token" /* #nosec G101 */
@@ -57,7 +66,8 @@ type Solver struct {
 
 	podLister     corev1listers.PodLister
 	serviceLister corev1listers.ServiceLister
-	ingressLister extv1beta1listers.IngressLister
+	ingressClient *ingressutil.Client
+	dynamicClient dynamic.Interface
 
 	testReachability reachabilityTest
 	requiredPasses   int
@@ -72,7 +82,8 @@ func NewSolver(ctx *controller.Context) *Solver {
 		Context:          ctx,
 		podLister:        ctx.KubeSharedInformerFactory.Core().V1().Pods().Lister(),
 		serviceLister:    ctx.KubeSharedInformerFactory.Core().V1().Services().Lister(),
-		ingressLister:    ctx.KubeSharedInformerFactory.Extensions().V1beta1().Ingresses().Lister(),
+		ingressClient:    ingressutil.NewClient(ctx.IngressResource, ctx.DynamicClient, ctx.DynamicSharedInformerFactory),
+		dynamicClient:    ctx.DynamicClient,
 		testReachability: testReachability,
 		requiredPasses:   5,
 	}
@@ -106,6 +117,15 @@ func httpDomainCfgForChallenge(issuer v1alpha1.GenericIssuer, ch *v1alpha1.Chall
 	return nil, fmt.Errorf("no HTTP01 ingress configuration found on challenge")
 }
 
+// httpRouteCfgForChallenge returns the OpenShift Route configuration for the
+// challenge, or nil if the challenge should be solved using an Ingress.
+func httpRouteCfgForChallenge(ch *v1alpha1.Challenge) *v1alpha1.ACMEChallengeSolverHTTP01Route {
+	if ch.Spec.Solver == nil || ch.Spec.Solver.HTTP01 == nil {
+		return nil
+	}
+	return ch.Spec.Solver.HTTP01.Route
+}
+
 // Present will realise the resources required to solve the given HTTP01
 // challenge validation in the apiserver. If those resources already exist, it
 // will return nil (i.e. this function is idempotent).
@@ -117,6 +137,10 @@ func (s *Solver) Present(ctx context.Context, issuer v1alpha1.GenericIssuer, ch
 	if svcErr != nil {
 		return utilerrors.NewAggregate([]error{podErr, svcErr})
 	}
+	if httpRouteCfgForChallenge(ch) != nil {
+		_, routeErr := s.ensureRoute(ctx, ch, svc.Name)
+		return utilerrors.NewAggregate([]error{podErr, svcErr, routeErr})
+	}
 	_, ingressErr := s.ensureIngress(ctx, issuer, ch, svc.Name)
 	return utilerrors.NewAggregate([]error{podErr, svcErr, ingressErr})
 }
@@ -130,7 +154,7 @@ func (s *Solver) Check(ctx context.Context, issuer v1alpha1.GenericIssuer, ch *v
 	// Call present again to be certain.
 	// if the listers are nil, that means we're in the present checks
 	// test
-	if s.podLister != nil && s.serviceLister != nil && s.ingressLister != nil {
+	if s.podLister != nil && s.serviceLister != nil && s.ingressClient != nil {
 		log.V(logf.DebugLevel).Info("calling Present function before running self check to ensure required resources exist")
 		err := s.Present(ctx, issuer, ch)
 		if err != nil {
@@ -160,13 +184,17 @@ func (s *Solver) Check(ctx context.Context, issuer v1alpha1.GenericIssuer, ch *v
 	return nil
 }
 
-// CleanUp will ensure the created service, ingress and pod are clean/deleted of any
-// cert-manager created data.
+// CleanUp will ensure the created service, ingress or route and pod are
+// clean/deleted of any cert-manager created data.
 func (s *Solver) CleanUp(ctx context.Context, issuer v1alpha1.GenericIssuer, ch *v1alpha1.Challenge) error {
 	var errs []error
 	errs = append(errs, s.cleanupPods(ctx, ch))
 	errs = append(errs, s.cleanupServices(ctx, ch))
-	errs = append(errs, s.cleanupIngresses(ctx, issuer, ch))
+	if httpRouteCfgForChallenge(ch) != nil {
+		errs = append(errs, s.cleanupRoutes(ctx, ch))
+	} else {
+		errs = append(errs, s.cleanupIngresses(ctx, issuer, ch))
+	}
 	return utilerrors.NewAggregate(errs)
 }
 
@@ -19,24 +19,24 @@ package http
 import (
 	"context"
 	"fmt"
+	"reflect"
 
-	extv1beta1 "k8s.io/api/extensions/v1beta1"
 	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/selection"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
-	"k8s.io/apimachinery/pkg/util/intstr"
 
 	"github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha1"
 	"github.com/jetstack/cert-manager/pkg/issuer/acme/http/solver"
 	logf "github.com/jetstack/cert-manager/pkg/logs"
 	"github.com/jetstack/cert-manager/pkg/util"
+	ingressutil "github.com/jetstack/cert-manager/pkg/util/ingress"
 )
 
 // getIngressesForChallenge returns a list of Ingresses that were created to solve
 // http challenges for the given domain
-func (s *Solver) getIngressesForChallenge(ctx context.Context, ch *v1alpha1.Challenge) ([]*extv1beta1.Ingress, error) {
+func (s *Solver) getIngressesForChallenge(ctx context.Context, ch *v1alpha1.Challenge) ([]*ingressutil.Ingress, error) {
 	log := logf.FromContext(ctx)
 
 	podLabels := podLabels(ch)
@@ -50,12 +50,12 @@ func (s *Solver) getIngressesForChallenge(ctx context.Context, ch *v1alpha1.Chal
 	}
 
 	log.V(logf.DebugLevel).Info("checking for existing HTTP01 solver ingresses")
-	ingressList, err := s.ingressLister.Ingresses(ch.Namespace).List(selector)
+	ingressList, err := s.ingressClient.List(ch.Namespace, selector)
 	if err != nil {
 		return nil, err
 	}
 
-	var relevantIngresses []*extv1beta1.Ingress
+	var relevantIngresses []*ingressutil.Ingress
 	for _, ingress := range ingressList {
 		if !metav1.IsControlledBy(ingress, ch) {
 			logf.WithRelatedResource(log, ingress).Info("found existing solver ingress for this challenge resource, however " +
@@ -71,7 +71,7 @@ func (s *Solver) getIngressesForChallenge(ctx context.Context, ch *v1alpha1.Chal
 // ensureIngress will ensure the ingress required to solve this challenge
 // exists, or if an existing ingress is specified on the secret will ensure
 // that the ingress has an appropriate challenge path configured
-func (s *Solver) ensureIngress(ctx context.Context, issuer v1alpha1.GenericIssuer, ch *v1alpha1.Challenge, svcName string) (ing *extv1beta1.Ingress, err error) {
+func (s *Solver) ensureIngress(ctx context.Context, issuer v1alpha1.GenericIssuer, ch *v1alpha1.Challenge, svcName string) (ing *ingressutil.Ingress, err error) {
 	log := logf.FromContext(ctx).WithName("ensureIngress")
 	httpDomainCfg, err := httpDomainCfgForChallenge(issuer, ch)
 	if err != nil {
@@ -106,15 +106,15 @@ func (s *Solver) ensureIngress(ctx context.Context, issuer v1alpha1.GenericIssue
 
 // createIngress will create a challenge solving pod for the given certificate,
 // domain, token and key.
-func (s *Solver) createIngress(issuer v1alpha1.GenericIssuer, ch *v1alpha1.Challenge, svcName string) (*extv1beta1.Ingress, error) {
+func (s *Solver) createIngress(issuer v1alpha1.GenericIssuer, ch *v1alpha1.Challenge, svcName string) (*ingressutil.Ingress, error) {
 	ing, err := buildIngressResource(issuer, ch, svcName)
 	if err != nil {
 		return nil, err
 	}
-	return s.Client.ExtensionsV1beta1().Ingresses(ch.Namespace).Create(ing)
+	return s.ingressClient.Create(ing)
 }
 
-func buildIngressResource(issuer v1alpha1.GenericIssuer, ch *v1alpha1.Challenge, svcName string) (*extv1beta1.Ingress, error) {
+func buildIngressResource(issuer v1alpha1.GenericIssuer, ch *v1alpha1.Challenge, svcName string) (*ingressutil.Ingress, error) {
 	httpDomainCfg, err := httpDomainCfgForChallenge(issuer, ch)
 	if err != nil {
 		return nil, err
@@ -135,7 +135,7 @@ func buildIngressResource(issuer v1alpha1.GenericIssuer, ch *v1alpha1.Challenge,
 
 	ingPathToAdd := ingressPath(ch.Spec.Token, svcName)
 
-	return &extv1beta1.Ingress{
+	return &ingressutil.Ingress{
 		ObjectMeta: metav1.ObjectMeta{
 			GenerateName:    "cm-acme-http-solver-",
 			Namespace:       ch.Namespace,
@@ -143,14 +143,13 @@ func buildIngressResource(issuer v1alpha1.GenericIssuer, ch *v1alpha1.Challenge,
 			Annotations:     ingAnnotations,
 			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(ch, challengeGvk)},
 		},
-		Spec: extv1beta1.IngressSpec{
-			Rules: []extv1beta1.IngressRule{
+		Spec: ingressutil.IngressSpec{
+			IngressClassName: httpDomainCfg.IngressClassName,
+			Rules: []ingressutil.IngressRule{
 				{
 					Host: ch.Spec.DNSName,
-					IngressRuleValue: extv1beta1.IngressRuleValue{
-						HTTP: &extv1beta1.HTTPIngressRuleValue{
-							Paths: []extv1beta1.HTTPIngressPath{ingPathToAdd},
-						},
+					HTTP: &ingressutil.HTTPIngressRuleValue{
+						Paths: []ingressutil.HTTPIngressPath{ingPathToAdd},
 					},
 				},
 			},
@@ -158,53 +157,51 @@ func buildIngressResource(issuer v1alpha1.GenericIssuer, ch *v1alpha1.Challenge,
 	}, nil
 }
 
-func (s *Solver) addChallengePathToIngress(ctx context.Context, issuer v1alpha1.GenericIssuer, ch *v1alpha1.Challenge, svcName string) (*extv1beta1.Ingress, error) {
+func (s *Solver) addChallengePathToIngress(ctx context.Context, issuer v1alpha1.GenericIssuer, ch *v1alpha1.Challenge, svcName string) (*ingressutil.Ingress, error) {
 	httpDomainCfg, err := httpDomainCfgForChallenge(issuer, ch)
 	if err != nil {
 		return nil, err
 	}
 	ingressName := httpDomainCfg.Name
 
-	ing, err := s.ingressLister.Ingresses(ch.Namespace).Get(ingressName)
+	ing, err := s.ingressClient.Get(ch.Namespace, ingressName)
 	if err != nil {
 		return nil, err
 	}
 
 	ingPathToAdd := ingressPath(ch.Spec.Token, svcName)
 	// check for an existing Rule for the given domain on the ingress resource
-	for _, rule := range ing.Spec.Rules {
+	for r := range ing.Spec.Rules {
+		rule := &ing.Spec.Rules[r]
 		if rule.Host == ch.Spec.DNSName {
 			if rule.HTTP == nil {
-				rule.HTTP = &extv1beta1.HTTPIngressRuleValue{}
+				rule.HTTP = &ingressutil.HTTPIngressRuleValue{}
 			}
 			for i, p := range rule.HTTP.Paths {
 				// if an existing path exists on this rule for the challenge path,
 				// we overwrite it else we'll confuse ingress controllers
 				if p.Path == ingPathToAdd.Path {
 					// ingress resource is already up to date
-					if p.Backend.ServiceName == ingPathToAdd.Backend.ServiceName &&
-						p.Backend.ServicePort == ingPathToAdd.Backend.ServicePort {
+					if reflect.DeepEqual(p.Backend, ingPathToAdd.Backend) {
 						return ing, nil
 					}
 					rule.HTTP.Paths[i] = ingPathToAdd
-					return s.Client.ExtensionsV1beta1().Ingresses(ing.Namespace).Update(ing)
+					return s.ingressClient.Update(ing)
 				}
 			}
-			rule.HTTP.Paths = append([]extv1beta1.HTTPIngressPath{ingPathToAdd}, rule.HTTP.Paths...)
-			return s.Client.ExtensionsV1beta1().Ingresses(ing.Namespace).Update(ing)
+			rule.HTTP.Paths = append([]ingressutil.HTTPIngressPath{ingPathToAdd}, rule.HTTP.Paths...)
+			return s.ingressClient.Update(ing)
 		}
 	}
 
 	// if one doesn't exist, create a new IngressRule
-	ing.Spec.Rules = append(ing.Spec.Rules, extv1beta1.IngressRule{
+	ing.Spec.Rules = append(ing.Spec.Rules, ingressutil.IngressRule{
 		Host: ch.Spec.DNSName,
-		IngressRuleValue: extv1beta1.IngressRuleValue{
-			HTTP: &extv1beta1.HTTPIngressRuleValue{
-				Paths: []extv1beta1.HTTPIngressPath{ingPathToAdd},
-			},
+		HTTP: &ingressutil.HTTPIngressRuleValue{
+			Paths: []ingressutil.HTTPIngressPath{ingPathToAdd},
 		},
 	})
-	return s.Client.ExtensionsV1beta1().Ingresses(ing.Namespace).Update(ing)
+	return s.ingressClient.Update(ing)
 }
 
 // cleanupIngresses will remove the rules added by cert-manager to an existing
@@ -231,7 +228,7 @@ func (s *Solver) cleanupIngresses(ctx context.Context, issuer v1alpha1.GenericIs
 			log := logf.WithRelatedResource(log, ingress).V(logf.DebugLevel)
 
 			log.Info("deleting ingress resource")
-			err := s.Client.ExtensionsV1beta1().Ingresses(ingress.Namespace).Delete(ingress.Name, nil)
+			err := s.ingressClient.Delete(ingress.Namespace, ingress.Name)
 			if err != nil {
 				log.Info("failed to delete ingress resource", "error", err)
 				errs = append(errs, err)
@@ -243,7 +240,7 @@ func (s *Solver) cleanupIngresses(ctx context.Context, issuer v1alpha1.GenericIs
 	}
 
 	// otherwise, we need to remove any cert-manager added rules from the ingress resource
-	ing, err := s.Client.ExtensionsV1beta1().Ingresses(ch.Namespace).Get(existingIngressName, metav1.GetOptions{})
+	ing, err := s.ingressClient.Get(ch.Namespace, existingIngressName)
 	if k8sErrors.IsNotFound(err) {
 		log.Error(err, "named ingress resource not found, skipping cleanup")
 		return nil
@@ -255,7 +252,7 @@ func (s *Solver) cleanupIngresses(ctx context.Context, issuer v1alpha1.GenericIs
 
 	log.Info("attempting to clean up automatically added solver paths on ingress resource")
 	ingPathToDel := solverPathFn(ch.Spec.Token)
-	var ingRules []extv1beta1.IngressRule
+	var ingRules []ingressutil.IngressRule
 	for _, rule := range ing.Spec.Rules {
 		// always retain rules that are not for the same DNSName
 		if rule.Host != ch.Spec.DNSName {
@@ -286,7 +283,7 @@ func (s *Solver) cleanupIngresses(ctx context.Context, issuer v1alpha1.GenericIs
 
 	ing.Spec.Rules = ingRules
 
-	_, err = s.Client.ExtensionsV1beta1().Ingresses(ing.Namespace).Update(ing)
+	_, err = s.ingressClient.Update(ing)
 	if err != nil {
 		return err
 	}
@@ -298,12 +295,16 @@ func (s *Solver) cleanupIngresses(ctx context.Context, issuer v1alpha1.GenericIs
 
 // ingressPath returns the ingress HTTPIngressPath object needed to solve this
 // challenge.
-func ingressPath(token, serviceName string) extv1beta1.HTTPIngressPath {
-	return extv1beta1.HTTPIngressPath{
+func ingressPath(token, serviceName string) ingressutil.HTTPIngressPath {
+	return ingressutil.HTTPIngressPath{
 		Path: solverPathFn(token),
-		Backend: extv1beta1.IngressBackend{
-			ServiceName: serviceName,
-			ServicePort: intstr.FromInt(acmeSolverListenPort),
+		Backend: ingressutil.IngressBackend{
+			Service: &ingressutil.IngressServiceBackend{
+				Name: serviceName,
+				Port: ingressutil.ServiceBackendPort{
+					Number: acmeSolverListenPort,
+				},
+			},
 		},
 	}
 }
@@ -22,16 +22,16 @@ import (
 	"reflect"
 	"testing"
 
-	"k8s.io/api/extensions/v1beta1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/diff"
-	"k8s.io/apimachinery/pkg/util/intstr"
 	coretesting "k8s.io/client-go/testing"
 
 	"github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha1"
 	"github.com/jetstack/cert-manager/pkg/controller/test"
+	ingressutil "github.com/jetstack/cert-manager/pkg/util/ingress"
 )
 
 func TestGetIngressesForChallenge(t *testing.T) {
@@ -66,9 +66,9 @@ func TestGetIngressesForChallenge(t *testing.T) {
 			},
 			CheckFn: func(t *testing.T, s *solverFixture, args ...interface{}) {
 				s.Builder.Sync()
-				createdIngress := s.testResources[createdIngressKey].(*v1beta1.Ingress)
+				createdIngress := s.testResources[createdIngressKey].(*ingressutil.Ingress)
 				s.Builder.Sync()
-				resp := args[0].([]*v1beta1.Ingress)
+				resp := args[0].([]*ingressutil.Ingress)
 				if len(resp) != 1 {
 					t.Errorf("expected one ingress to be returned, but got %d", len(resp))
 					t.Fail()
@@ -99,7 +99,7 @@ func TestGetIngressesForChallenge(t *testing.T) {
 				s.Builder.Sync()
 			},
 			CheckFn: func(t *testing.T, s *solverFixture, args ...interface{}) {
-				resp := args[0].([]*v1beta1.Ingress)
+				resp := args[0].([]*ingressutil.Ingress)
 				if len(resp) != 0 {
 					t.Errorf("expected zero ingresses to be returned, but got %d", len(resp))
 					t.Fail()
@@ -157,8 +157,8 @@ func TestCleanupIngresses(t *testing.T) {
 			},
 			CheckFn: func(t *testing.T, s *solverFixture, args ...interface{}) {
 				s.Builder.Sync()
-				createdIngress := s.testResources[createdIngressKey].(*v1beta1.Ingress)
-				ing, err := s.Builder.FakeKubeClient().ExtensionsV1beta1().Ingresses(s.Challenge.Namespace).Get(createdIngress.Name, metav1.GetOptions{})
+				createdIngress := s.testResources[createdIngressKey].(*ingressutil.Ingress)
+				ing, err := getIngress(s, s.Challenge.Namespace, createdIngress.Name)
 				if err != nil && !apierrors.IsNotFound(err) {
 					t.Errorf("error when getting test ingress, expected 'not found' but got: %v", err)
 				}
@@ -189,8 +189,8 @@ func TestCleanupIngresses(t *testing.T) {
 				s.testResources[createdIngressKey] = ing
 			},
 			CheckFn: func(t *testing.T, s *solverFixture, args ...interface{}) {
-				createdIngress := s.testResources[createdIngressKey].(*v1beta1.Ingress)
-				_, err := s.Builder.FakeKubeClient().ExtensionsV1beta1().Ingresses(s.Challenge.Namespace).Get(createdIngress.Name, metav1.GetOptions{})
+				createdIngress := s.testResources[createdIngressKey].(*ingressutil.Ingress)
+				_, err := getIngress(s, s.Challenge.Namespace, createdIngress.Name)
 				if apierrors.IsNotFound(err) {
 					t.Errorf("expected ingress resource %q to not be deleted, but it was deleted", createdIngress.Name)
 				}
@@ -201,40 +201,35 @@ func TestCleanupIngresses(t *testing.T) {
 		},
 		"should clean up an ingress with a single challenge path inserted": {
 			Builder: &test.Builder{
-				KubeObjects: []runtime.Object{
-					&v1beta1.Ingress{
+				DynamicObjects: []runtime.Object{
+					mustUnstructuredIngress(t, &ingressutil.Ingress{
 						ObjectMeta: metav1.ObjectMeta{
 							Name:      "testingress",
 							Namespace: defaultTestNamespace,
 						},
-						Spec: v1beta1.IngressSpec{
-							Backend: &v1beta1.IngressBackend{
-								ServiceName: "testsvc",
-								ServicePort: intstr.FromInt(8080),
-							},
-							Rules: []v1beta1.IngressRule{
+						Spec: ingressutil.IngressSpec{
+							DefaultBackend: serviceBackend("testsvc", 8080),
+							Rules: []ingressutil.IngressRule{
 								{
 									Host: "example.com",
-									IngressRuleValue: v1beta1.IngressRuleValue{
-										HTTP: &v1beta1.HTTPIngressRuleValue{
-											Paths: []v1beta1.HTTPIngressPath{
-												{
-													Path: "/.well-known/acme-challenge/abcd",
-													Backend: v1beta1.IngressBackend{
-														ServiceName: "solversvc",
-														ServicePort: intstr.FromInt(8081),
-													},
-												},
+									HTTP: &ingressutil.HTTPIngressRuleValue{
+										Paths: []ingressutil.HTTPIngressPath{
+											{
+												Path:    "/.well-known/acme-challenge/abcd",
+												Backend: *serviceBackend("solversvc", 8081),
 											},
 										},
 									},
 								},
 							},
 						},
-					},
+					}),
 				},
 			},
 			Challenge: &v1alpha1.Challenge{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: defaultTestNamespace,
+				},
 				Spec: v1alpha1.ChallengeSpec{
 					DNSName: "example.com",
 					Token:   "abcd",
@@ -248,10 +243,10 @@ func TestCleanupIngresses(t *testing.T) {
 			PreFn: func(t *testing.T, s *solverFixture) {
 			},
 			CheckFn: func(t *testing.T, s *solverFixture, args ...interface{}) {
-				expectedIng := s.KubeObjects[0].(*v1beta1.Ingress).DeepCopy()
+				expectedIng := mustIngress(t, s.DynamicObjects[0])
 				expectedIng.Spec.Rules = nil
 
-				actualIng, err := s.Builder.FakeKubeClient().ExtensionsV1beta1().Ingresses(s.Challenge.Namespace).Get(expectedIng.Name, metav1.GetOptions{})
+				actualIng, err := getIngress(s, s.Challenge.Namespace, expectedIng.Name)
 				if apierrors.IsNotFound(err) {
 					t.Errorf("expected ingress resource %q to not be deleted, but it was deleted", expectedIng.Name)
 				}
@@ -266,56 +261,46 @@ func TestCleanupIngresses(t *testing.T) {
 		},
 		"should clean up an ingress with a single challenge path inserted without removing second HTTP rule": {
 			Builder: &test.Builder{
-				KubeObjects: []runtime.Object{
-					&v1beta1.Ingress{
+				DynamicObjects: []runtime.Object{
+					mustUnstructuredIngress(t, &ingressutil.Ingress{
 						ObjectMeta: metav1.ObjectMeta{
 							Name:      "testingress",
 							Namespace: defaultTestNamespace,
 						},
-						Spec: v1beta1.IngressSpec{
-							Backend: &v1beta1.IngressBackend{
-								ServiceName: "testsvc",
-								ServicePort: intstr.FromInt(8080),
-							},
-							Rules: []v1beta1.IngressRule{
+						Spec: ingressutil.IngressSpec{
+							DefaultBackend: serviceBackend("testsvc", 8080),
+							Rules: []ingressutil.IngressRule{
 								{
 									Host: "example.com",
-									IngressRuleValue: v1beta1.IngressRuleValue{
-										HTTP: &v1beta1.HTTPIngressRuleValue{
-											Paths: []v1beta1.HTTPIngressPath{
-												{
-													Path: "/.well-known/acme-challenge/abcd",
-													Backend: v1beta1.IngressBackend{
-														ServiceName: "solversvc",
-														ServicePort: intstr.FromInt(8081),
-													},
-												},
+									HTTP: &ingressutil.HTTPIngressRuleValue{
+										Paths: []ingressutil.HTTPIngressPath{
+											{
+												Path:    "/.well-known/acme-challenge/abcd",
+												Backend: *serviceBackend("solversvc", 8081),
 											},
 										},
 									},
 								},
 								{
 									Host: "a.example.com",
-									IngressRuleValue: v1beta1.IngressRuleValue{
-										HTTP: &v1beta1.HTTPIngressRuleValue{
-											Paths: []v1beta1.HTTPIngressPath{
-												{
-													Path: "/",
-													Backend: v1beta1.IngressBackend{
-														ServiceName: "real-backend-svc",
-														ServicePort: intstr.FromInt(8081),
-													},
-												},
+									HTTP: &ingressutil.HTTPIngressRuleValue{
+										Paths: []ingressutil.HTTPIngressPath{
+											{
+												Path:    "/",
+												Backend: *serviceBackend("real-backend-svc", 8081),
 											},
 										},
 									},
 								},
 							},
 						},
-					},
+					}),
 				},
 			},
 			Challenge: &v1alpha1.Challenge{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: defaultTestNamespace,
+				},
 				Spec: v1alpha1.ChallengeSpec{
 					DNSName: "example.com",
 					Token:   "abcd",
@@ -329,10 +314,10 @@ func TestCleanupIngresses(t *testing.T) {
 			PreFn: func(t *testing.T, s *solverFixture) {
 			},
 			CheckFn: func(t *testing.T, s *solverFixture, args ...interface{}) {
-				expectedIng := s.KubeObjects[0].(*v1beta1.Ingress).DeepCopy()
-				expectedIng.Spec.Rules = []v1beta1.IngressRule{expectedIng.Spec.Rules[1]}
+				expectedIng := mustIngress(t, s.DynamicObjects[0])
+				expectedIng.Spec.Rules = []ingressutil.IngressRule{expectedIng.Spec.Rules[1]}
 
-				actualIng, err := s.Builder.FakeKubeClient().ExtensionsV1beta1().Ingresses(s.Challenge.Namespace).Get(expectedIng.Name, metav1.GetOptions{})
+				actualIng, err := getIngress(s, s.Challenge.Namespace, expectedIng.Name)
 				if apierrors.IsNotFound(err) {
 					t.Errorf("expected ingress resource %q to not be deleted, but it was deleted", expectedIng.Name)
 				}
@@ -359,7 +344,7 @@ func TestCleanupIngresses(t *testing.T) {
 			},
 			Err: true,
 			PreFn: func(t *testing.T, s *solverFixture) {
-				s.Builder.FakeKubeClient().PrependReactor("delete", "ingresses", func(action coretesting.Action) (handled bool, ret runtime.Object, err error) {
+				s.Builder.FakeDynamicClient().PrependReactor("delete", "ingresses", func(action coretesting.Action) (handled bool, ret runtime.Object, err error) {
 					return true, nil, fmt.Errorf("simulated error")
 				})
 				ing, err := s.Solver.createIngress(fakeIssuer, s.Challenge, "fakeservice")
@@ -385,3 +370,78 @@ func TestCleanupIngresses(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildIngressResource(t *testing.T) {
+	ch := &v1alpha1.Challenge{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: defaultTestNamespace,
+		},
+		Spec: v1alpha1.ChallengeSpec{
+			DNSName: "example.com",
+			Token:   "abcd",
+			Solver: &v1alpha1.ACMEChallengeSolver{
+				HTTP01: &v1alpha1.ACMEChallengeSolverHTTP01{
+					Ingress: &v1alpha1.ACMEChallengeSolverHTTP01Ingress{
+						IngressClassName: strPtr("nginx"),
+					},
+				},
+			},
+		},
+	}
+
+	ing, err := buildIngressResource(nil, ch, "fakeservice")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if ing.Spec.IngressClassName == nil || *ing.Spec.IngressClassName != "nginx" {
+		t.Errorf("expected ingressClassName to be %q, got %v", "nginx", ing.Spec.IngressClassName)
+	}
+	if _, ok := ing.Annotations["kubernetes.io/ingress.class"]; ok {
+		t.Errorf("expected ingress class annotation to not be set")
+	}
+	expectedPaths := []ingressutil.HTTPIngressPath{
+		{
+			Path:    "/.well-known/acme-challenge/abcd",
+			Backend: *serviceBackend("fakeservice", acmeSolverListenPort),
+		},
+	}
+	if len(ing.Spec.Rules) != 1 || !reflect.DeepEqual(ing.Spec.Rules[0].HTTP.Paths, expectedPaths) {
+		t.Errorf("unexpected ingress rules: %v", diff.ObjectDiff(expectedPaths, ing.Spec.Rules))
+	}
+}
+
+func serviceBackend(name string, port int32) *ingressutil.IngressBackend {
+	return &ingressutil.IngressBackend{
+		Service: &ingressutil.IngressServiceBackend{
+			Name: name,
+			Port: ingressutil.ServiceBackendPort{Number: port},
+		},
+	}
+}
+
+func mustUnstructuredIngress(t *testing.T, ing *ingressutil.Ingress) *unstructured.Unstructured {
+	u, err := ingressutil.ToUnstructured(ing, ingressutil.ExtensionsV1beta1)
+	if err != nil {
+		t.Fatalf("error converting ingress: %v", err)
+	}
+	return u
+}
+
+func mustIngress(t *testing.T, obj runtime.Object) *ingressutil.Ingress {
+	ing, err := ingressutil.FromUnstructured(obj.(*unstructured.Unstructured))
+	if err != nil {
+		t.Fatalf("error converting ingress: %v", err)
+	}
+	return ing
+}
+
+// getIngress reads an Ingress directly from the fake dynamic client,
+// bypassing the solver's informer cache.
+func getIngress(s *solverFixture, namespace, name string) (*ingressutil.Ingress, error) {
+	u, err := s.Builder.FakeDynamicClient().Resource(s.Builder.IngressResource).Namespace(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return ingressutil.FromUnstructured(u)
+}
@@ -148,16 +148,51 @@ func (s *Solver) buildPod(ch *v1alpha1.Challenge) *corev1.Pod {
 		pod = s.mergePodObjectMetaWithPodTemplate(pod,
 			ch.Spec.Solver.HTTP01.Ingress.PodTemplate)
 	}
+	if routeCfg := httpRouteCfgForChallenge(ch); routeCfg != nil {
+		pod = s.mergePodObjectMetaWithPodTemplate(pod, routeCfg.PodTemplate)
+	}
+	if ch.Spec.Solver != nil && ch.Spec.Solver.TLSALPN01 != nil {
+		pod = s.mergePodObjectMetaWithPodTemplate(pod,
+			ch.Spec.Solver.TLSALPN01.PodTemplate)
+	}
 
 	return pod
 }
 
+// solverResourceNamePrefix returns the GenerateName prefix used for the
+// solver pod and service of the given challenge.
+func solverResourceNamePrefix(ch *v1alpha1.Challenge) string {
+	if ch.Spec.Type == tlsALPN01ChallengeType {
+		return "cm-acme-tls-alpn-solver-"
+	}
+	return "cm-acme-http-solver-"
+}
+
 func (s *Solver) buildDefaultPod(ch *v1alpha1.Challenge) *corev1.Pod {
 	podLabels := podLabels(ch)
 
+	listenPort := int32(acmeSolverListenPort)
+	portName := "http"
+	args := []string{
+		fmt.Sprintf("--listen-port=%d", acmeSolverListenPort),
+		fmt.Sprintf("--domain=%s", ch.Spec.DNSName),
+		fmt.Sprintf("--token=%s", ch.Spec.Token),
+		fmt.Sprintf("--key=%s", ch.Spec.Key),
+	}
+	if ch.Spec.Type == tlsALPN01ChallengeType {
+		listenPort = acmeSolverTLSListenPort
+		portName = "https"
+		args = []string{
+			fmt.Sprintf("--listen-port=%d", acmeSolverTLSListenPort),
+			fmt.Sprintf("--challenge-type=%s", tlsALPN01ChallengeType),
+			fmt.Sprintf("--domain=%s", ch.Spec.DNSName),
+			fmt.Sprintf("--key=%s", ch.Spec.Key),
+		}
+	}
+
 	return &corev1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
-			GenerateName: "cm-acme-http-solver-",
+			GenerateName: solverResourceNamePrefix(ch),
 			Namespace:    ch.Namespace,
 			Labels:       podLabels,
 			Annotations: map[string]string{
@@ -174,12 +209,7 @@ func (s *Solver) buildDefaultPod(ch *v1alpha1.Challenge) *corev1.Pod {
 					Image:           s.Context.HTTP01SolverImage,
 					ImagePullPolicy: corev1.PullIfNotPresent,
 					// TODO: replace this with some kind of cmdline generator
-					Args: []string{
-						fmt.Sprintf("--listen-port=%d", acmeSolverListenPort),
-						fmt.Sprintf("--domain=%s", ch.Spec.DNSName),
-						fmt.Sprintf("--token=%s", ch.Spec.Token),
-						fmt.Sprintf("--key=%s", ch.Spec.Key),
-					},
+					Args: args,
 					Resources: corev1.ResourceRequirements{
 						Requests: corev1.ResourceList{
 							corev1.ResourceCPU:    s.ACMEOptions.HTTP01SolverResourceRequestCPU,
@@ -192,8 +222,8 @@ func (s *Solver) buildDefaultPod(ch *v1alpha1.Challenge) *corev1.Pod {
 					},
 					Ports: []corev1.ContainerPort{
 						{
-							Name:          "http",
-							ContainerPort: acmeSolverListenPort,
+							Name:          portName,
+							ContainerPort: listenPort,
 						},
 					},
 				},
@@ -0,0 +1,156 @@
+/*
+Copyright 2019 The Jetstack cert-manager contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha1"
+	logf "github.com/jetstack/cert-manager/pkg/logs"
+	"github.com/jetstack/cert-manager/pkg/util/route"
+)
+
+// getRoutesForChallenge returns a list of OpenShift Routes that were created
+// to solve http challenges for the given domain
+func (s *Solver) getRoutesForChallenge(ctx context.Context, ch *v1alpha1.Challenge) ([]*route.Route, error) {
+	log := logf.FromContext(ctx)
+
+	log.V(logf.DebugLevel).Info("checking for existing HTTP01 solver routes")
+	routeList, err := s.dynamicClient.Resource(route.GroupVersionResource).Namespace(ch.Namespace).List(metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(podLabels(ch)).String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var relevantRoutes []*route.Route
+	for i := range routeList.Items {
+		rt, err := route.FromUnstructured(&routeList.Items[i])
+		if err != nil {
+			return nil, err
+		}
+		if !metav1.IsControlledBy(rt, ch) {
+			logf.WithRelatedResourceName(log, rt.Name, rt.Namespace, "Route").Info("found existing solver route for this challenge resource, however " +
+				"it does not have an appropriate OwnerReference referencing this challenge. Skipping it altogether.")
+			continue
+		}
+		relevantRoutes = append(relevantRoutes, rt)
+	}
+
+	return relevantRoutes, nil
+}
+
+// ensureRoute will ensure the OpenShift Route required to solve this
+// challenge exists
+func (s *Solver) ensureRoute(ctx context.Context, ch *v1alpha1.Challenge, svcName string) (*route.Route, error) {
+	log := logf.FromContext(ctx).WithName("ensureRoute")
+	existingRoutes, err := s.getRoutesForChallenge(ctx, ch)
+	if err != nil {
+		return nil, err
+	}
+	if len(existingRoutes) == 1 {
+		logf.WithRelatedResourceName(log, existingRoutes[0].Name, existingRoutes[0].Namespace, "Route").Info("found one existing HTTP01 solver route")
+		return existingRoutes[0], nil
+	}
+	if len(existingRoutes) > 1 {
+		log.Info("multiple challenge solver routes found for challenge. cleaning up all existing routes.")
+		err := s.cleanupRoutes(ctx, ch)
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("multiple existing challenge solver routes found and cleaned up. retrying challenge sync")
+	}
+
+	log.Info("creating HTTP01 challenge solver route")
+	return s.createRoute(ch, svcName)
+}
+
+// createRoute will create a challenge solving route for the given challenge
+// and solver service.
+func (s *Solver) createRoute(ch *v1alpha1.Challenge, svcName string) (*route.Route, error) {
+	u, err := route.ToUnstructured(buildRoute(ch, svcName))
+	if err != nil {
+		return nil, err
+	}
+	u, err = s.dynamicClient.Resource(route.GroupVersionResource).Namespace(ch.Namespace).Create(u, metav1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return route.FromUnstructured(u)
+}
+
+func buildRoute(ch *v1alpha1.Challenge, svcName string) *route.Route {
+	routeLabels := podLabels(ch)
+	for k, v := range httpRouteCfgForChallenge(ch).Labels {
+		// never allow the solver identification labels to be overridden, as
+		// they are used to find the routes again during cleanup
+		if _, ok := routeLabels[k]; !ok {
+			routeLabels[k] = v
+		}
+	}
+
+	return &route.Route{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName:    "cm-acme-http-solver-",
+			Namespace:       ch.Namespace,
+			Labels:          routeLabels,
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(ch, challengeGvk)},
+		},
+		Spec: route.RouteSpec{
+			Host: ch.Spec.DNSName,
+			Path: solverPathFn(ch.Spec.Token),
+			To: route.RouteTargetReference{
+				Kind: "Service",
+				Name: svcName,
+			},
+			Port: &route.RoutePort{
+				TargetPort: intstr.FromString("http"),
+			},
+		},
+	}
+}
+
+// cleanupRoutes will delete the routes created to solve the given challenge.
+func (s *Solver) cleanupRoutes(ctx context.Context, ch *v1alpha1.Challenge) error {
+	log := logf.FromContext(ctx, "cleanupRoutes")
+
+	routes, err := s.getRoutesForChallenge(ctx, ch)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, rt := range routes {
+		log := logf.WithRelatedResourceName(log, rt.Name, rt.Namespace, "Route").V(logf.DebugLevel)
+
+		log.Info("deleting route resource")
+		err := s.dynamicClient.Resource(route.GroupVersionResource).Namespace(rt.Namespace).Delete(rt.Name, nil)
+		if err != nil {
+			log.Info("failed to delete route resource", "error", err)
+			errs = append(errs, err)
+			continue
+		}
+		log.Info("successfully deleted route resource")
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2019 The Jetstack cert-manager contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha1"
+	"github.com/jetstack/cert-manager/pkg/controller/test"
+	"github.com/jetstack/cert-manager/pkg/util/route"
+)
+
+func routeChallenge() *v1alpha1.Challenge {
+	return &v1alpha1.Challenge{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-challenge",
+			Namespace: defaultTestNamespace,
+			UID:       "challenge-uid",
+		},
+		Spec: v1alpha1.ChallengeSpec{
+			DNSName: "example.com",
+			Token:   "token",
+			Solver: &v1alpha1.ACMEChallengeSolver{
+				HTTP01: &v1alpha1.ACMEChallengeSolverHTTP01{
+					Route: &v1alpha1.ACMEChallengeSolverHTTP01Route{
+						Labels: map[string]string{"router": "public"},
+					},
+				},
+			},
+		},
+	}
+}
+
+func existingRoute(t *testing.T, name string, ch *v1alpha1.Challenge) runtime.Object {
+	rt := buildRoute(ch, "fakeservice")
+	rt.Name = name
+	u, err := route.ToUnstructured(rt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
+
+func listRoutes(t *testing.T, s *solverFixture) []unstructured.Unstructured {
+	list, err := s.Builder.DynamicClient.Resource(route.GroupVersionResource).Namespace(defaultTestNamespace).List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("error listing routes: %v", err)
+	}
+	return list.Items
+}
+
+func TestEnsureRoute(t *testing.T) {
+	ch := routeChallenge()
+	otherCh := routeChallenge()
+	otherCh.UID = "other-uid"
+
+	tests := map[string]solverFixture{
+		"should create a new route if none exist": {
+			Challenge: ch,
+			CheckFn: func(t *testing.T, s *solverFixture, args ...interface{}) {
+				routes := listRoutes(t, s)
+				if len(routes) != 1 {
+					t.Fatalf("expected one route to exist, but got %d", len(routes))
+				}
+				rt, err := route.FromUnstructured(&routes[0])
+				if err != nil {
+					t.Fatal(err)
+				}
+				if rt.Spec.Host != "example.com" {
+					t.Errorf("expected host %q, but got %q", "example.com", rt.Spec.Host)
+				}
+				if rt.Spec.Path != "/.well-known/acme-challenge/token" {
+					t.Errorf("expected path %q, but got %q", "/.well-known/acme-challenge/token", rt.Spec.Path)
+				}
+				if rt.Spec.To.Kind != "Service" || rt.Spec.To.Name != "fakeservice" {
+					t.Errorf("expected route to target Service fakeservice, but got %s %s", rt.Spec.To.Kind, rt.Spec.To.Name)
+				}
+				if rt.Labels["router"] != "public" {
+					t.Errorf("expected configured labels to be set on route, but got %v", rt.Labels)
+				}
+				if !metav1.IsControlledBy(rt, s.Challenge) {
+					t.Errorf("expected route to be owned by the challenge")
+				}
+			},
+		},
+		"should return an existing route": {
+			Challenge: ch,
+			Builder: &test.Builder{
+				DynamicObjects: []runtime.Object{existingRoute(t, "existing", ch)},
+			},
+			CheckFn: func(t *testing.T, s *solverFixture, args ...interface{}) {
+				if rt := args[0].(*route.Route); rt == nil || rt.Name != "existing" {
+					t.Errorf("expected existing route to be returned, but got %v", rt)
+				}
+				if routes := listRoutes(t, s); len(routes) != 1 {
+					t.Errorf("expected no additional routes to be created, but got %d", len(routes))
+				}
+			},
+		},
+		"should ignore routes owned by other challenges": {
+			Challenge: ch,
+			Builder: &test.Builder{
+				DynamicObjects: []runtime.Object{existingRoute(t, "other", otherCh)},
+			},
+			CheckFn: func(t *testing.T, s *solverFixture, args ...interface{}) {
+				if routes := listRoutes(t, s); len(routes) != 2 {
+					t.Errorf("expected a new route to be created, but got %d routes", len(routes))
+				}
+			},
+		},
+		"should clean up and error if multiple routes exist": {
+			Challenge: ch,
+			Builder: &test.Builder{
+				DynamicObjects: []runtime.Object{
+					existingRoute(t, "first", ch),
+					existingRoute(t, "second", ch),
+				},
+			},
+			Err: true,
+			CheckFn: func(t *testing.T, s *solverFixture, args ...interface{}) {
+				if routes := listRoutes(t, s); len(routes) != 0 {
+					t.Errorf("expected all routes to be cleaned up, but got %d", len(routes))
+				}
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			test.Setup(t)
+			resp, err := test.Solver.ensureRoute(context.TODO(), test.Challenge, "fakeservice")
+			if err != nil && !test.Err {
+				t.Errorf("Expected function to not error, but got: %v", err)
+			}
+			if err == nil && test.Err {
+				t.Errorf("Expected function to get an error, but got: %v", err)
+			}
+			test.Finish(t, resp)
+		})
+	}
+}
+
+func TestCleanupRoutes(t *testing.T) {
+	ch := routeChallenge()
+	otherCh := routeChallenge()
+	otherCh.UID = "other-uid"
+
+	s := solverFixture{
+		Challenge: ch,
+		Builder: &test.Builder{
+			DynamicObjects: []runtime.Object{
+				existingRoute(t, "owned", ch),
+				existingRoute(t, "other", otherCh),
+			},
+		},
+	}
+	s.Setup(t)
+	err := s.Solver.cleanupRoutes(context.TODO(), ch)
+	if err != nil {
+		t.Errorf("Expected function to not error, but got: %v", err)
+	}
+	routes := listRoutes(t, &s)
+	if len(routes) != 1 || routes[0].GetName() != "other" {
+		t.Errorf("expected only the route owned by another challenge to remain, but got %v", routes)
+	}
+	s.Finish(t)
+}
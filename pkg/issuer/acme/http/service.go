@@ -100,10 +100,14 @@ func (s *Solver) createService(issuer v1alpha1.GenericIssuer, ch *v1alpha1.Chall
 }
 
 func buildService(issuer v1alpha1.GenericIssuer, ch *v1alpha1.Challenge) (*corev1.Service, error) {
+	if ch.Spec.Type == tlsALPN01ChallengeType {
+		return buildTLSALPN01Service(ch)
+	}
+
 	podLabels := podLabels(ch)
 	service := &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
-			GenerateName: "cm-acme-http-solver-",
+			GenerateName: solverResourceNamePrefix(ch),
 			Namespace:    ch.Namespace,
 			Labels:       podLabels,
 			Annotations: map[string]string{
@@ -124,6 +128,13 @@ func buildService(issuer v1alpha1.GenericIssuer, ch *v1alpha1.Challenge) (*corev
 		},
 	}
 
+	if routeCfg := httpRouteCfgForChallenge(ch); routeCfg != nil {
+		if routeCfg.ServiceType != "" {
+			service.Spec.Type = routeCfg.ServiceType
+		}
+		return service, nil
+	}
+
 	// checking for presence of http01 config and if set serviceType is set, override our default (NodePort)
 	httpDomainCfg, err := httpDomainCfgForChallenge(issuer, ch)
 	if err != nil {
@@ -0,0 +1,169 @@
+/*
+Copyright 2019 The Jetstack cert-manager contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package solver
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"fmt"
+	"math/big"
+	"net"
+	"time"
+
+	logf "github.com/jetstack/cert-manager/pkg/logs"
+)
+
+// idPeACMEIdentifier is the OID of the acmeIdentifier certificate extension
+// defined in RFC 8737 section 3.
+var idPeACMEIdentifier = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 1, 31}
+
+type TLSALPN01Solver struct {
+	ListenPort int
+
+	Domain string
+	Key    string
+}
+
+func (s *TLSALPN01Solver) Listen(ctx context.Context) error {
+	l, err := net.Listen("tcp", fmt.Sprintf(":%d", s.ListenPort))
+	if err != nil {
+		return err
+	}
+	return s.Serve(ctx, l)
+}
+
+// Serve answers tls-alpn-01 challenge requests on the given listener until it
+// is closed.
+func (s *TLSALPN01Solver) Serve(ctx context.Context, l net.Listener) error {
+	log := logf.FromContext(ctx)
+	log.Info("starting listener",
+		"expected_domain", s.Domain,
+		"expected_key", s.Key,
+		"listen_address", l.Addr().String(),
+	)
+
+	cert, err := TLSALPN01ChallengeCert(s.Domain, s.Key)
+	if err != nil {
+		return err
+	}
+
+	cfg := &tls.Config{
+		NextProtos: []string{ACMETLS1Protocol},
+		GetCertificate: func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			log := log.WithValues("server_name", hello.ServerName, "protocols", hello.SupportedProtos)
+			log.Info("validating request")
+			if !supportsProtocol(hello.SupportedProtos, ACMETLS1Protocol) {
+				log.Info("client does not support the acme-tls/1 protocol")
+				return nil, fmt.Errorf("client does not support the %s protocol", ACMETLS1Protocol)
+			}
+			if hello.ServerName != s.Domain {
+				log.Info("invalid server name", "expected_server_name", s.Domain)
+				return nil, fmt.Errorf("unexpected server name %q", hello.ServerName)
+			}
+			log.Info("got successful challenge request, presenting validation certificate")
+			return cert, nil
+		},
+	}
+
+	tl := tls.NewListener(l, cfg)
+	defer tl.Close()
+	for {
+		conn, err := tl.Accept()
+		if err != nil {
+			return err
+		}
+		go func() {
+			defer conn.Close()
+			// the ACME server closes the connection once the handshake is
+			// complete, so there is nothing to serve after it
+			conn.SetDeadline(time.Now().Add(10 * time.Second))
+			if err := conn.(*tls.Conn).Handshake(); err != nil {
+				log.Info("TLS handshake failed", "remote_address", conn.RemoteAddr().String(), "error", err.Error())
+			}
+		}()
+	}
+}
+
+// TLSALPN01ChallengeCert returns a self-signed certificate for domain that
+// carries the acmeIdentifier extension for the given key authorization.
+func TLSALPN01ChallengeCert(domain, keyAuth string) (*tls.Certificate, error) {
+	ext, err := TLSALPN01ValidationExtension(keyAuth)
+	if err != nil {
+		return nil, err
+	}
+
+	pk, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: domain},
+		DNSNames:              []string{domain},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		ExtraExtensions:       []pkix.Extension{ext},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &pk.PublicKey, pk)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tls.Certificate{
+		Certificate: [][]byte{der},
+		PrivateKey:  pk,
+	}, nil
+}
+
+// TLSALPN01ValidationExtension returns the critical acmeIdentifier extension
+// containing the SHA-256 digest of the given key authorization.
+func TLSALPN01ValidationExtension(keyAuth string) (pkix.Extension, error) {
+	digest := sha256.Sum256([]byte(keyAuth))
+	value, err := asn1.Marshal(digest[:])
+	if err != nil {
+		return pkix.Extension{}, err
+	}
+	return pkix.Extension{
+		Id:       idPeACMEIdentifier,
+		Critical: true,
+		Value:    value,
+	}, nil
+}
+
+func supportsProtocol(protos []string, proto string) bool {
+	for _, p := range protos {
+		if p == proto {
+			return true
+		}
+	}
+	return false
+}
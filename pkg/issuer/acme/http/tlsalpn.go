@@ -0,0 +1,227 @@
+/*
+Copyright 2019 The Jetstack cert-manager contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha1"
+	"github.com/jetstack/cert-manager/pkg/controller"
+	"github.com/jetstack/cert-manager/pkg/issuer/acme/http/solver"
+	logf "github.com/jetstack/cert-manager/pkg/logs"
+)
+
+const (
+	// TLSALPN01Timeout is the max amount of time to wait for a TLS-ALPN-01
+	// challenge to succeed
+	TLSALPN01Timeout = time.Minute * 15
+)
+
+// TLSALPN01Solver is an implementation of the acme tls-alpn-01 challenge
+// solver protocol. It shares the solver pod and service management of the
+// HTTP01 Solver, but does not create any Ingress resources.
+type TLSALPN01Solver struct {
+	*Solver
+
+	testReachability tlsALPNReachabilityTest
+}
+
+type tlsALPNReachabilityTest func(ctx context.Context, addr, domain, key string) error
+
+// NewTLSALPN01Solver returns a new ACME TLS-ALPN-01 solver.
+func NewTLSALPN01Solver(ctx *controller.Context) *TLSALPN01Solver {
+	return &TLSALPN01Solver{
+		Solver:           NewSolver(ctx),
+		testReachability: testTLSALPNReachability,
+	}
+}
+
+func tlsALPN01LogCtx(ctx context.Context) context.Context {
+	return logf.NewContext(ctx, nil, "tlsalpn01")
+}
+
+func tlsALPN01CfgForChallenge(ch *v1alpha1.Challenge) (*v1alpha1.ACMEChallengeSolverTLSALPN01, error) {
+	if ch.Spec.Solver == nil || ch.Spec.Solver.TLSALPN01 == nil {
+		return nil, fmt.Errorf("no TLS-ALPN-01 config found on challenge. " +
+			"Ensure solvers[].tlsALPN01 is specified on your issuer resource")
+	}
+	return ch.Spec.Solver.TLSALPN01, nil
+}
+
+// Present will realise the resources required to solve the given TLS-ALPN-01
+// challenge validation in the apiserver. If those resources already exist, it
+// will return nil (i.e. this function is idempotent).
+func (s *TLSALPN01Solver) Present(ctx context.Context, issuer v1alpha1.GenericIssuer, ch *v1alpha1.Challenge) error {
+	ctx = tlsALPN01LogCtx(ctx)
+
+	_, podErr := s.ensurePod(ctx, ch)
+	_, svcErr := s.ensureService(ctx, issuer, ch)
+	return utilerrors.NewAggregate([]error{podErr, svcErr})
+}
+
+func (s *TLSALPN01Solver) Check(ctx context.Context, issuer v1alpha1.GenericIssuer, ch *v1alpha1.Challenge) error {
+	ctx = logf.NewContext(tlsALPN01LogCtx(ctx), nil, "selfCheck")
+	log := logf.FromContext(ctx)
+
+	// Present is idempotent and the state of the system may have changed
+	// since present was called by the controllers, so call it again to be
+	// certain the solver pod and service exist.
+	// if the listers are nil, that means we're in the present checks
+	// test
+	if s.podLister != nil && s.serviceLister != nil {
+		log.V(logf.DebugLevel).Info("calling Present function before running self check to ensure required resources exist")
+		err := s.Present(ctx, issuer, ch)
+		if err != nil {
+			log.V(logf.DebugLevel).Info("failed to call Present function", "error", err)
+			return err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, TLSALPN01Timeout)
+	defer cancel()
+	addr := net.JoinHostPort(ch.Spec.DNSName, strconv.Itoa(acmeSolverTLSServicePort))
+	log = log.WithValues("address", addr)
+	ctx = logf.NewContext(ctx, log)
+
+	log.V(logf.DebugLevel).Info("running self check multiple times to ensure challenge has propagated", "required_passes", s.requiredPasses)
+	for i := 0; i < s.requiredPasses; i++ {
+		err := s.testReachability(ctx, addr, ch.Spec.DNSName, ch.Spec.Key)
+		if err != nil {
+			return err
+		}
+		log.V(logf.DebugLevel).Info("reachability test passed, re-checking in 2s time")
+		time.Sleep(time.Second * 2)
+	}
+
+	log.V(logf.DebugLevel).Info("self check succeeded")
+
+	return nil
+}
+
+// CleanUp will ensure the created service and pod are deleted.
+func (s *TLSALPN01Solver) CleanUp(ctx context.Context, issuer v1alpha1.GenericIssuer, ch *v1alpha1.Challenge) error {
+	var errs []error
+	errs = append(errs, s.cleanupPods(ctx, ch))
+	errs = append(errs, s.cleanupServices(ctx, ch))
+	return utilerrors.NewAggregate(errs)
+}
+
+func buildTLSALPN01Service(ch *v1alpha1.Challenge) (*corev1.Service, error) {
+	cfg, err := tlsALPN01CfgForChallenge(ch)
+	if err != nil {
+		return nil, err
+	}
+
+	podLabels := podLabels(ch)
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: solverResourceNamePrefix(ch),
+			Namespace:    ch.Namespace,
+			Labels:       podLabels,
+			Annotations: map[string]string{
+				"auth.istio.io/443": "NONE",
+			},
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(ch, challengeGvk)},
+		},
+		Spec: corev1.ServiceSpec{
+			Type: corev1.ServiceTypeNodePort,
+			Ports: []corev1.ServicePort{
+				{
+					Name:       "https",
+					Port:       acmeSolverTLSServicePort,
+					TargetPort: intstr.FromInt(acmeSolverTLSListenPort),
+				},
+			},
+			Selector: podLabels,
+		},
+	}
+	if cfg.ServiceType != "" {
+		service.Spec.Type = cfg.ServiceType
+	}
+
+	return service, nil
+}
+
+// testTLSALPNReachability will attempt to connect to addr negotiating the
+// acme-tls/1 protocol and check that the presented certificate is a valid
+// tls-alpn-01 validation certificate for domain and key, as described in
+// RFC 8737 section 3.
+func testTLSALPNReachability(ctx context.Context, addr, domain, key string) error {
+	log := logf.FromContext(ctx)
+	log.V(logf.DebugLevel).Info("performing TLS-ALPN-01 reachability check")
+
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	if deadline, ok := ctx.Deadline(); ok {
+		dialer.Deadline = deadline
+	}
+	conn, err := tls.DialWithDialer(dialer, "tcp", addr, &tls.Config{
+		ServerName: domain,
+		NextProtos: []string{solver.ACMETLS1Protocol},
+		// the validation certificate is self-signed, and is verified below
+		InsecureSkipVerify: true, /* #nosec G402 */
+	})
+	if err != nil {
+		log.V(logf.DebugLevel).Info("failed to perform self check TLS handshake", "error", err)
+		return fmt.Errorf("failed to perform self check TLS handshake with '%s': %v", addr, err)
+	}
+	defer conn.Close()
+
+	state := conn.ConnectionState()
+	if state.NegotiatedProtocol != solver.ACMETLS1Protocol {
+		log.V(logf.DebugLevel).Info("server did not negotiate the acme-tls/1 protocol", "protocol", state.NegotiatedProtocol)
+		return fmt.Errorf("wrong negotiated protocol '%s', expected '%s'", state.NegotiatedProtocol, solver.ACMETLS1Protocol)
+	}
+	if len(state.PeerCertificates) == 0 {
+		return fmt.Errorf("no certificate presented by server")
+	}
+
+	cert := state.PeerCertificates[0]
+	if len(cert.DNSNames) != 1 || cert.DNSNames[0] != domain {
+		log.V(logf.DebugLevel).Info("presented certificate has unexpected DNS names", "dns_names", cert.DNSNames)
+		return fmt.Errorf("presented certificate DNS names %v did not match expected [%s]", cert.DNSNames, domain)
+	}
+
+	expected, err := solver.TLSALPN01ValidationExtension(key)
+	if err != nil {
+		return err
+	}
+	for _, ext := range cert.Extensions {
+		if !ext.Id.Equal(expected.Id) {
+			continue
+		}
+		if !ext.Critical || !bytes.Equal(ext.Value, expected.Value) {
+			log.V(logf.DebugLevel).Info("acmeIdentifier extension of presented certificate did not match expected")
+			return fmt.Errorf("presented acmeIdentifier extension did not match expected key authorization")
+		}
+		log.V(logf.DebugLevel).Info("reachability test succeeded")
+		return nil
+	}
+
+	log.V(logf.DebugLevel).Info("presented certificate has no acmeIdentifier extension")
+	return fmt.Errorf("presented certificate has no acmeIdentifier extension")
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2019 The Jetstack cert-manager contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha1"
+	"github.com/jetstack/cert-manager/pkg/controller"
+	"github.com/jetstack/cert-manager/pkg/issuer/acme/http/solver"
+)
+
+func TestTLSALPN01Check(t *testing.T) {
+	tests := map[string]struct {
+		reachabilityTest tlsALPNReachabilityTest
+		expectedErr      bool
+	}{
+		"should pass": {
+			reachabilityTest: func(context.Context, string, string, string) error {
+				return nil
+			},
+		},
+		"should error": {
+			reachabilityTest: func(context.Context, string, string, string) error {
+				return fmt.Errorf("failed")
+			},
+			expectedErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			calls := 0
+			requiredCallsForPass := 2
+			var addr string
+			s := TLSALPN01Solver{
+				Solver: &Solver{requiredPasses: requiredCallsForPass},
+				testReachability: func(ctx context.Context, a, domain, key string) error {
+					calls++
+					addr = a
+					return test.reachabilityTest(ctx, a, domain, key)
+				},
+			}
+
+			ch := &v1alpha1.Challenge{Spec: v1alpha1.ChallengeSpec{DNSName: "example.com"}}
+			err := s.Check(context.Background(), nil, ch)
+			if err != nil && !test.expectedErr {
+				t.Errorf("Expected Check to return non-nil error, but got %v", err)
+				return
+			}
+			if err == nil && test.expectedErr {
+				t.Errorf("Expected error from Check, but got none")
+				return
+			}
+			if addr != "example.com:443" {
+				t.Errorf("Expected reachability test against example.com:443, but got %q", addr)
+			}
+			if !test.expectedErr && calls != requiredCallsForPass {
+				t.Errorf("Expected Check to verify reachability test passes %d times, but only checked %d", requiredCallsForPass, calls)
+			}
+		})
+	}
+}
+
+func TestTestTLSALPNReachability(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	s := &solver.TLSALPN01Solver{Domain: "example.com", Key: "token.thumbprint"}
+	go s.Serve(context.Background(), l)
+
+	tests := map[string]struct {
+		domain      string
+		key         string
+		expectedErr bool
+	}{
+		"should pass if the validation certificate matches": {
+			domain: "example.com",
+			key:    "token.thumbprint",
+		},
+		"should error if the key authorization does not match": {
+			domain:      "example.com",
+			key:         "other.thumbprint",
+			expectedErr: true,
+		},
+		"should error if the solver does not answer for the domain": {
+			domain:      "other.example.com",
+			key:         "token.thumbprint",
+			expectedErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := testTLSALPNReachability(context.Background(), l.Addr().String(), test.domain, test.key)
+			if err != nil && !test.expectedErr {
+				t.Errorf("Expected reachability test to pass, but got %v", err)
+			}
+			if err == nil && test.expectedErr {
+				t.Errorf("Expected error from reachability test, but got none")
+			}
+		})
+	}
+}
+
+func TestBuildTLSALPN01SolverResources(t *testing.T) {
+	s := &Solver{Context: &controller.Context{ACMEOptions: controller.ACMEOptions{HTTP01SolverImage: "acmesolver"}}}
+	ch := &v1alpha1.Challenge{
+		Spec: v1alpha1.ChallengeSpec{
+			Type:    "tls-alpn-01",
+			DNSName: "example.com",
+			Token:   "token",
+			Key:     "token.thumbprint",
+			Solver: &v1alpha1.ACMEChallengeSolver{
+				TLSALPN01: &v1alpha1.ACMEChallengeSolverTLSALPN01{
+					ServiceType: corev1.ServiceTypeClusterIP,
+					PodTemplate: &v1alpha1.ACMEChallengeSolverHTTP01IngressPodTemplate{
+						Spec: v1alpha1.ACMEChallengeSolverHTTP01IngressPodSpec{
+							NodeSelector: map[string]string{"edge": "true"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	pod := s.buildPod(ch)
+	if pod.GenerateName != "cm-acme-tls-alpn-solver-" {
+		t.Errorf("unexpected pod generateName %q", pod.GenerateName)
+	}
+	expectedArgs := []string{
+		"--listen-port=8443",
+		"--challenge-type=tls-alpn-01",
+		"--domain=example.com",
+		"--key=token.thumbprint",
+	}
+	if args := pod.Spec.Containers[0].Args; !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("unexpected pod args %v, expected %v", args, expectedArgs)
+	}
+	if port := pod.Spec.Containers[0].Ports[0]; port.Name != "https" || port.ContainerPort != 8443 {
+		t.Errorf("unexpected pod port %v", port)
+	}
+	if pod.Spec.NodeSelector["edge"] != "true" {
+		t.Errorf("expected pod template node selector to be applied, got %v", pod.Spec.NodeSelector)
+	}
+
+	svc, err := buildService(nil, ch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if svc.Spec.Type != corev1.ServiceTypeClusterIP {
+		t.Errorf("unexpected service type %q", svc.Spec.Type)
+	}
+	expectedPorts := []corev1.ServicePort{
+		{Name: "https", Port: 443, TargetPort: intstr.FromInt(8443)},
+	}
+	if !reflect.DeepEqual(svc.Spec.Ports, expectedPorts) {
+		t.Errorf("unexpected service ports %v, expected %v", svc.Spec.Ports, expectedPorts)
+	}
+}
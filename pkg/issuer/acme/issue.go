@@ -33,6 +33,7 @@ import (
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 
 	"github.com/jetstack/cert-manager/pkg/acme"
+	apiutil "github.com/jetstack/cert-manager/pkg/api/util"
 	"github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha1"
 	"github.com/jetstack/cert-manager/pkg/issuer"
 	logf "github.com/jetstack/cert-manager/pkg/logs"
@@ -87,6 +88,11 @@ func (a *Acme) Issue(ctx context.Context, crt *v1alpha1.Certificate) (*issuer.Is
 		return nil, err
 	}
 
+	// Let the user know if the ACME server has rate limited requests for any
+	// of the Certificate's domains, as the Order will not progress until the
+	// rate limit has expired.
+	a.setRateLimitCondition(crt, expectedOrder)
+
 	// Cleanup Order resources that are owned by this Certificate but are not
 	// up to date (i.e. do not match the requirements on the Certificate).
 	// Because the order name returned by buildOrder is a hash of its spec, we
@@ -215,6 +221,28 @@ func (a *Acme) Issue(ctx context.Context, crt *v1alpha1.Certificate) (*issuer.Is
 	}, nil
 }
 
+// setRateLimitCondition sets the RateLimited condition on the Certificate if
+// the issuer has recorded that the ACME server rate limited requests for any
+// of the Order's domains. Once the rate limit has expired, an existing
+// condition is set to False.
+func (a *Acme) setRateLimitCondition(crt *v1alpha1.Certificate, o *v1alpha1.Order) {
+	dnsNames := append([]string{o.Spec.CommonName}, o.Spec.DNSNames...)
+	domain, retryAfter, limited := acme.RateLimitedUntil(a.issuer, dnsNames, a.clock.Now())
+	if limited {
+		apiutil.SetCertificateCondition(crt, v1alpha1.CertificateConditionRateLimited, v1alpha1.ConditionTrue, "RateLimited",
+			fmt.Sprintf("Rate limited by the ACME server for domain %q, will retry after %s", domain, retryAfter.Format(time.RFC3339)))
+		return
+	}
+
+	if apiutil.CertificateHasCondition(crt, v1alpha1.CertificateCondition{
+		Type:   v1alpha1.CertificateConditionRateLimited,
+		Status: v1alpha1.ConditionTrue,
+	}) {
+		apiutil.SetCertificateCondition(crt, v1alpha1.CertificateConditionRateLimited, v1alpha1.ConditionFalse, "RateLimitExpired",
+			"The ACME server rate limit has expired")
+	}
+}
+
 func (a *Acme) cleanupOwnedOrders(ctx context.Context, crt *v1alpha1.Certificate, retain string) error {
 	log := logf.FromContext(ctx)
 
@@ -278,6 +306,9 @@ func (a *Acme) getCertificatePrivateKey(ctx context.Context, crt *v1alpha1.Certi
 	// private key too to avoid this case.
 	key, err := kube.SecretTLSKey(ctx, a.secretsLister, crt.Namespace, crt.Spec.SecretName)
 	if err == nil {
+		if crt.Spec.RotationPolicy == v1alpha1.RotationPolicyAlways {
+			return a.nextCertificatePrivateKey(ctx, crt, key)
+		}
 		return key, false, nil
 	}
 
@@ -299,6 +330,32 @@ func (a *Acme) getCertificatePrivateKey(ctx context.Context, crt *v1alpha1.Certi
 	return privateKey, true, nil
 }
 
+// nextCertificatePrivateKey returns the private key to be used when the
+// Certificate requires a new private key for every issuance.
+// Once the Secret contains a certificate, orders are created for the next
+// private key, which is persisted separately so that the existing key pair is
+// not replaced until the order has completed.
+func (a *Acme) nextCertificatePrivateKey(ctx context.Context, crt *v1alpha1.Certificate, current crypto.Signer) (crypto.Signer, bool, error) {
+	secret, err := a.secretsLister.Secrets(crt.Namespace).Get(crt.Spec.SecretName)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(secret.Data[corev1.TLSCertKey]) == 0 {
+		return current, false, nil
+	}
+
+	logf.FromContext(ctx).V(logf.DebugLevel).Info("rotation policy is Always, using next private key")
+	keyBytes, err := kube.NextPrivateKey(a.Client, a.secretsLister, crt, current)
+	if err != nil {
+		return nil, false, err
+	}
+	key, err := pki.DecodePrivateKeyBytes(keyBytes)
+	if err != nil {
+		return nil, false, err
+	}
+	return key, false, nil
+}
+
 func (a *Acme) createNewOrder(ctx context.Context, crt *v1alpha1.Certificate, template *v1alpha1.Order, key crypto.Signer) error {
 	log := logf.FromContext(ctx)
 	log = logf.WithRelatedResource(log, template)
@@ -400,7 +400,84 @@ func TestIssueRetryCases(t *testing.T) {
 		},
 	}
 
+	retryAfter := nowTime.Add(time.Hour)
+	rateLimitedIssuer := &v1alpha1.Issuer{
+		Spec: v1alpha1.IssuerSpec{
+			IssuerConfig: v1alpha1.IssuerConfig{
+				ACME: &v1alpha1.ACMEIssuer{},
+			},
+		},
+		Status: v1alpha1.IssuerStatus{
+			ACME: &v1alpha1.ACMEIssuerStatus{
+				RateLimits: []v1alpha1.ACMERateLimit{
+					{Domain: "test.com", RetryAfter: metav1.NewTime(retryAfter)},
+				},
+			},
+		},
+	}
+	rateLimitedCertificate := testCert.DeepCopy()
+	rateLimitedCertificate.Status.Conditions = []v1alpha1.CertificateCondition{
+		{
+			Type:               v1alpha1.CertificateConditionRateLimited,
+			Status:             v1alpha1.ConditionTrue,
+			Reason:             "RateLimited",
+			Message:            fmt.Sprintf(`Rate limited by the ACME server for domain "test.com", will retry after %s`, retryAfter.Format(time.RFC3339)),
+			LastTransitionTime: &nowMetaTime,
+		},
+	}
+	rateLimitExpiredCertificate := testCert.DeepCopy()
+	rateLimitExpiredCertificate.Status.Conditions = []v1alpha1.CertificateCondition{
+		{
+			Type:               v1alpha1.CertificateConditionRateLimited,
+			Status:             v1alpha1.ConditionFalse,
+			Reason:             "RateLimitExpired",
+			Message:            "The ACME server rate limit has expired",
+			LastTransitionTime: &nowMetaTime,
+		},
+	}
+
 	tests := map[string]acmeFixture{
+		"set the RateLimited condition if the issuer has recorded a rate limit for the certificate's domain": {
+			Issuer:      rateLimitedIssuer,
+			Certificate: testCert,
+			Builder: &testpkg.Builder{
+				Clock:              fixedClock,
+				CertManagerObjects: []runtime.Object{pendingTestOrderCSR1},
+				KubeObjects:        []runtime.Object{testCertExistingPKSecret},
+				ExpectedActions:    []testpkg.Action{},
+			},
+			CheckFn: func(t *testing.T, s *acmeFixture, args ...interface{}) {
+				returnedCert := args[0].(*v1alpha1.Certificate)
+				resp := args[1].(*issuer.IssueResponse)
+
+				if resp != nil {
+					t.Errorf("expected IssuerResponse to be nil")
+				}
+				if !reflect.DeepEqual(returnedCert, rateLimitedCertificate) {
+					t.Errorf("expected RateLimited condition to be set: %s", pretty.Diff(returnedCert, rateLimitedCertificate))
+				}
+			},
+			Err: false,
+		},
+
+		"set the RateLimited condition to False once the rate limit has expired": {
+			Certificate: rateLimitedCertificate,
+			Builder: &testpkg.Builder{
+				Clock:              fixedClock,
+				CertManagerObjects: []runtime.Object{pendingTestOrderCSR1},
+				KubeObjects:        []runtime.Object{testCertExistingPKSecret},
+				ExpectedActions:    []testpkg.Action{},
+			},
+			CheckFn: func(t *testing.T, s *acmeFixture, args ...interface{}) {
+				returnedCert := args[0].(*v1alpha1.Certificate)
+
+				if !reflect.DeepEqual(returnedCert, rateLimitExpiredCertificate) {
+					t.Errorf("expected RateLimited condition to be False: %s", pretty.Diff(returnedCert, rateLimitExpiredCertificate))
+				}
+			},
+			Err: false,
+		},
+
 		"delete existing order and create a new one immediately if the order hash has changed": {
 			Certificate: testCert,
 			Builder: &testpkg.Builder{
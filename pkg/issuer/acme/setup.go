@@ -18,7 +18,8 @@ package acme
 
 import (
 	"context"
-	"crypto/rsa"
+	"crypto"
+	"encoding/base64"
 	"fmt"
 	"net/url"
 	"strings"
@@ -43,15 +44,23 @@ const (
 	errorAccountRegistrationFailed = "ErrRegisterACMEAccount"
 	errorAccountVerificationFailed = "ErrVerifyACMEAccount"
 	errorAccountUpdateFailed       = "ErrUpdateACMEAccount"
+	errorAccountKeyRolloverFailed  = "ErrRolloverACMEAccountKey"
 
-	successAccountRegistered = "ACMEAccountRegistered"
-	successAccountVerified   = "ACMEAccountVerified"
+	successAccountRegistered    = "ACMEAccountRegistered"
+	successAccountVerified      = "ACMEAccountVerified"
+	successAccountKeyRolledOver = "ACMEAccountKeyRolledOver"
 
 	messageAccountRegistrationFailed = "Failed to register ACME account: "
 	messageAccountVerificationFailed = "Failed to verify ACME account: "
 	messageAccountUpdateFailed       = "Failed to update ACME account:"
+	messageAccountKeyRolloverFailed  = "Failed to roll over ACME account private key: "
 	messageAccountRegistered         = "The ACME account was registered with the ACME server"
 	messageAccountVerified           = "The ACME account was verified with the ACME server"
+	messageAccountKeyRolledOver      = "The ACME account private key was rolled over to a new key"
+
+	// nextAccountKeySuffix is appended to the private key's Secret key to
+	// store the new private key while it is being rolled over.
+	nextAccountKeySuffix = ".next"
 )
 
 // Setup will verify an existing ACME registration, or create one if not
@@ -152,6 +161,34 @@ func (a *Acme) Setup(ctx context.Context) error {
 		return nil
 	}
 
+	// if the private key does not match the configured key algorithm and
+	// size, roll the account over to a new private key.
+	if !acme.AccountPrivateKeyMatches(a.issuer.GetSpec().ACME, pk) {
+		log.Info("rolling over acme account private key")
+		pk, err = a.rolloverAccountKey(ctx, cl, a.issuer.GetSpec().ACME.PrivateKey, ns)
+		if err != nil {
+			s := messageAccountKeyRolloverFailed + err.Error()
+			log.Error(err, "failed to roll over ACME account private key")
+			a.Recorder.Event(a.issuer, v1.EventTypeWarning, errorAccountKeyRolloverFailed, s)
+			apiutil.SetIssuerCondition(a.issuer, v1alpha1.IssuerConditionReady, v1alpha1.ConditionFalse, errorAccountKeyRolloverFailed, s)
+
+			// If the ACME server rejected the new key, retrying will not help
+			if acmeErr, ok := err.(*acmeapi.Error); ok && acmeErr.StatusCode >= 400 && acmeErr.StatusCode < 500 {
+				return nil
+			}
+			return err
+		}
+		a.Recorder.Event(a.issuer, v1.EventTypeNormal, successAccountKeyRolledOver, messageAccountKeyRolledOver)
+
+		acme.ClearClientCache()
+		cl, err = acme.ClientWithKey(a.issuer, pk)
+		if err != nil {
+			s := messageAccountVerificationFailed + err.Error()
+			apiutil.SetIssuerCondition(a.issuer, v1alpha1.IssuerConditionReady, v1alpha1.ConditionFalse, errorAccountVerificationFailed, s)
+			return err
+		}
+	}
+
 	hasReadyCondition := apiutil.IssuerHasCondition(a.issuer, v1alpha1.IssuerCondition{
 		Type:   v1alpha1.IssuerConditionReady,
 		Status: v1alpha1.ConditionTrue,
@@ -176,9 +213,30 @@ func (a *Acme) Setup(ctx context.Context) error {
 		a.issuer.GetStatus().ACMEStatus().URI = ""
 	}
 
+	// the external account binding is only sent when a new account is
+	// registered, but it is read here so that a misconfigured binding is
+	// reported on the issuer before contacting the ACME server.
+	var eab *acmeapi.ExternalAccountBinding
+	if eabSpec := a.issuer.GetSpec().ACME.ExternalAccountBinding; eabSpec != nil {
+		eab, err = a.externalAccountBinding(eabSpec, ns)
+		switch {
+		case apierrors.IsNotFound(err), errors.IsInvalidData(err):
+			s := messageAccountRegistrationFailed + err.Error()
+			a.Recorder.Event(a.issuer, v1.EventTypeWarning, errorAccountRegistrationFailed, s)
+			apiutil.SetIssuerCondition(a.issuer, v1alpha1.IssuerConditionReady, v1alpha1.ConditionFalse, errorAccountRegistrationFailed, s)
+			// the issuer will be resynced when the secret is changed
+			return nil
+
+		case err != nil:
+			s := messageAccountRegistrationFailed + err.Error()
+			apiutil.SetIssuerCondition(a.issuer, v1alpha1.IssuerConditionReady, v1alpha1.ConditionFalse, errorAccountRegistrationFailed, s)
+			return fmt.Errorf(s)
+		}
+	}
+
 	// registerAccount will also verify the account exists if it already
 	// exists.
-	account, err := a.registerAccount(ctx, cl)
+	account, err := a.registerAccount(ctx, cl, eab)
 	if err != nil {
 		s := messageAccountVerificationFailed + err.Error()
 		log.Error(err, "failed to verify ACME account")
@@ -275,8 +333,9 @@ func ensureEmailUpToDate(ctx context.Context, cl client.Interface, acc *acmeapi.
 // registerAccount will register a new ACME account with the server. If an
 // account with the clients private key already exists, it will attempt to look
 // up and verify the corresponding account, and will return that. If this fails
-// due to a not found error it will register a new account with the given key.
-func (a *Acme) registerAccount(ctx context.Context, cl client.Interface) (*acmeapi.Account, error) {
+// due to a not found error it will register a new account with the given key,
+// binding it to the given external account if one is provided.
+func (a *Acme) registerAccount(ctx context.Context, cl client.Interface, eab *acmeapi.ExternalAccountBinding) (*acmeapi.Account, error) {
 	// check if the account already exists
 	acc, err := cl.GetAccount(ctx)
 	if err == nil {
@@ -296,8 +355,9 @@ func (a *Acme) registerAccount(ctx context.Context, cl client.Interface) (*acmea
 	}
 
 	acc = &acmeapi.Account{
-		Contact:     emailurl,
-		TermsAgreed: true,
+		Contact:                emailurl,
+		TermsAgreed:            true,
+		ExternalAccountBinding: eab,
 	}
 
 	acc, err = cl.CreateAccount(ctx, acc)
@@ -312,11 +372,120 @@ func (a *Acme) registerAccount(ctx context.Context, cl client.Interface) (*acmea
 	return acc, nil
 }
 
-// createAccountPrivateKey will generate a new RSA private key, and create it
-// as a secret resource in the apiserver.
-func (a *Acme) createAccountPrivateKey(sel v1alpha1.SecretKeySelector, ns string) (*rsa.PrivateKey, error) {
+// externalAccountBinding reads the MAC key of the given external account
+// binding from its secret and returns the binding to be used when
+// registering a new account.
+func (a *Acme) externalAccountBinding(eab *v1alpha1.ACMEExternalAccountBinding, ns string) (*acmeapi.ExternalAccountBinding, error) {
+	secret, err := a.secretsLister.Secrets(ns).Get(eab.Key.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	data, ok := secret.Data[eab.Key.Key]
+	if !ok {
+		return nil, errors.NewInvalidData("No secret data found for key %q in secret %q", eab.Key.Key, eab.Key.Name)
+	}
+
+	// CAs hand out the key as un-padded base64 URL encoded data, but we
+	// tolerate padding and trailing whitespace as both are easily introduced
+	// when copying the key into a Secret.
+	key, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(strings.TrimSpace(string(data)), "="))
+	if err != nil {
+		return nil, errors.NewInvalidData("External account binding key in secret %q is not base64 URL encoded: %v", eab.Key.Name, err)
+	}
+
+	return &acmeapi.ExternalAccountBinding{
+		KID:       eab.KeyID,
+		Key:       key,
+		Algorithm: string(eab.KeyAlgorithm),
+	}, nil
+}
+
+// rolloverAccountKey will replace the ACME account private key stored in the
+// given secret with a newly generated key of the configured algorithm and
+// size, keeping the existing ACME account.
+// The new key is stored in the secret before the ACME server is asked to
+// change the account key, so that it is not lost if the secret cannot be
+// updated afterwards. An interrupted rollover will reuse the stored key.
+func (a *Acme) rolloverAccountKey(ctx context.Context, cl client.Interface, sel v1alpha1.SecretKeySelector, ns string) (crypto.Signer, error) {
+	spec := a.issuer.GetSpec().ACME
 	sel = acme.PrivateKeySelector(sel)
-	accountPrivKey, err := pki.GenerateRSAPrivateKey(pki.MinRSAKeySize)
+	nextSel := sel
+	nextSel.Key = sel.Key + nextAccountKeySuffix
+
+	newKey, err := a.helper.ReadPrivateKey(nextSel, ns)
+	if err != nil && !errors.IsInvalidData(err) {
+		return nil, err
+	}
+	generate := err != nil || !acme.AccountPrivateKeyMatches(spec, newKey)
+	if generate {
+		newKey, err = acme.GenerateAccountPrivateKey(spec)
+		if err != nil {
+			return nil, err
+		}
+	}
+	newKeyPEM, err := pki.EncodePrivateKey(newKey, v1alpha1.PKCS1)
+	if err != nil {
+		return nil, err
+	}
+	if generate {
+		if err := a.updateSecretData(sel.Name, ns, map[string][]byte{nextSel.Key: newKeyPEM}); err != nil {
+			return nil, err
+		}
+	}
+
+	// If the current key is not bound to an account, either no account was
+	// ever registered or a previous rollover completed, so there is nothing
+	// to change on the ACME server.
+	err = cl.AccountKeyRollover(ctx, newKey)
+	if acmeErr, ok := err.(*acmeapi.Error); ok && acmeErr.Type == "urn:ietf:params:acme:error:accountDoesNotExist" {
+		err = nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.updateSecretData(sel.Name, ns, map[string][]byte{
+		sel.Key:     newKeyPEM,
+		nextSel.Key: nil,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return newKey, nil
+}
+
+// updateSecretData will set the given keys in the data of the named secret,
+// deleting any keys that have a nil value.
+func (a *Acme) updateSecretData(name, ns string, data map[string][]byte) error {
+	secret, err := a.Client.CoreV1().Secrets(ns).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if secret.Data == nil {
+		secret.Data = make(map[string][]byte)
+	}
+	for k, v := range data {
+		if v == nil {
+			delete(secret.Data, k)
+			continue
+		}
+		secret.Data[k] = v
+	}
+	_, err = a.Client.CoreV1().Secrets(ns).Update(secret)
+	return err
+}
+
+// createAccountPrivateKey will generate a new private key of the configured
+// algorithm and size, and create it as a secret resource in the apiserver.
+func (a *Acme) createAccountPrivateKey(sel v1alpha1.SecretKeySelector, ns string) (crypto.Signer, error) {
+	sel = acme.PrivateKeySelector(sel)
+	accountPrivKey, err := acme.GenerateAccountPrivateKey(a.issuer.GetSpec().ACME)
+	if err != nil {
+		return nil, err
+	}
+	accountPrivKeyPEM, err := pki.EncodePrivateKey(accountPrivKey, v1alpha1.PKCS1)
 	if err != nil {
 		return nil, err
 	}
@@ -327,7 +496,7 @@ func (a *Acme) createAccountPrivateKey(sel v1alpha1.SecretKeySelector, ns string
 			Namespace: ns,
 		},
 		Data: map[string][]byte{
-			sel.Key: pki.EncodePKCS1PrivateKey(accountPrivKey),
+			sel.Key: accountPrivKeyPEM,
 		},
 	})
 
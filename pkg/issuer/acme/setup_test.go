@@ -17,17 +17,35 @@ limitations under the License.
 package acme
 
 import (
+	"bytes"
 	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
 	"testing"
 
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	coretesting "k8s.io/client-go/testing"
 	featuregatetesting "k8s.io/component-base/featuregate/testing"
 
+	"github.com/jetstack/cert-manager/pkg/acme"
+	apiutil "github.com/jetstack/cert-manager/pkg/api/util"
 	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha1"
 	testpkg "github.com/jetstack/cert-manager/pkg/controller/test"
 	"github.com/jetstack/cert-manager/pkg/feature"
 	utilfeature "github.com/jetstack/cert-manager/pkg/util/feature"
+	"github.com/jetstack/cert-manager/pkg/util/pki"
 	"github.com/jetstack/cert-manager/test/unit/gen"
+	acmeapi "github.com/jetstack/cert-manager/third_party/crypto/acme"
 )
 
 type testT struct {
@@ -83,6 +101,586 @@ func TestDisableOldConfigFeatureFlagDisabled(t *testing.T) {
 	}
 }
 
+const (
+	testEABKeyID = "test-key-id"
+	testEABKey   = "test-eab-hmac-key"
+)
+
+// fakeACMEServer is a minimal ACME server that only supports registering
+// accounts and changing account keys. If requireEAB is set, new accounts must
+// be bound to the external account testEABKeyID using the HS256 MAC key
+// testEABKey.
+type fakeACMEServer struct {
+	*httptest.Server
+
+	requireEAB bool
+
+	// accounts maps the JWK thumbprint of each account key to the account's
+	// URL.
+	accounts map[string]string
+	// newAccounts is the number of accounts that were registered.
+	newAccounts int
+}
+
+// fakeJWS is a JWS request, with the protected header and payload decoded.
+type fakeJWS struct {
+	Protected struct {
+		KID string            `json:"kid"`
+		JWK map[string]string `json:"jwk"`
+		URL string            `json:"url"`
+	}
+	Payload []byte
+}
+
+func newFakeACMEServer(t *testing.T, requireEAB bool) *fakeACMEServer {
+	f := &fakeACMEServer{
+		requireEAB: requireEAB,
+		accounts:   make(map[string]string),
+	}
+	// badRequest fails the test and responds with an error. Handlers must
+	// not call t.Fatalf as they do not run in the test goroutine.
+	badRequest := func(w http.ResponseWriter, format string, args ...interface{}) {
+		msg := fmt.Sprintf(format, args...)
+		t.Error(msg)
+		http.Error(w, msg, http.StatusBadRequest)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/directory", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"newNonce":%q,"newAccount":%q,"keyChange":%q}`,
+			f.URL+"/new-nonce", f.URL+"/new-account", f.URL+"/key-change")
+	})
+	mux.HandleFunc("/new-nonce", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Replay-Nonce", "nonce")
+	})
+	mux.HandleFunc("/new-account", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Replay-Nonce", "nonce")
+
+		jws, err := decodeFakeJWS(r.Body)
+		if err != nil {
+			badRequest(w, "%v", err)
+			return
+		}
+		var req struct {
+			OnlyReturnExisting     bool `json:"onlyReturnExisting"`
+			ExternalAccountBinding *struct {
+				Protected, Payload, Signature string
+			} `json:"externalAccountBinding"`
+		}
+		if err := json.Unmarshal(jws.Payload, &req); err != nil {
+			badRequest(w, "error decoding request payload: %v", err)
+			return
+		}
+
+		thumbprint := fakeJWKThumbprint(jws.Protected.JWK)
+		if accountURL, ok := f.accounts[thumbprint]; ok {
+			w.Header().Set("Location", accountURL)
+			fmt.Fprint(w, `{"status":"valid"}`)
+			return
+		}
+		if req.OnlyReturnExisting {
+			writeACMEProblem(w, http.StatusBadRequest, "accountDoesNotExist", "No account exists with the provided key")
+			return
+		}
+
+		if f.requireEAB {
+			eab := req.ExternalAccountBinding
+			if eab == nil {
+				writeACMEProblem(w, http.StatusBadRequest, "externalAccountRequired", "External account binding is required")
+				return
+			}
+
+			head, err := base64.RawURLEncoding.DecodeString(eab.Protected)
+			if err != nil {
+				badRequest(w, "error decoding external account binding header: %v", err)
+				return
+			}
+			var h struct{ Alg, KID, URL string }
+			if err := json.Unmarshal(head, &h); err != nil {
+				badRequest(w, "error decoding external account binding header: %v", err)
+				return
+			}
+			if h.Alg != "HS256" || h.KID != testEABKeyID || h.URL != f.URL+"/new-account" {
+				writeACMEProblem(w, http.StatusBadRequest, "malformed", fmt.Sprintf("Unexpected external account binding header %s", head))
+				return
+			}
+			mac := hmac.New(sha256.New, []byte(testEABKey))
+			mac.Write([]byte(eab.Protected + "." + eab.Payload))
+			if base64.RawURLEncoding.EncodeToString(mac.Sum(nil)) != eab.Signature {
+				writeACMEProblem(w, http.StatusUnauthorized, "unauthorized", "External account binding signature is invalid")
+				return
+			}
+		}
+
+		f.newAccounts++
+		accountURL := fmt.Sprintf("%s/account/%d", f.URL, f.newAccounts)
+		f.accounts[thumbprint] = accountURL
+		w.Header().Set("Location", accountURL)
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"status":"valid"}`)
+	})
+	mux.HandleFunc("/account/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Replay-Nonce", "nonce")
+		fmt.Fprint(w, `{"status":"valid"}`)
+	})
+	mux.HandleFunc("/key-change", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Replay-Nonce", "nonce")
+
+		outer, err := decodeFakeJWS(r.Body)
+		if err != nil {
+			badRequest(w, "%v", err)
+			return
+		}
+		inner, err := decodeFakeJWS(bytes.NewReader(outer.Payload))
+		if err != nil {
+			badRequest(w, "error decoding key change request: %v", err)
+			return
+		}
+		var req struct {
+			Account string            `json:"account"`
+			OldKey  map[string]string `json:"oldKey"`
+		}
+		if err := json.Unmarshal(inner.Payload, &req); err != nil {
+			badRequest(w, "error decoding key change payload: %v", err)
+			return
+		}
+
+		oldThumbprint := fakeJWKThumbprint(req.OldKey)
+		if f.accounts[oldThumbprint] != outer.Protected.KID || req.Account != outer.Protected.KID {
+			writeACMEProblem(w, http.StatusUnauthorized, "unauthorized", "Key change request does not match the account")
+			return
+		}
+		newThumbprint := fakeJWKThumbprint(inner.Protected.JWK)
+		if _, ok := f.accounts[newThumbprint]; ok {
+			writeACMEProblem(w, http.StatusConflict, "malformed", "New key is already in use by an account")
+			return
+		}
+		delete(f.accounts, oldThumbprint)
+		f.accounts[newThumbprint] = req.Account
+	})
+	f.Server = httptest.NewServer(mux)
+	return f
+}
+
+func decodeFakeJWS(r io.Reader) (*fakeJWS, error) {
+	var raw struct{ Protected, Payload string }
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("error decoding JWS: %v", err)
+	}
+	head, err := base64.RawURLEncoding.DecodeString(raw.Protected)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding JWS protected header: %v", err)
+	}
+	jws := &fakeJWS{}
+	if err := json.Unmarshal(head, &jws.Protected); err != nil {
+		return nil, fmt.Errorf("error decoding JWS protected header: %v", err)
+	}
+	if jws.Payload, err = base64.RawURLEncoding.DecodeString(raw.Payload); err != nil {
+		return nil, fmt.Errorf("error decoding JWS payload: %v", err)
+	}
+	return jws, nil
+}
+
+// fakeJWKThumbprint returns the RFC 7638 thumbprint of the given RSA or EC
+// JWK, which matches acmeapi.JWKThumbprint of the corresponding public key.
+func fakeJWKThumbprint(jwk map[string]string) string {
+	var s string
+	if jwk["kty"] == "RSA" {
+		s = fmt.Sprintf(`{"e":%q,"kty":"RSA","n":%q}`, jwk["e"], jwk["n"])
+	} else {
+		s = fmt.Sprintf(`{"crv":%q,"kty":"EC","x":%q,"y":%q}`, jwk["crv"], jwk["x"], jwk["y"])
+	}
+	b := sha256.Sum256([]byte(s))
+	return base64.RawURLEncoding.EncodeToString(b[:])
+}
+
+func writeACMEProblem(w http.ResponseWriter, status int, typ, detail string) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, `{"type":"urn:ietf:params:acme:error:%s","detail":%q}`, typ, detail)
+}
+
+func TestSetupExternalAccountBinding(t *testing.T) {
+	pk, err := pki.GenerateRSAPrivateKey(2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	accountKeySecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "account-key",
+			Namespace: gen.DefaultTestNamespace,
+		},
+		Data: map[string][]byte{
+			"tls.key": pki.EncodePKCS1PrivateKey(pk),
+		},
+	}
+	eabSecret := func(key string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "eab-secret",
+				Namespace: gen.DefaultTestNamespace,
+			},
+			Data: map[string][]byte{
+				"secret": []byte(key),
+			},
+		}
+	}
+
+	tests := map[string]struct {
+		testT
+		expectedCondition cmapi.IssuerCondition
+		expectedAccounts  int
+	}{
+		"register a new account bound to the external account": {
+			testT: testT{
+				builder: &testpkg.Builder{
+					KubeObjects: []runtime.Object{
+						accountKeySecret,
+						eabSecret(base64.RawURLEncoding.EncodeToString([]byte(testEABKey))),
+					},
+				},
+			},
+			expectedCondition: cmapi.IssuerCondition{
+				Type:   cmapi.IssuerConditionReady,
+				Status: cmapi.ConditionTrue,
+				Reason: successAccountRegistered,
+			},
+			expectedAccounts: 1,
+		},
+		"accept a padded external account binding key": {
+			testT: testT{
+				builder: &testpkg.Builder{
+					KubeObjects: []runtime.Object{
+						accountKeySecret,
+						eabSecret(base64.URLEncoding.EncodeToString([]byte(testEABKey)) + "\n"),
+					},
+				},
+			},
+			expectedCondition: cmapi.IssuerCondition{
+				Type:   cmapi.IssuerConditionReady,
+				Status: cmapi.ConditionTrue,
+				Reason: successAccountRegistered,
+			},
+			expectedAccounts: 1,
+		},
+		"set the issuer not ready if the external account binding secret does not exist": {
+			testT: testT{
+				builder: &testpkg.Builder{
+					KubeObjects: []runtime.Object{accountKeySecret},
+					ExpectedEvents: []string{
+						`Warning ErrRegisterACMEAccount Failed to register ACME account: secret "eab-secret" not found`,
+					},
+				},
+			},
+			expectedCondition: cmapi.IssuerCondition{
+				Type:   cmapi.IssuerConditionReady,
+				Status: cmapi.ConditionFalse,
+				Reason: errorAccountRegistrationFailed,
+			},
+		},
+		"set the issuer not ready if the external account binding key is not base64 URL encoded": {
+			testT: testT{
+				builder: &testpkg.Builder{
+					KubeObjects: []runtime.Object{
+						accountKeySecret,
+						eabSecret("not+base64/url"),
+					},
+					ExpectedEvents: []string{
+						`Warning ErrRegisterACMEAccount Failed to register ACME account: External account binding key in secret "eab-secret" is not base64 URL encoded: illegal base64 data at input byte 3`,
+					},
+				},
+			},
+			expectedCondition: cmapi.IssuerCondition{
+				Type:   cmapi.IssuerConditionReady,
+				Status: cmapi.ConditionFalse,
+				Reason: errorAccountRegistrationFailed,
+			},
+		},
+		"set the issuer not ready if the ACME server rejects the external account binding": {
+			testT: testT{
+				builder: &testpkg.Builder{
+					KubeObjects: []runtime.Object{
+						accountKeySecret,
+						eabSecret(base64.RawURLEncoding.EncodeToString([]byte("wrong-key"))),
+					},
+					ExpectedEvents: []string{
+						`Warning ErrVerifyACMEAccount Failed to verify ACME account: acme: urn:ietf:params:acme:error:unauthorized: External account binding signature is invalid`,
+					},
+				},
+			},
+			expectedCondition: cmapi.IssuerCondition{
+				Type:   cmapi.IssuerConditionReady,
+				Status: cmapi.ConditionFalse,
+				Reason: errorAccountRegistrationFailed,
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			srv := newFakeACMEServer(t, true)
+			defer srv.Close()
+
+			test.issuer = gen.Issuer("testissuer",
+				gen.SetIssuerACME(cmapi.ACMEIssuer{
+					Server: srv.URL + "/directory",
+					PrivateKey: cmapi.SecretKeySelector{
+						LocalObjectReference: cmapi.LocalObjectReference{Name: "account-key"},
+					},
+					ExternalAccountBinding: &cmapi.ACMEExternalAccountBinding{
+						KeyID: testEABKeyID,
+						Key: cmapi.SecretKeySelector{
+							LocalObjectReference: cmapi.LocalObjectReference{Name: "eab-secret"},
+							Key:                  "secret",
+						},
+						KeyAlgorithm: cmapi.HS256,
+					},
+				}),
+			)
+			test.builder.CertManagerObjects = []runtime.Object{test.issuer}
+			runSetupTest(t, test.testT)
+
+			if !apiutil.IssuerHasCondition(test.issuer, test.expectedCondition) {
+				t.Errorf("expected issuer to have condition %+v, but got %+v", test.expectedCondition, test.issuer.Status.Conditions)
+			}
+			for _, c := range test.issuer.Status.Conditions {
+				if c.Type == test.expectedCondition.Type && c.Reason != test.expectedCondition.Reason {
+					t.Errorf("expected condition reason %q, but got %q", test.expectedCondition.Reason, c.Reason)
+				}
+			}
+			if srv.newAccounts != test.expectedAccounts {
+				t.Errorf("expected %d accounts to be registered, but got %d", test.expectedAccounts, srv.newAccounts)
+			}
+		})
+	}
+}
+
+func TestSetupAccountKeyRollover(t *testing.T) {
+	rsaKey, err := pki.GenerateRSAPrivateKey(2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := pki.GenerateECPrivateKey(256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKeyPEM, err := pki.EncodeECPrivateKey(ecKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaThumbprint, _ := acmeapi.JWKThumbprint(rsaKey.Public())
+	ecThumbprint, _ := acmeapi.JWKThumbprint(ecKey.Public())
+
+	// the contents of the account key secret are checked after Setup is
+	// called, as the generated keys are not known in advance
+	secretsResource := corev1.SchemeGroupVersion.WithResource("secrets")
+	anySecret := func(coretesting.Action, coretesting.Action) error { return nil }
+	getSecret := testpkg.NewAction(coretesting.NewGetAction(secretsResource, gen.DefaultTestNamespace, "account-key"))
+	updateSecret := testpkg.NewCustomMatch(coretesting.NewUpdateAction(secretsResource, gen.DefaultTestNamespace, nil), anySecret)
+	createSecret := testpkg.NewCustomMatch(coretesting.NewCreateAction(secretsResource, gen.DefaultTestNamespace, nil), anySecret)
+
+	accountKeySecret := func(data map[string][]byte) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "account-key",
+				Namespace: gen.DefaultTestNamespace,
+			},
+			Data: data,
+		}
+	}
+
+	tests := map[string]struct {
+		testT
+		// accounts are the JWK thumbprints of the account keys registered
+		// with the ACME server before Setup is called.
+		accounts []string
+		// expectedKeyAlgorithm and expectedKeySize are the algorithm and size
+		// of the account key in the Secret after Setup is called.
+		expectedKeyAlgorithm cmapi.KeyAlgorithm
+		expectedKeySize      int
+		// expectedKey, if set, is the account key expected in the Secret.
+		expectedKey        crypto.Signer
+		expectedCondition  cmapi.IssuerCondition
+		expectedAccounts   int
+		expectedAccountURI string
+	}{
+		"roll over an existing account from an RSA key to an ECDSA key": {
+			testT: testT{
+				issuer: gen.Issuer("testissuer",
+					gen.SetIssuerACME(cmapi.ACMEIssuer{
+						PrivateKeyAlgorithm: cmapi.ECDSAKeyAlgorithm,
+						PrivateKeySize:      384,
+					}),
+				),
+				builder: &testpkg.Builder{
+					KubeObjects: []runtime.Object{
+						accountKeySecret(map[string][]byte{"tls.key": pki.EncodePKCS1PrivateKey(rsaKey)}),
+					},
+					ExpectedActions: []testpkg.Action{getSecret, updateSecret, getSecret, updateSecret},
+					ExpectedEvents: []string{
+						"Normal ACMEAccountKeyRolledOver The ACME account private key was rolled over to a new key",
+					},
+				},
+			},
+			accounts:             []string{rsaThumbprint},
+			expectedKeyAlgorithm: cmapi.ECDSAKeyAlgorithm,
+			expectedKeySize:      384,
+			expectedCondition: cmapi.IssuerCondition{
+				Type:   cmapi.IssuerConditionReady,
+				Status: cmapi.ConditionTrue,
+				Reason: successAccountRegistered,
+			},
+			expectedAccountURI: "/account/1",
+		},
+		"complete a rollover that was interrupted after the ACME server changed the key": {
+			testT: testT{
+				issuer: gen.Issuer("testissuer",
+					gen.SetIssuerACME(cmapi.ACMEIssuer{
+						PrivateKeyAlgorithm: cmapi.ECDSAKeyAlgorithm,
+					}),
+				),
+				builder: &testpkg.Builder{
+					KubeObjects: []runtime.Object{
+						accountKeySecret(map[string][]byte{
+							"tls.key":      pki.EncodePKCS1PrivateKey(rsaKey),
+							"tls.key.next": ecKeyPEM,
+						}),
+					},
+					ExpectedActions: []testpkg.Action{getSecret, updateSecret},
+					ExpectedEvents: []string{
+						"Normal ACMEAccountKeyRolledOver The ACME account private key was rolled over to a new key",
+					},
+				},
+			},
+			accounts:             []string{ecThumbprint},
+			expectedKeyAlgorithm: cmapi.ECDSAKeyAlgorithm,
+			expectedKeySize:      256,
+			expectedKey:          ecKey,
+			expectedCondition: cmapi.IssuerCondition{
+				Type:   cmapi.IssuerConditionReady,
+				Status: cmapi.ConditionTrue,
+				Reason: successAccountRegistered,
+			},
+			expectedAccountURI: "/account/1",
+		},
+		"not roll over a key that matches the configured algorithm": {
+			testT: testT{
+				issuer: gen.Issuer("testissuer",
+					gen.SetIssuerACME(cmapi.ACMEIssuer{
+						PrivateKeyAlgorithm: cmapi.RSAKeyAlgorithm,
+					}),
+				),
+				builder: &testpkg.Builder{
+					KubeObjects: []runtime.Object{
+						accountKeySecret(map[string][]byte{"tls.key": pki.EncodePKCS1PrivateKey(rsaKey)}),
+					},
+				},
+			},
+			accounts:             []string{rsaThumbprint},
+			expectedKeyAlgorithm: cmapi.RSAKeyAlgorithm,
+			expectedKeySize:      2048,
+			expectedKey:          rsaKey,
+			expectedCondition: cmapi.IssuerCondition{
+				Type:   cmapi.IssuerConditionReady,
+				Status: cmapi.ConditionTrue,
+				Reason: successAccountRegistered,
+			},
+			expectedAccountURI: "/account/1",
+		},
+		"not roll over an ECDSA key if no algorithm is configured": {
+			testT: testT{
+				issuer: gen.Issuer("testissuer",
+					gen.SetIssuerACME(cmapi.ACMEIssuer{}),
+				),
+				builder: &testpkg.Builder{
+					KubeObjects: []runtime.Object{
+						accountKeySecret(map[string][]byte{"tls.key": ecKeyPEM}),
+					},
+				},
+			},
+			accounts:             []string{ecThumbprint},
+			expectedKeyAlgorithm: cmapi.ECDSAKeyAlgorithm,
+			expectedKeySize:      256,
+			expectedKey:          ecKey,
+			expectedCondition: cmapi.IssuerCondition{
+				Type:   cmapi.IssuerConditionReady,
+				Status: cmapi.ConditionTrue,
+				Reason: successAccountRegistered,
+			},
+			expectedAccountURI: "/account/1",
+		},
+		"generate an ECDSA key and register a new account": {
+			testT: testT{
+				issuer: gen.Issuer("testissuer",
+					gen.SetIssuerACME(cmapi.ACMEIssuer{
+						PrivateKeyAlgorithm: cmapi.ECDSAKeyAlgorithm,
+						PrivateKeySize:      384,
+					}),
+				),
+				builder: &testpkg.Builder{
+					ExpectedActions: []testpkg.Action{createSecret},
+				},
+			},
+			expectedKeyAlgorithm: cmapi.ECDSAKeyAlgorithm,
+			expectedKeySize:      384,
+			expectedCondition: cmapi.IssuerCondition{
+				Type:   cmapi.IssuerConditionReady,
+				Status: cmapi.ConditionTrue,
+				Reason: successAccountRegistered,
+			},
+			expectedAccounts:   1,
+			expectedAccountURI: "/account/1",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			srv := newFakeACMEServer(t, false)
+			defer srv.Close()
+			for i, thumbprint := range test.accounts {
+				srv.accounts[thumbprint] = fmt.Sprintf("%s/account/%d", srv.URL, i+1)
+			}
+
+			test.issuer.Spec.ACME.Server = srv.URL + "/directory"
+			test.issuer.Spec.ACME.PrivateKey.Name = "account-key"
+			test.builder.CertManagerObjects = []runtime.Object{test.issuer}
+			runSetupTest(t, test.testT)
+
+			if !apiutil.IssuerHasCondition(test.issuer, test.expectedCondition) {
+				t.Errorf("expected issuer to have condition %+v, but got %+v", test.expectedCondition, test.issuer.Status.Conditions)
+			}
+			if uri := test.issuer.Status.ACME.URI; uri != srv.URL+test.expectedAccountURI {
+				t.Errorf("expected account URI %q, but got %q", srv.URL+test.expectedAccountURI, uri)
+			}
+			if srv.newAccounts != test.expectedAccounts {
+				t.Errorf("expected %d accounts to be registered, but got %d", test.expectedAccounts, srv.newAccounts)
+			}
+
+			secret, err := test.builder.Client.CoreV1().Secrets(gen.DefaultTestNamespace).Get("account-key", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("error getting account key secret: %v", err)
+			}
+			if _, ok := secret.Data["tls.key.next"]; ok {
+				t.Errorf("expected the next account key to be removed from the secret")
+			}
+			pk, err := pki.DecodePrivateKeyBytes(secret.Data["tls.key"])
+			if err != nil {
+				t.Fatalf("error decoding account key: %v", err)
+			}
+			spec := &cmapi.ACMEIssuer{PrivateKeyAlgorithm: test.expectedKeyAlgorithm, PrivateKeySize: test.expectedKeySize}
+			if !acme.AccountPrivateKeyMatches(spec, pk) {
+				t.Errorf("expected a %d bit %s account key, but got %T", test.expectedKeySize, test.expectedKeyAlgorithm, pk)
+			}
+			if test.expectedKey != nil && !reflect.DeepEqual(pk.Public(), test.expectedKey.Public()) {
+				t.Errorf("expected the account key to be unchanged")
+			}
+
+			// the account must now be bound to the key in the secret
+			thumbprint, _ := acmeapi.JWKThumbprint(pk.Public())
+			if accountURL := srv.accounts[thumbprint]; accountURL != srv.URL+test.expectedAccountURI {
+				t.Errorf("expected the account key to be bound to account %q, but got %q", srv.URL+test.expectedAccountURI, accountURL)
+			}
+		})
+	}
+}
+
 func runSetupTest(t *testing.T, test testT) {
 	test.builder.T = t
 	test.builder.Init()
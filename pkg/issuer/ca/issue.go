@@ -20,7 +20,6 @@ import (
 	"context"
 
 	corev1 "k8s.io/api/core/v1"
-	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
 
 	"github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha1"
 	"github.com/jetstack/cert-manager/pkg/issuer"
@@ -47,20 +46,16 @@ func (c *CA) Issue(ctx context.Context, crt *v1alpha1.Certificate) (*issuer.Issu
 	log := logf.FromContext(ctx, "issue")
 	log = logf.WithRelatedResourceName(log, crt.Spec.SecretName, crt.Namespace, "Secret")
 
-	// get a copy of the existing/currently issued Certificate's private key
-	signeeKey, err := kube.SecretTLSKey(ctx, c.secretsLister, crt.Namespace, crt.Spec.SecretName)
-	if k8sErrors.IsNotFound(err) || errors.IsInvalidData(err) {
-		log.Info("generating new private key")
-		// if one does not already exist, generate a new one
-		signeeKey, err = pki.GeneratePrivateKeyForCertificate(crt)
-		if err != nil {
-			log.Error(err, "error generating private key")
-			c.Recorder.Eventf(crt, corev1.EventTypeWarning, "PrivateKeyError", "Error generating certificate private key: %v", err)
-			// don't trigger a retry. An error from this function implies some
-			// invalid input parameters, and retrying without updating the
-			// resource will not help.
-			return nil, nil
-		}
+	// get the private key to sign the certificate for. The certificate is
+	// signed synchronously, so any existing key is only replaced along with it.
+	signeeKey, err := kube.SecretTLSKeyForIssuance(ctx, c.secretsLister, crt)
+	if errors.IsInvalidData(err) {
+		log.Error(err, "error generating private key")
+		c.Recorder.Eventf(crt, corev1.EventTypeWarning, "PrivateKeyError", "Error generating certificate private key: %v", err)
+		// don't trigger a retry. An error from this function implies some
+		// invalid input parameters, and retrying without updating the
+		// resource will not help.
+		return nil, nil
 	}
 	if err != nil {
 		log.Error(err, "error getting private key for certificate")
@@ -92,7 +87,7 @@ func (c *CA) Issue(ctx context.Context, crt *v1alpha1.Certificate) (*issuer.Issu
 
 	template.PublicKey = signeePublicKey
 
-	certPEM, caPEM, err := pki.SignCSRTemplate(caCerts, caKey, template)
+	certPEM, caPEM, err := pki.SignCSRTemplate(caCerts, caKey, template, c.issuer.GetSpec().CA)
 	if err != nil {
 		log.Error(err, "error signing certificate")
 		c.Recorder.Eventf(crt, corev1.EventTypeWarning, "ErrorSigning", "Error signing certificate: %v", err)
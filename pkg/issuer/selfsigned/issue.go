@@ -20,7 +20,6 @@ import (
 	"context"
 
 	corev1 "k8s.io/api/core/v1"
-	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/klog"
 
 	"github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha1"
@@ -31,18 +30,14 @@ import (
 )
 
 func (c *SelfSigned) Issue(ctx context.Context, crt *v1alpha1.Certificate) (*issuer.IssueResponse, error) {
-	// get a copy of the existing/currently issued Certificate's private key
-	signeePrivateKey, err := kube.SecretTLSKey(ctx, c.secretsLister, crt.Namespace, crt.Spec.SecretName)
-	if k8sErrors.IsNotFound(err) || errors.IsInvalidData(err) {
-		// if one does not already exist, generate a new one
-		signeePrivateKey, err = pki.GeneratePrivateKeyForCertificate(crt)
-		if err != nil {
-			c.Recorder.Eventf(crt, corev1.EventTypeWarning, "PrivateKeyError", "Error generating certificate private key: %v", err)
-			// don't trigger a retry. An error from this function implies some
-			// invalid input parameters, and retrying without updating the
-			// resource will not help.
-			return nil, nil
-		}
+	// get the private key to sign the certificate for
+	signeePrivateKey, err := kube.SecretTLSKeyForIssuance(ctx, c.secretsLister, crt)
+	if errors.IsInvalidData(err) {
+		c.Recorder.Eventf(crt, corev1.EventTypeWarning, "PrivateKeyError", "Error generating certificate private key: %v", err)
+		// don't trigger a retry. An error from this function implies some
+		// invalid input parameters, and retrying without updating the
+		// resource will not help.
+		return nil, nil
 	}
 	if err != nil {
 		klog.Errorf("Error getting private key %q for certificate: %v", crt.Spec.SecretName, err)
@@ -38,8 +38,11 @@ const (
 	messageVaultStatusVerificationFailed = "Vault is not initialized or is sealed"
 	messageVaultConfigRequired           = "Vault config cannot be empty"
 	messageServerAndPathRequired         = "Vault server and path are required fields"
-	messsageAuthFieldsRequired           = "Vault tokenSecretRef or appRole is required"
-	messageAuthFieldRequired             = "Vault tokenSecretRef and appRole cannot be set on the same issuer"
+	messsageAuthFieldsRequired           = "Vault tokenSecretRef, appRole, kubernetes or clientCertificate is required"
+	messageAuthFieldRequired             = "Only one of Vault tokenSecretRef, appRole, kubernetes or clientCertificate can be set on the same issuer"
+	messageKubernetesAuthFieldsRequired  = "Vault kubernetes auth requires both role and secretRef.name to be set"
+	messageClientCertificateAuthRequired = "Vault clientCertificate auth requires secretName to be set"
+	messageCABundleFieldRequired         = "Only one of Vault caBundle or caBundleSecretRef can be set on the same issuer"
 )
 
 func (v *Vault) Setup(ctx context.Context) error {
@@ -57,26 +60,65 @@ func (v *Vault) Setup(ctx context.Context) error {
 		return nil
 	}
 
+	// count the auth methods specified.
+	authMethods := 0
+	if v.issuer.GetSpec().Vault.Auth.TokenSecretRef.Name != "" {
+		authMethods++
+	}
+	if v.issuer.GetSpec().Vault.Auth.AppRole.RoleId != "" ||
+		v.issuer.GetSpec().Vault.Auth.AppRole.SecretRef.Name != "" {
+		authMethods++
+	}
+	if v.issuer.GetSpec().Vault.Auth.Kubernetes != nil {
+		authMethods++
+	}
+	if v.issuer.GetSpec().Vault.Auth.ClientCertificate != nil {
+		authMethods++
+	}
+
 	// check if at least one auth method is specified.
-	if v.issuer.GetSpec().Vault.Auth.TokenSecretRef.Name == "" &&
-		v.issuer.GetSpec().Vault.Auth.AppRole.RoleId == "" &&
-		v.issuer.GetSpec().Vault.Auth.AppRole.SecretRef.Name == "" {
+	if authMethods == 0 {
 		klog.Infof("%s: %s", v.issuer.GetObjectMeta().Name, messsageAuthFieldsRequired)
 		apiutil.SetIssuerCondition(v.issuer, v1alpha1.IssuerConditionReady, v1alpha1.ConditionFalse, errorVault, messsageAuthFieldsRequired)
 		return nil
 	}
 
-	// check if only token auth method is set.
-	if v.issuer.GetSpec().Vault.Auth.TokenSecretRef.Name != "" &&
-		(v.issuer.GetSpec().Vault.Auth.AppRole.RoleId != "" ||
-			v.issuer.GetSpec().Vault.Auth.AppRole.SecretRef.Name != "") {
+	// check if only one auth method is set.
+	if authMethods > 1 {
 		klog.Infof("%s: %s", v.issuer.GetObjectMeta().Name, messageAuthFieldRequired)
 		apiutil.SetIssuerCondition(v.issuer, v1alpha1.IssuerConditionReady, v1alpha1.ConditionFalse, errorVault, messageAuthFieldRequired)
 		return nil
 	}
 
+	// check if all mandatory Vault clientCertificate fields are set.
+	if v.issuer.GetSpec().Vault.Auth.ClientCertificate != nil &&
+		v.issuer.GetSpec().Vault.Auth.ClientCertificate.SecretName == "" {
+		klog.Infof("%s: %s", v.issuer.GetObjectMeta().Name, messageClientCertificateAuthRequired)
+		apiutil.SetIssuerCondition(v.issuer, v1alpha1.IssuerConditionReady, v1alpha1.ConditionFalse, errorVault, messageClientCertificateAuthRequired)
+		return nil
+	}
+
+	// check if only one source of CA bundle is set.
+	if len(v.issuer.GetSpec().Vault.CABundle) > 0 &&
+		v.issuer.GetSpec().Vault.CABundleSecretRef != nil {
+		klog.Infof("%s: %s", v.issuer.GetObjectMeta().Name, messageCABundleFieldRequired)
+		apiutil.SetIssuerCondition(v.issuer, v1alpha1.IssuerConditionReady, v1alpha1.ConditionFalse, errorVault, messageCABundleFieldRequired)
+		return nil
+	}
+
+	// check if all mandatory Vault kubernetes fields are set.
+	if v.issuer.GetSpec().Vault.Auth.Kubernetes != nil &&
+		(v.issuer.GetSpec().Vault.Auth.Kubernetes.Role == "" ||
+			v.issuer.GetSpec().Vault.Auth.Kubernetes.SecretRef.Name == "") {
+		klog.Infof("%s: %s", v.issuer.GetObjectMeta().Name, messageKubernetesAuthFieldsRequired)
+		apiutil.SetIssuerCondition(v.issuer, v1alpha1.IssuerConditionReady, v1alpha1.ConditionFalse, errorVault, messageKubernetesAuthFieldsRequired)
+		return nil
+	}
+
 	// check if all mandatory Vault appRole fields are set.
 	if v.issuer.GetSpec().Vault.Auth.TokenSecretRef.Name == "" &&
+		v.issuer.GetSpec().Vault.Auth.Kubernetes == nil &&
+		v.issuer.GetSpec().Vault.Auth.ClientCertificate == nil &&
 		(v.issuer.GetSpec().Vault.Auth.AppRole.RoleId == "" ||
 			v.issuer.GetSpec().Vault.Auth.AppRole.SecretRef.Name == "") {
 		klog.Infof("%s: %s", v.issuer.GetObjectMeta().Name, messageAuthFieldRequired)
@@ -18,10 +18,9 @@ package venafi
 
 import (
 	"context"
-	"crypto/x509"
+	"encoding/pem"
 	"fmt"
 
-	"github.com/Venafi/vcert/pkg/certificate"
 	"github.com/Venafi/vcert/pkg/endpoint"
 	corev1 "k8s.io/api/core/v1"
 
@@ -41,12 +40,12 @@ const (
 // The control flow is as follows:
 // - Attempt to retrieve the existing private key
 // 		- If it does not exist, generate one
-// - Generate a certificate template
+// - Generate a CSR for the certificate
 // - Read the zone configuration from the Venafi server
-// - Create a Venafi request based on the certificate template
+// - Create a Venafi request based on the CSR
 // - Set defaults on the request based on the zone
 // - Validate the request against the zone
-// - Submit the original CSR in request request
+// - Submit the original CSR in the request
 // - Wait for the request to be fulfilled and the certificate to be available
 func (v *Venafi) Issue(ctx context.Context, crt *v1alpha1.Certificate) (*issuer.IssueResponse, error) {
 	log := logf.FromContext(ctx, "venafi")
@@ -73,14 +72,6 @@ func (v *Venafi) Issue(ctx context.Context, crt *v1alpha1.Certificate) (*issuer.
 	dbg.Info("generated new private key")
 	v.Recorder.Event(crt, corev1.EventTypeNormal, "GenerateKey", "Generated new private key")
 
-	// extract the public component of the key
-	dbg.Info("extracting public key from private key")
-	signeePublicKey, err := pki.PublicKeyForPrivateKey(signeeKey)
-	if err != nil {
-		log.Error(err, "Error getting public key from private key")
-		return nil, err
-	}
-
 	// Encode the private key ready to be saved
 	dbg.Info("encoding generated private key")
 	skPEM, err := pki.EncodePrivateKey(signeeKey, crt.Spec.KeyEncoding)
@@ -88,50 +79,41 @@ func (v *Venafi) Issue(ctx context.Context, crt *v1alpha1.Certificate) (*issuer.
 		return nil, err
 	}
 
-	// We build a x509.Certificate as the vcert library has support for converting
-	// this into its own internal Certificate Request type.
-	dbg.Info("constructing certificate request template to submit to venafi")
-	tmpl, err := pki.GenerateTemplate(crt)
+	// Generate the CSR submitted to Venafi. It is built by cert-manager
+	// rather than vcert so that it contains the requested key usages and
+	// basic constraints, and is submitted as-is after being validated
+	// against the zone's policy.
+	dbg.Info("generating CSR to submit to venafi")
+	tmpl, err := pki.GenerateCSR(crt)
 	if err != nil {
+		v.Recorder.Eventf(crt, corev1.EventTypeWarning, "GenerateCSR", "Failed to generate a CSR for the certificate: %v", err)
 		return nil, err
 	}
-
 	// TODO: we need some way to detect fields are defaulted on the template,
 	// or otherwise move certificate/csr template defaulting into its own
 	// function within the PKI package.
-	// For now, we manually 'fix' the certificate template returned above
+	// For now, we manually 'fix' the CSR template returned above so that the
+	// zone's default organization is used.
 	if len(crt.Spec.Organization) == 0 {
-		tmpl.Subject.Organization = []string{}
+		tmpl.Subject.Organization = nil
 	}
-
-	// set the PublicKey field on the certificate template so it can be checked
-	// by the vcert library
-	tmpl.PublicKey = signeePublicKey
-
-	client, err := v.clientBuilder(v.resourceNamespace, v.secretsLister, v.issuer)
+	csrDER, err := pki.EncodeCSR(tmpl, signeeKey)
 	if err != nil {
-		v.Recorder.Eventf(v.issuer, corev1.EventTypeWarning, "FailedInit", "Failed to create Venafi client: %v", err)
-		return nil, fmt.Errorf("error creating Venafi client: %s", err.Error())
+		v.Recorder.Eventf(crt, corev1.EventTypeWarning, "GenerateCSR", "Failed to generate a CSR for the certificate: %v", err)
+		return nil, err
 	}
+	csrPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csrDER})
 
-	//// Begin building Venafi certificate Request
-
-	// Create a vcert Request structure
-	vreq := newVRequest(tmpl)
-	vreq.PrivateKey = signeeKey
-
-	// Generate the actual x509 CSR and set it on the vreq
-	dbg.Info("generating CSR to submit to venafi")
-	err = vreq.GenerateCSR()
+	client, err := v.clientBuilder(v.resourceNamespace, v.secretsLister, v.secretsClient, v.issuer)
 	if err != nil {
-		v.Recorder.Eventf(crt, corev1.EventTypeWarning, "GenerateCSR", "Failed to generate a CSR for the certificate: %v", err)
-		return nil, err
+		v.Recorder.Eventf(v.issuer, corev1.EventTypeWarning, "FailedInit", "Failed to create Venafi client: %v", err)
+		return nil, fmt.Errorf("error creating Venafi client: %s", err.Error())
 	}
 
 	duration := apiutil.DefaultCertDuration(crt.Spec.Duration)
 
 	dbg.Info("submitting generated CSR to venafi")
-	cert, err := client.Sign(vreq.GetCSR(), duration)
+	cert, err := client.Sign(csrPEM, duration)
 
 	// Check some known error types
 	if err, ok := err.(endpoint.ErrCertificatePending); ok {
@@ -160,10 +142,3 @@ func (v *Venafi) Issue(ctx context.Context, crt *v1alpha1.Certificate) (*issuer.
 		// CA: []byte{},
 	}, nil
 }
-
-func newVRequest(cert *x509.Certificate) *certificate.Request {
-	req := certificate.NewRequest(cert)
-	// overwrite entire Subject block
-	req.Subject = cert.Subject
-	return req
-}
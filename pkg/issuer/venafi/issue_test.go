@@ -23,6 +23,7 @@ import (
 	"time"
 
 	"github.com/Venafi/vcert/pkg/endpoint"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 	corelisters "k8s.io/client-go/listers/core/v1"
 
 	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha1"
@@ -98,12 +99,12 @@ func TestIssue(t *testing.T) {
 	)
 
 	failingClientBuilder := func(string, corelisters.SecretLister,
-		cmapi.GenericIssuer) (internalvenafi.Interface, error) {
+		corev1client.SecretsGetter, cmapi.GenericIssuer) (internalvenafi.Interface, error) {
 		return nil, errors.New("this is an error")
 	}
 
 	clientBuilderBackedByFakeVenafi := func(string, corelisters.SecretLister,
-		cmapi.GenericIssuer) (internalvenafi.Interface, error) {
+		corev1client.SecretsGetter, cmapi.GenericIssuer) (internalvenafi.Interface, error) {
 		return &internalvenafifake.Venafi{
 			SignFn: func(csr []byte, duration time.Duration) ([]byte, error) {
 				client := internalvenafifake.Connector{
@@ -132,14 +133,15 @@ func TestIssue(t *testing.T) {
 			expectedErr: false,
 			checkFn:     checkIssueResponseNil,
 		},
-		"if fail to generate template from certificate then return error": {
+		"if fail to generate CSR from certificate then return error": {
 			crt: gen.CertificateFrom(baseCertificate,
 				gen.SetCertificateCommonName(""),
 				gen.SetCertificateDNSNames(),
 			),
-			expectedEvents: baseWithGenEvents,
-			expectedErr:    true,
-			checkFn:        checkIssueResponseNil,
+			expectedEvents: append(baseWithGenEvents,
+				"Warning GenerateCSR Failed to generate a CSR for the certificate: no domains specified on certificate"),
+			expectedErr: true,
+			checkFn:     checkIssueResponseNil,
 		},
 		"if fail to build client then return with error": {
 			crt:           baseCertificate,
@@ -152,7 +154,7 @@ func TestIssue(t *testing.T) {
 		"if sign returns a pending error, return error": {
 			crt: baseCertificate,
 			clientBuilder: func(string, corelisters.SecretLister,
-				cmapi.GenericIssuer) (internalvenafi.Interface, error) {
+				corev1client.SecretsGetter, cmapi.GenericIssuer) (internalvenafi.Interface, error) {
 				return &internalvenafifake.Venafi{
 					SignFn: func([]byte, time.Duration) ([]byte, error) {
 						return nil, endpoint.ErrCertificatePending{
@@ -170,7 +172,7 @@ func TestIssue(t *testing.T) {
 		"if sign returns a timeout error, return error": {
 			crt: baseCertificate,
 			clientBuilder: func(string, corelisters.SecretLister,
-				cmapi.GenericIssuer) (internalvenafi.Interface, error) {
+				corev1client.SecretsGetter, cmapi.GenericIssuer) (internalvenafi.Interface, error) {
 				return &internalvenafifake.Venafi{
 					SignFn: func([]byte, time.Duration) ([]byte, error) {
 						return nil, endpoint.ErrRetrieveCertificateTimeout{
@@ -187,7 +189,7 @@ func TestIssue(t *testing.T) {
 		"if sign returns a generic error, return error": {
 			crt: baseCertificate,
 			clientBuilder: func(string, corelisters.SecretLister,
-				cmapi.GenericIssuer) (internalvenafi.Interface, error) {
+				corev1client.SecretsGetter, cmapi.GenericIssuer) (internalvenafi.Interface, error) {
 				return &internalvenafifake.Venafi{
 					SignFn: func([]byte, time.Duration) ([]byte, error) {
 						return nil, errors.New("sign error")
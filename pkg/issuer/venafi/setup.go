@@ -25,15 +25,24 @@ import (
 
 	apiutil "github.com/jetstack/cert-manager/pkg/api/util"
 	"github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha1"
+	"github.com/jetstack/cert-manager/pkg/internal/venafi"
 )
 
 func (v *Venafi) Setup(ctx context.Context) error {
-	client, err := v.clientBuilder(v.resourceNamespace, v.secretsLister, v.issuer)
+	client, err := v.clientBuilder(v.resourceNamespace, v.secretsLister, v.secretsClient, v.issuer)
+	if authErr, ok := err.(venafi.ErrAuthentication); ok {
+		v.setAuthenticationFailed(authErr)
+		return err
+	}
 	if err != nil {
 		return err
 	}
 
 	err = client.Ping()
+	if authErr, ok := err.(venafi.ErrAuthentication); ok {
+		v.setAuthenticationFailed(authErr)
+		return fmt.Errorf("error verifying Venafi client: %s", err.Error())
+	}
 	if err != nil {
 		klog.Infof("Issuer could not connect to endpoint with provided credentials. Issuer failed to connect to endpoint\n")
 		apiutil.SetIssuerCondition(v.issuer, v1alpha1.IssuerConditionReady, v1alpha1.ConditionFalse,
@@ -55,3 +64,11 @@ func (v *Venafi) Setup(ctx context.Context) error {
 
 	return nil
 }
+
+// setAuthenticationFailed marks the issuer as not ready because its access
+// token was rejected or could not be refreshed.
+func (v *Venafi) setAuthenticationFailed(err venafi.ErrAuthentication) {
+	klog.Infof("Issuer failed to authenticate with Venafi endpoint: %v", err)
+	apiutil.SetIssuerCondition(v.issuer, v1alpha1.IssuerConditionReady, v1alpha1.ConditionFalse,
+		"ErrorAuthentication", fmt.Sprintf("Failed to authenticate with Venafi endpoint: %v", err))
+}
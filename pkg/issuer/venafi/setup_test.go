@@ -20,6 +20,7 @@ import (
 	"errors"
 	"testing"
 
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 	corelisters "k8s.io/client-go/listers/core/v1"
 
 	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha1"
@@ -35,12 +36,12 @@ func TestSetup(t *testing.T) {
 	baseIssuer := gen.Issuer("test-issuer")
 
 	failingClientBuilder := func(string, corelisters.SecretLister,
-		cmapi.GenericIssuer) (internalvenafi.Interface, error) {
+		corev1client.SecretsGetter, cmapi.GenericIssuer) (internalvenafi.Interface, error) {
 		return nil, errors.New("this is an error")
 	}
 
 	failingPingClient := func(string, corelisters.SecretLister,
-		cmapi.GenericIssuer) (internalvenafi.Interface, error) {
+		corev1client.SecretsGetter, cmapi.GenericIssuer) (internalvenafi.Interface, error) {
 		return &internalvenafifake.Venafi{
 			PingFn: func() error {
 				return errors.New("this is a ping error")
@@ -49,7 +50,7 @@ func TestSetup(t *testing.T) {
 	}
 
 	pingClient := func(string, corelisters.SecretLister,
-		cmapi.GenericIssuer) (internalvenafi.Interface, error) {
+		corev1client.SecretsGetter, cmapi.GenericIssuer) (internalvenafi.Interface, error) {
 		return &internalvenafifake.Venafi{
 			PingFn: func() error {
 				return nil
@@ -57,6 +58,22 @@ func TestSetup(t *testing.T) {
 		}, nil
 	}
 
+	authErr := internalvenafi.ErrAuthentication{Err: errors.New("refresh token rejected")}
+
+	failingAuthClientBuilder := func(string, corelisters.SecretLister,
+		corev1client.SecretsGetter, cmapi.GenericIssuer) (internalvenafi.Interface, error) {
+		return nil, authErr
+	}
+
+	failingAuthPingClient := func(string, corelisters.SecretLister,
+		corev1client.SecretsGetter, cmapi.GenericIssuer) (internalvenafi.Interface, error) {
+		return &internalvenafifake.Venafi{
+			PingFn: func() error {
+				return authErr
+			},
+		}, nil
+	}
+
 	tests := map[string]testSetupT{
 		"if client builder fails then should error": {
 			clientBuilder: failingClientBuilder,
@@ -64,6 +81,28 @@ func TestSetup(t *testing.T) {
 			iss:           baseIssuer.DeepCopy(),
 		},
 
+		"if client builder fails to authenticate then should error and set condition": {
+			clientBuilder: failingAuthClientBuilder,
+			iss:           baseIssuer.DeepCopy(),
+			expectedErr:   true,
+			expectedCondition: &cmapi.IssuerCondition{
+				Reason:  "ErrorAuthentication",
+				Message: "Failed to authenticate with Venafi endpoint: failed to authenticate with Venafi TPP: refresh token rejected",
+				Status:  "False",
+			},
+		},
+
+		"if ping fails to authenticate then should error and set condition": {
+			clientBuilder: failingAuthPingClient,
+			iss:           baseIssuer.DeepCopy(),
+			expectedErr:   true,
+			expectedCondition: &cmapi.IssuerCondition{
+				Reason:  "ErrorAuthentication",
+				Message: "Failed to authenticate with Venafi endpoint: failed to authenticate with Venafi TPP: refresh token rejected",
+				Status:  "False",
+			},
+		},
+
 		"if ping fails then should error": {
 			clientBuilder: failingPingClient,
 			iss:           baseIssuer.DeepCopy(),
@@ -17,6 +17,7 @@ limitations under the License.
 package venafi
 
 import (
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 	corelisters "k8s.io/client-go/listers/core/v1"
 
 	apiutil "github.com/jetstack/cert-manager/pkg/api/util"
@@ -39,6 +40,7 @@ type Venafi struct {
 	*controller.Context
 
 	secretsLister corelisters.SecretLister
+	secretsClient corev1client.SecretsGetter
 
 	// Namespace in which to read resources related to this Issuer from.
 	// For Issuers, this will be the namespace of the Issuer.
@@ -52,6 +54,7 @@ func NewVenafi(ctx *controller.Context, issuer cmapi.GenericIssuer) (issuer.Inte
 	return &Venafi{
 		issuer:            issuer,
 		secretsLister:     ctx.KubeSharedInformerFactory.Core().V1().Secrets().Lister(),
+		secretsClient:     ctx.Client.CoreV1(),
 		resourceNamespace: ctx.IssuerOptions.ResourceNamespace(issuer),
 		clientBuilder:     venafi.New,
 		Context:           ctx,
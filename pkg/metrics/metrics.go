@@ -18,6 +18,7 @@ limitations under the License.
 // cert-manager exposes the following metrics:
 // certificate_expiration_timestamp_seconds{name, namespace}
 // certificate_ready_status{name, namespace, condition}
+// pod_refresh_restart_count{kind, namespace, result}
 package metrics
 
 import (
@@ -110,6 +111,29 @@ var ControllerSyncCallCount = prometheus.NewCounterVec(
 	[]string{"controller"},
 )
 
+// PodRefreshRestartCount is a Prometheus counter of the workloads restarted
+// by the pod refresh controller, partitioned by whether the restart succeeded.
+var PodRefreshRestartCount = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "pod_refresh_restart_count",
+		Help:      "The number of workload restarts triggered by certificate renewals.",
+	},
+	[]string{"kind", "namespace", "result"},
+)
+
+// VaultTokenRequestCount is a Prometheus counter of the requests made by
+// Vault issuers to log in to Vault or renew a cached token, partitioned by
+// whether the request succeeded.
+var VaultTokenRequestCount = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "vault_token_request_count",
+		Help:      "The number of Vault logins and token renewals made by Vault issuers.",
+	},
+	[]string{"operation", "result"},
+)
+
 // registeredCertificates holds the set of all certificates which are currently
 // registered by Prometheus
 var registeredCertificates = &struct {
@@ -142,6 +166,8 @@ type Metrics struct {
 	ACMEClientRequestDurationSeconds *prometheus.SummaryVec
 	ACMEClientRequestCount           *prometheus.CounterVec
 	ControllerSyncCallCount          *prometheus.CounterVec
+	PodRefreshRestartCount           *prometheus.CounterVec
+	VaultTokenRequestCount           *prometheus.CounterVec
 }
 
 func New(ctx context.Context) *Metrics {
@@ -164,6 +190,8 @@ func New(ctx context.Context) *Metrics {
 		ACMEClientRequestDurationSeconds: ACMEClientRequestDurationSeconds,
 		ACMEClientRequestCount:           ACMEClientRequestCount,
 		ControllerSyncCallCount:          ControllerSyncCallCount,
+		PodRefreshRestartCount:           PodRefreshRestartCount,
+		VaultTokenRequestCount:           VaultTokenRequestCount,
 	}
 
 	router.Handle("/metrics", promhttp.HandlerFor(s.registry, promhttp.HandlerOpts{}))
@@ -195,6 +223,8 @@ func (m *Metrics) Start(stopCh <-chan struct{}) {
 	m.registry.MustRegister(m.ACMEClientRequestDurationSeconds)
 	m.registry.MustRegister(m.ACMEClientRequestCount)
 	m.registry.MustRegister(m.ControllerSyncCallCount)
+	m.registry.MustRegister(m.PodRefreshRestartCount)
+	m.registry.MustRegister(m.VaultTokenRequestCount)
 
 	go func() {
 		log := log.WithValues("address", m.Addr)
@@ -384,3 +414,23 @@ func (m *Metrics) IncrementSyncCallCount(controllerName string) {
 	log.V(logf.DebugLevel).Info("incrementing controller sync call count", "controllerName", controllerName)
 	ControllerSyncCallCount.WithLabelValues(controllerName).Inc()
 }
+
+// IncrementPodRefreshRestart records the result of a workload restart
+// performed by the pod refresh controller.
+func (m *Metrics) IncrementPodRefreshRestart(kind, namespace string, err error) {
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	PodRefreshRestartCount.WithLabelValues(kind, namespace, result).Inc()
+}
+
+// IncrementVaultTokenRequest records the result of a Vault login or token
+// renewal performed on behalf of a Vault issuer.
+func (m *Metrics) IncrementVaultTokenRequest(operation string, err error) {
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	VaultTokenRequestCount.WithLabelValues(operation, result).Inc()
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2019 The Jetstack cert-manager contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package responder
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha1"
+	"github.com/jetstack/cert-manager/pkg/util/pki"
+)
+
+const (
+	serialNumberIndex = "ocsp-serial-number"
+	secretNameIndex   = "ocsp-secret-name"
+)
+
+// CertificateRequestIndexers must be added to the informer backing
+// Responder.CertificateRequestIndexer. They index CertificateRequests by the
+// issuer and serial number of their issued certificate.
+var CertificateRequestIndexers = cache.Indexers{serialNumberIndex: certificateRequestSerialNumberIndexFunc}
+
+// CertificateIndexers must be added to the informer backing
+// Responder.CertificateIndexer. They index Certificates by the namespaced
+// name of their Secret.
+var CertificateIndexers = cache.Indexers{secretNameIndex: certificateSecretNameIndexFunc}
+
+// SecretIndexers must be added to the informer backing
+// Responder.SecretIndexer. They index Secrets by the issuer and serial
+// number of the certificate they contain.
+var SecretIndexers = cache.Indexers{serialNumberIndex: secretSerialNumberIndexFunc}
+
+// serialNumberIndexKey returns the index key used for the certificate with
+// the given serial number issued by the CA with the given raw subject.
+func serialNumberIndexKey(rawIssuer []byte, serialNumber *big.Int) string {
+	h := sha256.Sum256(rawIssuer)
+	return hex.EncodeToString(h[:]) + "/" + serialNumber.String()
+}
+
+func secretNameIndexKey(namespace, name string) string {
+	return namespace + "/" + name
+}
+
+// certificateSerialNumberIndexKeys returns the index keys for the PEM
+// encoded certificate, or none if it cannot be decoded. Errors are not
+// returned as they would cause the informer to panic.
+func certificateSerialNumberIndexKeys(certPEM []byte) []string {
+	if len(certPEM) == 0 {
+		return nil
+	}
+	cert, err := pki.DecodeX509CertificateBytes(certPEM)
+	if err != nil {
+		return nil
+	}
+	return []string{serialNumberIndexKey(cert.RawIssuer, cert.SerialNumber)}
+}
+
+func certificateRequestSerialNumberIndexFunc(obj interface{}) ([]string, error) {
+	cr, ok := obj.(*cmapi.CertificateRequest)
+	if !ok {
+		return nil, nil
+	}
+	return certificateSerialNumberIndexKeys(cr.Status.Certificate), nil
+}
+
+func secretSerialNumberIndexFunc(obj interface{}) ([]string, error) {
+	secret, ok := obj.(*corev1.Secret)
+	if !ok {
+		return nil, nil
+	}
+	return certificateSerialNumberIndexKeys(secret.Data[corev1.TLSCertKey]), nil
+}
+
+func certificateSecretNameIndexFunc(obj interface{}) ([]string, error) {
+	crt, ok := obj.(*cmapi.Certificate)
+	if !ok || crt.Spec.SecretName == "" {
+		return nil, nil
+	}
+	return []string{secretNameIndexKey(crt.Namespace, crt.Spec.SecretName)}, nil
+}
@@ -0,0 +1,379 @@
+/*
+Copyright 2019 The Jetstack cert-manager contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package responder
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+	corev1 "k8s.io/api/core/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/clock"
+
+	apiutil "github.com/jetstack/cert-manager/pkg/api/util"
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha1"
+	cmlisters "github.com/jetstack/cert-manager/pkg/client/listers/certmanager/v1alpha1"
+	"github.com/jetstack/cert-manager/pkg/issuer"
+	logf "github.com/jetstack/cert-manager/pkg/logs"
+	"github.com/jetstack/cert-manager/pkg/util/errors"
+	"github.com/jetstack/cert-manager/pkg/util/kube"
+	"github.com/jetstack/cert-manager/pkg/util/pki"
+)
+
+const (
+	// maxRequestSize is the maximum size of an OCSP request read from the
+	// body of a POST request
+	maxRequestSize = 10 * 1024
+
+	contentTypeRequest  = "application/ocsp-request"
+	contentTypeResponse = "application/ocsp-response"
+)
+
+// Responder answers OCSP requests, as described in RFC 6960, for
+// certificates signed by a CA Issuer or ClusterIssuer.
+// The status of each certificate is determined from the CertificateRequests
+// and Certificates in the cluster that reference the Issuer, along with the
+// CRL published by the Issuer if it has spec.ca.crl set. Responses are
+// signed by a delegated OCSP signing certificate issued by the CA.
+type Responder struct {
+	ListenAddress string
+
+	// IssuerRef references the CA Issuer or ClusterIssuer to answer requests
+	// for.
+	IssuerRef cmapi.ObjectReference
+
+	// Namespace is the namespace of the Issuer, or the cluster resource
+	// namespace if IssuerRef references a ClusterIssuer. The CA, CRL and
+	// signer Secrets are read from this namespace.
+	Namespace string
+
+	// SignerSecretName is the name of the Secret containing the OCSP signing
+	// certificate and private key. The certificate must be signed by the CA
+	// and have the 'ocsp signing' extended key usage. It is read on every
+	// request so that it can be renewed by cert-manager.
+	SignerSecretName string
+
+	// ResponseValidity is how long each response is valid for.
+	ResponseValidity time.Duration
+
+	IssuerLister        cmlisters.IssuerLister
+	ClusterIssuerLister cmlisters.ClusterIssuerLister
+	SecretLister        corelisters.SecretLister
+	ConfigMapLister     corelisters.ConfigMapLister
+
+	// CertificateRequestIndexer, CertificateIndexer and SecretIndexer are
+	// used to look up certificates by serial number. Their informers must
+	// have CertificateRequestIndexers, CertificateIndexers and
+	// SecretIndexers added respectively.
+	CertificateRequestIndexer cache.Indexer
+	CertificateIndexer        cache.Indexer
+	SecretIndexer             cache.Indexer
+
+	Clock clock.Clock
+}
+
+// Listen serves OCSP requests on ListenAddress until ctx is cancelled.
+func (r *Responder) Listen(ctx context.Context) error {
+	log := logf.FromContext(ctx)
+	log.Info("starting listener", "listen_address", r.ListenAddress)
+
+	server := &http.Server{
+		Addr:    r.ListenAddress,
+		Handler: r.Handler(ctx),
+	}
+	go func() {
+		<-ctx.Done()
+		server.Shutdown(context.Background())
+	}()
+
+	if err := server.ListenAndServe(); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// Handler returns an http.Handler that answers OCSP requests sent using
+// either GET or POST, as described in RFC 6960 appendix A.
+// The handler must not be wrapped in an http.ServeMux, as the paths of GET
+// requests are base64 encoded requests which may contain '/' characters.
+func (r *Responder) Handler(ctx context.Context) http.Handler {
+	log := logf.FromContext(ctx)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/healthz" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		var (
+			der []byte
+			err error
+		)
+		switch req.Method {
+		case http.MethodGet:
+			der, err = base64.StdEncoding.DecodeString(strings.TrimPrefix(req.URL.Path, "/"))
+		case http.MethodPost:
+			if req.Header.Get("Content-Type") != contentTypeRequest {
+				http.Error(w, fmt.Sprintf("content type must be %s", contentTypeRequest), http.StatusUnsupportedMediaType)
+				return
+			}
+			der, err = ioutil.ReadAll(http.MaxBytesReader(w, req.Body, maxRequestSize))
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var resp []byte
+		if err != nil {
+			log.V(logf.DebugLevel).Info("error reading request", "error", err.Error())
+			resp = ocsp.MalformedRequestErrorResponse
+		} else {
+			resp = r.respond(ctx, der)
+		}
+
+		w.Header().Set("Content-Type", contentTypeResponse)
+		w.Write(resp)
+	})
+}
+
+// respond returns the DER encoded response to the given DER encoded OCSP
+// request. Errors are reported using the unsuccessful response statuses
+// defined by RFC 6960.
+func (r *Responder) respond(ctx context.Context, der []byte) []byte {
+	log := logf.FromContext(ctx)
+
+	req, err := ocsp.ParseRequest(der)
+	if err != nil {
+		log.V(logf.DebugLevel).Info("error parsing request", "error", err.Error())
+		return ocsp.MalformedRequestErrorResponse
+	}
+	log = log.WithValues("serial_number", req.SerialNumber.String())
+
+	iss, err := issuer.NewHelper(r.IssuerLister, r.ClusterIssuerLister).GetGenericIssuer(r.IssuerRef, r.Namespace)
+	if err != nil {
+		log.Error(err, "error getting issuer")
+		return ocsp.InternalErrorErrorResponse
+	}
+	spec := iss.GetSpec().CA
+	if spec == nil {
+		log.Error(nil, "issuer is not a CA issuer")
+		return ocsp.InternalErrorErrorResponse
+	}
+
+	caCerts, err := kube.SecretTLSCertChain(ctx, r.SecretLister, r.Namespace, spec.SecretName)
+	if err != nil {
+		log.Error(err, "error getting CA certificate")
+		return ocsp.InternalErrorErrorResponse
+	}
+	caCert := caCerts[0]
+
+	ok, err := issuedBy(req, caCert)
+	if err != nil {
+		log.V(logf.DebugLevel).Info("error checking issuer of request", "error", err.Error())
+		return ocsp.MalformedRequestErrorResponse
+	}
+	if !ok {
+		log.V(logf.DebugLevel).Info("request is for a certificate not issued by this CA")
+		return ocsp.UnauthorizedErrorResponse
+	}
+
+	signerCerts, signerKey, err := kube.SecretTLSKeyPair(ctx, r.SecretLister, r.Namespace, r.SignerSecretName)
+	if err != nil {
+		log.Error(err, "error getting OCSP signing key pair")
+		return ocsp.InternalErrorErrorResponse
+	}
+	signerCert := signerCerts[0]
+	if err := validateSigner(signerCert, caCert, r.Clock.Now()); err != nil {
+		log.Error(err, "OCSP signing certificate is not valid")
+		return ocsp.InternalErrorErrorResponse
+	}
+
+	template, err := r.status(ctx, iss, caCert, req.SerialNumber)
+	if err != nil {
+		log.Error(err, "error determining certificate status")
+		return ocsp.InternalErrorErrorResponse
+	}
+	now := r.Clock.Now()
+	template.SerialNumber = req.SerialNumber
+	template.IssuerHash = req.HashAlgorithm
+	template.ThisUpdate = now
+	template.NextUpdate = now.Add(r.ResponseValidity)
+	template.Certificate = signerCert
+
+	resp, err := ocsp.CreateResponse(caCert, signerCert, template, signerKey)
+	if err != nil {
+		log.Error(err, "error creating response")
+		return ocsp.InternalErrorErrorResponse
+	}
+
+	log.V(logf.DebugLevel).Info("responding to request", "status", template.Status)
+	return resp
+}
+
+// status returns a response template containing the status of the
+// certificate with the given serial number signed by caCert.
+// Certificates listed in the CRL published by the Issuer are revoked at the
+// time recorded in the CRL. Other certificates that were issued for a
+// CertificateRequest, or are currently stored in the Secret of a
+// Certificate, are good, including those annotated with a revocation reason
+// that have not yet been listed in the CRL. The status of any other
+// certificate is unknown.
+func (r *Responder) status(ctx context.Context, iss cmapi.GenericIssuer, caCert *x509.Certificate, serialNumber *big.Int) (ocsp.Response, error) {
+	if crlSpec := iss.GetSpec().CA.CRL; crlSpec != nil {
+		crl, err := kube.PublishedCRL(r.SecretLister, r.ConfigMapLister, r.Namespace, crlSpec)
+		if err != nil && !errors.IsInvalidData(err) {
+			return ocsp.Response{}, err
+		}
+		if crl != nil && crl.CheckSignatureFrom(caCert) == nil {
+			for _, e := range crl.Entries {
+				if e.SerialNumber.Cmp(serialNumber) == 0 {
+					return ocsp.Response{
+						Status:           ocsp.Revoked,
+						RevokedAt:        e.RevocationTime,
+						RevocationReason: e.ReasonCode,
+					}, nil
+				}
+			}
+		}
+	}
+
+	found, err := r.issuedCertificate(ctx, iss, caCert, serialNumber)
+	if err != nil {
+		return ocsp.Response{}, err
+	}
+	if !found {
+		return ocsp.Response{Status: ocsp.Unknown}, nil
+	}
+	return ocsp.Response{Status: ocsp.Good}, nil
+}
+
+// issuedCertificate finds the CertificateRequest or Certificate referencing
+// the Issuer that the certificate with the given serial number signed by
+// caCert was issued for, and returns true if one exists.
+// Only the objects indexed under the serial number and issuer are checked.
+func (r *Responder) issuedCertificate(ctx context.Context, iss cmapi.GenericIssuer, caCert *x509.Certificate, serialNumber *big.Int) (bool, error) {
+	log := logf.FromContext(ctx)
+
+	matches := func(cert *x509.Certificate) bool {
+		return cert.SerialNumber.Cmp(serialNumber) == 0 &&
+			bytes.Equal(cert.RawIssuer, caCert.RawSubject) &&
+			cert.CheckSignatureFrom(caCert) == nil
+	}
+	key := serialNumberIndexKey(caCert.RawSubject, serialNumber)
+
+	objs, err := r.CertificateRequestIndexer.ByIndex(serialNumberIndex, key)
+	if err != nil {
+		return false, err
+	}
+	for _, obj := range objs {
+		cr, ok := obj.(*cmapi.CertificateRequest)
+		if !ok || !apiutil.IssuerRefersTo(cr.Spec.IssuerRef, cr.Namespace, iss) {
+			continue
+		}
+		cert, err := pki.DecodeX509CertificateBytes(cr.Status.Certificate)
+		if err != nil {
+			log.V(logf.DebugLevel).Info("error decoding certificate of certificate request", "resource_name", cr.Name, "resource_namespace", cr.Namespace, "error", err.Error())
+			continue
+		}
+		if matches(cert) {
+			return true, nil
+		}
+	}
+
+	secrets, err := r.SecretIndexer.ByIndex(serialNumberIndex, key)
+	if err != nil {
+		return false, err
+	}
+	for _, obj := range secrets {
+		secret, ok := obj.(*corev1.Secret)
+		if !ok {
+			continue
+		}
+		cert, err := pki.DecodeX509CertificateBytes(secret.Data[corev1.TLSCertKey])
+		if err != nil || !matches(cert) {
+			continue
+		}
+		crts, err := r.CertificateIndexer.ByIndex(secretNameIndex, secretNameIndexKey(secret.Namespace, secret.Name))
+		if err != nil {
+			return false, err
+		}
+		for _, obj := range crts {
+			crt, ok := obj.(*cmapi.Certificate)
+			if ok && apiutil.IssuerRefersTo(crt.Spec.IssuerRef, crt.Namespace, iss) {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// issuedBy returns true if the issuer name and key hashes in the given
+// request identify caCert.
+func issuedBy(req *ocsp.Request, caCert *x509.Certificate) (bool, error) {
+	if !req.HashAlgorithm.Available() {
+		return false, fmt.Errorf("unsupported hash algorithm %v", req.HashAlgorithm)
+	}
+
+	var publicKeyInfo struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}
+	if _, err := asn1.Unmarshal(caCert.RawSubjectPublicKeyInfo, &publicKeyInfo); err != nil {
+		return false, err
+	}
+
+	return bytes.Equal(hash(req.HashAlgorithm, caCert.RawSubject), req.IssuerNameHash) &&
+		bytes.Equal(hash(req.HashAlgorithm, publicKeyInfo.PublicKey.RightAlign()), req.IssuerKeyHash), nil
+}
+
+func hash(h crypto.Hash, data []byte) []byte {
+	hh := h.New()
+	hh.Write(data)
+	return hh.Sum(nil)
+}
+
+// validateSigner checks that signerCert is a currently valid delegated OCSP
+// signing certificate for caCert, as described in RFC 6960 section 4.2.2.2.
+func validateSigner(signerCert, caCert *x509.Certificate, now time.Time) error {
+	if err := signerCert.CheckSignatureFrom(caCert); err != nil {
+		return fmt.Errorf("certificate is not signed by the CA: %v", err)
+	}
+	if now.Before(signerCert.NotBefore) || now.After(signerCert.NotAfter) {
+		return fmt.Errorf("certificate is only valid between %s and %s", signerCert.NotBefore, signerCert.NotAfter)
+	}
+	for _, u := range signerCert.ExtKeyUsage {
+		if u == x509.ExtKeyUsageOCSPSigning {
+			return nil
+		}
+	}
+	return fmt.Errorf("certificate does not have the OCSP signing extended key usage")
+}
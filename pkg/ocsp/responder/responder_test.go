@@ -0,0 +1,472 @@
+/*
+Copyright 2019 The Jetstack cert-manager contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package responder
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	fakeclock "k8s.io/utils/clock/testing"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha1"
+	cmlisters "github.com/jetstack/cert-manager/pkg/client/listers/certmanager/v1alpha1"
+	"github.com/jetstack/cert-manager/pkg/util/pki"
+	"github.com/jetstack/cert-manager/test/unit/gen"
+)
+
+var fixedClockStart = time.Date(2019, time.October, 1, 12, 30, 0, 0, time.UTC)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  crypto.Signer
+}
+
+func newTestCA(t *testing.T, name string) *testCA {
+	key, err := pki.GenerateECPrivateKey(256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             fixedClockStart.Add(-time.Hour),
+		NotAfter:              fixedClockStart.Add(time.Hour * 24 * 365),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	_, cert, err := pki.SignCertificate(template, template, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCA{cert: cert, key: key}
+}
+
+// sign returns a certificate with the given serial number and extended key
+// usages signed by the CA, along with its private key
+func (ca *testCA) sign(t *testing.T, serial int64, usages ...x509.ExtKeyUsage) (*x509.Certificate, crypto.Signer) {
+	key, err := pki.GenerateECPrivateKey(256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "leaf"},
+		NotBefore:    fixedClockStart.Add(-time.Hour),
+		NotAfter:     fixedClockStart.Add(time.Hour * 24),
+		ExtKeyUsage:  usages,
+	}
+	_, cert, err := pki.SignCertificate(template, ca.cert, key.Public(), ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
+
+func (ca *testCA) secret(t *testing.T, name string) *corev1.Secret {
+	keyPEM, err := pki.EncodePrivateKey(ca.key, cmapi.PKCS8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tlsSecret(name, ca.cert, keyPEM)
+}
+
+func tlsSecret(name string, cert *x509.Certificate, keyPEM []byte) *corev1.Secret {
+	certPEM, _ := pki.EncodeX509(cert)
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: gen.DefaultTestNamespace, Name: name},
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       certPEM,
+			corev1.TLSPrivateKeyKey: keyPEM,
+		},
+	}
+}
+
+type testResponder struct {
+	*Responder
+
+	issuers             cache.Indexer
+	certificates        cache.Indexer
+	certificateRequests cache.Indexer
+	secrets             cache.Indexer
+	configMaps          cache.Indexer
+}
+
+func newTestResponder(objs ...interface{}) *testResponder {
+	newIndexer := func(indexers cache.Indexers) cache.Indexer {
+		i := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+		if err := i.AddIndexers(indexers); err != nil {
+			panic(err)
+		}
+		return i
+	}
+	r := &testResponder{
+		issuers:             newIndexer(nil),
+		certificates:        newIndexer(CertificateIndexers),
+		certificateRequests: newIndexer(CertificateRequestIndexers),
+		secrets:             newIndexer(SecretIndexers),
+		configMaps:          newIndexer(nil),
+	}
+	for _, obj := range objs {
+		switch obj.(type) {
+		case *cmapi.Issuer:
+			r.issuers.Add(obj)
+		case *cmapi.Certificate:
+			r.certificates.Add(obj)
+		case *cmapi.CertificateRequest:
+			r.certificateRequests.Add(obj)
+		case *corev1.Secret:
+			r.secrets.Add(obj)
+		case *corev1.ConfigMap:
+			r.configMaps.Add(obj)
+		}
+	}
+	r.Responder = &Responder{
+		IssuerRef:                 cmapi.ObjectReference{Name: "ca"},
+		Namespace:                 gen.DefaultTestNamespace,
+		SignerSecretName:          "ocsp-signer",
+		ResponseValidity:          time.Hour,
+		IssuerLister:              cmlisters.NewIssuerLister(r.issuers),
+		SecretLister:              corelisters.NewSecretLister(r.secrets),
+		ConfigMapLister:           corelisters.NewConfigMapLister(r.configMaps),
+		CertificateRequestIndexer: r.certificateRequests,
+		CertificateIndexer:        r.certificates,
+		SecretIndexer:             r.secrets,
+		Clock:                     fakeclock.NewFakeClock(fixedClockStart),
+	}
+	return r
+}
+
+func TestRespond(t *testing.T) {
+	ca := newTestCA(t, "ca")
+	otherCA := newTestCA(t, "other-ca")
+
+	signerCert, signerKey := ca.sign(t, 2, x509.ExtKeyUsageOCSPSigning)
+	signerKeyPEM, err := pki.EncodePrivateKey(signerKey, cmapi.PKCS8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signerSecret := tlsSecret("ocsp-signer", signerCert, signerKeyPEM)
+	notOCSPSignerCert, _ := ca.sign(t, 3, x509.ExtKeyUsageServerAuth)
+	notOCSPSignerSecret := tlsSecret("ocsp-signer", notOCSPSignerCert, signerKeyPEM)
+
+	issuer := gen.Issuer("ca", gen.SetIssuerCA(cmapi.CAIssuer{SecretName: "ca-key-pair"}))
+	crlIssuer := gen.Issuer("ca", gen.SetIssuerCA(cmapi.CAIssuer{SecretName: "ca-key-pair", CRL: &cmapi.CACRL{ConfigMapName: "ca-crl"}}))
+	caSecret := ca.secret(t, "ca-key-pair")
+
+	leaf, _ := ca.sign(t, 10)
+	leafPEM, _ := pki.EncodeX509(leaf)
+	otherCALeaf, _ := otherCA.sign(t, 10)
+	otherCALeafPEM, _ := pki.EncodeX509(otherCALeaf)
+
+	cr := gen.CertificateRequest("cr",
+		gen.SetCertificateRequestIssuer(cmapi.ObjectReference{Name: "ca"}),
+		gen.SetCertificateRequestCertificate(leafPEM),
+	)
+	revokedCR := gen.CertificateRequestFrom(cr,
+		gen.SetCertificateRequestAnnotations(map[string]string{cmapi.RevocationReasonAnnotationKey: string(cmapi.RevocationReasonKeyCompromise)}),
+	)
+	otherIssuerCR := gen.CertificateRequestFrom(cr,
+		gen.SetCertificateRequestIssuer(cmapi.ObjectReference{Name: "other"}),
+	)
+	otherCACR := gen.CertificateRequestFrom(cr,
+		gen.SetCertificateRequestCertificate(otherCALeafPEM),
+	)
+	crt := gen.Certificate("crt",
+		gen.SetCertificateIssuer(cmapi.ObjectReference{Name: "ca"}),
+		gen.SetCertificateSecretName("crt-tls"),
+	)
+	revokedCrt := gen.CertificateFrom(crt,
+		gen.SetCertificateAnnotations(map[string]string{cmapi.RevocationReasonAnnotationKey: string(cmapi.RevocationReasonSuperseded)}),
+	)
+	crtSecret := tlsSecret("crt-tls", leaf, nil)
+
+	revokedAt := fixedClockStart.Add(-time.Minute * 30)
+	crlPEM, err := pki.CreateCRL(ca.cert, ca.key, []pki.CRLEntry{
+		{SerialNumber: big.NewInt(10), RevocationTime: revokedAt, ReasonCode: ocsp.CessationOfOperation},
+	}, big.NewInt(1), fixedClockStart.Add(-time.Hour), fixedClockStart.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	crlConfigMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: gen.DefaultTestNamespace, Name: "ca-crl"},
+		Data:       map[string]string{cmapi.CRLKey: string(crlPEM)},
+	}
+
+	tests := map[string]struct {
+		objects []interface{}
+		// cert and issuer of the certificate to request the status of
+		cert, issuer *x509.Certificate
+
+		// expectedError is set if an unsuccessful response is expected
+		expectedError    []byte
+		expectedStatus   int
+		expectedReason   int
+		expectedRevokeAt time.Time
+	}{
+		"good if issued for a CertificateRequest": {
+			objects:        []interface{}{issuer, caSecret, signerSecret, cr},
+			cert:           leaf,
+			issuer:         ca.cert,
+			expectedStatus: ocsp.Good,
+		},
+		"good if stored in the Secret of a Certificate": {
+			objects:        []interface{}{issuer, caSecret, signerSecret, crt, crtSecret},
+			cert:           leaf,
+			issuer:         ca.cert,
+			expectedStatus: ocsp.Good,
+		},
+		"unknown if not issued for any resource": {
+			objects:        []interface{}{issuer, caSecret, signerSecret},
+			cert:           leaf,
+			issuer:         ca.cert,
+			expectedStatus: ocsp.Unknown,
+		},
+		"unknown if issued for a resource referencing another issuer": {
+			objects:        []interface{}{issuer, caSecret, signerSecret, otherIssuerCR},
+			cert:           leaf,
+			issuer:         ca.cert,
+			expectedStatus: ocsp.Unknown,
+		},
+		"unknown if a certificate with the same serial number was signed by another CA": {
+			objects:        []interface{}{issuer, caSecret, signerSecret, otherCACR},
+			cert:           leaf,
+			issuer:         ca.cert,
+			expectedStatus: ocsp.Unknown,
+		},
+		"good until the certificate of an annotated CertificateRequest is listed in the CRL": {
+			objects:        []interface{}{issuer, caSecret, signerSecret, revokedCR},
+			cert:           leaf,
+			issuer:         ca.cert,
+			expectedStatus: ocsp.Good,
+		},
+		"good until the certificate of an annotated Certificate is listed in the CRL": {
+			objects:        []interface{}{issuer, caSecret, signerSecret, revokedCrt, crtSecret},
+			cert:           leaf,
+			issuer:         ca.cert,
+			expectedStatus: ocsp.Good,
+		},
+		"revoked at the time recorded in the published CRL": {
+			objects:          []interface{}{crlIssuer, caSecret, signerSecret, revokedCR, crlConfigMap},
+			cert:             leaf,
+			issuer:           ca.cert,
+			expectedStatus:   ocsp.Revoked,
+			expectedReason:   ocsp.CessationOfOperation,
+			expectedRevokeAt: revokedAt,
+		},
+		"revoked if listed in the published CRL after the resource has been deleted": {
+			objects:          []interface{}{crlIssuer, caSecret, signerSecret, crlConfigMap},
+			cert:             leaf,
+			issuer:           ca.cert,
+			expectedStatus:   ocsp.Revoked,
+			expectedReason:   ocsp.CessationOfOperation,
+			expectedRevokeAt: revokedAt,
+		},
+		"unauthorized if the request is for another CA": {
+			objects:       []interface{}{issuer, caSecret, signerSecret, cr},
+			cert:          otherCALeaf,
+			issuer:        otherCA.cert,
+			expectedError: ocsp.UnauthorizedErrorResponse,
+		},
+		"internal error if the issuer does not exist": {
+			objects:       []interface{}{caSecret, signerSecret, cr},
+			cert:          leaf,
+			issuer:        ca.cert,
+			expectedError: ocsp.InternalErrorErrorResponse,
+		},
+		"internal error if the signer Secret does not exist": {
+			objects:       []interface{}{issuer, caSecret, cr},
+			cert:          leaf,
+			issuer:        ca.cert,
+			expectedError: ocsp.InternalErrorErrorResponse,
+		},
+		"internal error if the signer certificate cannot be used for OCSP signing": {
+			objects:       []interface{}{issuer, caSecret, notOCSPSignerSecret, cr},
+			cert:          leaf,
+			issuer:        ca.cert,
+			expectedError: ocsp.InternalErrorErrorResponse,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := newTestResponder(test.objects...)
+
+			req, err := ocsp.CreateRequest(test.cert, test.issuer, &ocsp.RequestOptions{Hash: crypto.SHA256})
+			if err != nil {
+				t.Fatal(err)
+			}
+			respBytes := r.respond(context.Background(), req)
+
+			if test.expectedError != nil {
+				if !bytes.Equal(respBytes, test.expectedError) {
+					t.Fatalf("expected error response %x but got %x", test.expectedError, respBytes)
+				}
+				return
+			}
+
+			resp, err := ocsp.ParseResponseForCert(respBytes, test.cert, ca.cert)
+			if err != nil {
+				t.Fatalf("error parsing response: %v", err)
+			}
+			if resp.Status != test.expectedStatus {
+				t.Errorf("expected status %d but got %d", test.expectedStatus, resp.Status)
+			}
+			if resp.Status == ocsp.Revoked {
+				if resp.RevocationReason != test.expectedReason {
+					t.Errorf("expected revocation reason %d but got %d", test.expectedReason, resp.RevocationReason)
+				}
+				if !resp.RevokedAt.Equal(test.expectedRevokeAt) {
+					t.Errorf("expected revocation time %s but got %s", test.expectedRevokeAt, resp.RevokedAt)
+				}
+			}
+			if !resp.ThisUpdate.Equal(fixedClockStart) || !resp.NextUpdate.Equal(fixedClockStart.Add(time.Hour)) {
+				t.Errorf("expected response valid from %s to %s but got %s to %s", fixedClockStart, fixedClockStart.Add(time.Hour), resp.ThisUpdate, resp.NextUpdate)
+			}
+			if resp.Certificate == nil || !resp.Certificate.Equal(signerCert) {
+				t.Errorf("expected response to include the OCSP signing certificate")
+			}
+		})
+	}
+}
+
+func TestHandler(t *testing.T) {
+	ca := newTestCA(t, "ca")
+	signerCert, signerKey := ca.sign(t, 2, x509.ExtKeyUsageOCSPSigning)
+	signerKeyPEM, err := pki.EncodePrivateKey(signerKey, cmapi.PKCS8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, _ := ca.sign(t, 10)
+	leafPEM, _ := pki.EncodeX509(leaf)
+
+	r := newTestResponder(
+		gen.Issuer("ca", gen.SetIssuerCA(cmapi.CAIssuer{SecretName: "ca-key-pair"})),
+		ca.secret(t, "ca-key-pair"),
+		tlsSecret("ocsp-signer", signerCert, signerKeyPEM),
+		gen.CertificateRequest("cr",
+			gen.SetCertificateRequestIssuer(cmapi.ObjectReference{Name: "ca"}),
+			gen.SetCertificateRequestCertificate(leafPEM),
+		),
+	)
+	server := httptest.NewServer(r.Handler(context.Background()))
+	defer server.Close()
+
+	req, err := ocsp.CreateRequest(leaf, ca.cert, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		method, path, contentType string
+		body                      []byte
+
+		expectedCode     int
+		expectedResponse bool
+	}{
+		"GET": {
+			method:           http.MethodGet,
+			path:             "/" + base64.StdEncoding.EncodeToString(req),
+			expectedCode:     http.StatusOK,
+			expectedResponse: true,
+		},
+		"POST": {
+			method:           http.MethodPost,
+			path:             "/",
+			contentType:      contentTypeRequest,
+			body:             req,
+			expectedCode:     http.StatusOK,
+			expectedResponse: true,
+		},
+		"POST with wrong content type": {
+			method:       http.MethodPost,
+			path:         "/",
+			contentType:  "application/octet-stream",
+			body:         req,
+			expectedCode: http.StatusUnsupportedMediaType,
+		},
+		"GET with malformed request": {
+			method:       http.MethodGet,
+			path:         "/not-base64!",
+			expectedCode: http.StatusOK,
+		},
+		"PUT": {
+			method:       http.MethodPut,
+			path:         "/",
+			expectedCode: http.StatusMethodNotAllowed,
+		},
+		"health check": {
+			method:       http.MethodGet,
+			path:         "/healthz",
+			expectedCode: http.StatusOK,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			httpReq, err := http.NewRequest(test.method, server.URL+test.path, bytes.NewReader(test.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if test.contentType != "" {
+				httpReq.Header.Set("Content-Type", test.contentType)
+			}
+
+			httpResp, err := http.DefaultClient.Do(httpReq)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer httpResp.Body.Close()
+			body, err := ioutil.ReadAll(httpResp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if httpResp.StatusCode != test.expectedCode {
+				t.Fatalf("expected status code %d but got %d", test.expectedCode, httpResp.StatusCode)
+			}
+			if !test.expectedResponse {
+				return
+			}
+			if ct := httpResp.Header.Get("Content-Type"); ct != contentTypeResponse {
+				t.Errorf("expected content type %q but got %q", contentTypeResponse, ct)
+			}
+			resp, err := ocsp.ParseResponseForCert(body, leaf, ca.cert)
+			if err != nil {
+				t.Fatalf("error parsing response: %v", err)
+			}
+			if resp.Status != ocsp.Good {
+				t.Errorf("expected status %d but got %d", ocsp.Good, resp.Status)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2019 The Jetstack cert-manager contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Client reads and writes Ingresses at the API version served by the
+// cluster. Reads are served from a shared informer, which must be started
+// and synced before use.
+// +k8s:deepcopy-gen=false
+type Client struct {
+	resource schema.GroupVersionResource
+	client   dynamic.Interface
+	informer informers.GenericInformer
+}
+
+// NewClient returns a Client for the given Ingress resource, as returned by
+// Discover.
+func NewClient(resource schema.GroupVersionResource, client dynamic.Interface, factory dynamicinformer.DynamicSharedInformerFactory) *Client {
+	return &Client{
+		resource: resource,
+		client:   client,
+		informer: factory.ForResource(resource),
+	}
+}
+
+// Informer returns the shared informer used to read Ingresses.
+func (c *Client) Informer() cache.SharedIndexInformer {
+	return c.informer.Informer()
+}
+
+// GroupVersionKind returns the kind of the Ingresses read and written by this
+// client, for use in owner references.
+func (c *Client) GroupVersionKind() schema.GroupVersionKind {
+	return c.resource.GroupVersion().WithKind("Ingress")
+}
+
+// List returns the Ingresses in the given namespace matching the selector.
+// If namespace is empty, Ingresses in all namespaces are returned.
+func (c *Client) List(namespace string, selector labels.Selector) ([]*Ingress, error) {
+	var objs []runtime.Object
+	var err error
+	if namespace == metav1.NamespaceAll {
+		objs, err = c.informer.Lister().List(selector)
+	} else {
+		objs, err = c.informer.Lister().ByNamespace(namespace).List(selector)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ings := make([]*Ingress, 0, len(objs))
+	for _, obj := range objs {
+		ing, err := fromObject(obj)
+		if err != nil {
+			return nil, err
+		}
+		ings = append(ings, ing)
+	}
+	return ings, nil
+}
+
+// Get returns the named Ingress.
+func (c *Client) Get(namespace, name string) (*Ingress, error) {
+	obj, err := c.informer.Lister().ByNamespace(namespace).Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return fromObject(obj)
+}
+
+// Create creates the given Ingress, returning the created resource.
+func (c *Client) Create(ing *Ingress) (*Ingress, error) {
+	u, err := ToUnstructured(ing, c.resource)
+	if err != nil {
+		return nil, err
+	}
+	u, err = c.client.Resource(c.resource).Namespace(ing.Namespace).Create(u, metav1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return FromUnstructured(u)
+}
+
+// Update updates the given Ingress, returning the updated resource.
+func (c *Client) Update(ing *Ingress) (*Ingress, error) {
+	u, err := ToUnstructured(ing, c.resource)
+	if err != nil {
+		return nil, err
+	}
+	u, err = c.client.Resource(c.resource).Namespace(ing.Namespace).Update(u, metav1.UpdateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return FromUnstructured(u)
+}
+
+// Delete deletes the named Ingress.
+func (c *Client) Delete(namespace, name string) error {
+	return c.client.Resource(c.resource).Namespace(namespace).Delete(name, nil)
+}
+
+func fromObject(obj runtime.Object) (*Ingress, error) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, fmt.Errorf("unexpected object type %T", obj)
+	}
+	return FromUnstructured(u)
+}
@@ -0,0 +1,204 @@
+/*
+Copyright 2019 The Jetstack cert-manager contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+)
+
+var (
+	// ExtensionsV1beta1 is the extensions/v1beta1 Ingress resource, removed
+	// in Kubernetes 1.22
+	ExtensionsV1beta1 = schema.GroupVersionResource{Group: "extensions", Version: "v1beta1", Resource: "ingresses"}
+	// NetworkingV1beta1 is the networking.k8s.io/v1beta1 Ingress resource,
+	// removed in Kubernetes 1.22
+	NetworkingV1beta1 = schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1beta1", Resource: "ingresses"}
+	// NetworkingV1 is the networking.k8s.io/v1 Ingress resource, served since
+	// Kubernetes 1.19
+	NetworkingV1 = schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"}
+)
+
+// Discover returns the Ingress resource that should be used with the cluster.
+// networking.k8s.io/v1 is used if it is served. Otherwise extensions/v1beta1
+// is preferred over networking.k8s.io/v1beta1, so that the behaviour on older
+// clusters is unchanged.
+func Discover(d discovery.DiscoveryInterface) (schema.GroupVersionResource, error) {
+	groups, err := d.ServerGroups()
+	if err != nil {
+		return schema.GroupVersionResource{}, fmt.Errorf("error discovering API groups: %v", err)
+	}
+	served := make(map[string]bool)
+	for _, g := range groups.Groups {
+		for _, v := range g.Versions {
+			served[v.GroupVersion] = true
+		}
+	}
+
+	for _, gvr := range []schema.GroupVersionResource{NetworkingV1, ExtensionsV1beta1, NetworkingV1beta1} {
+		gv := gvr.GroupVersion().String()
+		if !served[gv] {
+			continue
+		}
+		resources, err := d.ServerResourcesForGroupVersion(gv)
+		if err != nil {
+			return schema.GroupVersionResource{}, fmt.Errorf("error discovering resources in %s: %v", gv, err)
+		}
+		for _, r := range resources.APIResources {
+			if r.Name == gvr.Resource {
+				return gvr, nil
+			}
+		}
+	}
+
+	return schema.GroupVersionResource{}, fmt.Errorf("the cluster does not serve a supported Ingress API version")
+}
+
+// FromUnstructured converts an Ingress served at any of the supported API
+// versions into an Ingress.
+func FromUnstructured(u *unstructured.Unstructured) (*Ingress, error) {
+	gv, err := schema.ParseGroupVersion(u.GetAPIVersion())
+	if err != nil {
+		return nil, err
+	}
+
+	obj := u.DeepCopy().Object
+	if spec, ok := obj["spec"].(map[string]interface{}); ok && isV1beta1(gv) {
+		if backend, ok := spec["backend"].(map[string]interface{}); ok {
+			spec["defaultBackend"] = backendFromV1beta1(backend)
+			delete(spec, "backend")
+		}
+		forEachPath(spec, func(path map[string]interface{}) {
+			if backend, ok := path["backend"].(map[string]interface{}); ok {
+				path["backend"] = backendFromV1beta1(backend)
+			}
+		})
+	}
+
+	ing := &Ingress{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj, ing); err != nil {
+		return nil, fmt.Errorf("error decoding ingress %s/%s: %v", u.GetNamespace(), u.GetName(), err)
+	}
+	return ing, nil
+}
+
+// ToUnstructured converts an Ingress into an unstructured object in the
+// shape of the given Ingress resource's API version.
+func ToUnstructured(ing *Ingress, gvr schema.GroupVersionResource) (*unstructured.Unstructured, error) {
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(ing)
+	if err != nil {
+		return nil, err
+	}
+
+	if spec, ok := obj["spec"].(map[string]interface{}); ok {
+		if isV1beta1(gvr.GroupVersion()) {
+			if backend, ok := spec["defaultBackend"].(map[string]interface{}); ok {
+				spec["backend"] = backendToV1beta1(backend)
+				delete(spec, "defaultBackend")
+			}
+			forEachPath(spec, func(path map[string]interface{}) {
+				if backend, ok := path["backend"].(map[string]interface{}); ok {
+					path["backend"] = backendToV1beta1(backend)
+				}
+			})
+		} else {
+			// pathType is required in networking.k8s.io/v1, so paths that do
+			// not set one are given the default used by v1beta1
+			forEachPath(spec, func(path map[string]interface{}) {
+				if _, ok := path["pathType"]; !ok {
+					path["pathType"] = string(PathTypeImplementationSpecific)
+				}
+			})
+		}
+	}
+
+	u := &unstructured.Unstructured{Object: obj}
+	u.SetGroupVersionKind(gvr.GroupVersion().WithKind("Ingress"))
+	return u, nil
+}
+
+func isV1beta1(gv schema.GroupVersion) bool {
+	return gv.Version == "v1beta1"
+}
+
+func forEachPath(spec map[string]interface{}, fn func(path map[string]interface{})) {
+	rules, _ := spec["rules"].([]interface{})
+	for _, r := range rules {
+		rule, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		http, ok := rule["http"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		paths, _ := http["paths"].([]interface{})
+		for _, p := range paths {
+			if path, ok := p.(map[string]interface{}); ok {
+				fn(path)
+			}
+		}
+	}
+}
+
+// backendFromV1beta1 converts a v1beta1 IngressBackend, which references a
+// service using serviceName and servicePort, into the v1 shape.
+func backendFromV1beta1(in map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{})
+	if resource, ok := in["resource"]; ok {
+		out["resource"] = resource
+	}
+	name, _ := in["serviceName"].(string)
+	if name == "" {
+		return out
+	}
+	port := make(map[string]interface{})
+	switch p := in["servicePort"].(type) {
+	case string:
+		port["name"] = p
+	case int64:
+		port["number"] = p
+	case float64:
+		port["number"] = int64(p)
+	}
+	out["service"] = map[string]interface{}{"name": name, "port": port}
+	return out
+}
+
+// backendToV1beta1 converts a v1 IngressBackend into the v1beta1 shape.
+func backendToV1beta1(in map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{})
+	if resource, ok := in["resource"]; ok {
+		out["resource"] = resource
+	}
+	service, ok := in["service"].(map[string]interface{})
+	if !ok {
+		return out
+	}
+	out["serviceName"] = service["name"]
+	port, _ := service["port"].(map[string]interface{})
+	if name, ok := port["name"].(string); ok && name != "" {
+		out["servicePort"] = name
+	} else if number, ok := port["number"]; ok {
+		out["servicePort"] = number
+	}
+	return out
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2019 The Jetstack cert-manager contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/diff"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	coretesting "k8s.io/client-go/testing"
+)
+
+func TestDiscover(t *testing.T) {
+	ingresses := []metav1.APIResource{{Name: "ingresses", Namespaced: true, Kind: "Ingress"}}
+	tests := map[string]struct {
+		resources []*metav1.APIResourceList
+		expected  schema.GroupVersionResource
+		err       bool
+	}{
+		"uses networking.k8s.io/v1 if served": {
+			resources: []*metav1.APIResourceList{
+				{GroupVersion: "extensions/v1beta1", APIResources: ingresses},
+				{GroupVersion: "networking.k8s.io/v1beta1", APIResources: ingresses},
+				{GroupVersion: "networking.k8s.io/v1", APIResources: ingresses},
+			},
+			expected: NetworkingV1,
+		},
+		"prefers extensions/v1beta1 over networking.k8s.io/v1beta1": {
+			resources: []*metav1.APIResourceList{
+				{GroupVersion: "extensions/v1beta1", APIResources: ingresses},
+				{GroupVersion: "networking.k8s.io/v1beta1", APIResources: ingresses},
+			},
+			expected: ExtensionsV1beta1,
+		},
+		"uses networking.k8s.io/v1beta1 if extensions/v1beta1 does not serve ingresses": {
+			resources: []*metav1.APIResourceList{
+				{GroupVersion: "extensions/v1beta1", APIResources: []metav1.APIResource{{Name: "podsecuritypolicies", Kind: "PodSecurityPolicy"}}},
+				{GroupVersion: "networking.k8s.io/v1beta1", APIResources: ingresses},
+			},
+			expected: NetworkingV1beta1,
+		},
+		"errors if no ingress api is served": {
+			resources: []*metav1.APIResourceList{
+				{GroupVersion: "networking.k8s.io/v1", APIResources: []metav1.APIResource{{Name: "networkpolicies", Kind: "NetworkPolicy"}}},
+			},
+			err: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			d := &fakediscovery.FakeDiscovery{Fake: &coretesting.Fake{Resources: test.resources}}
+			gvr, err := Discover(d)
+			if err != nil != test.err {
+				t.Fatalf("expected error=%t, got: %v", test.err, err)
+			}
+			if gvr != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, gvr)
+			}
+		})
+	}
+}
+
+func TestConvert(t *testing.T) {
+	pathType := PathTypeImplementationSpecific
+	ing := &Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "default",
+		},
+		Spec: IngressSpec{
+			DefaultBackend: &IngressBackend{
+				Service: &IngressServiceBackend{Name: "default", Port: ServiceBackendPort{Name: "http"}},
+			},
+			Rules: []IngressRule{
+				{
+					Host: "example.com",
+					HTTP: &HTTPIngressRuleValue{
+						Paths: []HTTPIngressPath{
+							{
+								Path: "/",
+								Backend: IngressBackend{
+									Service: &IngressServiceBackend{Name: "svc", Port: ServiceBackendPort{Number: 8080}},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	v1beta1 := map[string]interface{}{
+		"apiVersion": "extensions/v1beta1",
+		"kind":       "Ingress",
+		"metadata": map[string]interface{}{
+			"name":              "test",
+			"namespace":         "default",
+			"creationTimestamp": nil,
+		},
+		"spec": map[string]interface{}{
+			"backend": map[string]interface{}{
+				"serviceName": "default",
+				"servicePort": "http",
+			},
+			"rules": []interface{}{
+				map[string]interface{}{
+					"host": "example.com",
+					"http": map[string]interface{}{
+						"paths": []interface{}{
+							map[string]interface{}{
+								"path": "/",
+								"backend": map[string]interface{}{
+									"serviceName": "svc",
+									"servicePort": int64(8080),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	u, err := ToUnstructured(ing, ExtensionsV1beta1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(u.Object, v1beta1) {
+		t.Errorf("unexpected v1beta1 ingress: %s", diff.ObjectReflectDiff(v1beta1, u.Object))
+	}
+
+	out, err := FromUnstructured(&unstructured.Unstructured{Object: v1beta1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := ing.DeepCopy()
+	expected.TypeMeta = metav1.TypeMeta{APIVersion: "extensions/v1beta1", Kind: "Ingress"}
+	if !reflect.DeepEqual(out, expected) {
+		t.Errorf("unexpected ingress: %s", diff.ObjectReflectDiff(expected, out))
+	}
+
+	u, err = ToUnstructured(ing, NetworkingV1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out, err = FromUnstructured(u)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// pathType is required by networking.k8s.io/v1 and so is defaulted
+	expected.TypeMeta = metav1.TypeMeta{APIVersion: "networking.k8s.io/v1", Kind: "Ingress"}
+	expected.Spec.Rules[0].HTTP.Paths[0].PathType = &pathType
+	if !reflect.DeepEqual(out, expected) {
+		t.Errorf("unexpected ingress: %s", diff.ObjectReflectDiff(expected, out))
+	}
+}
@@ -0,0 +1,27 @@
+/*
+Copyright 2019 The Jetstack cert-manager contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// +k8s:deepcopy-gen=package
+
+// Package ingress provides access to Ingress resources across the different
+// API versions that Kubernetes has served them from.
+//
+// The Ingress types in this package are modelled on networking.k8s.io/v1,
+// and are converted to and from extensions/v1beta1 and
+// networking.k8s.io/v1beta1 when reading and writing resources served at
+// those versions. All resources are accessed using the dynamic client, so
+// that fields only served by newer API versions are not dropped.
+package ingress
@@ -0,0 +1,84 @@
+/*
+Copyright 2019 The Jetstack cert-manager contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PathType is the type of an Ingress path match.
+type PathType string
+
+const (
+	// PathTypeImplementationSpecific leaves matching up to the ingress
+	// controller, and is the default for v1beta1 Ingresses.
+	PathTypeImplementationSpecific PathType = "ImplementationSpecific"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// Ingress is a version independent representation of an Ingress resource.
+type Ingress struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec IngressSpec `json:"spec,omitempty"`
+}
+
+type IngressSpec struct {
+	IngressClassName *string         `json:"ingressClassName,omitempty"`
+	DefaultBackend   *IngressBackend `json:"defaultBackend,omitempty"`
+	TLS              []IngressTLS    `json:"tls,omitempty"`
+	Rules            []IngressRule   `json:"rules,omitempty"`
+}
+
+type IngressTLS struct {
+	Hosts      []string `json:"hosts,omitempty"`
+	SecretName string   `json:"secretName,omitempty"`
+}
+
+type IngressRule struct {
+	Host string                `json:"host,omitempty"`
+	HTTP *HTTPIngressRuleValue `json:"http,omitempty"`
+}
+
+type HTTPIngressRuleValue struct {
+	Paths []HTTPIngressPath `json:"paths"`
+}
+
+type HTTPIngressPath struct {
+	Path     string         `json:"path,omitempty"`
+	PathType *PathType      `json:"pathType,omitempty"`
+	Backend  IngressBackend `json:"backend"`
+}
+
+type IngressBackend struct {
+	Service  *IngressServiceBackend            `json:"service,omitempty"`
+	Resource *corev1.TypedLocalObjectReference `json:"resource,omitempty"`
+}
+
+type IngressServiceBackend struct {
+	Name string             `json:"name"`
+	Port ServiceBackendPort `json:"port,omitempty"`
+}
+
+// ServiceBackendPort references a Service port by either name or number.
+type ServiceBackendPort struct {
+	Name   string `json:"name,omitempty"`
+	Number int32  `json:"number,omitempty"`
+}
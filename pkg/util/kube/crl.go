@@ -0,0 +1,56 @@
+/*
+Copyright 2019 The Jetstack cert-manager contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	corelisters "k8s.io/client-go/listers/core/v1"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha1"
+	"github.com/jetstack/cert-manager/pkg/util/pki"
+)
+
+// PublishedCRL returns the CRL published by a CA issuer to the Secret or
+// ConfigMap named in the given config, or nil if none has been published
+// yet.
+func PublishedCRL(secretLister corelisters.SecretLister, configMapLister corelisters.ConfigMapLister, namespace string, cfg *cmapi.CACRL) (*pki.CRL, error) {
+	var data []byte
+	if cfg.SecretName != "" {
+		secret, err := secretLister.Secrets(namespace).Get(cfg.SecretName)
+		if k8sErrors.IsNotFound(err) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		data = secret.Data[cmapi.CRLKey]
+	} else {
+		cm, err := configMapLister.ConfigMaps(namespace).Get(cfg.ConfigMapName)
+		if k8sErrors.IsNotFound(err) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		data = []byte(cm.Data[cmapi.CRLKey])
+	}
+
+	if len(data) == 0 {
+		return nil, nil
+	}
+	return pki.DecodeX509CRLBytes(data)
+}
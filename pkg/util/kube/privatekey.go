@@ -0,0 +1,181 @@
+/*
+Copyright 2019 The Jetstack cert-manager contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+
+	"github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha1"
+	logf "github.com/jetstack/cert-manager/pkg/logs"
+	"github.com/jetstack/cert-manager/pkg/util/errors"
+	"github.com/jetstack/cert-manager/pkg/util/pki"
+)
+
+const nextPrivateKeySuffix = "-next-private-key"
+
+// NextPrivateKeySecretName returns the name of the Secret used to store the
+// private key that will be used for the next issuance of the given
+// Certificate when its rotationPolicy is Always.
+func NextPrivateKeySecretName(crt *v1alpha1.Certificate) string {
+	name := crt.Name
+	// ensure the name stays within the limit for Secret resource names
+	if max := 253 - len(nextPrivateKeySuffix); len(name) > max {
+		name = name[:max]
+	}
+	return name + nextPrivateKeySuffix
+}
+
+// SecretTLSKeyForIssuance returns the private key that a certificate for the
+// given Certificate should be issued for, by issuers that sign certificates
+// synchronously and return them along with their private key.
+// The private key stored in the Certificate's Secret is reused unless the
+// Certificate's rotationPolicy is Always, or the Secret does not contain a
+// valid private key, in which case a new private key is generated.
+// Errors generating a private key are caused by the Certificate's spec and
+// are returned as InvalidData errors, as retrying will not resolve them.
+func SecretTLSKeyForIssuance(ctx context.Context, secretLister corelisters.SecretLister, crt *v1alpha1.Certificate) (crypto.Signer, error) {
+	if crt.Spec.RotationPolicy != v1alpha1.RotationPolicyAlways {
+		key, err := SecretTLSKey(ctx, secretLister, crt.Namespace, crt.Spec.SecretName)
+		if err == nil {
+			return key, nil
+		}
+		if !k8sErrors.IsNotFound(err) && !errors.IsInvalidData(err) {
+			return nil, err
+		}
+	}
+
+	logf.FromContext(ctx).Info("generating new private key")
+	key, err := pki.GeneratePrivateKeyForCertificate(crt)
+	if err != nil {
+		return nil, errors.NewInvalidData("%v", err)
+	}
+	return key, nil
+}
+
+// NextPrivateKey returns the PEM encoded private key that should be used for
+// the next issuance of the given Certificate when its rotationPolicy is
+// Always.
+// The key is stored in a Secret owned by the Certificate so that the private
+// key stored alongside the currently issued certificate is not replaced until
+// a certificate has been issued for the new key.
+// A new key is generated and stored if none exists yet, or if the stored key
+// is equal to 'current' (the key stored alongside the currently issued
+// certificate) or no longer matches the Certificate's keyAlgorithm. The
+// Secret is read using secretLister and only written if a new key is
+// generated.
+func NextPrivateKey(cl kubernetes.Interface, secretLister corelisters.SecretLister, crt *v1alpha1.Certificate, current crypto.Signer) ([]byte, error) {
+	name := NextPrivateKeySecretName(crt)
+	secret, err := secretLister.Secrets(crt.Namespace).Get(name)
+	if err != nil && !k8sErrors.IsNotFound(err) {
+		return nil, err
+	}
+	exists := err == nil
+	if exists {
+		if !metav1.IsControlledBy(secret, crt) {
+			return nil, fmt.Errorf("secret %q already exists and is not owned by Certificate %q", name, crt.Name)
+		}
+		keyBytes := secret.Data[corev1.TLSPrivateKeyKey]
+		if nextPrivateKeyUsable(crt, keyBytes, current) {
+			return keyBytes, nil
+		}
+	}
+
+	key, err := pki.GeneratePrivateKeyForCertificate(crt)
+	if err != nil {
+		return nil, err
+	}
+	keyBytes, err := pki.EncodePrivateKey(key, crt.Spec.KeyEncoding)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   crt.Namespace,
+				Annotations: map[string]string{v1alpha1.CertificateNameKey: crt.Name},
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(crt, v1alpha1.SchemeGroupVersion.WithKind(v1alpha1.CertificateKind)),
+				},
+			},
+			Data: map[string][]byte{corev1.TLSPrivateKeyKey: keyBytes},
+		}
+		if _, err := cl.CoreV1().Secrets(crt.Namespace).Create(secret); err != nil {
+			return nil, err
+		}
+		return keyBytes, nil
+	}
+
+	secret = secret.DeepCopy()
+	if secret.Data == nil {
+		secret.Data = make(map[string][]byte)
+	}
+	secret.Data[corev1.TLSPrivateKeyKey] = keyBytes
+	if _, err := cl.CoreV1().Secrets(crt.Namespace).Update(secret); err != nil {
+		return nil, err
+	}
+	return keyBytes, nil
+}
+
+// DeleteNextPrivateKey deletes the Secret used to store the next private key
+// of the given Certificate, if it exists.
+// It should be called once a certificate issued for the next private key has
+// been stored in the Certificate's Secret.
+func DeleteNextPrivateKey(cl kubernetes.Interface, crt *v1alpha1.Certificate) error {
+	err := cl.CoreV1().Secrets(crt.Namespace).Delete(NextPrivateKeySecretName(crt), nil)
+	if err != nil && !k8sErrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// nextPrivateKeyUsable returns true if keyBytes contains a valid private key
+// that has not yet been used for an issued certificate and matches the
+// Certificate's keyAlgorithm.
+func nextPrivateKeyUsable(crt *v1alpha1.Certificate, keyBytes []byte, current crypto.Signer) bool {
+	key, err := pki.DecodePrivateKeyBytes(keyBytes)
+	if err != nil {
+		return false
+	}
+	if current != nil {
+		if equal, err := pki.PublicKeysEqual(key.Public(), current.Public()); err != nil || equal {
+			return false
+		}
+	}
+
+	switch key.(type) {
+	case *rsa.PrivateKey:
+		return crt.Spec.KeyAlgorithm == "" || crt.Spec.KeyAlgorithm == v1alpha1.RSAKeyAlgorithm
+	case *ecdsa.PrivateKey:
+		return crt.Spec.KeyAlgorithm == v1alpha1.ECDSAKeyAlgorithm
+	case ed25519.PrivateKey:
+		return crt.Spec.KeyAlgorithm == v1alpha1.Ed25519KeyAlgorithm
+	}
+	return false
+}
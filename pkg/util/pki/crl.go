@@ -0,0 +1,255 @@
+/*
+Copyright 2019 The Jetstack cert-manager contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pki
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha1"
+)
+
+// revocationReasonCodes maps each RevocationReason to its CRLReason code as
+// defined in RFC 5280 section 5.3.1. removeFromCRL is omitted as it is only
+// meaningful in delta CRLs.
+var revocationReasonCodes = map[v1alpha1.RevocationReason]int{
+	v1alpha1.RevocationReasonUnspecified:          0,
+	v1alpha1.RevocationReasonKeyCompromise:        1,
+	v1alpha1.RevocationReasonCACompromise:         2,
+	v1alpha1.RevocationReasonAffiliationChanged:   3,
+	v1alpha1.RevocationReasonSuperseded:           4,
+	v1alpha1.RevocationReasonCessationOfOperation: 5,
+	v1alpha1.RevocationReasonCertificateHold:      6,
+	v1alpha1.RevocationReasonPrivilegeWithdrawn:   9,
+	v1alpha1.RevocationReasonAACompromise:         10,
+}
+
+// RevocationReasonCode returns the RFC 5280 CRLReason code for the given
+// revocation reason.
+func RevocationReasonCode(reason v1alpha1.RevocationReason) (int, error) {
+	code, ok := revocationReasonCodes[reason]
+	if !ok {
+		return 0, fmt.Errorf("unknown revocation reason %q", reason)
+	}
+	return code, nil
+}
+
+// CRL is a decoded certificate revocation list.
+type CRL struct {
+	*pkix.CertificateList
+
+	// Number is the CRL number, or nil if the CRL does not have one.
+	Number *big.Int
+
+	// Entries are the revoked certificates listed in the CRL.
+	Entries []CRLEntry
+}
+
+// CRLEntry is a revoked certificate listed in a CRL.
+type CRLEntry struct {
+	SerialNumber   *big.Int
+	RevocationTime time.Time
+
+	// ReasonCode is the RFC 5280 CRLReason code of the revocation. The
+	// unspecified (0) reason is not encoded in the CRL.
+	ReasonCode int
+}
+
+var (
+	oidExtensionAuthorityKeyId = asn1.ObjectIdentifier{2, 5, 29, 35}
+	oidExtensionCRLNumber      = asn1.ObjectIdentifier{2, 5, 29, 20}
+	oidExtensionReasonCode     = asn1.ObjectIdentifier{2, 5, 29, 21}
+
+	oidSignatureSHA256WithRSA   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 11}
+	oidSignatureECDSAWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 2}
+	oidSignatureECDSAWithSHA384 = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 3}
+	oidSignatureECDSAWithSHA512 = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 4}
+	oidSignatureEd25519         = asn1.ObjectIdentifier{1, 3, 101, 112}
+)
+
+// tbsCertificateList mirrors pkix.TBSCertificateList, but holds the issuer
+// as raw bytes so that it exactly matches the subject of the CA certificate.
+type tbsCertificateList struct {
+	Version             int `asn1:"optional,default:0"`
+	Signature           pkix.AlgorithmIdentifier
+	Issuer              asn1.RawValue
+	ThisUpdate          time.Time
+	NextUpdate          time.Time                 `asn1:"optional"`
+	RevokedCertificates []pkix.RevokedCertificate `asn1:"optional"`
+	Extensions          []pkix.Extension          `asn1:"tag:0,optional,explicit"`
+}
+
+type certificateList struct {
+	TBSCertList        asn1.RawValue
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	SignatureValue     asn1.BitString
+}
+
+type authorityKeyId struct {
+	Id []byte `asn1:"optional,tag:0"`
+}
+
+// CreateCRL returns a PEM encoded version 2 certificate revocation list
+// containing the given entries, signed by the given CA.
+// number is the CRL number, which must be greater than that of any CRL
+// previously published by the CA.
+func CreateCRL(caCert *x509.Certificate, caKey crypto.Signer, entries []CRLEntry, number *big.Int, thisUpdate, nextUpdate time.Time) ([]byte, error) {
+	sigAlg, hash, err := crlSignatureAlgorithm(caKey.Public())
+	if err != nil {
+		return nil, fmt.Errorf("error creating x509 CRL: %s", err.Error())
+	}
+
+	revoked := make([]pkix.RevokedCertificate, len(entries))
+	for i, e := range entries {
+		revoked[i] = pkix.RevokedCertificate{
+			SerialNumber:   e.SerialNumber,
+			RevocationTime: e.RevocationTime.UTC(),
+		}
+		if e.ReasonCode == 0 {
+			continue
+		}
+		value, err := asn1.Marshal(asn1.Enumerated(e.ReasonCode))
+		if err != nil {
+			return nil, fmt.Errorf("error encoding CRL reason code: %s", err.Error())
+		}
+		revoked[i].Extensions = []pkix.Extension{{Id: oidExtensionReasonCode, Value: value}}
+	}
+
+	numberValue, err := asn1.Marshal(number)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding CRL number: %s", err.Error())
+	}
+	extensions := []pkix.Extension{{Id: oidExtensionCRLNumber, Value: numberValue}}
+	if len(caCert.SubjectKeyId) > 0 {
+		akiValue, err := asn1.Marshal(authorityKeyId{Id: caCert.SubjectKeyId})
+		if err != nil {
+			return nil, fmt.Errorf("error encoding CRL authority key identifier: %s", err.Error())
+		}
+		extensions = append(extensions, pkix.Extension{Id: oidExtensionAuthorityKeyId, Value: akiValue})
+	}
+
+	tbsBytes, err := asn1.Marshal(tbsCertificateList{
+		// version 2 CRLs are required to contain extensions
+		Version:             1,
+		Signature:           sigAlg,
+		Issuer:              asn1.RawValue{FullBytes: caCert.RawSubject},
+		ThisUpdate:          thisUpdate.UTC(),
+		NextUpdate:          nextUpdate.UTC(),
+		RevokedCertificates: revoked,
+		Extensions:          extensions,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error encoding x509 CRL: %s", err.Error())
+	}
+
+	signed := tbsBytes
+	if hash != 0 {
+		h := hash.New()
+		h.Write(tbsBytes)
+		signed = h.Sum(nil)
+	}
+	signature, err := caKey.Sign(rand.Reader, signed, hash)
+	if err != nil {
+		return nil, fmt.Errorf("error signing x509 CRL: %s", err.Error())
+	}
+
+	derBytes, err := asn1.Marshal(certificateList{
+		TBSCertList:        asn1.RawValue{FullBytes: tbsBytes},
+		SignatureAlgorithm: sigAlg,
+		SignatureValue:     asn1.BitString{Bytes: signature, BitLength: len(signature) * 8},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error encoding x509 CRL: %s", err.Error())
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: derBytes}), nil
+}
+
+// crlSignatureAlgorithm returns the signature algorithm and hash used to sign
+// CRLs with a key of the given type, matching the defaults used by the
+// crypto/x509 package when signing certificates.
+func crlSignatureAlgorithm(pub crypto.PublicKey) (pkix.AlgorithmIdentifier, crypto.Hash, error) {
+	switch pub := pub.(type) {
+	case *rsa.PublicKey:
+		return pkix.AlgorithmIdentifier{Algorithm: oidSignatureSHA256WithRSA, Parameters: asn1.NullRawValue}, crypto.SHA256, nil
+	case *ecdsa.PublicKey:
+		switch pub.Curve {
+		case elliptic.P256():
+			return pkix.AlgorithmIdentifier{Algorithm: oidSignatureECDSAWithSHA256}, crypto.SHA256, nil
+		case elliptic.P384():
+			return pkix.AlgorithmIdentifier{Algorithm: oidSignatureECDSAWithSHA384}, crypto.SHA384, nil
+		case elliptic.P521():
+			return pkix.AlgorithmIdentifier{Algorithm: oidSignatureECDSAWithSHA512}, crypto.SHA512, nil
+		}
+		return pkix.AlgorithmIdentifier{}, 0, fmt.Errorf("unsupported elliptic curve %q", pub.Curve.Params().Name)
+	case ed25519.PublicKey:
+		return pkix.AlgorithmIdentifier{Algorithm: oidSignatureEd25519}, 0, nil
+	}
+	return pkix.AlgorithmIdentifier{}, 0, fmt.Errorf("unsupported public key type %T", pub)
+}
+
+// parseCRL parses a DER encoded certificate revocation list, along with its
+// CRL number and the reason code of each entry.
+func parseCRL(derBytes []byte) (*CRL, error) {
+	certList, err := x509.ParseCRL(derBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	crl := &CRL{CertificateList: certList}
+	for _, ext := range certList.TBSCertList.Extensions {
+		if !ext.Id.Equal(oidExtensionCRLNumber) {
+			continue
+		}
+		if _, err := asn1.Unmarshal(ext.Value, &crl.Number); err != nil {
+			return nil, fmt.Errorf("invalid CRL number: %s", err.Error())
+		}
+	}
+
+	for _, rc := range certList.TBSCertList.RevokedCertificates {
+		e := CRLEntry{SerialNumber: rc.SerialNumber, RevocationTime: rc.RevocationTime}
+		for _, ext := range rc.Extensions {
+			if !ext.Id.Equal(oidExtensionReasonCode) {
+				continue
+			}
+			var code asn1.Enumerated
+			if _, err := asn1.Unmarshal(ext.Value, &code); err != nil {
+				return nil, fmt.Errorf("invalid reason code for serial number %s: %s", rc.SerialNumber, err.Error())
+			}
+			e.ReasonCode = int(code)
+		}
+		crl.Entries = append(crl.Entries, e)
+	}
+
+	return crl, nil
+}
+
+// CheckSignatureFrom verifies that the CRL was signed by the given CA.
+func (c *CRL) CheckSignatureFrom(caCert *x509.Certificate) error {
+	return caCert.CheckCRLSignature(c.CertificateList)
+}
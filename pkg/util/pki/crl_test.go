@@ -0,0 +1,143 @@
+/*
+Copyright 2019 The Jetstack cert-manager contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pki
+
+import (
+	"crypto"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha1"
+)
+
+func TestCreateCRL(t *testing.T) {
+	rsaKey, err := GenerateRSAPrivateKey(2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := GenerateECPrivateKey(384)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ed25519Key, err := GenerateEd25519PrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyCompromise, err := RevocationReasonCode(v1alpha1.RevocationReasonKeyCompromise)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]crypto.Signer{
+		"rsa":     rsaKey,
+		"ecdsa":   ecKey,
+		"ed25519": ed25519Key,
+	}
+
+	for name, caKey := range tests {
+		t.Run(name, func(t *testing.T) {
+			caTemplate := &x509.Certificate{
+				SerialNumber:          big.NewInt(1),
+				Subject:               pkix.Name{CommonName: "root"},
+				NotBefore:             time.Now(),
+				NotAfter:              time.Now().Add(time.Hour),
+				KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+				BasicConstraintsValid: true,
+				IsCA:                  true,
+				SubjectKeyId:          []byte{1, 2, 3, 4},
+			}
+			_, caCert, err := SignCertificate(caTemplate, caTemplate, caKey.Public(), caKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			revokedAt := time.Now().Add(-time.Minute).UTC().Truncate(time.Second)
+			entries := []CRLEntry{
+				{SerialNumber: big.NewInt(10), RevocationTime: revokedAt},
+				{SerialNumber: big.NewInt(11), RevocationTime: revokedAt, ReasonCode: keyCompromise},
+			}
+			thisUpdate := time.Now().UTC().Truncate(time.Second)
+			nextUpdate := thisUpdate.Add(time.Hour)
+
+			crlPEM, err := CreateCRL(caCert, caKey, entries, big.NewInt(3), thisUpdate, nextUpdate)
+			if err != nil {
+				t.Fatalf("unexpected error creating CRL: %v", err)
+			}
+
+			crl, err := DecodeX509CRLBytes(crlPEM)
+			if err != nil {
+				t.Fatalf("unexpected error decoding CRL: %v", err)
+			}
+			if err := crl.CheckSignatureFrom(caCert); err != nil {
+				t.Errorf("CRL not signed by CA: %v", err)
+			}
+			if issuer := crl.TBSCertList.Issuer.String(); issuer != caCert.Subject.String() {
+				t.Errorf("expected issuer %q but got %q", caCert.Subject, issuer)
+			}
+			if crl.Number == nil || crl.Number.Cmp(big.NewInt(3)) != 0 {
+				t.Errorf("expected CRL number 3 but got %v", crl.Number)
+			}
+			if tbs := crl.TBSCertList; !tbs.ThisUpdate.Equal(thisUpdate) || !tbs.NextUpdate.Equal(nextUpdate) {
+				t.Errorf("expected update times %s/%s but got %s/%s", thisUpdate, nextUpdate, tbs.ThisUpdate, tbs.NextUpdate)
+			}
+			if len(crl.Entries) != len(entries) {
+				t.Fatalf("expected %d revoked certificates but got %d", len(entries), len(crl.Entries))
+			}
+			for i, e := range crl.Entries {
+				if e.SerialNumber.Cmp(entries[i].SerialNumber) != 0 {
+					t.Errorf("expected serial number %s but got %s", entries[i].SerialNumber, e.SerialNumber)
+				}
+				if !e.RevocationTime.Equal(entries[i].RevocationTime) {
+					t.Errorf("expected revocation time %s but got %s", entries[i].RevocationTime, e.RevocationTime)
+				}
+				if e.ReasonCode != entries[i].ReasonCode {
+					t.Errorf("expected reason code %d but got %d", entries[i].ReasonCode, e.ReasonCode)
+				}
+			}
+		})
+	}
+}
+
+func TestRevocationReasonCode(t *testing.T) {
+	tests := map[v1alpha1.RevocationReason]struct {
+		code      int
+		expectErr bool
+	}{
+		v1alpha1.RevocationReasonUnspecified:        {code: 0},
+		v1alpha1.RevocationReasonKeyCompromise:      {code: 1},
+		v1alpha1.RevocationReasonPrivilegeWithdrawn: {code: 9},
+		v1alpha1.RevocationReasonAACompromise:       {code: 10},
+		"removeFromCRL":                             {expectErr: true},
+		"":                                          {expectErr: true},
+	}
+
+	for reason, test := range tests {
+		t.Run(string(reason), func(t *testing.T) {
+			code, err := RevocationReasonCode(reason)
+			if test.expectErr != (err != nil) {
+				t.Errorf("expected error %t but got: %v", test.expectErr, err)
+			}
+			if code != test.code {
+				t.Errorf("expected code %d but got %d", test.code, code)
+			}
+		})
+	}
+}
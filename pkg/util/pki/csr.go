@@ -27,6 +27,7 @@ import (
 	"fmt"
 	"math/big"
 	"net"
+	"net/url"
 	"time"
 
 	apiutil "github.com/jetstack/cert-manager/pkg/api/util"
@@ -80,6 +81,28 @@ func IPAddressesToString(ipAddresses []net.IP) []string {
 	return ipNames
 }
 
+// URIsForCertificate returns the URI subject alt names that should be used
+// for the given Certificate resource.
+func URIsForCertificate(crt *v1alpha1.Certificate) ([]*url.URL, error) {
+	var uris []*url.URL
+	for _, uriName := range crt.Spec.URISANs {
+		uri, err := url.Parse(uriName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse uri SAN %q: %v", uriName, err)
+		}
+		uris = append(uris, uri)
+	}
+	return uris, nil
+}
+
+func URIsToString(uris []*url.URL) []string {
+	var uriNames []string
+	for _, uri := range uris {
+		uriNames = append(uriNames, uri.String())
+	}
+	return uriNames
+}
+
 func removeDuplicates(in []string) []string {
 	var found []string
 Outer:
@@ -143,6 +166,9 @@ func SubjectForCertificate(crt *v1alpha1.Certificate) v1alpha1.X509Subject {
 
 // GenerateCSR will generate a new *x509.CertificateRequest template to be used
 // by issuers that utilise CSRs to obtain Certificates.
+// The requested key usages and, for CA certificates, basic constraints are
+// encoded as extensions so that signers do not need to rely on the
+// CertificateRequest's usages and isCA fields.
 // The CSR will not be signed, and should be passed to either EncodeCSR or
 // to the x509.CreateCertificateRequest function.
 func GenerateCSR(crt *v1alpha1.Certificate) (*x509.CertificateRequest, error) {
@@ -151,8 +177,20 @@ func GenerateCSR(crt *v1alpha1.Certificate) (*x509.CertificateRequest, error) {
 	iPAddresses := IPAddressesForCertificate(crt)
 	organization := OrganizationForCertificate(crt)
 	subject := SubjectForCertificate(crt)
+	uris, err := URIsForCertificate(crt)
+	if err != nil {
+		return nil, err
+	}
+	keyUsages, extKeyUsages, err := buildUsages(crt.Spec.Usages, crt.Spec.IsCA)
+	if err != nil {
+		return nil, err
+	}
+	extensions, err := buildCSRExtensions(keyUsages, extKeyUsages, crt.Spec.IsCA)
+	if err != nil {
+		return nil, err
+	}
 
-	if len(commonName) == 0 && len(dnsNames) == 0 {
+	if len(commonName) == 0 && len(dnsNames) == 0 && len(uris) == 0 && len(crt.Spec.EmailSANs) == 0 {
 		return nil, fmt.Errorf("no domains specified on certificate")
 	}
 
@@ -176,10 +214,11 @@ func GenerateCSR(crt *v1alpha1.Certificate) (*x509.CertificateRequest, error) {
 			SerialNumber:       subject.SerialNumber,
 			CommonName:         commonName,
 		},
-		DNSNames:    dnsNames,
-		IPAddresses: iPAddresses,
-		// TODO: work out how best to handle extensions/key usages here
-		ExtraExtensions: []pkix.Extension{},
+		DNSNames:        dnsNames,
+		IPAddresses:     iPAddresses,
+		URIs:            uris,
+		EmailAddresses:  crt.Spec.EmailSANs,
+		ExtraExtensions: extensions,
 	}, nil
 }
 
@@ -198,7 +237,12 @@ func GenerateTemplate(crt *v1alpha1.Certificate) (*x509.Certificate, error) {
 		return nil, err
 	}
 
-	if len(commonName) == 0 && len(dnsNames) == 0 {
+	uris, err := URIsForCertificate(crt)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(commonName) == 0 && len(dnsNames) == 0 && len(uris) == 0 && len(crt.Spec.EmailSANs) == 0 {
 		return nil, fmt.Errorf("no domains specified on certificate")
 	}
 
@@ -234,10 +278,12 @@ func GenerateTemplate(crt *v1alpha1.Certificate) (*x509.Certificate, error) {
 		NotBefore: time.Now(),
 		NotAfter:  time.Now().Add(certDuration),
 		// see http://golang.org/pkg/crypto/x509/#KeyUsage
-		KeyUsage:    keyUsages,
-		ExtKeyUsage: extKeyUsages,
-		DNSNames:    dnsNames,
-		IPAddresses: ipAddresses,
+		KeyUsage:       keyUsages,
+		ExtKeyUsage:    extKeyUsages,
+		DNSNames:       dnsNames,
+		IPAddresses:    ipAddresses,
+		URIs:           uris,
+		EmailAddresses: crt.Spec.EmailSANs,
 	}, nil
 }
 
@@ -260,6 +306,11 @@ func GenerateTemplateFromCSRPEM(csrPEM []byte, duration time.Duration, isCA bool
 	return GenerateTemplateFromCSRPEMWithUsages(csrPEM, duration, isCA, ku, eku)
 }
 
+// GenerateTemplateFromCSRPEMWithUsages will create a x509.Certificate for the
+// given PEM encoded CSR, using its subject, SANs and public key.
+// Key usages, extended key usages and basic constraints encoded in the CSR
+// take precedence over the given isCA, keyUsage and extKeyUsage, which are
+// only used if the CSR does not contain the corresponding extension.
 func GenerateTemplateFromCSRPEMWithUsages(csrPEM []byte, duration time.Duration, isCA bool, keyUsage x509.KeyUsage, extKeyUsage []x509.ExtKeyUsage) (*x509.Certificate, error) {
 	block, _ := pem.Decode(csrPEM)
 	if block == nil {
@@ -275,6 +326,23 @@ func GenerateTemplateFromCSRPEMWithUsages(csrPEM []byte, duration time.Duration,
 		return nil, err
 	}
 
+	usages, err := usagesForCSR(csr)
+	if err != nil {
+		return nil, err
+	}
+	if usages.hasKeyUsage {
+		keyUsage = usages.keyUsage
+	}
+	if usages.hasExtKeyUsage {
+		extKeyUsage = usages.extKeyUsage
+	}
+	if usages.hasBasicConstraints {
+		isCA = usages.isCA
+	}
+	if isCA {
+		keyUsage |= x509.KeyUsageCertSign
+	}
+
 	serialNumber, err := rand.Int(rand.Reader, serialNumberLimit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate serial number: %s", err.Error())
@@ -291,11 +359,12 @@ func GenerateTemplateFromCSRPEMWithUsages(csrPEM []byte, duration time.Duration,
 		NotBefore:             time.Now(),
 		NotAfter:              time.Now().Add(duration),
 		// see http://golang.org/pkg/crypto/x509/#KeyUsage
-		KeyUsage:    keyUsage,
-		ExtKeyUsage: extKeyUsage,
-		DNSNames:    csr.DNSNames,
-		IPAddresses: csr.IPAddresses,
-		URIs:        csr.URIs,
+		KeyUsage:       keyUsage,
+		ExtKeyUsage:    extKeyUsage,
+		DNSNames:       csr.DNSNames,
+		IPAddresses:    csr.IPAddresses,
+		URIs:           csr.URIs,
+		EmailAddresses: csr.EmailAddresses,
 	}, nil
 }
 
@@ -329,12 +398,25 @@ func SignCertificate(template *x509.Certificate, issuerCert *x509.Certificate, p
 // SignCSRTemplate signs a certificate template usually based upon a CSR. This
 // function expects all fields to be present in the certificate template,
 // including it's public key.
+// caCerts is the chain found in the CA Secret, ordered from the signing
+// certificate up to the root. The chain layout of iss controls which of these
+// are appended to the signed certificate, defaulting to LeafAndIntermediates.
+// Any CRL distribution points and OCSP servers configured on iss are added to
+// the signed certificate. iss may be nil, in which case the defaults are used.
 // It returns the certificate data followed by the CA data, encoded in PEM format.
-func SignCSRTemplate(caCerts []*x509.Certificate, caKey crypto.Signer, template *x509.Certificate) ([]byte, []byte, error) {
+// The CA data is the top-most certificate in caCerts.
+func SignCSRTemplate(caCerts []*x509.Certificate, caKey crypto.Signer, template *x509.Certificate, iss *v1alpha1.CAIssuer) ([]byte, []byte, error) {
 	if len(caCerts) == 0 {
 		return nil, nil, errors.New("no CA certificates given to sign CSR template")
 	}
 
+	var layout v1alpha1.CAChainLayout
+	if iss != nil {
+		layout = iss.ChainLayout
+		template.CRLDistributionPoints = iss.CRLDistributionPoints
+		template.OCSPServer = iss.OCSPServers
+	}
+
 	caCert := caCerts[0]
 
 	certPem, _, err := SignCertificate(template, caCert, template.PublicKey, caKey)
@@ -343,15 +425,31 @@ func SignCSRTemplate(caCerts []*x509.Certificate, caKey crypto.Signer, template
 
 	}
 
-	chainPem, err := EncodeX509Chain(caCerts)
+	var chainPem []byte
+	switch layout {
+	case v1alpha1.CAChainLayoutLeaf:
+	case "", v1alpha1.CAChainLayoutLeafAndIntermediates:
+		chainPem, err = EncodeX509Chain(caCerts)
+	case v1alpha1.CAChainLayoutFullChain:
+		for _, c := range caCerts {
+			var cPem []byte
+			cPem, err = EncodeX509(c)
+			if err != nil {
+				break
+			}
+			chainPem = append(chainPem, cPem...)
+		}
+	default:
+		err = fmt.Errorf("unsupported chain layout %q", layout)
+	}
 	if err != nil {
 		return nil, nil, err
 	}
 
 	certPem = append(certPem, chainPem...)
 
-	// encode the CA certificate to be bundled in the output
-	caPem, err := EncodeX509(caCerts[0])
+	// encode the top-most CA certificate to be bundled in the output
+	caPem, err := EncodeX509(caCerts[len(caCerts)-1])
 	if err != nil {
 		return nil, nil, err
 	}
@@ -438,8 +536,15 @@ func SignatureAlgorithm(crt *v1alpha1.Certificate) (x509.PublicKeyAlgorithm, x50
 		default:
 			return x509.UnknownPublicKeyAlgorithm, x509.UnknownSignatureAlgorithm, fmt.Errorf("unsupported ecdsa keysize specified: %d", crt.Spec.KeySize)
 		}
+	case v1alpha1.Ed25519KeyAlgorithm:
+		// Ed25519 keys have a fixed size
+		if crt.Spec.KeySize != 0 {
+			return x509.UnknownPublicKeyAlgorithm, x509.UnknownSignatureAlgorithm, fmt.Errorf("keysize cannot be specified for ed25519 keys: %d", crt.Spec.KeySize)
+		}
+		pubKeyAlgo = x509.Ed25519
+		sigAlgo = x509.PureEd25519
 	default:
-		return x509.UnknownPublicKeyAlgorithm, x509.UnknownSignatureAlgorithm, fmt.Errorf("unsupported algorithm specified: %s. should be either 'ecdsa' or 'rsa", crt.Spec.KeyAlgorithm)
+		return x509.UnknownPublicKeyAlgorithm, x509.UnknownSignatureAlgorithm, fmt.Errorf("unsupported algorithm specified: %s. should be one of 'ecdsa', 'rsa' or 'ed25519'", crt.Spec.KeyAlgorithm)
 	}
 	return pubKeyAlgo, sigAlgo, nil
 }
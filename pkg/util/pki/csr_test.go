@@ -17,9 +17,13 @@ limitations under the License.
 package pki
 
 import (
+	"crypto/ecdsa"
 	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha1"
 	"github.com/jetstack/cert-manager/pkg/util"
@@ -215,6 +219,42 @@ func TestDNSNamesForCertificate(t *testing.T) {
 	}
 }
 
+func TestURIAndEmailSANsForCertificate(t *testing.T) {
+	crt := &v1alpha1.Certificate{
+		Spec: v1alpha1.CertificateSpec{
+			URISANs:   []string{"spiffe://cluster.local/ns/default/sa/app"},
+			EmailSANs: []string{"app@example.com"},
+		},
+	}
+
+	csr, err := GenerateCSR(crt)
+	if err != nil {
+		t.Fatalf("unexpected error generating CSR: %v", err)
+	}
+	if !reflect.DeepEqual(URIsToString(csr.URIs), crt.Spec.URISANs) {
+		t.Errorf("expected CSR URIs %q but got %q", crt.Spec.URISANs, URIsToString(csr.URIs))
+	}
+	if !reflect.DeepEqual(csr.EmailAddresses, crt.Spec.EmailSANs) {
+		t.Errorf("expected CSR email addresses %q but got %q", crt.Spec.EmailSANs, csr.EmailAddresses)
+	}
+
+	template, err := GenerateTemplate(crt)
+	if err != nil {
+		t.Fatalf("unexpected error generating template: %v", err)
+	}
+	if !reflect.DeepEqual(URIsToString(template.URIs), crt.Spec.URISANs) {
+		t.Errorf("expected template URIs %q but got %q", crt.Spec.URISANs, URIsToString(template.URIs))
+	}
+	if !reflect.DeepEqual(template.EmailAddresses, crt.Spec.EmailSANs) {
+		t.Errorf("expected template email addresses %q but got %q", crt.Spec.EmailSANs, template.EmailAddresses)
+	}
+
+	crt.Spec.URISANs = []string{"%"}
+	if _, err := GenerateCSR(crt); err == nil {
+		t.Errorf("expected an error generating a CSR with an invalid uri SAN")
+	}
+}
+
 func TestSignatureAlgorithmForCertificate(t *testing.T) {
 	type testT struct {
 		name            string
@@ -298,6 +338,18 @@ func TestSignatureAlgorithmForCertificate(t *testing.T) {
 			keySize:   100,
 			expectErr: true,
 		},
+		{
+			name:            "certificate with KeyAlgorithm ed25519",
+			keyAlgo:         v1alpha1.Ed25519KeyAlgorithm,
+			expectedSigAlgo: x509.PureEd25519,
+			expectedKeyType: x509.Ed25519,
+		},
+		{
+			name:      "certificate with KeyAlgorithm ed25519 and size 256",
+			keyAlgo:   v1alpha1.Ed25519KeyAlgorithm,
+			keySize:   256,
+			expectErr: true,
+		},
 		{
 			name:      "certificate with KeyAlgorithm set to unknown key algo",
 			keyAlgo:   v1alpha1.KeyAlgorithm("blah"),
@@ -369,3 +421,158 @@ func TestRemoveDuplicates(t *testing.T) {
 		}
 	}
 }
+
+func TestSignCSRTemplateChainLayout(t *testing.T) {
+	newCA := func(t *testing.T, cn string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+		key, err := GenerateECPrivateKey(256)
+		if err != nil {
+			t.Fatal(err)
+		}
+		template := &x509.Certificate{
+			SerialNumber:          big.NewInt(1),
+			Subject:               pkix.Name{CommonName: cn},
+			NotBefore:             time.Now(),
+			NotAfter:              time.Now().Add(time.Hour),
+			KeyUsage:              x509.KeyUsageCertSign,
+			BasicConstraintsValid: true,
+			IsCA:                  true,
+		}
+		if parent == nil {
+			parent, parentKey = template, key
+		}
+		_, cert, err := SignCertificate(template, parent, key.Public(), parentKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cert, key
+	}
+
+	root, rootKey := newCA(t, "root", nil, nil)
+	intermediate, intermediateKey := newCA(t, "intermediate", root, rootKey)
+	caCerts := []*x509.Certificate{intermediate, root}
+
+	tests := map[string]struct {
+		layout      v1alpha1.CAChainLayout
+		expectedCNs []string
+		expectErr   bool
+	}{
+		"default layout should include the leaf and intermediates": {
+			expectedCNs: []string{"leaf", "intermediate"},
+		},
+		"Leaf should only include the leaf": {
+			layout:      v1alpha1.CAChainLayoutLeaf,
+			expectedCNs: []string{"leaf"},
+		},
+		"LeafAndIntermediates should include the leaf and intermediates": {
+			layout:      v1alpha1.CAChainLayoutLeafAndIntermediates,
+			expectedCNs: []string{"leaf", "intermediate"},
+		},
+		"FullChain should include the leaf, intermediates and root": {
+			layout:      v1alpha1.CAChainLayoutFullChain,
+			expectedCNs: []string{"leaf", "intermediate", "root"},
+		},
+		"unknown layout should error": {
+			layout:    "RootOnly",
+			expectErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			leafKey, err := GenerateECPrivateKey(256)
+			if err != nil {
+				t.Fatal(err)
+			}
+			template := &x509.Certificate{
+				SerialNumber: big.NewInt(2),
+				Subject:      pkix.Name{CommonName: "leaf"},
+				NotBefore:    time.Now(),
+				NotAfter:     time.Now().Add(time.Hour),
+				PublicKey:    leafKey.Public(),
+			}
+
+			certPEM, caPEM, err := SignCSRTemplate(caCerts, intermediateKey, template, &v1alpha1.CAIssuer{ChainLayout: test.layout})
+			if test.expectErr {
+				if err == nil {
+					t.Errorf("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			chain, err := DecodeX509CertificateChainBytes(certPEM)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var cns []string
+			for _, c := range chain {
+				cns = append(cns, c.Subject.CommonName)
+			}
+			if !reflect.DeepEqual(cns, test.expectedCNs) {
+				t.Errorf("expected chain %v but got %v", test.expectedCNs, cns)
+			}
+
+			ca, err := DecodeX509CertificateBytes(caPEM)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ca.Subject.CommonName != "root" {
+				t.Errorf("expected ca.crt to contain the root but got %q", ca.Subject.CommonName)
+			}
+		})
+	}
+}
+
+func TestSignCSRTemplateRevocationInfo(t *testing.T) {
+	caKey, err := GenerateECPrivateKey(256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "root"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	_, caCert, err := SignCertificate(caTemplate, caTemplate, caKey.Public(), caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	leafKey, err := GenerateECPrivateKey(256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "leaf"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+		PublicKey:    leafKey.Public(),
+	}
+
+	iss := &v1alpha1.CAIssuer{
+		CRLDistributionPoints: []string{"http://crl.example.com/ca.crl"},
+		OCSPServers:           []string{"http://ocsp.example.com"},
+	}
+	certPEM, _, err := SignCSRTemplate([]*x509.Certificate{caCert}, caKey, template, iss)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := DecodeX509CertificateBytes(certPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cert.CRLDistributionPoints, iss.CRLDistributionPoints) {
+		t.Errorf("expected CRL distribution points %v but got %v", iss.CRLDistributionPoints, cert.CRLDistributionPoints)
+	}
+	if !reflect.DeepEqual(cert.OCSPServer, iss.OCSPServers) {
+		t.Errorf("expected OCSP servers %v but got %v", iss.OCSPServers, cert.OCSPServer)
+	}
+}
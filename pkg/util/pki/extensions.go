@@ -0,0 +1,245 @@
+/*
+Copyright 2019 The Jetstack cert-manager contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pki
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"fmt"
+)
+
+// The crypto/x509 package does not encode key usages or basic constraints
+// into CSRs, nor does it decode them from CSRs, so the extensions are
+// marshalled and parsed here instead.
+var (
+	oidExtensionKeyUsage         = asn1.ObjectIdentifier{2, 5, 29, 15}
+	oidExtensionExtendedKeyUsage = asn1.ObjectIdentifier{2, 5, 29, 37}
+	oidExtensionBasicConstraints = asn1.ObjectIdentifier{2, 5, 29, 19}
+	oidExtensionSubjectAltName   = asn1.ObjectIdentifier{2, 5, 29, 17}
+)
+
+var extKeyUsageOIDs = map[x509.ExtKeyUsage]asn1.ObjectIdentifier{
+	x509.ExtKeyUsageAny:                        {2, 5, 29, 37, 0},
+	x509.ExtKeyUsageServerAuth:                 {1, 3, 6, 1, 5, 5, 7, 3, 1},
+	x509.ExtKeyUsageClientAuth:                 {1, 3, 6, 1, 5, 5, 7, 3, 2},
+	x509.ExtKeyUsageCodeSigning:                {1, 3, 6, 1, 5, 5, 7, 3, 3},
+	x509.ExtKeyUsageEmailProtection:            {1, 3, 6, 1, 5, 5, 7, 3, 4},
+	x509.ExtKeyUsageIPSECEndSystem:             {1, 3, 6, 1, 5, 5, 7, 3, 5},
+	x509.ExtKeyUsageIPSECTunnel:                {1, 3, 6, 1, 5, 5, 7, 3, 6},
+	x509.ExtKeyUsageIPSECUser:                  {1, 3, 6, 1, 5, 5, 7, 3, 7},
+	x509.ExtKeyUsageTimeStamping:               {1, 3, 6, 1, 5, 5, 7, 3, 8},
+	x509.ExtKeyUsageOCSPSigning:                {1, 3, 6, 1, 5, 5, 7, 3, 9},
+	x509.ExtKeyUsageMicrosoftServerGatedCrypto: {1, 3, 6, 1, 4, 1, 311, 10, 3, 3},
+	x509.ExtKeyUsageNetscapeServerGatedCrypto:  {2, 16, 840, 1, 113730, 4, 1},
+}
+
+type basicConstraints struct {
+	IsCA       bool `asn1:"optional"`
+	MaxPathLen int  `asn1:"optional,default:-1"`
+}
+
+// OtherName is an otherName subject alternative name with a UTF8String value.
+type OtherName struct {
+	TypeID asn1.ObjectIdentifier
+	Value  string
+}
+
+// otherName is the ASN.1 structure of an otherName general name, as defined
+// in RFC 5280 section 4.2.1.6. encoding/asn1 does not strip the explicit tag
+// from a RawValue, so Value holds the tagged value.
+type otherName struct {
+	TypeID asn1.ObjectIdentifier
+	Value  asn1.RawValue `asn1:"tag:0,explicit"`
+}
+
+// csrUsages holds the key usages and basic constraints requested by a CSR.
+// The has* fields record whether the corresponding extension was present.
+type csrUsages struct {
+	keyUsage    x509.KeyUsage
+	extKeyUsage []x509.ExtKeyUsage
+	isCA        bool
+
+	hasKeyUsage         bool
+	hasExtKeyUsage      bool
+	hasBasicConstraints bool
+}
+
+// buildCSRExtensions returns the key usage, extended key usage and basic
+// constraints extensions to be added to a CSR. The basic constraints
+// extension is only added if isCA is true.
+func buildCSRExtensions(ku x509.KeyUsage, eku []x509.ExtKeyUsage, isCA bool) ([]pkix.Extension, error) {
+	var exts []pkix.Extension
+
+	if ku != 0 {
+		ext, err := marshalKeyUsage(ku)
+		if err != nil {
+			return nil, err
+		}
+		exts = append(exts, ext)
+	}
+
+	if len(eku) > 0 {
+		ext, err := marshalExtKeyUsage(eku)
+		if err != nil {
+			return nil, err
+		}
+		exts = append(exts, ext)
+	}
+
+	if isCA {
+		value, err := asn1.Marshal(basicConstraints{IsCA: true, MaxPathLen: -1})
+		if err != nil {
+			return nil, err
+		}
+		exts = append(exts, pkix.Extension{Id: oidExtensionBasicConstraints, Critical: true, Value: value})
+	}
+
+	return exts, nil
+}
+
+func marshalKeyUsage(ku x509.KeyUsage) (pkix.Extension, error) {
+	// bit 0 of the key usage is the most significant bit of the first byte
+	var b [2]byte
+	bitLength := 0
+	for i := 0; i < 9; i++ {
+		if ku&(1<<uint(i)) != 0 {
+			b[i/8] |= 0x80 >> uint(i%8)
+			bitLength = i + 1
+		}
+	}
+	bs := asn1.BitString{Bytes: b[:(bitLength+7)/8], BitLength: bitLength}
+
+	value, err := asn1.Marshal(bs)
+	if err != nil {
+		return pkix.Extension{}, err
+	}
+	return pkix.Extension{Id: oidExtensionKeyUsage, Critical: true, Value: value}, nil
+}
+
+func marshalExtKeyUsage(eku []x509.ExtKeyUsage) (pkix.Extension, error) {
+	oids := make([]asn1.ObjectIdentifier, len(eku))
+	for i, u := range eku {
+		oid, ok := extKeyUsageOIDs[u]
+		if !ok {
+			return pkix.Extension{}, fmt.Errorf("unknown extended key usage: %v", u)
+		}
+		oids[i] = oid
+	}
+
+	value, err := asn1.Marshal(oids)
+	if err != nil {
+		return pkix.Extension{}, err
+	}
+	return pkix.Extension{Id: oidExtensionExtendedKeyUsage, Value: value}, nil
+}
+
+// usagesForCSR parses the key usage, extended key usage and basic
+// constraints extensions in the given CSR.
+// Unknown extended key usages are ignored.
+func usagesForCSR(csr *x509.CertificateRequest) (*csrUsages, error) {
+	u := &csrUsages{}
+	for _, ext := range csr.Extensions {
+		switch {
+		case ext.Id.Equal(oidExtensionKeyUsage):
+			var bs asn1.BitString
+			if _, err := asn1.Unmarshal(ext.Value, &bs); err != nil {
+				return nil, fmt.Errorf("error parsing key usage extension: %v", err)
+			}
+			for i := 0; i < 9; i++ {
+				if bs.At(i) != 0 {
+					u.keyUsage |= 1 << uint(i)
+				}
+			}
+			u.hasKeyUsage = true
+
+		case ext.Id.Equal(oidExtensionExtendedKeyUsage):
+			var oids []asn1.ObjectIdentifier
+			if _, err := asn1.Unmarshal(ext.Value, &oids); err != nil {
+				return nil, fmt.Errorf("error parsing extended key usage extension: %v", err)
+			}
+			for _, oid := range oids {
+				for eku, ekuOID := range extKeyUsageOIDs {
+					if oid.Equal(ekuOID) {
+						u.extKeyUsage = append(u.extKeyUsage, eku)
+						break
+					}
+				}
+			}
+			u.hasExtKeyUsage = true
+
+		case ext.Id.Equal(oidExtensionBasicConstraints):
+			var bc basicConstraints
+			if _, err := asn1.Unmarshal(ext.Value, &bc); err != nil {
+				return nil, fmt.Errorf("error parsing basic constraints extension: %v", err)
+			}
+			u.isCA = bc.IsCA
+			u.hasBasicConstraints = true
+		}
+	}
+	return u, nil
+}
+
+// KeyUsagesForCSR returns the key usages and extended key usages requested by
+// the given CSR. If the CSR does not contain the corresponding extension, the
+// zero value is returned. Unknown extended key usages are ignored.
+func KeyUsagesForCSR(csr *x509.CertificateRequest) (x509.KeyUsage, []x509.ExtKeyUsage, error) {
+	u, err := usagesForCSR(csr)
+	if err != nil {
+		return 0, nil, err
+	}
+	return u.keyUsage, u.extKeyUsage, nil
+}
+
+// OtherNamesForCSR returns the otherName subject alternative names in the
+// given CSR, which crypto/x509 does not decode.
+// otherNames with a value other than a UTF8String are ignored.
+func OtherNamesForCSR(csr *x509.CertificateRequest) ([]OtherName, error) {
+	var names []OtherName
+	for _, ext := range csr.Extensions {
+		if !ext.Id.Equal(oidExtensionSubjectAltName) {
+			continue
+		}
+
+		var generalNames []asn1.RawValue
+		if _, err := asn1.Unmarshal(ext.Value, &generalNames); err != nil {
+			return nil, fmt.Errorf("error parsing subject alternative name extension: %v", err)
+		}
+
+		for _, gn := range generalNames {
+			if gn.Class != asn1.ClassContextSpecific || gn.Tag != 0 {
+				continue
+			}
+
+			var on otherName
+			if _, err := asn1.UnmarshalWithParams(gn.FullBytes, &on, "tag:0"); err != nil {
+				return nil, fmt.Errorf("error parsing otherName subject alternative name: %v", err)
+			}
+
+			var value asn1.RawValue
+			if _, err := asn1.Unmarshal(on.Value.Bytes, &value); err != nil {
+				return nil, fmt.Errorf("error parsing otherName subject alternative name: %v", err)
+			}
+			if value.Class != asn1.ClassUniversal || value.Tag != asn1.TagUTF8String {
+				continue
+			}
+
+			names = append(names, OtherName{TypeID: on.TypeID, Value: string(value.Bytes)})
+		}
+	}
+	return names, nil
+}
@@ -0,0 +1,181 @@
+/*
+Copyright 2019 The Jetstack cert-manager contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pki
+
+import (
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha1"
+)
+
+func TestCSRExtensions(t *testing.T) {
+	key, err := GenerateRSAPrivateKey(2048)
+	if err != nil {
+		t.Fatalf("error generating private key: %v", err)
+	}
+
+	encodeCSR := func(t *testing.T, crt *v1alpha1.Certificate) []byte {
+		csr, err := GenerateCSR(crt)
+		if err != nil {
+			t.Fatalf("error generating CSR: %v", err)
+		}
+		derBytes, err := EncodeCSR(csr, key)
+		if err != nil {
+			t.Fatalf("error encoding CSR: %v", err)
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: derBytes})
+	}
+
+	tests := map[string]struct {
+		crt                 *v1alpha1.Certificate
+		isCA                bool
+		keyUsage            x509.KeyUsage
+		extKeyUsage         []x509.ExtKeyUsage
+		expectedIsCA        bool
+		expectedKeyUsage    x509.KeyUsage
+		expectedExtKeyUsage []x509.ExtKeyUsage
+	}{
+		"default usages are encoded in the CSR": {
+			crt:              &v1alpha1.Certificate{Spec: v1alpha1.CertificateSpec{CommonName: "test"}},
+			expectedKeyUsage: x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		},
+		"usages in the CSR take precedence over the given usages": {
+			crt: &v1alpha1.Certificate{Spec: v1alpha1.CertificateSpec{
+				CommonName: "test",
+				Usages:     []v1alpha1.KeyUsage{v1alpha1.UsageDigitalSignature, v1alpha1.UsageServerAuth, v1alpha1.UsageClientAuth},
+			}},
+			keyUsage:            x509.KeyUsageKeyAgreement,
+			extKeyUsage:         []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+			expectedKeyUsage:    x509.KeyUsageDigitalSignature,
+			expectedExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		},
+		"CA basic constraints are encoded in the CSR": {
+			crt: &v1alpha1.Certificate{Spec: v1alpha1.CertificateSpec{
+				CommonName: "test",
+				IsCA:       true,
+				Usages:     []v1alpha1.KeyUsage{v1alpha1.UsageCRLSign},
+			}},
+			expectedIsCA:     true,
+			expectedKeyUsage: x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		},
+		"given isCA is used if the CSR has no basic constraints": {
+			crt:              &v1alpha1.Certificate{Spec: v1alpha1.CertificateSpec{CommonName: "test"}},
+			isCA:             true,
+			expectedIsCA:     true,
+			expectedKeyUsage: x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tmpl, err := GenerateTemplateFromCSRPEMWithUsages(encodeCSR(t, test.crt), time.Hour, test.isCA, test.keyUsage, test.extKeyUsage)
+			if err != nil {
+				t.Fatalf("error generating template: %v", err)
+			}
+			if tmpl.IsCA != test.expectedIsCA {
+				t.Errorf("expected isCA %t but got %t", test.expectedIsCA, tmpl.IsCA)
+			}
+			if tmpl.KeyUsage != test.expectedKeyUsage {
+				t.Errorf("expected key usage %v but got %v", test.expectedKeyUsage, tmpl.KeyUsage)
+			}
+			if !reflect.DeepEqual(tmpl.ExtKeyUsage, test.expectedExtKeyUsage) {
+				t.Errorf("expected extended key usages %v but got %v", test.expectedExtKeyUsage, tmpl.ExtKeyUsage)
+			}
+		})
+	}
+}
+
+func TestOtherNamesForCSR(t *testing.T) {
+	key, err := GenerateRSAPrivateKey(2048)
+	if err != nil {
+		t.Fatalf("error generating private key: %v", err)
+	}
+
+	upnOID := asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 20, 2, 3}
+	customOID := asn1.ObjectIdentifier{1, 2, 3, 4}
+
+	mustMarshal := func(val interface{}, params string) asn1.RawValue {
+		b, err := asn1.MarshalWithParams(val, params)
+		if err != nil {
+			t.Fatalf("error marshalling %v: %v", val, err)
+		}
+		return asn1.RawValue{FullBytes: b}
+	}
+
+	// otherNameSAN encodes an otherName general name. The explicitly tagged
+	// value is wrapped by hand, as encoding/asn1 ignores the tag of a
+	// RawValue with FullBytes set.
+	otherNameSAN := func(typeID asn1.ObjectIdentifier, value interface{}, valueParams string) asn1.RawValue {
+		return mustMarshal(struct {
+			TypeID asn1.ObjectIdentifier
+			Value  asn1.RawValue
+		}{typeID, asn1.RawValue{
+			Class:      asn1.ClassContextSpecific,
+			Tag:        0,
+			IsCompound: true,
+			Bytes:      mustMarshal(value, valueParams).FullBytes,
+		}}, "tag:0")
+	}
+
+	sanValue, err := asn1.Marshal([]asn1.RawValue{
+		mustMarshal("example.com", "tag:2,ia5"),
+		otherNameSAN(upnOID, "user@example.com", "utf8"),
+		// otherNames with values other than a UTF8String are ignored
+		otherNameSAN(customOID, 42, ""),
+		otherNameSAN(customOID, "value", "utf8"),
+	})
+	if err != nil {
+		t.Fatalf("error marshalling subject alternative names: %v", err)
+	}
+
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject: pkix.Name{CommonName: "example.com"},
+		ExtraExtensions: []pkix.Extension{
+			{Id: oidExtensionSubjectAltName, Value: sanValue},
+		},
+	}, key)
+	if err != nil {
+		t.Fatalf("error creating CSR: %v", err)
+	}
+	csr, err := x509.ParseCertificateRequest(der)
+	if err != nil {
+		t.Fatalf("error parsing CSR: %v", err)
+	}
+
+	names, err := OtherNamesForCSR(csr)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []OtherName{
+		{TypeID: upnOID, Value: "user@example.com"},
+		{TypeID: customOID, Value: "value"},
+	}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected otherNames %v, got %v", expected, names)
+	}
+	if len(csr.DNSNames) != 1 || csr.DNSNames[0] != "example.com" {
+		t.Errorf("expected DNS names to still be decoded, got %v", csr.DNSNames)
+	}
+}
@@ -17,8 +17,10 @@ limitations under the License.
 package pki
 
 import (
+	"bytes"
 	"crypto"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
@@ -49,7 +51,7 @@ const (
 // GeneratePrivateKeyForCertificate will generate a private key suitable for
 // the provided cert-manager Certificate resource, taking into account the
 // parameters on the provided resource.
-// The returned key will either be RSA or ECDSA.
+// The returned key will either be RSA, ECDSA or Ed25519.
 func GeneratePrivateKeyForCertificate(crt *v1alpha1.Certificate) (crypto.Signer, error) {
 	switch crt.Spec.KeyAlgorithm {
 	case v1alpha1.KeyAlgorithm(""), v1alpha1.RSAKeyAlgorithm:
@@ -68,6 +70,8 @@ func GeneratePrivateKeyForCertificate(crt *v1alpha1.Certificate) (crypto.Signer,
 		}
 
 		return GenerateECPrivateKey(keySize)
+	case v1alpha1.Ed25519KeyAlgorithm:
+		return GenerateEd25519PrivateKey()
 	default:
 		return nil, fmt.Errorf("unsupported private key algorithm specified: %s", crt.Spec.KeyAlgorithm)
 	}
@@ -107,9 +111,16 @@ func GenerateECPrivateKey(keySize int) (*ecdsa.PrivateKey, error) {
 	return ecdsa.GenerateKey(ecCurve, rand.Reader)
 }
 
+// GenerateEd25519PrivateKey will generate an Ed25519 private key.
+func GenerateEd25519PrivateKey() (ed25519.PrivateKey, error) {
+	_, pk, err := ed25519.GenerateKey(rand.Reader)
+	return pk, err
+}
+
 // EncodePrivateKey will encode a given crypto.PrivateKey by first inspecting
 // the type of key encoding and then inspecting the type of key provided.
-// It only supports encoding RSA or ECDSA keys.
+// It supports encoding RSA, ECDSA and Ed25519 keys. Ed25519 keys have no
+// PKCS#1 representation, so they are always encoded as PKCS#8.
 func EncodePrivateKey(pk crypto.PrivateKey, keyEncoding v1alpha1.KeyEncoding) ([]byte, error) {
 	switch keyEncoding {
 	case v1alpha1.KeyEncoding(""), v1alpha1.PKCS1:
@@ -118,6 +129,8 @@ func EncodePrivateKey(pk crypto.PrivateKey, keyEncoding v1alpha1.KeyEncoding) ([
 			return EncodePKCS1PrivateKey(k), nil
 		case *ecdsa.PrivateKey:
 			return EncodeECPrivateKey(k)
+		case ed25519.PrivateKey:
+			return EncodePKCS8PrivateKey(k)
 		default:
 			return nil, fmt.Errorf("error encoding private key: unknown key type: %T", pk)
 		}
@@ -158,13 +171,15 @@ func EncodeECPrivateKey(pk *ecdsa.PrivateKey) ([]byte, error) {
 }
 
 // PublicKeyForPrivateKey will return the crypto.PublicKey for the given
-// crypto.PrivateKey. It only supports RSA and ECDSA keys.
+// crypto.PrivateKey. It only supports RSA, ECDSA and Ed25519 keys.
 func PublicKeyForPrivateKey(pk crypto.PrivateKey) (crypto.PublicKey, error) {
 	switch k := pk.(type) {
 	case *rsa.PrivateKey:
 		return k.Public(), nil
 	case *ecdsa.PrivateKey:
 		return k.Public(), nil
+	case ed25519.PrivateKey:
+		return k.Public(), nil
 	default:
 		return nil, fmt.Errorf("unknown private key type: %T", pk)
 	}
@@ -176,7 +191,7 @@ func PublicKeyForPrivateKey(pk crypto.PrivateKey) (crypto.PublicKey, error) {
 // given Certificate.
 // It will return true if the public key *is* valid for the given Certificate.
 // It will return an error if either of the passed parameters are of an
-// unrecognised type (i.e. non RSA/ECDSA)
+// unrecognised type (i.e. non RSA/ECDSA/Ed25519)
 func PublicKeyMatchesCertificate(check crypto.PublicKey, crt *x509.Certificate) (bool, error) {
 	switch pub := crt.PublicKey.(type) {
 	case *rsa.PublicKey:
@@ -197,6 +212,12 @@ func PublicKeyMatchesCertificate(check crypto.PublicKey, crt *x509.Certificate)
 			return false, nil
 		}
 		return true, nil
+	case ed25519.PublicKey:
+		ed25519Check, ok := check.(ed25519.PublicKey)
+		if !ok {
+			return false, nil
+		}
+		return bytes.Equal(pub, ed25519Check), nil
 	default:
 		return false, fmt.Errorf("unrecognised Certificate public key type")
 	}
@@ -208,11 +229,14 @@ func PublicKeyMatchesCertificate(check crypto.PublicKey, crt *x509.Certificate)
 // given CertificateRequest.
 // It will return true if the public key *is* valid for the given CertificateRequest.
 // It will return an error if either of the passed parameters are of an
-// unrecognised type (i.e. non RSA/ECDSA)
+// unrecognised type (i.e. non RSA/ECDSA/Ed25519)
 func PublicKeyMatchesCSR(check crypto.PublicKey, csr *x509.CertificateRequest) (bool, error) {
 	return PublicKeysEqual(check, csr.PublicKey)
 }
 
+// PublicKeysEqual returns true if the two given public keys are equal.
+// It will return an error if 'a' is of an unrecognised type (i.e. non
+// RSA/ECDSA/Ed25519).
 func PublicKeysEqual(a, b crypto.PublicKey) (bool, error) {
 	switch pub := a.(type) {
 	case *rsa.PublicKey:
@@ -233,6 +257,12 @@ func PublicKeysEqual(a, b crypto.PublicKey) (bool, error) {
 			return false, nil
 		}
 		return true, nil
+	case ed25519.PublicKey:
+		ed25519Check, ok := b.(ed25519.PublicKey)
+		if !ok {
+			return false, nil
+		}
+		return bytes.Equal(pub, ed25519Check), nil
 	default:
 		return false, fmt.Errorf("unrecognised public key type")
 	}
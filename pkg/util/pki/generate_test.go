@@ -19,6 +19,7 @@ package pki
 import (
 	"crypto"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
@@ -141,6 +142,11 @@ func TestGeneratePrivateKeyForCertificate(t *testing.T) {
 			keyAlgo:   v1alpha1.ECDSAKeyAlgorithm,
 			expectErr: false,
 		},
+		{
+			name:      "ed25519 key",
+			keyAlgo:   v1alpha1.Ed25519KeyAlgorithm,
+			expectErr: false,
+		},
 	}
 
 	testFn := func(test testT) func(*testing.T) {
@@ -214,6 +220,19 @@ func TestGeneratePrivateKeyForCertificate(t *testing.T) {
 						return
 					}
 				}
+
+				if test.keyAlgo == "ed25519" {
+					key, ok := privateKey.(ed25519.PrivateKey)
+					if !ok {
+						t.Errorf("expected ed25519 private key, but got %T", privateKey)
+						return
+					}
+
+					if len(key) != ed25519.PrivateKeySize {
+						t.Errorf("expected %d byte private key, but got %d", ed25519.PrivateKeySize, len(key))
+						return
+					}
+				}
 			}
 		}
 	}
@@ -235,7 +254,6 @@ func signTestCert(key crypto.Signer) *x509.Certificate {
 		Version:               3,
 		BasicConstraintsValid: true,
 		SerialNumber:          serialNumber,
-		SignatureAlgorithm:    x509.SHA256WithRSA,
 		Subject: pkix.Name{
 			Organization: []string{defaultOrganization},
 			CommonName:   commonName,
@@ -284,6 +302,49 @@ func TestPublicKeyMatchesCertificate(t *testing.T) {
 	}
 }
 
+func TestPublicKeyMatchesCertificateEd25519(t *testing.T) {
+	privKey1, err := GenerateEd25519PrivateKey()
+	if err != nil {
+		t.Errorf("error generating private key: %v", err)
+	}
+	privKey2, err := GenerateEd25519PrivateKey()
+	if err != nil {
+		t.Errorf("error generating private key: %v", err)
+	}
+	rsaKey, err := GenerateRSAPrivateKey(2048)
+	if err != nil {
+		t.Errorf("error generating private key: %v", err)
+	}
+
+	testCert1 := signTestCert(privKey1)
+
+	matches, err := PublicKeyMatchesCertificate(privKey1.Public(), testCert1)
+	if err != nil {
+		t.Errorf("expected no error, but got: %v", err)
+	}
+	if !matches {
+		t.Errorf("expected private key to match certificate, but it did not")
+	}
+
+	for _, check := range []crypto.PublicKey{privKey2.Public(), rsaKey.Public()} {
+		matches, err = PublicKeyMatchesCertificate(check, testCert1)
+		if err != nil {
+			t.Errorf("expected no error, but got: %v", err)
+		}
+		if matches {
+			t.Errorf("expected %T public key to not match certificate, but it did", check)
+		}
+	}
+
+	equal, err := PublicKeysEqual(privKey1.Public(), testCert1.PublicKey)
+	if err != nil {
+		t.Errorf("expected no error, but got: %v", err)
+	}
+	if !equal {
+		t.Errorf("expected public keys to be equal, but they were not")
+	}
+}
+
 func TestPublicKeyMatchesCertificateRequest(t *testing.T) {
 	privKey1, err := GenerateRSAPrivateKey(2048)
 	if err != nil {
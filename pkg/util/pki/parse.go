@@ -26,7 +26,7 @@ import (
 )
 
 // DecodePrivateKeyBytes will decode a PEM encoded private key into a crypto.Signer.
-// It supports ECDSA and RSA private keys only. All other types will return err.
+// It supports ECDSA, RSA and Ed25519 private keys only. All other types will return err.
 func DecodePrivateKeyBytes(keyBytes []byte) (crypto.Signer, error) {
 	// decode the private key pem
 	block, _ := pem.Decode(keyBytes)
@@ -140,3 +140,18 @@ func DecodeX509CertificateRequestBytes(csrBytes []byte) (*x509.CertificateReques
 
 	return csr, nil
 }
+
+// DecodeX509CRLBytes will decode a PEM encoded x509 certificate revocation list.
+func DecodeX509CRLBytes(crlBytes []byte) (*CRL, error) {
+	block, _ := pem.Decode(crlBytes)
+	if block == nil {
+		return nil, errors.NewInvalidData("error decoding CRL PEM block")
+	}
+
+	crl, err := parseCRL(block.Bytes)
+	if err != nil {
+		return nil, errors.NewInvalidData("error parsing CRL: %s", err.Error())
+	}
+
+	return crl, nil
+}
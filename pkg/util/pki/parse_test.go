@@ -18,6 +18,7 @@ package pki
 
 import (
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/rsa"
 	"encoding/pem"
 	"strings"
@@ -77,6 +78,12 @@ func TestDecodePrivateKeyBytes(t *testing.T) {
 		return
 	}
 
+	ed25519KeyBytes, err := generatePrivateKeyBytes(v1alpha1.Ed25519KeyAlgorithm, 0)
+	if err != nil {
+		t.Errorf("error generating key bytes: %s", err)
+		return
+	}
+
 	block := &pem.Block{Type: "BLAH BLAH BLAH", Bytes: []byte("blahblahblah")}
 	blahKeyBytes := pem.EncodeToMemory(block)
 
@@ -110,6 +117,12 @@ func TestDecodePrivateKeyBytes(t *testing.T) {
 			keyAlgo:   v1alpha1.ECDSAKeyAlgorithm,
 			expectErr: false,
 		},
+		{
+			name:      "decode pkcs#8 encoded ed25519 private key bytes",
+			keyBytes:  ed25519KeyBytes,
+			keyAlgo:   v1alpha1.Ed25519KeyAlgorithm,
+			expectErr: false,
+		},
 		{
 			name:         "fail to decode unknown pem encoded key bytes",
 			keyBytes:     blahKeyBytes,
@@ -166,6 +179,14 @@ func TestDecodePrivateKeyBytes(t *testing.T) {
 						return
 					}
 				}
+
+				if test.keyAlgo == v1alpha1.Ed25519KeyAlgorithm {
+					_, ok := privateKey.(ed25519.PrivateKey)
+					if !ok {
+						t.Errorf("expected ed25519 private key, but got %T", privateKey)
+						return
+					}
+				}
 			}
 		}
 	}
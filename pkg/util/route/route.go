@@ -0,0 +1,102 @@
+/*
+Copyright 2019 The Jetstack cert-manager contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package route contains helpers for working with OpenShift Route resources.
+// The OpenShift API types are not vendored, so Routes are read and written
+// as unstructured objects using the Kubernetes dynamic client.
+package route
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+var (
+	// GroupVersionResource is the resource for route.openshift.io/v1 Routes
+	GroupVersionResource = schema.GroupVersionResource{Group: "route.openshift.io", Version: "v1", Resource: "routes"}
+	// GroupVersionKind is the kind for route.openshift.io/v1 Routes
+	GroupVersionKind = schema.GroupVersionKind{Group: "route.openshift.io", Version: "v1", Kind: "Route"}
+)
+
+// TLSTerminationType is the TLS termination type of a Route.
+type TLSTerminationType string
+
+const (
+	TLSTerminationEdge        TLSTerminationType = "edge"
+	TLSTerminationPassthrough TLSTerminationType = "passthrough"
+	TLSTerminationReencrypt   TLSTerminationType = "reencrypt"
+)
+
+// Route is the subset of the route.openshift.io/v1 Route type that
+// cert-manager reads. Fields not listed here are dropped when converting,
+// so changes must be written back to the original unstructured object
+// rather than by converting a Route back again.
+type Route struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec RouteSpec `json:"spec"`
+}
+
+type RouteSpec struct {
+	Host string               `json:"host,omitempty"`
+	Path string               `json:"path,omitempty"`
+	To   RouteTargetReference `json:"to"`
+	Port *RoutePort           `json:"port,omitempty"`
+	TLS  *TLSConfig           `json:"tls,omitempty"`
+}
+
+type RouteTargetReference struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+type RoutePort struct {
+	TargetPort intstr.IntOrString `json:"targetPort"`
+}
+
+type TLSConfig struct {
+	Termination   TLSTerminationType `json:"termination"`
+	Certificate   string             `json:"certificate,omitempty"`
+	Key           string             `json:"key,omitempty"`
+	CACertificate string             `json:"caCertificate,omitempty"`
+}
+
+// FromUnstructured converts an unstructured Route into a Route.
+func FromUnstructured(u *unstructured.Unstructured) (*Route, error) {
+	r := &Route{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), r); err != nil {
+		return nil, fmt.Errorf("error decoding route %s/%s: %v", u.GetNamespace(), u.GetName(), err)
+	}
+	return r, nil
+}
+
+// ToUnstructured converts a Route into an unstructured object suitable for
+// creating with the dynamic client.
+func ToUnstructured(r *Route) (*unstructured.Unstructured, error) {
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(r)
+	if err != nil {
+		return nil, err
+	}
+	u := &unstructured.Unstructured{Object: obj}
+	u.SetGroupVersionKind(GroupVersionKind)
+	return u, nil
+}
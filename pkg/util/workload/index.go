@@ -0,0 +1,41 @@
+/*
+Copyright 2019 The Jetstack cert-manager contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workload
+
+// SecretIndex is the name of the index built by SecretIndexFunc.
+const SecretIndex = "workload-secret"
+
+// SecretIndexKey returns the index key used for the named Secret.
+func SecretIndexKey(namespace, name string) string {
+	return namespace + "/" + name
+}
+
+// SecretIndexFunc is a cache.IndexFunc that indexes workloads by the
+// namespaced names of the Secrets their pod template references.
+// Objects that are not workloads are not indexed.
+func SecretIndexFunc(obj interface{}) ([]string, error) {
+	w, ok := FromObject(obj)
+	if !ok {
+		return nil, nil
+	}
+	ns := w.Meta().GetNamespace()
+	var keys []string
+	for _, name := range w.SecretNames() {
+		keys = append(keys, SecretIndexKey(ns, name))
+	}
+	return keys, nil
+}
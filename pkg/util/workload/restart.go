@@ -0,0 +1,150 @@
+/*
+Copyright 2019 The Jetstack cert-manager contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workload
+
+import (
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Get fetches the named workload of the given kind from the apiserver.
+func Get(cl kubernetes.Interface, kind Kind, namespace, name string) (*Workload, error) {
+	var obj interface{}
+	var err error
+	opts := metav1.GetOptions{}
+	switch kind {
+	case KindDeployment:
+		obj, err = cl.AppsV1().Deployments(namespace).Get(name, opts)
+	case KindStatefulSet:
+		obj, err = cl.AppsV1().StatefulSets(namespace).Get(name, opts)
+	case KindDaemonSet:
+		obj, err = cl.AppsV1().DaemonSets(namespace).Get(name, opts)
+	case KindReplicaSet:
+		obj, err = cl.AppsV1().ReplicaSets(namespace).Get(name, opts)
+	case KindCronJob:
+		obj, err = cl.BatchV1beta1().CronJobs(namespace).Get(name, opts)
+	default:
+		return nil, fmt.Errorf("unsupported workload kind %q", kind)
+	}
+	if err != nil {
+		return nil, err
+	}
+	w, ok := FromObject(obj)
+	if !ok {
+		return nil, fmt.Errorf("%s %s/%s is not a restartable workload", kind, namespace, name)
+	}
+	return w, nil
+}
+
+// SetLabel sets the given label on both the workload and its pod template.
+// Changing a pod template label causes Deployments, StatefulSets and
+// DaemonSets to roll their pods, and future CronJob runs to use a new
+// template.
+func (w *Workload) SetLabel(key, value string) {
+	m := w.Meta()
+	labels := m.GetLabels()
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	labels[key] = value
+	m.SetLabels(labels)
+
+	if w.Template.Labels == nil {
+		w.Template.Labels = make(map[string]string)
+	}
+	w.Template.Labels[key] = value
+}
+
+// Restart sets the given label on the workload and its pod template and
+// persists the change, causing the workload's pods to be replaced.
+// ReplicaSets do not roll their pods when their template changes, so the
+// pods of a standalone ReplicaSet must then be replaced one at a time using
+// ReplaceStalePod.
+func Restart(cl kubernetes.Interface, w *Workload, key, value string) error {
+	w.SetLabel(key, value)
+	return update(cl, w)
+}
+
+func update(cl kubernetes.Interface, w *Workload) error {
+	switch o := w.Object.(type) {
+	case *appsv1.Deployment:
+		_, err := cl.AppsV1().Deployments(o.Namespace).Update(o)
+		return err
+	case *appsv1.StatefulSet:
+		_, err := cl.AppsV1().StatefulSets(o.Namespace).Update(o)
+		return err
+	case *appsv1.DaemonSet:
+		_, err := cl.AppsV1().DaemonSets(o.Namespace).Update(o)
+		return err
+	case *appsv1.ReplicaSet:
+		_, err := cl.AppsV1().ReplicaSets(o.Namespace).Update(o)
+		return err
+	case *batchv1beta1.CronJob:
+		_, err := cl.BatchV1beta1().CronJobs(o.Namespace).Update(o)
+		return err
+	}
+	return fmt.Errorf("unsupported workload type %T", w.Object)
+}
+
+// ReplaceStalePod deletes a single pod controlled by the given ReplicaSet
+// whose label key does not match the ReplicaSet's pod template, so that the
+// ReplicaSet recreates it from its current template. No pod is deleted while
+// another pod of the ReplicaSet is still terminating.
+// It returns the number of stale pods found, including any pod deleted by
+// this call. All pods have been replaced once it returns zero.
+func ReplaceStalePod(cl kubernetes.Interface, rs *appsv1.ReplicaSet, key string) (int, error) {
+	selector, err := metav1.LabelSelectorAsSelector(rs.Spec.Selector)
+	if err != nil {
+		return 0, fmt.Errorf("invalid replicaset selector: %v", err)
+	}
+	pods, err := cl.CoreV1().Pods(rs.Namespace).List(metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return 0, fmt.Errorf("error listing pods for replicaset: %v", err)
+	}
+
+	value := rs.Spec.Template.Labels[key]
+	terminating := false
+	var stale []*corev1.Pod
+	for i := range pods.Items {
+		p := &pods.Items[i]
+		if ref := metav1.GetControllerOf(p); ref == nil || ref.UID != rs.UID {
+			continue
+		}
+		if p.DeletionTimestamp != nil {
+			terminating = true
+			continue
+		}
+		if p.Labels[key] != value {
+			stale = append(stale, p)
+		}
+	}
+	if len(stale) == 0 || terminating {
+		return len(stale), nil
+	}
+
+	p := stale[0]
+	if err := cl.CoreV1().Pods(p.Namespace).Delete(p.Name, nil); err != nil && !k8sErrors.IsNotFound(err) {
+		return 0, fmt.Errorf("error deleting pod %q: %v", p.Name, err)
+	}
+	return len(stale), nil
+}
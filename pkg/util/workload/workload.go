@@ -0,0 +1,133 @@
+/*
+Copyright 2019 The Jetstack cert-manager contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package workload discovers the pod template owners (Deployments,
+// StatefulSets, DaemonSets, CronJobs and standalone ReplicaSets) that
+// consume a given Secret, so that they can be rolled when the Secret changes.
+package workload
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// Kind is the kind of a pod template owner.
+type Kind string
+
+const (
+	KindDeployment  Kind = "Deployment"
+	KindStatefulSet Kind = "StatefulSet"
+	KindDaemonSet   Kind = "DaemonSet"
+	KindReplicaSet  Kind = "ReplicaSet"
+	KindCronJob     Kind = "CronJob"
+)
+
+// Workload is a resource that owns a pod template.
+type Workload struct {
+	Kind Kind
+
+	// Object is the underlying API object, e.g. an *appsv1.Deployment.
+	Object runtime.Object
+
+	// Template is a pointer to the pod template embedded in Object.
+	// Modifying it modifies Object.
+	Template *corev1.PodTemplateSpec
+}
+
+// FromObject returns the Workload for the given object. The second return
+// value is false if the object does not own a pod template that should be
+// rolled directly, for example a ReplicaSet managed by a Deployment.
+func FromObject(obj interface{}) (*Workload, bool) {
+	switch o := obj.(type) {
+	case *appsv1.Deployment:
+		return &Workload{Kind: KindDeployment, Object: o, Template: &o.Spec.Template}, true
+	case *appsv1.StatefulSet:
+		return &Workload{Kind: KindStatefulSet, Object: o, Template: &o.Spec.Template}, true
+	case *appsv1.DaemonSet:
+		return &Workload{Kind: KindDaemonSet, Object: o, Template: &o.Spec.Template}, true
+	case *appsv1.ReplicaSet:
+		// ReplicaSets owned by a Deployment are rolled through the Deployment
+		if ref := metav1.GetControllerOf(o); ref != nil && ref.Kind == string(KindDeployment) {
+			return nil, false
+		}
+		return &Workload{Kind: KindReplicaSet, Object: o, Template: &o.Spec.Template}, true
+	case *batchv1beta1.CronJob:
+		return &Workload{Kind: KindCronJob, Object: o, Template: &o.Spec.JobTemplate.Spec.Template}, true
+	}
+	return nil, false
+}
+
+// Meta returns the object metadata of the workload.
+func (w *Workload) Meta() metav1.Object {
+	return w.Object.(metav1.Object)
+}
+
+// SecretNames returns the sorted names of all Secrets referenced by the
+// workload's pod template.
+func (w *Workload) SecretNames() []string {
+	return SecretNames(&w.Template.Spec)
+}
+
+// ReferencesSecret returns true if the workload's pod template references the
+// named Secret.
+func (w *Workload) ReferencesSecret(name string) bool {
+	for _, s := range w.SecretNames() {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
+
+// SecretNames returns the sorted names of all Secrets referenced by the given
+// pod spec through secret volumes, projected volumes, env[].valueFrom and
+// envFrom in both containers and init containers.
+func SecretNames(spec *corev1.PodSpec) []string {
+	names := sets.NewString()
+
+	for _, v := range spec.Volumes {
+		if v.Secret != nil && v.Secret.SecretName != "" {
+			names.Insert(v.Secret.SecretName)
+		}
+		if v.Projected != nil {
+			for _, src := range v.Projected.Sources {
+				if src.Secret != nil && src.Secret.Name != "" {
+					names.Insert(src.Secret.Name)
+				}
+			}
+		}
+	}
+
+	containers := append(append([]corev1.Container{}, spec.InitContainers...), spec.Containers...)
+	for _, c := range containers {
+		for _, e := range c.Env {
+			if e.ValueFrom != nil && e.ValueFrom.SecretKeyRef != nil && e.ValueFrom.SecretKeyRef.Name != "" {
+				names.Insert(e.ValueFrom.SecretKeyRef.Name)
+			}
+		}
+		for _, e := range c.EnvFrom {
+			if e.SecretRef != nil && e.SecretRef.Name != "" {
+				names.Insert(e.SecretRef.Name)
+			}
+		}
+	}
+
+	return names.List()
+}
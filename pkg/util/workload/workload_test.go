@@ -0,0 +1,214 @@
+/*
+Copyright 2019 The Jetstack cert-manager contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workload
+
+import (
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func podSpecWithAllRefs() corev1.PodSpec {
+	return corev1.PodSpec{
+		Volumes: []corev1.Volume{
+			{Name: "a", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "volume"}}},
+			{Name: "b", VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{
+				Sources: []corev1.VolumeProjection{
+					{Secret: &corev1.SecretProjection{LocalObjectReference: corev1.LocalObjectReference{Name: "projected"}}},
+					{ConfigMap: &corev1.ConfigMapProjection{LocalObjectReference: corev1.LocalObjectReference{Name: "configmap"}}},
+				},
+			}}},
+		},
+		InitContainers: []corev1.Container{
+			{EnvFrom: []corev1.EnvFromSource{{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "init-envfrom"}}}}},
+		},
+		Containers: []corev1.Container{
+			{
+				Env: []corev1.EnvVar{
+					{Name: "PLAIN", Value: "value"},
+					{Name: "KEY", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "env"}, Key: "tls.key"}}},
+				},
+				EnvFrom: []corev1.EnvFromSource{{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "envfrom"}}}},
+			},
+		},
+	}
+}
+
+func TestSecretNames(t *testing.T) {
+	spec := podSpecWithAllRefs()
+	expected := []string{"env", "envfrom", "init-envfrom", "projected", "volume"}
+	if actual := SecretNames(&spec); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
+
+func TestFromObject(t *testing.T) {
+	isController := true
+	tests := map[string]struct {
+		obj  interface{}
+		kind Kind
+		ok   bool
+	}{
+		"deployment":  {obj: &appsv1.Deployment{}, kind: KindDeployment, ok: true},
+		"statefulset": {obj: &appsv1.StatefulSet{}, kind: KindStatefulSet, ok: true},
+		"daemonset":   {obj: &appsv1.DaemonSet{}, kind: KindDaemonSet, ok: true},
+		"cronjob":     {obj: &batchv1beta1.CronJob{}, kind: KindCronJob, ok: true},
+		"replicaset":  {obj: &appsv1.ReplicaSet{}, kind: KindReplicaSet, ok: true},
+		"unrelated":   {obj: &corev1.Secret{}, ok: false},
+		"replicaset owned by a deployment": {
+			obj: &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{OwnerReferences: []metav1.OwnerReference{
+				{Kind: "Deployment", Name: "owner", Controller: &isController},
+			}}},
+			ok: false,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			w, ok := FromObject(test.obj)
+			if ok != test.ok {
+				t.Fatalf("expected ok=%t, got %t", test.ok, ok)
+			}
+			if ok && w.Kind != test.kind {
+				t.Errorf("expected kind %q, got %q", test.kind, w.Kind)
+			}
+		})
+	}
+}
+
+func TestSecretIndexFunc(t *testing.T) {
+	spec := podSpecWithAllRefs()
+	cj := &batchv1beta1.CronJob{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "cj"}}
+	cj.Spec.JobTemplate.Spec.Template.Spec.Containers = spec.Containers
+
+	keys, err := SecretIndexFunc(cj)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{"ns/env", "ns/envfrom"}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("expected index keys %v, got %v", expected, keys)
+	}
+	if keys, _ := SecretIndexFunc(&corev1.Secret{}); len(keys) != 0 {
+		t.Errorf("expected non-workloads to not be indexed, got %v", keys)
+	}
+}
+
+func TestRestart(t *testing.T) {
+	dep := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "dep"}}
+	cl := fake.NewSimpleClientset(dep)
+
+	w, err := Get(cl, KindDeployment, "ns", "dep")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := Restart(cl, w, "restarted", "now"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	updated, err := cl.AppsV1().Deployments("ns").Get("dep", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if updated.Labels["restarted"] != "now" || updated.Spec.Template.Labels["restarted"] != "now" {
+		t.Errorf("expected restart label to be set on deployment and its template, got %v and %v", updated.Labels, updated.Spec.Template.Labels)
+	}
+}
+
+func TestReplaceStalePod(t *testing.T) {
+	rs := &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "rs", UID: types.UID("rs-uid")},
+		Spec: appsv1.ReplicaSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "test"}},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "test", "restarted": "now"}},
+			},
+		},
+	}
+	isController := true
+	pod := func(name, restarted string, terminating bool) *corev1.Pod {
+		p := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns",
+			Name:      name,
+			Labels:    map[string]string{"app": "test"},
+			OwnerReferences: []metav1.OwnerReference{
+				{Kind: "ReplicaSet", Name: "rs", UID: rs.UID, Controller: &isController},
+			},
+		}}
+		if restarted != "" {
+			p.Labels["restarted"] = restarted
+		}
+		if terminating {
+			now := metav1.Now()
+			p.DeletionTimestamp = &now
+		}
+		return p
+	}
+	podNames := func(cl *fake.Clientset) []string {
+		pods, err := cl.CoreV1().Pods("ns").List(metav1.ListOptions{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var names []string
+		for _, p := range pods.Items {
+			names = append(names, p.Name)
+		}
+		return names
+	}
+
+	tests := map[string]struct {
+		pods              []runtime.Object
+		expectedStale     int
+		expectedRemaining []string
+	}{
+		"delete only one stale pod": {
+			pods:              []runtime.Object{pod("a", "", false), pod("b", "earlier", false), pod("c", "now", false)},
+			expectedStale:     2,
+			expectedRemaining: []string{"b", "c"},
+		},
+		"do not delete a pod while another is terminating": {
+			pods:              []runtime.Object{pod("a", "", true), pod("b", "", false)},
+			expectedStale:     1,
+			expectedRemaining: []string{"a", "b"},
+		},
+		"report no stale pods once all have been replaced": {
+			pods:              []runtime.Object{pod("a", "now", false), pod("b", "now", false)},
+			expectedRemaining: []string{"a", "b"},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cl := fake.NewSimpleClientset(append([]runtime.Object{rs}, test.pods...)...)
+			stale, err := ReplaceStalePod(cl, rs, "restarted")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if stale != test.expectedStale {
+				t.Errorf("expected %d stale pods, got %d", test.expectedStale, stale)
+			}
+			if names := podNames(cl); !reflect.DeepEqual(names, test.expectedRemaining) {
+				t.Errorf("expected remaining pods %v, got %v", test.expectedRemaining, names)
+			}
+		})
+	}
+}
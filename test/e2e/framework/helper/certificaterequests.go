@@ -19,6 +19,7 @@ package helper
 import (
 	"crypto"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/rsa"
 	"crypto/x509"
 	"fmt"
@@ -76,7 +77,7 @@ func (h *Helper) ValidateIssuedCertificateRequest(cr *v1alpha1.CertificateReques
 		return nil, fmt.Errorf("failed to decode CertificateRequest's Spec.CSRPEM: %s", err)
 	}
 
-	// validate private key is of the correct type (rsa or ecdsa)
+	// validate private key is of the correct type (rsa, ecdsa or ed25519)
 	switch csr.PublicKeyAlgorithm {
 	case x509.RSA:
 		_, ok := key.(*rsa.PrivateKey)
@@ -88,6 +89,11 @@ func (h *Helper) ValidateIssuedCertificateRequest(cr *v1alpha1.CertificateReques
 		if !ok {
 			return nil, fmt.Errorf("Expected private key of type ECDSA, but it was: %T", key)
 		}
+	case x509.Ed25519:
+		_, ok := key.(ed25519.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("Expected private key of type Ed25519, but it was: %T", key)
+		}
 	default:
 		return nil, fmt.Errorf("unrecognised requested private key algorithm %q", csr.PublicKeyAlgorithm)
 	}
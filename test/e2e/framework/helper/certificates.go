@@ -18,6 +18,7 @@ package helper
 
 import (
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/rsa"
 	"crypto/x509"
 	"fmt"
@@ -119,7 +120,7 @@ func (h *Helper) ValidateIssuedCertificate(certificate *v1alpha1.Certificate, ro
 		return nil, err
 	}
 
-	// validate private key is of the correct type (rsa or ecdsa)
+	// validate private key is of the correct type (rsa, ecdsa or ed25519)
 	switch certificate.Spec.KeyAlgorithm {
 	case v1alpha1.KeyAlgorithm(""),
 		v1alpha1.RSAKeyAlgorithm:
@@ -132,6 +133,11 @@ func (h *Helper) ValidateIssuedCertificate(certificate *v1alpha1.Certificate, ro
 		if !ok {
 			return nil, fmt.Errorf("Expected private key of type ECDSA, but it was: %T", key)
 		}
+	case v1alpha1.Ed25519KeyAlgorithm:
+		_, ok := key.(ed25519.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("Expected private key of type Ed25519, but it was: %T", key)
+		}
 	default:
 		return nil, fmt.Errorf("unrecognised requested private key algorithm %q", certificate.Spec.KeyAlgorithm)
 	}
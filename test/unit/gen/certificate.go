@@ -174,3 +174,45 @@ func SetCertificateKeyUsages(usages ...v1alpha1.KeyUsage) CertificateModifier {
 		cr.Spec.Usages = usages
 	}
 }
+
+func SetCertificatePodRefresh(cfg v1alpha1.PodRefreshConfig) CertificateModifier {
+	return func(crt *v1alpha1.Certificate) {
+		crt.Spec.PodRefresh = &cfg
+	}
+}
+
+func SetCertificateRotationPolicy(p v1alpha1.PrivateKeyRotationPolicy) CertificateModifier {
+	return func(crt *v1alpha1.Certificate) {
+		crt.Spec.RotationPolicy = p
+	}
+}
+
+func SetCertificateAnnotations(annotations map[string]string) CertificateModifier {
+	return func(crt *v1alpha1.Certificate) {
+		crt.SetAnnotations(annotations)
+	}
+}
+
+func SetCertificateRevokeOnDelete(revokeOnDelete bool) CertificateModifier {
+	return func(crt *v1alpha1.Certificate) {
+		crt.Spec.RevokeOnDelete = revokeOnDelete
+	}
+}
+
+func SetCertificateFinalizers(finalizers ...string) CertificateModifier {
+	return func(crt *v1alpha1.Certificate) {
+		crt.Finalizers = finalizers
+	}
+}
+
+func SetCertificateDeletionTimestamp(p metav1.Time) CertificateModifier {
+	return func(crt *v1alpha1.Certificate) {
+		crt.DeletionTimestamp = &p
+	}
+}
+
+func SetCertificateRevocationSerialNumber(serialNumber string) CertificateModifier {
+	return func(crt *v1alpha1.Certificate) {
+		crt.Status.RevocationSerialNumber = serialNumber
+	}
+}
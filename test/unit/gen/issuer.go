@@ -93,3 +93,9 @@ func AddIssuerCondition(c v1alpha1.IssuerCondition) IssuerModifier {
 		iss.GetStatus().Conditions = append(iss.GetStatus().Conditions, c)
 	}
 }
+
+func SetIssuerACMERateLimits(rateLimits ...v1alpha1.ACMERateLimit) IssuerModifier {
+	return func(iss v1alpha1.GenericIssuer) {
+		iss.GetStatus().ACMEStatus().RateLimits = rateLimits
+	}
+}
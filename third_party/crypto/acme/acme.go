@@ -30,6 +30,7 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
@@ -372,6 +373,46 @@ func (c *Client) UpdateAccount(ctx context.Context, a *Account) (*Account, error
 	return c.doAccount(ctx, a.URL, false, a)
 }
 
+// AccountKeyRollover attempts to transition a client's account key to a new
+// key. On success the client's Key is updated, and the account URL remains
+// unchanged. The new key must not already be in use by another account.
+// See https://tools.ietf.org/html/rfc8555#section-7.3.5.
+func (c *Client) AccountKeyRollover(ctx context.Context, newKey crypto.Signer) error {
+	if _, err := c.Discover(ctx); err != nil {
+		return err
+	}
+	accountURL, err := c.cacheAccountURL(ctx)
+	if err != nil {
+		return err
+	}
+	oldKey, err := jwkEncode(c.Key.Public())
+	if err != nil {
+		return err
+	}
+	payload := struct {
+		Account string          `json:"account"`
+		OldKey  json.RawMessage `json:"oldKey"`
+	}{
+		Account: accountURL,
+		OldKey:  json.RawMessage(oldKey),
+	}
+	// the inner JWS is signed with the new key, and must not have a nonce
+	inner, err := jwsEncodeJSON(payload, newKey, "", c.dir.KeyChangeURL, "")
+	if err != nil {
+		return err
+	}
+	res, err := c.retryPostJWS(ctx, c.Key, accountURL, c.dir.KeyChangeURL, json.RawMessage(inner))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return responseError(res)
+	}
+	c.Key = newKey
+	return nil
+}
+
 // GetAuthorization retrieves an authorization identified by the given URL.
 //
 // If a caller needs to poll an authorization until its status is final,
@@ -563,9 +604,10 @@ func (c *Client) HTTP01ChallengePath(token string) string {
 // the Account. Only the Contact field can be updated.
 func (c *Client) doAccount(ctx context.Context, url string, getExistingWithKey bool, acct *Account) (*Account, error) {
 	req := struct {
-		Contact     []string `json:"contact,omitempty"`
-		TermsAgreed bool     `json:"termsOfServiceAgreed,omitempty"`
-		GetExisting bool     `json:"onlyReturnExisting,omitempty"`
+		Contact     []string        `json:"contact,omitempty"`
+		TermsAgreed bool            `json:"termsOfServiceAgreed,omitempty"`
+		GetExisting bool            `json:"onlyReturnExisting,omitempty"`
+		EAB         json.RawMessage `json:"externalAccountBinding,omitempty"`
 	}{
 		GetExisting: getExistingWithKey,
 	}
@@ -576,6 +618,13 @@ func (c *Client) doAccount(ctx context.Context, url string, getExistingWithKey b
 	if acct != nil {
 		req.Contact = acct.Contact
 		req.TermsAgreed = acct.TermsAgreed
+		if acct.ExternalAccountBinding != nil && url == c.dir.NewAccountURL {
+			eab, err := jwsEncodeEAB(c.Key.Public(), acct.ExternalAccountBinding, url)
+			if err != nil {
+				return nil, err
+			}
+			req.EAB = eab
+		}
 	}
 	res, err := c.retryPostJWS(ctx, c.Key, accountURL, url, req)
 	if err != nil {
@@ -868,6 +917,61 @@ func (c *Client) GetCertificate(ctx context.Context, url string) ([][]byte, erro
 	return chain, nil
 }
 
+// ListCertAlternates retrieves any alternate certificate chain URLs for the
+// given certificate chain URL. These alternate URLs can be passed to
+// GetCertificate in order to retrieve the alternate certificate chains.
+//
+// If there are no alternate issuer certificate chains, a nil slice will be
+// returned.
+// See https://tools.ietf.org/html/rfc8555#section-7.4.2.
+func (c *Client) ListCertAlternates(ctx context.Context, url string) ([]string, error) {
+	if _, err := c.Discover(ctx); err != nil {
+		return nil, err
+	}
+
+	res, err := c.postWithJWSAccount(ctx, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, responseError(res)
+	}
+
+	// We don't need the body but we need to discard it so we don't end up
+	// preventing keep-alive
+	if _, err := io.Copy(ioutil.Discard, res.Body); err != nil {
+		return nil, fmt.Errorf("acme: cert alternates response stream: %v", err)
+	}
+	return linkHeader(url, res.Header, "alternate")
+}
+
+// linkHeader returns the URLs of all Link headers with the given relation
+// type, resolved against the base URL.
+// See https://tools.ietf.org/html/rfc8288#section-3 for details.
+func linkHeader(base string, h http.Header, rel string) ([]string, error) {
+	u, err := url.Parse(base)
+	if err != nil {
+		return nil, err
+	}
+	var links []string
+	for _, v := range h["Link"] {
+		parts := strings.Split(v, ";")
+		for _, p := range parts[1:] {
+			p = strings.TrimSpace(p)
+			if !strings.HasPrefix(p, "rel=") || strings.Trim(p[4:], `"`) != rel {
+				continue
+			}
+			l, err := u.Parse(strings.Trim(strings.TrimSpace(parts[0]), "<>"))
+			if err != nil {
+				return nil, fmt.Errorf("acme: error parsing Link: %s", err)
+			}
+			links = append(links, l.String())
+		}
+	}
+	return links, nil
+}
+
 // responseError creates an error of Error type from resp.
 func responseError(resp *http.Response) error {
 	// don't care if ReadAll returns an error:
@@ -160,6 +160,61 @@ func TestCreateAccount(t *testing.T) {
 	}
 }
 
+func TestCreateAccountWithExternalAccountBinding(t *testing.T) {
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "HEAD" {
+			w.Header().Set("Replay-Nonce", "test-nonce")
+			return
+		}
+
+		var j struct {
+			ExternalAccountBinding struct {
+				Protected string
+				Payload   string
+			}
+		}
+		decodeJWSRequest(t, &j, r)
+
+		b, err := base64.RawURLEncoding.DecodeString(j.ExternalAccountBinding.Protected)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var head struct{ Alg, KID, URL string }
+		if err := json.Unmarshal(b, &head); err != nil {
+			t.Fatal(err)
+		}
+		if head.Alg != "HS256" || head.KID != "test-kid" || head.URL != ts.URL {
+			t.Errorf("eab head = %+v; want alg HS256, kid test-kid, url %s", head, ts.URL)
+		}
+		payload, err := base64.RawURLEncoding.DecodeString(j.ExternalAccountBinding.Payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if jwk, _ := jwkEncode(testKeyEC.Public()); string(payload) != jwk {
+			t.Errorf("eab payload = %s; want %s", payload, jwk)
+		}
+
+		w.Header().Set("Location", "https://example.com/acme/account/1")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"status":"valid"}`)
+	}))
+	defer ts.Close()
+
+	c := Client{Key: testKeyEC, dir: &Directory{NewAccountURL: ts.URL, NewNonceURL: ts.URL}}
+	a := &Account{
+		TermsAgreed: true,
+		ExternalAccountBinding: &ExternalAccountBinding{
+			KID:       "test-kid",
+			Key:       []byte("test-hmac-key"),
+			Algorithm: "HS256",
+		},
+	}
+	if _, err := c.CreateAccount(context.Background(), a); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func TestUpdateAccount(t *testing.T) {
 	contacts := []string{"mailto:admin@example.com"}
 
@@ -898,6 +953,107 @@ func TestRevokeCert(t *testing.T) {
 	}
 }
 
+func TestAccountKeyRollover(t *testing.T) {
+	const accountURL = "https://example.com/acme/account"
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "HEAD" {
+			w.Header().Set("Replay-Nonce", "nonce")
+			return
+		}
+
+		var outer struct{ Protected, Payload string }
+		if err := json.NewDecoder(r.Body).Decode(&outer); err != nil {
+			t.Fatal(err)
+		}
+		b, _ := base64.RawURLEncoding.DecodeString(outer.Protected)
+		var outerHead struct{ Alg, KID, Nonce string }
+		if err := json.Unmarshal(b, &outerHead); err != nil {
+			t.Fatal(err)
+		}
+		if outerHead.Alg != "RS256" || outerHead.KID != accountURL || outerHead.Nonce != "nonce" {
+			t.Errorf("outer head = %+v; want alg RS256, kid %s, nonce nonce", outerHead, accountURL)
+		}
+
+		b, _ = base64.RawURLEncoding.DecodeString(outer.Payload)
+		var inner struct{ Protected, Payload string }
+		if err := json.Unmarshal(b, &inner); err != nil {
+			t.Fatal(err)
+		}
+		b, _ = base64.RawURLEncoding.DecodeString(inner.Protected)
+		var innerHead struct {
+			Alg   string
+			Nonce *string
+			URL   string
+			JWK   map[string]string `json:"jwk"`
+		}
+		if err := json.Unmarshal(b, &innerHead); err != nil {
+			t.Fatal(err)
+		}
+		if innerHead.Alg != "ES256" {
+			t.Errorf("innerHead.Alg = %q; want ES256", innerHead.Alg)
+		}
+		if innerHead.Nonce != nil {
+			t.Errorf("innerHead.Nonce = %q; want no nonce", *innerHead.Nonce)
+		}
+		if innerHead.URL != ts.URL {
+			t.Errorf("innerHead.URL = %q; want %q", innerHead.URL, ts.URL)
+		}
+		if innerHead.JWK["x"] != testKeyECPubX || innerHead.JWK["y"] != testKeyECPubY {
+			t.Errorf("innerHead.JWK = %v; want the new key", innerHead.JWK)
+		}
+
+		b, _ = base64.RawURLEncoding.DecodeString(inner.Payload)
+		var req struct {
+			Account string
+			OldKey  map[string]string
+		}
+		if err := json.Unmarshal(b, &req); err != nil {
+			t.Fatal(err)
+		}
+		if req.Account != accountURL {
+			t.Errorf("req.Account = %q; want %q", req.Account, accountURL)
+		}
+		if req.OldKey["kty"] != "RSA" {
+			t.Errorf("req.OldKey = %v; want the old RSA key", req.OldKey)
+		}
+	}))
+	defer ts.Close()
+
+	client := &Client{Key: testKey, accountURL: accountURL, dir: &Directory{KeyChangeURL: ts.URL, NewNonceURL: ts.URL}}
+	if err := client.AccountKeyRollover(context.Background(), testKeyEC); err != nil {
+		t.Fatal(err)
+	}
+	if client.Key != testKeyEC {
+		t.Error("client.Key was not updated to the new key")
+	}
+}
+
+func TestListCertAlternates(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "HEAD" {
+			w.Header().Set("Replay-Nonce", "nonce")
+			return
+		}
+		w.Header().Add("Link", `<https://example.com/acme/cert/1/alt/1>;rel="alternate"`)
+		w.Header().Add("Link", `</acme/cert/1/alt/2>; rel="alternate"`)
+		w.Header().Add("Link", `<https://example.com/acme/directory>;rel="index"`)
+		w.Header().Set("Content-Type", "application/pem-certificate-chain")
+		fmt.Fprint(w, "-----BEGIN CERTIFICATE-----\n-----END CERTIFICATE-----\n")
+	}))
+	defer ts.Close()
+
+	client := &Client{Key: testKeyEC, accountURL: "https://example.com/acme/account", dir: &Directory{NewNonceURL: ts.URL}}
+	alternates, err := client.ListCertAlternates(context.Background(), ts.URL+"/acme/cert/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"https://example.com/acme/cert/1/alt/1", ts.URL + "/acme/cert/1/alt/2"}
+	if !reflect.DeepEqual(alternates, expected) {
+		t.Errorf("alternates = %v; want %v", alternates, expected)
+	}
+}
+
 func TestNonce_add(t *testing.T) {
 	var c Client
 	c.addNonce(http.Header{"Replay-Nonce": {"nonce"}})
@@ -7,17 +7,21 @@ package acme
 import (
 	"crypto"
 	"crypto/ecdsa"
+	"crypto/hmac"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/sha256"
-	_ "crypto/sha512" // need for EC keys
+	"crypto/sha512"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"hash"
 	"math/big"
 )
 
 // jwsEncodeJSON signs claimset using provided key and a nonce.
+// The nonce is omitted from the protected header if it is empty, as is
+// required for the inner JWS of an account key rollover request.
 // The result is serialized in JSON format.
 // See https://tools.ietf.org/html/rfc7515#section-7.
 func jwsEncodeJSON(claimset interface{}, key crypto.Signer, accountURL, url, nonce string) ([]byte, error) {
@@ -31,10 +35,14 @@ func jwsEncodeJSON(claimset interface{}, key crypto.Signer, accountURL, url, non
 		if err != nil {
 			return nil, err
 		}
-		phead = fmt.Sprintf(`{"alg":%q,"jwk":%s,"nonce":%q,"url":%q}`, alg, jwk, nonce, url)
+		phead = fmt.Sprintf(`{"alg":%q,"jwk":%s`, alg, jwk)
 	} else {
-		phead = fmt.Sprintf(`{"alg":%q,"kid":%q,"nonce":%q,"url":%q}`, alg, accountURL, nonce, url)
+		phead = fmt.Sprintf(`{"alg":%q,"kid":%q`, alg, accountURL)
 	}
+	if nonce != "" {
+		phead += fmt.Sprintf(`,"nonce":%q`, nonce)
+	}
+	phead += fmt.Sprintf(`,"url":%q}`, url)
 	phead = base64.RawURLEncoding.EncodeToString([]byte(phead))
 	payload := ""
 	if claimset != nil {
@@ -63,6 +71,45 @@ func jwsEncodeJSON(claimset interface{}, key crypto.Signer, accountURL, url, non
 	return json.Marshal(&enc)
 }
 
+// jwsEncodeEAB returns the External Account Binding JWS for the given
+// account public key, which is a JWS over the account JWK signed with the
+// MAC key of the external account.
+// See https://tools.ietf.org/html/rfc8555#section-7.3.4.
+func jwsEncodeEAB(pub crypto.PublicKey, eab *ExternalAccountBinding, url string) ([]byte, error) {
+	var h func() hash.Hash
+	switch eab.Algorithm {
+	case "HS256":
+		h = sha256.New
+	case "HS384":
+		h = sha512.New384
+	case "HS512":
+		h = sha512.New
+	default:
+		return nil, fmt.Errorf("acme: unsupported external account binding algorithm %q", eab.Algorithm)
+	}
+	jwk, err := jwkEncode(pub)
+	if err != nil {
+		return nil, err
+	}
+	phead := fmt.Sprintf(`{"alg":%q,"kid":%q,"url":%q}`, eab.Algorithm, eab.KID, url)
+	phead = base64.RawURLEncoding.EncodeToString([]byte(phead))
+	payload := base64.RawURLEncoding.EncodeToString([]byte(jwk))
+
+	mac := hmac.New(h, eab.Key)
+	mac.Write([]byte(phead + "." + payload))
+
+	enc := struct {
+		Protected string `json:"protected"`
+		Payload   string `json:"payload"`
+		Sig       string `json:"signature"`
+	}{
+		Protected: phead,
+		Payload:   payload,
+		Sig:       base64.RawURLEncoding.EncodeToString(mac.Sum(nil)),
+	}
+	return json.Marshal(&enc)
+}
+
 // jwkEncode encodes public part of an RSA or ECDSA key into a JWK.
 // The result is also suitable for creating a JWK thumbprint.
 // https://tools.ietf.org/html/rfc7517
@@ -7,12 +7,16 @@ package acme
 import (
 	"crypto/ecdsa"
 	"crypto/elliptic"
+	"crypto/hmac"
 	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
 	"crypto/x509"
 	"encoding/base64"
 	"encoding/json"
 	"encoding/pem"
 	"fmt"
+	"hash"
 	"math/big"
 	"testing"
 )
@@ -242,6 +246,79 @@ func TestJWSEncodeJSONEC(t *testing.T) {
 	}
 }
 
+func TestJWSEncodeEAB(t *testing.T) {
+	key := []byte("test-hmac-key")
+	tt := []struct {
+		alg  string
+		hash func() hash.Hash
+	}{
+		{"HS256", sha256.New},
+		{"HS384", sha512.New384},
+		{"HS512", sha512.New},
+	}
+	for i, test := range tt {
+		eab := &ExternalAccountBinding{KID: "test-kid", Key: key, Algorithm: test.alg}
+		b, err := jwsEncodeEAB(testKeyEC.Public(), eab, "https://example.com/new-account")
+		if err != nil {
+			t.Errorf("%d: %v", i, err)
+			continue
+		}
+		var jws struct{ Protected, Payload, Signature string }
+		if err := json.Unmarshal(b, &jws); err != nil {
+			t.Errorf("%d: %v", i, err)
+			continue
+		}
+
+		b, err = base64.RawURLEncoding.DecodeString(jws.Protected)
+		if err != nil {
+			t.Errorf("%d: jws.Protected: %v", i, err)
+		}
+		var head struct {
+			Alg   string
+			KID   string
+			URL   string
+			Nonce string
+		}
+		if err := json.Unmarshal(b, &head); err != nil {
+			t.Errorf("%d: jws.Protected: %v", i, err)
+		}
+		if head.Alg != test.alg {
+			t.Errorf("%d: head.Alg = %q; want %q", i, head.Alg, test.alg)
+		}
+		if head.KID != "test-kid" {
+			t.Errorf("%d: head.KID = %q; want test-kid", i, head.KID)
+		}
+		if head.URL != "https://example.com/new-account" {
+			t.Errorf("%d: head.URL = %q; want https://example.com/new-account", i, head.URL)
+		}
+		if head.Nonce != "" {
+			t.Errorf("%d: head.Nonce = %q; want empty", i, head.Nonce)
+		}
+
+		payload, err := base64.RawURLEncoding.DecodeString(jws.Payload)
+		if err != nil {
+			t.Errorf("%d: jws.Payload: %v", i, err)
+		}
+		jwk, _ := jwkEncode(testKeyEC.Public())
+		if string(payload) != jwk {
+			t.Errorf("%d: payload = %s; want %s", i, payload, jwk)
+		}
+
+		mac := hmac.New(test.hash, key)
+		mac.Write([]byte(jws.Protected + "." + jws.Payload))
+		if sig := base64.RawURLEncoding.EncodeToString(mac.Sum(nil)); jws.Signature != sig {
+			t.Errorf("%d: jws.Signature = %q; want %q", i, jws.Signature, sig)
+		}
+	}
+}
+
+func TestJWSEncodeEABUnsupportedAlgorithm(t *testing.T) {
+	eab := &ExternalAccountBinding{KID: "test-kid", Key: []byte("key"), Algorithm: "RS256"}
+	if _, err := jwsEncodeEAB(testKeyEC.Public(), eab, "https://example.com"); err == nil {
+		t.Error("jwsEncodeEAB: expected an error for an unsupported algorithm")
+	}
+}
+
 func TestJWKThumbprintRSA(t *testing.T) {
 	// Key example from RFC 7638
 	const base64N = "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAt" +
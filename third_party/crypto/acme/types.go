@@ -156,6 +156,25 @@ type Account struct {
 	// OrdersURL is the URL used to fetch a list of orders submitted by this
 	// account.
 	OrdersURL string
+
+	// ExternalAccountBinding, if set, binds the account to an existing account
+	// with the CA when it is created. It is ignored for existing accounts.
+	// See https://tools.ietf.org/html/rfc8555#section-7.3.4.
+	ExternalAccountBinding *ExternalAccountBinding
+}
+
+// ExternalAccountBinding holds the key identifier and MAC key provided by
+// the CA out of band, used to bind an ACME account to an external account.
+type ExternalAccountBinding struct {
+	// KID is the key identifier of the external account.
+	KID string
+
+	// Key is the decoded MAC key of the external account.
+	Key []byte
+
+	// Algorithm is the JWS MAC algorithm used to sign the binding. Valid
+	// values are "HS256", "HS384" and "HS512".
+	Algorithm string
 }
 
 // Directory is ACME server discovery data.